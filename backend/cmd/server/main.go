@@ -11,15 +11,21 @@ import (
 
 	"app-env-manager/internal/api/handlers"
 	"app-env-manager/internal/api/routes"
+	"app-env-manager/internal/domain/entities"
 	"app-env-manager/internal/infrastructure/config"
 	"app-env-manager/internal/infrastructure/database"
 	"app-env-manager/internal/repository/mongodb"
 	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/approval"
 	"app-env-manager/internal/service/auth"
+	"app-env-manager/internal/service/bulk"
 	"app-env-manager/internal/service/environment"
 	"app-env-manager/internal/service/health"
 	"app-env-manager/internal/service/log"
+	"app-env-manager/internal/service/remotelog"
+	"app-env-manager/internal/service/rollout"
 	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/terminal"
 	"app-env-manager/internal/service/user"
 	"app-env-manager/internal/websocket/hub"
 	"github.com/sirupsen/logrus"
@@ -59,12 +65,19 @@ func main() {
 	auditRepo := mongodb.NewAuditLogRepository(mongoDB.Database())
 	logRepo := mongodb.NewLogRepository(mongoDB.Database())
 	userRepo := mongodb.NewUserRepository(mongoDB.Database())
+	approvalRepo := mongodb.NewApprovalRepository(mongoDB.Database())
+	bulkRepo := mongodb.NewBulkOperationRepository(mongoDB.Database())
+	rolloutRepo := mongodb.NewRolloutRepository(mongoDB.Database())
 
 	// Initialize services
+	if err := ssh.ValidateRules(cfg.SSH.CommandAllowlist); err != nil {
+		logger.WithError(err).Fatal("Invalid SSH command allowlist")
+	}
 	sshManager := ssh.NewManager(ssh.Config{
 		ConnectionTimeout: cfg.SSH.ConnectionTimeout,
 		CommandTimeout:    cfg.SSH.CommandTimeout,
 		MaxConnections:    cfg.SSH.MaxConnections,
+		Allowlist:         cfg.SSH.CommandAllowlist,
 	})
 	defer sshManager.Close()
 
@@ -94,13 +107,56 @@ func main() {
 		logService,
 		cfg.Security.AllowedHosts,
 	)
+	envService.SetHealthPolicy(environment.HealthPolicy{
+		Retries:         cfg.Health.MaxRetries,
+		RetryBackoff:    cfg.Health.RetryBackoff,
+		FlapTransitions: cfg.Health.FlapTransitions,
+		FlapWindow:      cfg.Health.FlapWindow,
+	})
+
+	approvalService := approval.NewService(
+		approvalRepo,
+		envRepo,
+		auditRepo,
+		logService,
+		envService,
+		cfg.Approval.Expiry,
+		entities.UserRole(cfg.Approval.ApproverRole),
+	)
+
+	bulkService := bulk.NewService(bulkRepo, envRepo, envService, cfg.Bulk.MaxParallel)
+
+	rolloutService := rollout.NewService(rolloutRepo, envRepo, envService, 10*time.Second)
+
+	terminalService := terminal.NewService(envRepo, auditRepo, sshManager, envService, terminal.Config{
+		MinRole:      entities.UserRole(cfg.Terminal.MinRole),
+		IdleTimeout:  cfg.Terminal.IdleTimeout,
+		MaxDuration:  cfg.Terminal.MaxDuration,
+		RecordingDir: cfg.Terminal.RecordingDir,
+	})
+
+	remoteLogService := remotelog.NewService(envRepo, sshManager, envService, remotelog.Config{
+		DefaultLines:      cfg.RemoteLogs.DefaultLines,
+		MaxLines:          cfg.RemoteLogs.MaxLines,
+		MaxTailsPerHost:   cfg.RemoteLogs.MaxTailsPerHost,
+		LinesPerSecond:    cfg.RemoteLogs.LinesPerSecond,
+		MaxFollowDuration: cfg.RemoteLogs.MaxFollowDuration,
+	})
 
 	// Initialize WebSocket hub
 	wsHub := hub.NewHub(logger)
 	go wsHub.Run()
+	envService.OnHealthChange(func(env *entities.Environment, status entities.Status) {
+		wsHub.BroadcastEnvironmentStatus(env.ID.Hex(), status)
+	})
 
 	// Initialize handlers
-	envHandler := handlers.NewEnvironmentHandler(envService, wsHub, logger)
+	envHandler := handlers.NewEnvironmentHandler(envService, approvalService, wsHub, logger)
+	approvalHandler := handlers.NewApprovalHandler(approvalService, wsHub, logger)
+	bulkHandler := handlers.NewBulkOperationHandler(bulkService, wsHub, logger)
+	rolloutHandler := handlers.NewRolloutHandler(rolloutService, wsHub, logger)
+	terminalHandler := handlers.NewTerminalHandler(terminalService, logger)
+	remoteLogHandler := handlers.NewRemoteLogHandler(remoteLogService, logger)
 	logHandler := handlers.NewLogHandler(logService, logger)
 	authHandler := handlers.NewAuthHandler(authService, logger)
 	userHandler := handlers.NewUserHandler(userService, logger)
@@ -111,6 +167,11 @@ func main() {
 		LogHandler:        logHandler,
 		AuthHandler:       authHandler,
 		UserHandler:       userHandler,
+		ApprovalHandler:   approvalHandler,
+		BulkHandler:       bulkHandler,
+		RolloutHandler:    rolloutHandler,
+		TerminalHandler:   terminalHandler,
+		RemoteLogHandler:  remoteLogHandler,
 		AuthService:       authService,
 		UserService:       userService,
 		WebSocketHub:      wsHub,
@@ -10,6 +10,7 @@ import (
 type RestartRequest struct {
 	Force           bool `json:"force"`
 	GracefulTimeout int  `json:"gracefulTimeout,omitempty"`
+	DryRun          bool `json:"dryRun,omitempty"`
 }
 
 
@@ -18,6 +19,17 @@ type UpgradeRequest struct {
 	Version           string `json:"version"`
 	BackupFirst       bool   `json:"backupFirst"`
 	RollbackOnFailure bool   `json:"rollbackOnFailure"`
+	DryRun            bool   `json:"dryRun,omitempty"`
+}
+
+// ActionRequest represents a custom action request
+type ActionRequest struct {
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ExecRequest represents an ad-hoc command request
+type ExecRequest struct {
+	Command string `json:"command"`
 }
 
 // Response DTOs
@@ -75,6 +87,23 @@ type OperationResponse struct {
 	StartedAt   string `json:"startedAt,omitempty"`
 }
 
+// ApprovalPendingResponse is returned when an operation is held for approval
+type ApprovalPendingResponse struct {
+	ApprovalID string `json:"approvalId"`
+	Status     string `json:"status"`
+	ExpiresAt  string `json:"expiresAt"`
+}
+
+// ApprovalDecisionRequest represents an approve or reject request
+type ApprovalDecisionRequest struct {
+	Comment string `json:"comment"`
+}
+
+// ListApprovalsResponse represents a list of approval requests
+type ListApprovalsResponse struct {
+	Approvals []*entities.ApprovalRequest `json:"approvals"`
+}
+
 // MessageResponse represents a simple message response
 type MessageResponse struct {
 	Message string `json:"message"`
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"app-env-manager/internal/api/dto"
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/approval"
+	"app-env-manager/internal/websocket/hub"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// ApprovalHandler handles approval-request HTTP requests
+type ApprovalHandler struct {
+	service *approval.Service
+	hub     *hub.Hub
+	logger  *logrus.Logger
+}
+
+// NewApprovalHandler creates a new approval handler
+func NewApprovalHandler(service *approval.Service, hub *hub.Hub, logger *logrus.Logger) *ApprovalHandler {
+	return &ApprovalHandler{
+		service: service,
+		hub:     hub,
+		logger:  logger,
+	}
+}
+
+// List handles GET /approvals
+func (h *ApprovalHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := interfaces.ApprovalFilter{
+		EnvironmentID: query.Get("environmentId"),
+		RequesterID:   query.Get("requesterId"),
+		Pagination: &interfaces.Pagination{
+			Page:  1,
+			Limit: 100,
+		},
+	}
+
+	if status := query.Get("status"); status != "" {
+		approvalStatus := entities.ApprovalStatus(status)
+		filter.Status = &approvalStatus
+	}
+
+	approvals, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, dto.ListApprovalsResponse{Approvals: approvals})
+}
+
+// Get handles GET /approvals/{id}
+func (h *ApprovalHandler) Get(w http.ResponseWriter, r *http.Request) {
+	req, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, req)
+}
+
+// Approve handles POST /approvals/{id}/approve and starts the approved
+// operation asynchronously
+func (h *ApprovalHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	var body dto.ApprovalDecisionRequest
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	req, err := h.service.Approve(r.Context(), mux.Vars(r)["id"], body.Comment)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	h.broadcast(req, "approved")
+	go h.execute(req)
+
+	writeJSON(w, http.StatusAccepted, dto.OperationResponse{
+		OperationID: req.OperationID,
+		Status:      "in_progress",
+	})
+}
+
+// Reject handles POST /approvals/{id}/reject
+func (h *ApprovalHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	var body dto.ApprovalDecisionRequest
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	req, err := h.service.Reject(r.Context(), mux.Vars(r)["id"], body.Comment)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	h.broadcast(req, "rejected")
+
+	writeJSON(w, http.StatusOK, req)
+}
+
+// execute runs an approved operation as the original requester and reports
+// the outcome over the WebSocket hub. The environment's timeout for the
+// operation bounds it.
+func (h *ApprovalHandler) execute(req *entities.ApprovalRequest) {
+	ctx := ctxutil.WithUser(context.Background(), req.RequestedBy.ID, req.RequestedBy.Name)
+
+	fields := logrus.Fields{
+		"operationId":   req.OperationID,
+		"approvalId":    req.ID.Hex(),
+		"environmentId": req.EnvironmentID.Hex(),
+		"operation":     req.Operation,
+	}
+	h.logger.WithFields(fields).Info("Starting approved operation")
+
+	if err := h.service.Execute(ctx, req); err != nil {
+		h.logger.WithError(err).WithFields(fields).Error("Approved operation failed")
+		h.hub.BroadcastOperationUpdate(req.OperationID, map[string]interface{}{
+			"status": "failed",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	h.logger.WithFields(fields).Info("Approved operation completed")
+	h.hub.BroadcastOperationUpdate(req.OperationID, map[string]interface{}{
+		"status": "completed",
+	})
+}
+
+// broadcast notifies approvers that a request has been decided
+func (h *ApprovalHandler) broadcast(req *entities.ApprovalRequest, action string) {
+	h.hub.BroadcastApprovalUpdate(req.ID.Hex(), h.service.ApproverRoles(), map[string]interface{}{
+		"action":   action,
+		"approval": req,
+	})
+}
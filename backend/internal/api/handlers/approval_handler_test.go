@@ -0,0 +1,229 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/api/handlers"
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/approval"
+	"app-env-manager/internal/service/environment"
+	"app-env-manager/internal/service/health"
+	"app-env-manager/internal/service/log"
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/websocket/hub"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// approvalTestMockRepo satisfies interfaces.ApprovalRepository for handler tests.
+type approvalTestMockRepo struct{ mock.Mock }
+
+func (m *approvalTestMockRepo) Create(ctx context.Context, req *entities.ApprovalRequest) error {
+	return m.Called(ctx, req).Error(0)
+}
+func (m *approvalTestMockRepo) GetByID(ctx context.Context, id string) (*entities.ApprovalRequest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ApprovalRequest), args.Error(1)
+}
+func (m *approvalTestMockRepo) List(ctx context.Context, filter interfaces.ApprovalFilter) ([]*entities.ApprovalRequest, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.ApprovalRequest), args.Error(1)
+}
+func (m *approvalTestMockRepo) UpdateIfStatus(ctx context.Context, id string, expected entities.ApprovalStatus, req *entities.ApprovalRequest) error {
+	return m.Called(ctx, id, expected, req).Error(0)
+}
+func (m *approvalTestMockRepo) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// approvalSetup wires environment and approval handlers against mocked repos.
+type approvalSetup struct {
+	envRepo      *envTestMockEnvRepo
+	approvalRepo *approvalTestMockRepo
+	envHandler   *handlers.EnvironmentHandler
+	handler      *handlers.ApprovalHandler
+}
+
+func newApprovalSetup(t *testing.T) *approvalSetup {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	envRepo := new(envTestMockEnvRepo)
+	logRepo := new(MockLogRepository)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	auditRepo := new(envTestMockAuditRepo)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	approvalRepo := new(approvalTestMockRepo)
+	logSvc := log.NewService(logRepo)
+
+	sshMgr := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: time.Second,
+		CommandTimeout:    time.Second,
+		MaxConnections:    1,
+	})
+	svc := environment.NewService(envRepo, auditRepo, sshMgr, health.NewChecker(time.Second), logSvc, nil)
+	approvals := approval.NewService(approvalRepo, envRepo, auditRepo, logSvc, svc, time.Hour, entities.UserRoleAdmin)
+
+	h := hub.NewHub(logger)
+	go h.Run()
+
+	return &approvalSetup{
+		envRepo:      envRepo,
+		approvalRepo: approvalRepo,
+		envHandler:   handlers.NewEnvironmentHandler(svc, approvals, h, logger),
+		handler:      handlers.NewApprovalHandler(approvals, h, logger),
+	}
+}
+
+func withUser(r *http.Request, id, name string, role entities.UserRole) *http.Request {
+	return r.WithContext(ctxutil.WithUserFull(r.Context(), id, name, string(role)))
+}
+
+func TestEnvironmentHandler_Restart_RequiresApproval(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.RequiresApproval = true
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	s.approvalRepo.On("Create", mock.Anything, mock.AnythingOfType("*entities.ApprovalRequest")).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/environments/"+env.ID.Hex()+"/restart", bytes.NewBufferString(`{"force":true}`))
+	req = mux.SetURLVars(withUser(req, "u1", "alice", entities.UserRoleUser), map[string]string{"id": env.ID.Hex()})
+	w := httptest.NewRecorder()
+
+	s.envHandler.Restart(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), "pending_approval")
+	s.approvalRepo.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestEnvironmentHandler_RunAction_RequiresApproval(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.RequiresApproval = true
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	s.approvalRepo.On("Create", mock.Anything, mock.MatchedBy(func(req *entities.ApprovalRequest) bool {
+		return req.Operation == entities.ApprovalOperationCustomAction && req.Parameters["action"] == "flush-cache"
+	})).Return(nil)
+
+	req := httptest.NewRequest("POST", "/api/environments/"+env.ID.Hex()+"/actions/flush-cache", nil)
+	req = mux.SetURLVars(withUser(req, "u1", "alice", entities.UserRoleUser),
+		map[string]string{"id": env.ID.Hex(), "name": "flush-cache"})
+	w := httptest.NewRecorder()
+
+	s.envHandler.RunAction(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), "pending_approval")
+	assert.NotContains(t, w.Body.String(), "in_progress")
+	s.approvalRepo.AssertExpectations(t)
+}
+
+func TestApprovalHandler_List(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	pending := entities.NewApprovalRequest(env, entities.ApprovalOperationRestart, nil,
+		entities.Actor{Type: "user", ID: "u1", Name: "alice"}, time.Hour)
+	s.approvalRepo.On("ExpirePending", mock.Anything, mock.Anything).Return(int64(0), nil)
+	s.approvalRepo.On("List", mock.Anything, mock.MatchedBy(func(f interfaces.ApprovalFilter) bool {
+		return f.Status != nil && *f.Status == entities.ApprovalStatusPending
+	})).Return([]*entities.ApprovalRequest{pending}, nil)
+
+	req := httptest.NewRequest("GET", "/api/approvals?status=pending", nil)
+	w := httptest.NewRecorder()
+
+	s.handler.List(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	data := resp["data"].(map[string]interface{})
+	assert.Len(t, data["approvals"], 1)
+}
+
+func TestApprovalHandler_Get_NotFound(t *testing.T) {
+	s := newApprovalSetup(t)
+	id := primitive.NewObjectID().Hex()
+	s.approvalRepo.On("ExpirePending", mock.Anything, mock.Anything).Return(int64(0), nil)
+	s.approvalRepo.On("GetByID", mock.Anything, id).Return(nil, errors.ErrApprovalNotFound)
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/api/approvals/"+id, nil), map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	s.handler.Get(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestApprovalHandler_Approve(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	pending := entities.NewApprovalRequest(env, entities.ApprovalOperationRestart, nil,
+		entities.Actor{Type: "user", ID: "u1", Name: "alice"}, time.Hour)
+	id := pending.ID.Hex()
+	s.approvalRepo.On("GetByID", mock.Anything, id).Return(pending, nil)
+	s.approvalRepo.On("UpdateIfStatus", mock.Anything, id, entities.ApprovalStatusPending, pending).Return(nil)
+	// The approved restart runs in the background; fail it fast
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(nil, errors.ErrEnvironmentNotFound).Maybe()
+
+	req := httptest.NewRequest("POST", "/api/approvals/"+id+"/approve", bytes.NewBufferString(`{"comment":"ok"}`))
+	req = mux.SetURLVars(withUser(req, "u2", "bob", entities.UserRoleAdmin), map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	s.handler.Approve(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), "operationId")
+}
+
+func TestApprovalHandler_Approve_Self(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	pending := entities.NewApprovalRequest(env, entities.ApprovalOperationRestart, nil,
+		entities.Actor{Type: "user", ID: "u1", Name: "alice"}, time.Hour)
+	id := pending.ID.Hex()
+	s.approvalRepo.On("GetByID", mock.Anything, id).Return(pending, nil)
+
+	req := httptest.NewRequest("POST", "/api/approvals/"+id+"/approve", nil)
+	req = mux.SetURLVars(withUser(req, "u1", "alice", entities.UserRoleAdmin), map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	s.handler.Approve(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestApprovalHandler_Reject_NotApprover(t *testing.T) {
+	s := newApprovalSetup(t)
+	id := primitive.NewObjectID().Hex()
+
+	req := httptest.NewRequest("POST", "/api/approvals/"+id+"/reject", nil)
+	req = mux.SetURLVars(withUser(req, "u2", "bob", entities.UserRoleUser), map[string]string{"id": id})
+	w := httptest.NewRecorder()
+
+	s.handler.Reject(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/bulk"
+	"app-env-manager/internal/websocket/hub"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// BulkOperationHandler handles bulk operation HTTP requests
+type BulkOperationHandler struct {
+	service *bulk.Service
+	hub     *hub.Hub
+	logger  *logrus.Logger
+}
+
+// NewBulkOperationHandler creates a new bulk operation handler
+func NewBulkOperationHandler(service *bulk.Service, hub *hub.Hub, logger *logrus.Logger) *BulkOperationHandler {
+	return &BulkOperationHandler{
+		service: service,
+		hub:     hub,
+		logger:  logger,
+	}
+}
+
+// Create handles POST /bulk-operations and starts the operation asynchronously
+func (h *BulkOperationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req bulk.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, h.logger, errors.NewValidationError("body", "Invalid request body"))
+		return
+	}
+
+	op, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"operationId":  op.OperationID,
+		"operation":    op.Operation,
+		"environments": len(op.Children),
+		"maxParallel":  op.MaxParallel,
+	}).Info("Starting bulk operation")
+
+	// Respond before the run starts mutating the operation
+	writeJSON(w, http.StatusAccepted, op)
+
+	userID, username := ctxutil.UserFromContext(r.Context())
+	go h.service.Run(ctxutil.WithUser(context.Background(), userID, username), op, h.broadcast)
+}
+
+// Get handles GET /bulk-operations/{id}
+func (h *BulkOperationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	op, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, op)
+}
+
+// List handles GET /bulk-operations
+func (h *BulkOperationHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := interfaces.BulkOperationFilter{
+		Pagination: &interfaces.Pagination{
+			Page:  1,
+			Limit: 50,
+		},
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		bulkStatus := entities.BulkOperationStatus(status)
+		filter.Status = &bulkStatus
+	}
+
+	ops, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"operations": ops})
+}
+
+// broadcast publishes progress for the parent operation and the changed child
+func (h *BulkOperationHandler) broadcast(op *entities.BulkOperation, child *entities.ChildOperation) {
+	update := map[string]interface{}{
+		"status":  op.Status,
+		"summary": op.Summary,
+	}
+	if child != nil {
+		update["child"] = *child
+		h.hub.BroadcastOperationUpdate(child.OperationID, map[string]interface{}{
+			"status":            child.Status,
+			"error":             child.Error,
+			"environmentId":     child.EnvironmentID.Hex(),
+			"parentOperationId": op.OperationID,
+		})
+	}
+	h.hub.BroadcastOperationUpdate(op.OperationID, update)
+
+	if op.IsFinished() && child == nil {
+		h.logger.WithFields(logrus.Fields{
+			"operationId": op.OperationID,
+			"status":      op.Status,
+			"succeeded":   op.Summary.Succeeded,
+			"failed":      op.Summary.Failed,
+		}).Info("Bulk operation finished")
+	}
+}
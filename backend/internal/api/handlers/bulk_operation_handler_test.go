@@ -0,0 +1,153 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/api/handlers"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/bulk"
+	"app-env-manager/internal/websocket/hub"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bulkTestMockRepo satisfies interfaces.BulkOperationRepository for handler tests.
+type bulkTestMockRepo struct{ mock.Mock }
+
+func (m *bulkTestMockRepo) Create(ctx context.Context, op *entities.BulkOperation) error {
+	return m.Called(ctx, op).Error(0)
+}
+func (m *bulkTestMockRepo) GetByID(ctx context.Context, id string) (*entities.BulkOperation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BulkOperation), args.Error(1)
+}
+func (m *bulkTestMockRepo) List(ctx context.Context, filter interfaces.BulkOperationFilter) ([]*entities.BulkOperation, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.BulkOperation), args.Error(1)
+}
+func (m *bulkTestMockRepo) Update(ctx context.Context, op *entities.BulkOperation) error {
+	return m.Called(ctx, op).Error(0)
+}
+
+// bulkTestRunner completes every operation immediately
+type bulkTestRunner struct{}
+
+func (bulkTestRunner) RestartEnvironment(ctx context.Context, id string, force bool) error {
+	return nil
+}
+func (bulkTestRunner) UpgradeEnvironment(ctx context.Context, id string, version string) error {
+	return nil
+}
+func (bulkTestRunner) RunCustomAction(ctx context.Context, id string, name string) error { return nil }
+func (bulkTestRunner) CheckHealth(ctx context.Context, id string) error                  { return nil }
+
+func newBulkSetup(t *testing.T) (*handlers.BulkOperationHandler, *bulkTestMockRepo, *envTestMockEnvRepo) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	repo := new(bulkTestMockRepo)
+	envRepo := new(envTestMockEnvRepo)
+	h := hub.NewHub(logger)
+	go h.Run()
+
+	svc := bulk.NewService(repo, envRepo, bulkTestRunner{}, 5)
+	return handlers.NewBulkOperationHandler(svc, h, logger), repo, envRepo
+}
+
+func TestBulkOperationHandler_Create(t *testing.T) {
+	handler, repo, envRepo := newBulkSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	done := make(chan struct{})
+	repo.On("Update", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		if args.Get(1).(*entities.BulkOperation).IsFinished() {
+			close(done)
+		}
+	})
+
+	body := `{"environmentIds":["` + env.ID.Hex() + `"],"operation":"health_check"}`
+	req := withUser(httptest.NewRequest("POST", "/api/v1/bulk-operations", bytes.NewBufferString(body)),
+		"u1", "alice", entities.UserRoleUser)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"operationId":"op-`)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("bulk operation did not finish")
+	}
+}
+
+func TestBulkOperationHandler_Create_InvalidBody(t *testing.T) {
+	handler, _, _ := newBulkSetup(t)
+
+	req := httptest.NewRequest("POST", "/api/v1/bulk-operations", bytes.NewBufferString("{"))
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBulkOperationHandler_Create_ValidationError(t *testing.T) {
+	handler, _, _ := newBulkSetup(t)
+
+	req := httptest.NewRequest("POST", "/api/v1/bulk-operations", bytes.NewBufferString(`{"operation":"restart"}`))
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBulkOperationHandler_Get(t *testing.T) {
+	handler, repo, _ := newBulkSetup(t)
+	id := primitive.NewObjectID().Hex()
+	repo.On("GetByID", mock.Anything, id).Return(&entities.BulkOperation{Status: entities.BulkStatusRunning}, nil)
+	missing := primitive.NewObjectID().Hex()
+	repo.On("GetByID", mock.Anything, missing).Return(nil, errors.ErrBulkOperationNotFound)
+
+	w := httptest.NewRecorder()
+	handler.Get(w, mux.SetURLVars(httptest.NewRequest("GET", "/", nil), map[string]string{"id": id}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "running")
+
+	w = httptest.NewRecorder()
+	handler.Get(w, mux.SetURLVars(httptest.NewRequest("GET", "/", nil), map[string]string{"id": missing}))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBulkOperationHandler_List(t *testing.T) {
+	handler, repo, _ := newBulkSetup(t)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f interfaces.BulkOperationFilter) bool {
+		return f.Status != nil && *f.Status == entities.BulkStatusCompleted
+	})).Return([]*entities.BulkOperation{{Status: entities.BulkStatusCompleted}}, nil)
+
+	w := httptest.NewRecorder()
+	handler.List(w, httptest.NewRequest("GET", "/api/v1/bulk-operations?status=completed", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"operations"`)
+}
@@ -12,6 +12,7 @@ import (
 	"app-env-manager/internal/domain/entities"
 	"app-env-manager/internal/domain/errors"
 	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/approval"
 	"app-env-manager/internal/service/environment"
 	"app-env-manager/internal/websocket/hub"
 	"github.com/go-playground/validator/v10"
@@ -66,19 +67,23 @@ type (
 // EnvironmentHandler handles environment-related HTTP requests
 type EnvironmentHandler struct {
 	service   *environment.Service
+	approvals *approval.Service
 	validator *validator.Validate
 	hub       *hub.Hub
 	logger    *logrus.Logger
 }
 
-// NewEnvironmentHandler creates a new environment handler
+// NewEnvironmentHandler creates a new environment handler. approvals may be
+// nil, in which case operations are never held for approval.
 func NewEnvironmentHandler(
 	service *environment.Service,
+	approvals *approval.Service,
 	hub *hub.Hub,
 	logger *logrus.Logger,
 ) *EnvironmentHandler {
 	return &EnvironmentHandler{
 		service:   service,
+		approvals: approvals,
 		validator: validator.New(),
 		hub:       hub,
 		logger:    logger,
@@ -244,6 +249,39 @@ func (h *EnvironmentHandler) Restart(w http.ResponseWriter, r *http.Request) {
 		req = dto.RestartRequest{Force: false}
 	}
 
+	// Dry runs only render and check the plan, so they skip approval
+	if req.DryRun {
+		plan, err := h.service.PlanRestart(r.Context(), id, req.Force)
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+		h.respondJSON(w, http.StatusOK, plan)
+		return
+	}
+
+	// Refuse restarts the environment's rate limit or cooldown would stop,
+	// before they are queued for approval
+	if err := h.service.CheckOperationLimit(r.Context(), id, entities.ActionTypeRestart); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	// Protected environments queue the restart for a second user's approval
+	if h.submitForApproval(w, r, id, entities.ApprovalOperationRestart, map[string]interface{}{
+		"force": req.Force,
+	}) {
+		return
+	}
+
+	// Record the run now, so a concurrent request is refused with its
+	// retry time rather than failing in the background
+	reservation, err := h.service.ReserveOperation(r.Context(), id, entities.ActionTypeRestart)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
 	// Capture user identity before the goroutine (request context won't be available inside)
 	userID, username := ctxutil.UserFromContext(r.Context())
 
@@ -251,9 +289,9 @@ func (h *EnvironmentHandler) Restart(w http.ResponseWriter, r *http.Request) {
 
 	// Start operation asynchronously with a background context
 	go func() {
-		// Create a new context with timeout and carry user identity into the async operation
-		bgCtx, cancel := context.WithTimeout(ctxutil.WithUser(context.Background(), userID, username), 5*time.Minute)
-		defer cancel()
+		// Carry user identity into the async operation; the environment's
+		// restart timeout bounds it
+		bgCtx := environment.WithReservation(ctxutil.WithUser(context.Background(), userID, username), reservation)
 
 		h.logger.WithFields(logrus.Fields{
 			"operationId": operationID,
@@ -332,6 +370,39 @@ func (h *EnvironmentHandler) Upgrade(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Dry runs only render and check the plan, so they skip approval
+	if req.DryRun {
+		plan, err := h.service.PlanUpgrade(r.Context(), id, req.Version)
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+		h.respondJSON(w, http.StatusOK, plan)
+		return
+	}
+
+	// Refuse upgrades the environment's rate limit or cooldown would stop,
+	// before they are queued for approval
+	if err := h.service.CheckOperationLimit(r.Context(), id, entities.ActionTypeUpgrade); err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	// Protected environments queue the upgrade for a second user's approval
+	if h.submitForApproval(w, r, id, entities.ApprovalOperationUpgrade, map[string]interface{}{
+		"version": req.Version,
+	}) {
+		return
+	}
+
+	// Record the run now, so a concurrent request is refused with its
+	// retry time rather than failing in the background
+	reservation, err := h.service.ReserveOperation(r.Context(), id, entities.ActionTypeUpgrade)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
 	// Capture user identity before the goroutine (request context won't be available inside)
 	userID, username := ctxutil.UserFromContext(r.Context())
 
@@ -339,9 +410,9 @@ func (h *EnvironmentHandler) Upgrade(w http.ResponseWriter, r *http.Request) {
 
 	// Start operation asynchronously with a background context
 	go func() {
-		// Create a new context with timeout and carry user identity into the async operation
-		bgCtx, cancel := context.WithTimeout(ctxutil.WithUser(context.Background(), userID, username), 10*time.Minute)
-		defer cancel()
+		// Carry user identity into the async operation; the environment's
+		// upgrade timeout bounds it
+		bgCtx := environment.WithReservation(ctxutil.WithUser(context.Background(), userID, username), reservation)
 
 		h.logger.WithFields(logrus.Fields{
 			"operationId": operationID,
@@ -369,65 +440,148 @@ func (h *EnvironmentHandler) Upgrade(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// Helper functions
+// RunAction handles POST /environments/{id}/actions/{name}
+func (h *EnvironmentHandler) RunAction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+	name := vars["name"]
 
-func (h *EnvironmentHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
-	response := dto.SuccessResponse{
-		Success: true,
-		Data:    data,
-		Metadata: dto.ResponseMetadata{
-			Timestamp: currentTimestamp(),
-			Version:   "1.0.0",
-		},
+	var req dto.ActionRequest
+	// The body is optional
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	if req.DryRun {
+		plan, err := h.service.PlanCustomAction(r.Context(), id, name)
+		if err != nil {
+			h.respondError(w, err)
+			return
+		}
+		h.respondJSON(w, http.StatusOK, plan)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(response)
-}
+	if err := h.service.CheckOperationLimit(r.Context(), id, entities.ActionTypeCustom); err != nil {
+		h.respondError(w, err)
+		return
+	}
 
-func (h *EnvironmentHandler) respondError(w http.ResponseWriter, err error) {
-	status := http.StatusInternalServerError
-	// Default to a generic message so internal details are never leaked
-	errorResponse := dto.ErrorInfo{
-		Code:    "INTERNAL_ERROR",
-		Message: "An internal server error occurred",
-	}
-
-	// Map domain errors to HTTP status codes; domain error messages are safe
-	// to expose because they are authored for client consumption.
-	if domainErr, ok := err.(errors.DomainError); ok {
-		errorResponse.Code = domainErr.Code
-		errorResponse.Message = domainErr.Message
-		errorResponse.Details = domainErr.Details
-
-		switch domainErr.Code {
-		case "ENV_NOT_FOUND":
-			status = http.StatusNotFound
-		case "ENV_DUPLICATE":
-			status = http.StatusConflict
-		case "VALIDATION_ERROR":
-			status = http.StatusBadRequest
-		case "AUTH_INVALID", "AUTH_UNAUTHORIZED":
-			status = http.StatusUnauthorized
+	if h.submitForApproval(w, r, id, entities.ApprovalOperationCustomAction, map[string]interface{}{"action": name}) {
+		return
+	}
+
+	// Record the run now, so a concurrent request is refused with its
+	// retry time rather than failing in the background
+	reservation, err := h.service.ReserveOperation(r.Context(), id, entities.ActionTypeCustom)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	// Capture user identity before the goroutine (request context won't be available inside)
+	userID, username := ctxutil.UserFromContext(r.Context())
+
+	operationID := generateOperationID()
+
+	// The environment's timeout for custom actions bounds the operation
+	go func() {
+		bgCtx := environment.WithReservation(ctxutil.WithUser(context.Background(), userID, username), reservation)
+
+		h.logger.WithFields(logrus.Fields{
+			"operationId":   operationID,
+			"environmentId": id,
+			"action":        name,
+		}).Info("Starting custom action")
+
+		if err := h.service.RunCustomAction(bgCtx, id, name); err != nil {
+			h.logger.WithError(err).WithField("operationId", operationID).Error("Custom action failed")
+			h.hub.BroadcastOperationUpdate(operationID, map[string]interface{}{
+				"status": "failed",
+				"error":  err.Error(),
+			})
+			return
 		}
-	} else {
-		// Log internal errors server-side without surfacing details to the client
-		h.logger.WithError(err).Error("Unexpected internal error in environment handler")
+
+		h.logger.WithField("operationId", operationID).Info("Custom action completed")
+		h.hub.BroadcastOperationUpdate(operationID, map[string]interface{}{
+			"status": "completed",
+		})
+	}()
+
+	h.respondJSON(w, http.StatusAccepted, dto.OperationResponse{
+		OperationID: operationID,
+		Status:      "in_progress",
+	})
+}
+
+// Exec handles POST /environments/{id}/exec
+func (h *EnvironmentHandler) Exec(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	var req dto.ExecRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondError(w, errors.NewValidationError("body", "invalid JSON"))
+		return
+	}
+	if req.Command == "" {
+		h.respondError(w, errors.NewValidationError("command", "command is required"))
+		return
 	}
 
-	response := dto.ErrorResponse{
-		Success: false,
-		Error:   errorResponse,
-		Metadata: dto.ResponseMetadata{
-			Timestamp: currentTimestamp(),
-			Version:   "1.0.0",
-		},
+	result, err := h.service.ExecCommand(r.Context(), id, req.Command)
+	if err != nil {
+		h.respondError(w, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"environmentId": id,
+		"exitCode":      result.ExitCode,
+		"rule":          result.Rule,
+	}).Info("Ad-hoc command executed")
+
+	h.respondJSON(w, http.StatusOK, result)
+}
+
+// submitForApproval files an approval request when the environment requires
+// one. It returns true when the operation was queued (or submission failed)
+// and the response has already been written.
+func (h *EnvironmentHandler) submitForApproval(w http.ResponseWriter, r *http.Request, id string,
+	operation entities.ApprovalOperation, params map[string]interface{}) bool {
+
+	if h.approvals == nil {
+		return false
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(response)
+	pending, err := h.approvals.Submit(r.Context(), id, operation, params)
+	if err != nil {
+		h.respondError(w, err)
+		return true
+	}
+	if pending == nil {
+		return false
+	}
+
+	h.hub.BroadcastApprovalUpdate(pending.ID.Hex(), h.approvals.ApproverRoles(), map[string]interface{}{
+		"action":   "requested",
+		"approval": pending,
+	})
+
+	h.respondJSON(w, http.StatusAccepted, dto.ApprovalPendingResponse{
+		ApprovalID: pending.ID.Hex(),
+		Status:     "pending_approval",
+		ExpiresAt:  pending.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+	return true
+}
+
+// Helper functions
+
+func (h *EnvironmentHandler) respondJSON(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, data)
+}
+
+func (h *EnvironmentHandler) respondError(w http.ResponseWriter, err error) {
+	writeError(w, h.logger, err)
 }
 
 func parseListFilter(r *http.Request) interfaces.ListFilter {
@@ -491,7 +645,8 @@ func redactSensitiveFields(env *entities.Environment) *entities.Environment {
 		redactedMeta := make(map[string]interface{})
 		for k, v := range redacted.Metadata {
 			// Skip known sensitive fields
-			if k == "password" || k == "privateKey" || k == "key" || k == "secret" {
+			if k == "password" || k == "privateKey" || k == "key" || k == "secret" || k == "dockerClientKey" ||
+				k == "kubeconfig" || k == "kubernetesToken" {
 				continue
 			}
 			redactedMeta[k] = v
@@ -0,0 +1,96 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEnvironmentHandler_Restart_DryRunSkipsApproval(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.RequiresApproval = true
+	env.Commands = entities.CommandConfig{
+		Type:    entities.CommandTypeHTTP,
+		Restart: entities.RestartConfig{Enabled: true, URL: "http://169.254.169.254/restart"},
+	}
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"dryRun":true}`))
+	req = mux.SetURLVars(withUser(req, "u1", "alice", entities.UserRoleUser), map[string]string{"id": env.ID.Hex()})
+	w := httptest.NewRecorder()
+
+	s.envHandler.Restart(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data entities.OperationPlan `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.DryRun)
+	assert.False(t, resp.Data.Valid, "metadata endpoint is blocked by the SSRF rules")
+	s.approvalRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestEnvironmentHandler_Upgrade_DryRun(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.UpgradeConfig = entities.UpgradeConfig{
+		Enabled:        true,
+		Type:           entities.CommandTypeHTTP,
+		UpgradeCommand: entities.CommandDetails{URL: "https://example.com/deploy/{VERSION}"},
+	}
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"version":"2.0.0","dryRun":true}`))
+	req = mux.SetURLVars(withUser(req, "u1", "alice", entities.UserRoleUser), map[string]string{"id": env.ID.Hex()})
+	w := httptest.NewRecorder()
+
+	s.envHandler.Upgrade(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "https://example.com/deploy/2.0.0")
+}
+
+func TestEnvironmentHandler_RunAction_DryRunUnknownAction(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"dryRun":true}`))
+	req = mux.SetURLVars(req, map[string]string{"id": env.ID.Hex(), "name": "missing"})
+	w := httptest.NewRecorder()
+
+	s.envHandler.RunAction(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestEnvironmentHandler_RunAction_Accepted(t *testing.T) {
+	s := newApprovalSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	done := make(chan struct{})
+	// The rate limit check, the approval check, the reservation, then the
+	// action itself
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil).Times(3)
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil).Run(func(mock.Arguments) { close(done) }).Once()
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": env.ID.Hex(), "name": "missing"})
+	w := httptest.NewRecorder()
+
+	s.envHandler.RunAction(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), "in_progress")
+	<-done
+}
@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app-env-manager/internal/api/dto"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newExecRequest(t *testing.T, id string, body interface{}) *http.Request {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest("POST", "/api/environments/"+id+"/exec", bytes.NewReader(data))
+	return muxSetVar(req, "id", id)
+}
+
+func TestEnvironmentHandler_Exec_InvalidJSON(t *testing.T) {
+	s := newHandlerSetup(t)
+
+	req := httptest.NewRequest("POST", "/api/environments/x/exec", bytes.NewBufferString("{"))
+	req = muxSetVar(req, "id", "x")
+	w := httptest.NewRecorder()
+
+	s.handler.Exec(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestEnvironmentHandler_Exec_MissingCommand(t *testing.T) {
+	s := newHandlerSetup(t)
+
+	w := httptest.NewRecorder()
+	s.handler.Exec(w, newExecRequest(t, "x", dto.ExecRequest{}))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	s.envRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestEnvironmentHandler_Exec_NotAllowed(t *testing.T) {
+	s := newHandlerSetup(t)
+	s.auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	id := primitive.NewObjectID()
+	env := sampleEnvForHandler(id)
+	env.Commands = entities.CommandConfig{Type: entities.CommandTypeSSH}
+	s.envRepo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+
+	w := httptest.NewRecorder()
+	s.handler.Exec(w, newExecRequest(t, id.Hex(), dto.ExecRequest{Command: "uptime"}))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	var resp dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "COMMAND_NOT_ALLOWED", resp.Error.Code)
+}
+
+func TestEnvironmentHandler_Exec_NotFound(t *testing.T) {
+	s := newHandlerSetup(t)
+	s.envRepo.On("GetByID", mock.Anything, "missing").Return(nil, errors.ErrEnvironmentNotFound)
+
+	w := httptest.NewRecorder()
+	s.handler.Exec(w, newExecRequest(t, "missing", dto.ExecRequest{Command: "uptime"}))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -182,6 +182,13 @@ func TestParseListFilter(t *testing.T) {
 			expectedLimit: 200,
 			expectedStatus: func() *entities.HealthStatus { s := entities.HealthStatusUnhealthy; return &s }(),
 		},
+		{
+			name:          "Degraded filter",
+			queryString:   "status=degraded",
+			expectedPage:  1,
+			expectedLimit: 200,
+			expectedStatus: func() *entities.HealthStatus { s := entities.HealthStatusDegraded; return &s }(),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -272,6 +279,9 @@ func TestRedactSensitiveFields(t *testing.T) {
 			"privateKey":  "secret-private-key",
 			"key":         "secret-key",
 			"secret":      "secret-value",
+			"dockerClientKey": "secret-docker-key",
+			"kubeconfig":      "secret-kubeconfig",
+			"kubernetesToken": "secret-token",
 			"environment": "production",
 			"region":      "us-east-1",
 		},
@@ -306,11 +316,17 @@ func TestRedactSensitiveFields(t *testing.T) {
 	_, hasPrivateKey := redacted.Metadata["privateKey"]
 	_, hasKey := redacted.Metadata["key"]
 	_, hasSecret := redacted.Metadata["secret"]
+	_, hasDockerClientKey := redacted.Metadata["dockerClientKey"]
+	_, hasKubeconfig := redacted.Metadata["kubeconfig"]
+	_, hasKubernetesToken := redacted.Metadata["kubernetesToken"]
 	
 	assert.False(t, hasPassword)
 	assert.False(t, hasPrivateKey)
 	assert.False(t, hasKey)
 	assert.False(t, hasSecret)
+	assert.False(t, hasDockerClientKey)
+	assert.False(t, hasKubeconfig)
+	assert.False(t, hasKubernetesToken)
 	assert.Equal(t, "production", redacted.Metadata["environment"])
 	assert.Equal(t, "us-east-1", redacted.Metadata["region"])
 }
@@ -105,6 +105,7 @@ type handlerSetup struct {
 	logRepo   *MockLogRepository
 	auditRepo *envTestMockAuditRepo
 	hub       *hub.Hub
+	service   *environment.Service
 	handler   *handlers.EnvironmentHandler
 }
 
@@ -130,13 +131,14 @@ func newHandlerSetup(t *testing.T) *handlerSetup {
 	h := hub.NewHub(logger)
 	go h.Run()
 
-	envHandler := handlers.NewEnvironmentHandler(svc, h, logger)
+	envHandler := handlers.NewEnvironmentHandler(svc, nil, h, logger)
 
 	return &handlerSetup{
 		envRepo:   envRepo,
 		logRepo:   logRepo,
 		auditRepo: auditRepo,
 		hub:       h,
+		service:   svc,
 		handler:   envHandler,
 	}
 }
@@ -371,8 +373,9 @@ func TestEnvironmentHandler_Restart_AcceptsRequest(t *testing.T) {
 	body := dto.RestartRequest{Force: false}
 	bodyBytes, _ := json.Marshal(body)
 
-	// The background goroutine will call GetByID; allow it
-	s.envRepo.On("GetByID", mock.Anything, "env1").Return(nil, errors.ErrEnvironmentNotFound).Maybe()
+	// The rate limit check and the background goroutine call GetByID; the
+	// restart itself fails as it is not enabled
+	s.envRepo.On("GetByID", mock.Anything, "env1").Return(&entities.Environment{}, nil).Maybe()
 
 	req := httptest.NewRequest("POST", "/api/environments/env1/restart", bytes.NewReader(bodyBytes))
 	req.Header.Set("Content-Type", "application/json")
@@ -394,7 +397,7 @@ func TestEnvironmentHandler_Restart_AcceptsRequest(t *testing.T) {
 func TestEnvironmentHandler_Restart_NoBody(t *testing.T) {
 	s := newHandlerSetup(t)
 
-	s.envRepo.On("GetByID", mock.Anything, "env1").Return(nil, errors.ErrEnvironmentNotFound).Maybe()
+	s.envRepo.On("GetByID", mock.Anything, "env1").Return(&entities.Environment{}, nil).Maybe()
 
 	req := httptest.NewRequest("POST", "/api/environments/env1/restart", http.NoBody)
 	req = muxSetVar(req, "id", "env1")
@@ -406,6 +409,59 @@ func TestEnvironmentHandler_Restart_NoBody(t *testing.T) {
 	time.Sleep(10 * time.Millisecond)
 }
 
+func TestEnvironmentHandler_Restart_RateLimited(t *testing.T) {
+	s := newHandlerSetup(t)
+
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.Commands = entities.CommandConfig{Type: entities.CommandTypeSimulated, Restart: entities.RestartConfig{Enabled: true}}
+	env.Limits = &entities.OperationLimits{Restart: &entities.OperationLimit{MaxRuns: 1}}
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	s.envRepo.On("Update", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	// The health check that follows a restart
+	s.envRepo.On("UpdateStatus", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	s.logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	require.NoError(t, s.service.RestartEnvironment(context.Background(), env.ID.Hex(), false))
+
+	req := httptest.NewRequest("POST", "/api/environments/"+env.ID.Hex()+"/restart", http.NoBody)
+	req = muxSetVar(req, "id", env.ID.Hex())
+	w := httptest.NewRecorder()
+
+	s.handler.Restart(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "3600", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "OPERATION_RATE_LIMITED")
+}
+
+func TestEnvironmentHandler_Restart_RepeatedRequestRateLimited(t *testing.T) {
+	s := newHandlerSetup(t)
+
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.Commands = entities.CommandConfig{Type: entities.CommandTypeSimulated, Restart: entities.RestartConfig{Enabled: true}}
+	env.Limits = &entities.OperationLimits{Restart: &entities.OperationLimit{MaxRuns: 1}}
+	s.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	s.envRepo.On("Update", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	s.envRepo.On("UpdateStatus", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	s.logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	s.auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	restart := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/environments/"+env.ID.Hex()+"/restart", http.NoBody)
+		req = muxSetVar(req, "id", env.ID.Hex())
+		w := httptest.NewRecorder()
+		s.handler.Restart(w, req)
+		return w
+	}
+
+	// The second request comes before the first restart has started
+	first, second := restart(), restart()
+
+	assert.Equal(t, http.StatusAccepted, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, "3600", second.Header().Get("Retry-After"))
+}
+
 // ---- CheckHealth ----
 
 func TestEnvironmentHandler_CheckHealth_NotFound(t *testing.T) {
@@ -472,8 +528,8 @@ func TestEnvironmentHandler_Upgrade_EmptyVersion(t *testing.T) {
 func TestEnvironmentHandler_Upgrade_ValidVersion(t *testing.T) {
 	s := newHandlerSetup(t)
 
-	// Background goroutine will call GetByID
-	s.envRepo.On("GetByID", mock.Anything, "e1").Return(nil, errors.ErrEnvironmentNotFound).Maybe()
+	// The rate limit check and the background goroutine call GetByID
+	s.envRepo.On("GetByID", mock.Anything, "e1").Return(&entities.Environment{}, nil).Maybe()
 
 	body := dto.UpgradeRequest{Version: "1.2.3"}
 	bodyBytes, _ := json.Marshal(body)
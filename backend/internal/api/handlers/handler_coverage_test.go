@@ -139,7 +139,7 @@ func TestEnvironmentHandler_GetVersions_UpgradeNotEnabled(t *testing.T) {
 func TestEnvironmentHandler_Restart_InvalidJSON_Still202(t *testing.T) {
 	s := newHandlerSetup(t)
 
-	s.envRepo.On("GetByID", mock.Anything, "e2").Return(nil, errors.ErrEnvironmentNotFound).Maybe()
+	s.envRepo.On("GetByID", mock.Anything, "e2").Return(&entities.Environment{}, nil).Maybe()
 
 	req := httptest.NewRequest("POST", "/api/environments/e2/restart", bytes.NewReader([]byte("bad json")))
 	req.Header.Set("Content-Type", "application/json")
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/service/remotelog"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const remoteLogWriteTimeout = 10 * time.Second
+
+// RemoteLogHandler handles reading and following environment log sources
+type RemoteLogHandler struct {
+	service *remotelog.Service
+	logger  *logrus.Logger
+}
+
+// NewRemoteLogHandler creates a new remote log handler
+func NewRemoteLogHandler(service *remotelog.Service, logger *logrus.Logger) *RemoteLogHandler {
+	return &RemoteLogHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Tail handles GET /environments/{id}/remote-logs/{source}
+func (h *RemoteLogHandler) Tail(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	lines, err := parseLines(r)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	snapshot, err := h.service.Tail(r.Context(), vars["id"], vars["source"], lines)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, snapshot)
+}
+
+// Follow handles GET /ws/remote-logs/{id}/{source}. The caller must already
+// be authenticated; the stream is opened before the upgrade so refusals are
+// plain HTTP errors.
+func (h *RemoteLogHandler) Follow(w http.ResponseWriter, r *http.Request, upgrader *websocket.Upgrader) {
+	vars := mux.Vars(r)
+
+	lines, err := parseLines(r)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	stream, err := h.service.OpenFollow(r.Context(), vars["id"], vars["source"], lines)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+	defer stream.Close()
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to upgrade remote log connection")
+		return
+	}
+	defer conn.Close()
+	// Followers only listen; the stream enforces its own duration limit
+	conn.SetReadDeadline(time.Time{})
+
+	logger := h.logger.WithFields(logrus.Fields{
+		"environmentId": vars["id"],
+		"source":        vars["source"],
+	})
+	logger.Info("Remote log follow started")
+
+	reason := stream.Serve(r.Context(), &remoteLogClient{conn: conn})
+
+	conn.SetWriteDeadline(time.Now().Add(remoteLogWriteTimeout))
+	conn.WriteJSON(remotelog.Message{Type: remotelog.MessageClosed, Reason: reason})
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason))
+
+	logger.WithField("reason", reason).Info("Remote log follow ended")
+}
+
+// parseLines reads the optional lines query parameter; zero means the default
+func parseLines(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("lines")
+	if value == "" {
+		return 0, nil
+	}
+	lines, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errors.NewValidationError("lines", "must be a number")
+	}
+	return lines, nil
+}
+
+// remoteLogClient adapts a WebSocket to a follow client
+type remoteLogClient struct {
+	conn *websocket.Conn
+}
+
+func (c *remoteLogClient) Send(message remotelog.Message) error {
+	c.conn.SetWriteDeadline(time.Now().Add(remoteLogWriteTimeout))
+	return c.conn.WriteJSON(message)
+}
+
+// Wait reads and discards messages until the browser closes the connection
+func (c *remoteLogClient) Wait() error {
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return err
+		}
+	}
+}
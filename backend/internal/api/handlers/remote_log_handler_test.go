@@ -0,0 +1,147 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/api/handlers"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/remotelog"
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type remoteLogSetup struct {
+	server  *httptest.Server
+	env     *entities.Environment
+	logFile string
+}
+
+func newRemoteLogSetup(t *testing.T, config remotelog.Config) *remoteLogSetup {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	sshServer := sshtest.NewServer(t)
+	manager := ssh.NewManager(ssh.Config{ConnectionTimeout: 5 * time.Second, CommandTimeout: 10 * time.Second, MaxConnections: 5})
+	t.Cleanup(func() { manager.Close() })
+
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("one\ntwo\nthree\n"), 0o644))
+
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.LogSources = []entities.LogSource{{Name: "app", Type: entities.LogSourceFile, Path: logFile}}
+	envRepo := new(envTestMockEnvRepo)
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil).Maybe()
+
+	service := remotelog.NewService(envRepo, manager, terminalTargets{sshServer}, config)
+	handler := handlers.NewRemoteLogHandler(service, logger)
+
+	upgrader := &websocket.Upgrader{}
+	router := mux.NewRouter()
+	router.HandleFunc("/environments/{id}/remote-logs/{source}", handler.Tail)
+	router.HandleFunc("/ws/remote-logs/{id}/{source}", func(w http.ResponseWriter, r *http.Request) {
+		handler.Follow(w, r, upgrader)
+	})
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &remoteLogSetup{server: server, env: env, logFile: logFile}
+}
+
+func (s *remoteLogSetup) get(t *testing.T, path string) (int, map[string]interface{}) {
+	t.Helper()
+	resp, err := http.Get(s.server.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func TestRemoteLogHandler_Tail(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{})
+
+	status, body := s.get(t, "/environments/"+s.env.ID.Hex()+"/remote-logs/app?lines=2")
+	assert.Equal(t, http.StatusOK, status)
+	data := body["data"].(map[string]interface{})
+	assert.Equal(t, "app", data["source"])
+	assert.Equal(t, []interface{}{"two", "three"}, data["lines"])
+}
+
+func TestRemoteLogHandler_Tail_Errors(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{MaxLines: 10})
+	base := "/environments/" + s.env.ID.Hex() + "/remote-logs/"
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		code   string
+	}{
+		{"not a number", base + "app?lines=abc", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"too many lines", base + "app?lines=11", http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"unknown source", base + "missing", http.StatusNotFound, "LOG_SOURCE_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := s.get(t, tt.path)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, body["error"].(map[string]interface{})["code"])
+		})
+	}
+}
+
+func TestRemoteLogHandler_Follow(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{})
+	url := "ws" + strings.TrimPrefix(s.server.URL, "http") + "/ws/remote-logs/" + s.env.ID.Hex() + "/app?lines=1"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var message remotelog.Message
+	require.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, remotelog.Message{Type: remotelog.MessageLines, Lines: []string{"three"}}, message)
+
+	f, err := os.OpenFile(s.logFile, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("four\n")
+	require.NoError(t, err)
+	f.Close()
+
+	require.NoError(t, conn.ReadJSON(&message))
+	assert.Equal(t, []string{"four"}, message.Lines)
+}
+
+func TestRemoteLogHandler_Follow_LimitReached(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{MaxTailsPerHost: 1})
+	url := "ws" + strings.TrimPrefix(s.server.URL, "http") + "/ws/remote-logs/" + s.env.ID.Hex() + "/app"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+
+	status, _ := s.get(t, "/environments/"+s.env.ID.Hex()+"/remote-logs/app")
+	assert.Equal(t, http.StatusTooManyRequests, status)
+}
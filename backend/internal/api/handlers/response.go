@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"app-env-manager/internal/api/dto"
+	"app-env-manager/internal/domain/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// writeJSON writes a successful API response envelope
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	response := dto.SuccessResponse{
+		Success: true,
+		Data:    data,
+		Metadata: dto.ResponseMetadata{
+			Timestamp: currentTimestamp(),
+			Version:   "1.0.0",
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeError writes an error API response envelope, mapping domain errors to
+// HTTP status codes
+func writeError(w http.ResponseWriter, logger *logrus.Logger, err error) {
+	status := http.StatusInternalServerError
+	// Default to a generic message so internal details are never leaked
+	errorResponse := dto.ErrorInfo{
+		Code:    "INTERNAL_ERROR",
+		Message: "An internal server error occurred",
+	}
+
+	// Map domain errors to HTTP status codes; domain error messages are safe
+	// to expose because they are authored for client consumption.
+	if domainErr, ok := err.(errors.DomainError); ok {
+		errorResponse.Code = domainErr.Code
+		errorResponse.Message = domainErr.Message
+		errorResponse.Details = domainErr.Details
+
+		switch domainErr.Code {
+		case "ENV_NOT_FOUND", "APPROVAL_NOT_FOUND", "BULK_OPERATION_NOT_FOUND", "ACTION_NOT_FOUND", "ROLLOUT_NOT_FOUND",
+			"RECORDING_NOT_FOUND", "LOG_SOURCE_NOT_FOUND":
+			status = http.StatusNotFound
+		case "ENV_DUPLICATE", "APPROVAL_NOT_PENDING", "APPROVAL_EXPIRED", "ROLLOUT_NOT_ACTIVE":
+			status = http.StatusConflict
+		case "VALIDATION_ERROR":
+			status = http.StatusBadRequest
+		case "AUTH_INVALID", "AUTH_UNAUTHORIZED":
+			status = http.StatusUnauthorized
+		case "AUTH_FORBIDDEN", "APPROVAL_SELF", "COMMAND_NOT_ALLOWED":
+			status = http.StatusForbidden
+		case "SSH_CONNECTION_FAILED":
+			status = http.StatusBadGateway
+		case "TAIL_LIMIT_REACHED":
+			status = http.StatusTooManyRequests
+		case "OPERATION_RATE_LIMITED":
+			status = http.StatusTooManyRequests
+			if retryAfter, ok := domainErr.Details["retryAfter"].(int); ok {
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			}
+		}
+	} else {
+		// Log internal errors server-side without surfacing details to the client
+		logger.WithError(err).Error("Unexpected internal error in API handler")
+	}
+
+	response := dto.ErrorResponse{
+		Success: false,
+		Error:   errorResponse,
+		Metadata: dto.ResponseMetadata{
+			Timestamp: currentTimestamp(),
+			Version:   "1.0.0",
+		},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/rollout"
+	"app-env-manager/internal/websocket/hub"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// RolloutHandler handles rolling upgrade HTTP requests
+type RolloutHandler struct {
+	service *rollout.Service
+	hub     *hub.Hub
+	logger  *logrus.Logger
+}
+
+// NewRolloutHandler creates a new rollout handler
+func NewRolloutHandler(service *rollout.Service, hub *hub.Hub, logger *logrus.Logger) *RolloutHandler {
+	return &RolloutHandler{
+		service: service,
+		hub:     hub,
+		logger:  logger,
+	}
+}
+
+// Create handles POST /rollouts and starts the rollout asynchronously
+func (h *RolloutHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req rollout.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, h.logger, errors.NewValidationError("body", "Invalid request body"))
+		return
+	}
+
+	ro, err := h.service.Create(r.Context(), req)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"rolloutId":    ro.ID.Hex(),
+		"version":      ro.Version,
+		"environments": len(ro.Members),
+		"batches":      ro.TotalBatches,
+		"canary":       ro.Canary,
+	}).Info("Starting rollout")
+
+	// Respond before the run starts mutating the rollout
+	writeJSON(w, http.StatusAccepted, ro)
+
+	userID, username := ctxutil.UserFromContext(r.Context())
+	go h.service.Run(ctxutil.WithUser(context.Background(), userID, username), ro, h.broadcast)
+}
+
+// Get handles GET /rollouts/{id}
+func (h *RolloutHandler) Get(w http.ResponseWriter, r *http.Request) {
+	ro, err := h.service.Get(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ro)
+}
+
+// List handles GET /rollouts
+func (h *RolloutHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter := interfaces.RolloutFilter{
+		Pagination: &interfaces.Pagination{
+			Page:  1,
+			Limit: 50,
+		},
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		rolloutStatus := entities.RolloutStatus(status)
+		filter.Status = &rolloutStatus
+	}
+
+	rollouts, err := h.service.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"rollouts": rollouts})
+}
+
+// Pause handles POST /rollouts/{id}/pause
+func (h *RolloutHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.steer(w, r, "pause", h.service.Pause)
+}
+
+// Resume handles POST /rollouts/{id}/resume
+func (h *RolloutHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.steer(w, r, "resume", h.service.Resume)
+}
+
+// Abort handles POST /rollouts/{id}/abort
+func (h *RolloutHandler) Abort(w http.ResponseWriter, r *http.Request) {
+	h.steer(w, r, "abort", h.service.Abort)
+}
+
+// steer applies a pause/resume/abort request to a running rollout
+func (h *RolloutHandler) steer(w http.ResponseWriter, r *http.Request, action string,
+	fn func(ctx context.Context, id string) (*entities.Rollout, error)) {
+
+	id := mux.Vars(r)["id"]
+	ro, err := fn(r.Context(), id)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	userID, username := ctxutil.UserFromContext(r.Context())
+	h.logger.WithFields(logrus.Fields{
+		"rolloutId": id,
+		"action":    action,
+		"userId":    userID,
+		"username":  username,
+	}).Info("Rollout control requested")
+
+	writeJSON(w, http.StatusAccepted, ro)
+}
+
+// broadcast publishes rollout progress over the hub. The members are copied
+// because clients encode the update later, while the rollout goes on.
+func (h *RolloutHandler) broadcast(ro *entities.Rollout) {
+	h.hub.BroadcastRolloutUpdate(ro.ID.Hex(), map[string]interface{}{
+		"status":       ro.Status,
+		"currentBatch": ro.CurrentBatch,
+		"totalBatches": ro.TotalBatches,
+		"members":      append([]entities.RolloutMember(nil), ro.Members...),
+		"message":      ro.Message,
+	})
+
+	if ro.IsFinished() {
+		h.logger.WithFields(logrus.Fields{
+			"rolloutId": ro.ID.Hex(),
+			"status":    ro.Status,
+			"message":   ro.Message,
+		}).Info("Rollout finished")
+	}
+}
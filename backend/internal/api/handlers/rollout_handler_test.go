@@ -0,0 +1,243 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/api/handlers"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/health"
+	"app-env-manager/internal/service/rollout"
+	"app-env-manager/internal/websocket/hub"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rolloutTestMockRepo satisfies interfaces.RolloutRepository for handler tests.
+type rolloutTestMockRepo struct{ mock.Mock }
+
+func (m *rolloutTestMockRepo) Create(ctx context.Context, r *entities.Rollout) error {
+	return m.Called(ctx, r).Error(0)
+}
+func (m *rolloutTestMockRepo) GetByID(ctx context.Context, id string) (*entities.Rollout, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Rollout), args.Error(1)
+}
+func (m *rolloutTestMockRepo) List(ctx context.Context, filter interfaces.RolloutFilter) ([]*entities.Rollout, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Rollout), args.Error(1)
+}
+func (m *rolloutTestMockRepo) Update(ctx context.Context, r *entities.Rollout) error {
+	return m.Called(ctx, r).Error(0)
+}
+
+// rolloutTestUpgrader upgrades and verifies every environment immediately
+type rolloutTestUpgrader struct{}
+
+func (rolloutTestUpgrader) UpgradeEnvironment(ctx context.Context, id string, version string) error {
+	return nil
+}
+func (rolloutTestUpgrader) RollbackEnvironment(ctx context.Context, id string, version string) error {
+	return nil
+}
+func (rolloutTestUpgrader) ProbeHealth(ctx context.Context, id string) (*health.CheckResult, error) {
+	return &health.CheckResult{Status: entities.HealthStatusHealthy}, nil
+}
+
+func newRolloutSetup(t *testing.T) (*handlers.RolloutHandler, *rolloutTestMockRepo, *envTestMockEnvRepo) {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	repo := new(rolloutTestMockRepo)
+	envRepo := new(envTestMockEnvRepo)
+	h := hub.NewHub(logger)
+	go h.Run()
+
+	svc := rollout.NewService(repo, envRepo, rolloutTestUpgrader{}, time.Millisecond)
+	return handlers.NewRolloutHandler(svc, h, logger), repo, envRepo
+}
+
+func TestRolloutHandler_Create(t *testing.T) {
+	handler, repo, envRepo := newRolloutSetup(t)
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	env.Status.Health = entities.HealthStatusHealthy
+	env.HealthCheck.Enabled = true
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	done := make(chan struct{})
+	repo.On("Update", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		if args.Get(1).(*entities.Rollout).IsFinished() {
+			close(done)
+		}
+	})
+
+	body := `{"environmentIds":["` + env.ID.Hex() + `"],"version":"2.0.0","canary":true}`
+	req := withUser(httptest.NewRequest("POST", "/api/v1/rollouts", bytes.NewBufferString(body)),
+		"u1", "alice", entities.UserRoleUser)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"pending"`)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("rollout did not finish")
+	}
+}
+
+// dialHub connects a WebSocket client registered with the hub, so broadcasts
+// are encoded and sent
+func dialHub(t *testing.T, h *hub.Hub, logger *logrus.Logger) *websocket.Conn {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := hub.NewClient("rollout-client", conn, h, logger)
+		h.RegisterClient(client)
+		go client.ReadPump()
+		go client.WritePump()
+	}))
+	t.Cleanup(server.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Run with -race: batch members are upgraded concurrently while clients
+// encode earlier updates
+func TestRolloutHandler_Create_BroadcastsProgress(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	h := hub.NewHub(logger)
+	go h.Run()
+	conn := dialHub(t, h, logger)
+
+	repo := new(rolloutTestMockRepo)
+	envRepo := new(envTestMockEnvRepo)
+	handler := handlers.NewRolloutHandler(rollout.NewService(repo, envRepo, rolloutTestUpgrader{}, time.Millisecond), h, logger)
+
+	var ids []string
+	for i := 0; i < 4; i++ {
+		env := sampleEnvForHandler(primitive.NewObjectID())
+		env.Status.Health = entities.HealthStatusHealthy
+		env.HealthCheck.Enabled = true
+		envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+		ids = append(ids, `"`+env.ID.Hex()+`"`)
+	}
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	body := `{"environmentIds":[` + strings.Join(ids, ",") + `],"version":"2.0.0","batchSize":4}`
+	req := withUser(httptest.NewRequest("POST", "/api/v1/rollouts", bytes.NewBufferString(body)),
+		"u1", "alice", entities.UserRoleUser)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		var msg hub.Message
+		require.NoError(t, conn.ReadJSON(&msg))
+		update, _ := msg.Payload["update"].(map[string]interface{})
+		if msg.Type != "rollout_update" || update["status"] != string(entities.RolloutStatusCompleted) {
+			continue
+		}
+		members, ok := update["members"].([]interface{})
+		require.True(t, ok)
+		assert.Len(t, members, 4)
+		return
+	}
+}
+
+func TestRolloutHandler_Create_InvalidBody(t *testing.T) {
+	handler, _, _ := newRolloutSetup(t)
+
+	req := httptest.NewRequest("POST", "/api/v1/rollouts", bytes.NewBufferString("{"))
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRolloutHandler_Create_ValidationError(t *testing.T) {
+	handler, _, _ := newRolloutSetup(t)
+
+	req := withUser(httptest.NewRequest("POST", "/api/v1/rollouts", bytes.NewBufferString(`{"environmentIds":["a"]}`)),
+		"u1", "alice", entities.UserRoleUser)
+	w := httptest.NewRecorder()
+
+	handler.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRolloutHandler_Get(t *testing.T) {
+	handler, repo, _ := newRolloutSetup(t)
+	id := primitive.NewObjectID().Hex()
+	repo.On("GetByID", mock.Anything, id).Return(&entities.Rollout{Status: entities.RolloutStatusPaused}, nil)
+	missing := primitive.NewObjectID().Hex()
+	repo.On("GetByID", mock.Anything, missing).Return(nil, errors.ErrRolloutNotFound)
+
+	w := httptest.NewRecorder()
+	handler.Get(w, mux.SetURLVars(httptest.NewRequest("GET", "/", nil), map[string]string{"id": id}))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "paused")
+
+	w = httptest.NewRecorder()
+	handler.Get(w, mux.SetURLVars(httptest.NewRequest("GET", "/", nil), map[string]string{"id": missing}))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRolloutHandler_List(t *testing.T) {
+	handler, repo, _ := newRolloutSetup(t)
+	repo.On("List", mock.Anything, mock.MatchedBy(func(f interfaces.RolloutFilter) bool {
+		return f.Status != nil && *f.Status == entities.RolloutStatusRunning
+	})).Return([]*entities.Rollout{{Status: entities.RolloutStatusRunning}}, nil)
+
+	w := httptest.NewRecorder()
+	handler.List(w, httptest.NewRequest("GET", "/api/v1/rollouts?status=running", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"rollouts"`)
+}
+
+func TestRolloutHandler_Steer_NotActive(t *testing.T) {
+	handler, repo, _ := newRolloutSetup(t)
+	id := primitive.NewObjectID().Hex()
+	repo.On("GetByID", mock.Anything, id).Return(&entities.Rollout{Status: entities.RolloutStatusCompleted}, nil)
+
+	for _, fn := range []http.HandlerFunc{handler.Pause, handler.Resume, handler.Abort} {
+		w := httptest.NewRecorder()
+		fn(w, mux.SetURLVars(httptest.NewRequest("POST", "/", nil), map[string]string{"id": id}))
+		assert.Equal(t, http.StatusConflict, w.Code)
+	}
+}
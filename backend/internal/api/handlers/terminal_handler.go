@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"app-env-manager/internal/service/terminal"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	terminalWriteTimeout = 10 * time.Second
+	terminalReadLimit    = 64 * 1024
+)
+
+// TerminalHandler handles browser terminal sessions and their recordings
+type TerminalHandler struct {
+	service *terminal.Service
+	logger  *logrus.Logger
+}
+
+// NewTerminalHandler creates a new terminal handler
+func NewTerminalHandler(service *terminal.Service, logger *logrus.Logger) *TerminalHandler {
+	return &TerminalHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Connect handles GET /ws/terminal/{id}. The caller must already be
+// authenticated; the session is opened before the upgrade so refusals are
+// plain HTTP errors.
+func (h *TerminalHandler) Connect(w http.ResponseWriter, r *http.Request, upgrader *websocket.Upgrader) {
+	id := mux.Vars(r)["id"]
+	size := terminal.Size{}
+	size.Cols, _ = strconv.Atoi(r.URL.Query().Get("cols"))
+	size.Rows, _ = strconv.Atoi(r.URL.Query().Get("rows"))
+
+	session, err := h.service.Open(r.Context(), id, size)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to upgrade terminal connection")
+		session.Close(r.Context())
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(terminalReadLimit)
+	// The session enforces its own idle and duration limits
+	conn.SetReadDeadline(time.Time{})
+
+	logger := h.logger.WithFields(logrus.Fields{
+		"environmentId": id,
+		"sessionId":     session.ID,
+	})
+	logger.Info("Terminal session started")
+
+	reason := session.Serve(r.Context(), &terminalClient{conn: conn})
+
+	conn.SetWriteDeadline(time.Now().Add(terminalWriteTimeout))
+	conn.WriteJSON(map[string]string{"type": "closed", "reason": reason})
+	conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason))
+
+	logger.WithField("reason", reason).Info("Terminal session ended")
+}
+
+// Recording handles GET /terminal-sessions/{id}/recording
+func (h *TerminalHandler) Recording(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	file, err := h.service.OpenRecording(id)
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		writeError(w, h.logger, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+id+".cast\"")
+	http.ServeContent(w, r, id+".cast", info.ModTime(), file)
+}
+
+// terminalClient adapts a WebSocket to a terminal client. Text messages are
+// JSON events; binary messages are raw input.
+type terminalClient struct {
+	conn *websocket.Conn
+}
+
+func (c *terminalClient) Next() (terminal.Event, error) {
+	for {
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return terminal.Event{}, err
+		}
+		if messageType == websocket.BinaryMessage {
+			return terminal.Event{Type: terminal.EventInput, Data: string(data)}, nil
+		}
+		var event terminal.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			continue
+		}
+		return event, nil
+	}
+}
+
+func (c *terminalClient) Send(p []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(terminalWriteTimeout))
+	return c.conn.WriteMessage(websocket.BinaryMessage, p)
+}
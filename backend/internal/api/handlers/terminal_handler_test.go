@@ -0,0 +1,169 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/api/handlers"
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"app-env-manager/internal/service/terminal"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// terminalTargets resolves every environment to the test SSH server
+type terminalTargets struct{ server *sshtest.Server }
+
+func (t terminalTargets) SSHTarget(env *entities.Environment) (*ssh.Target, error) {
+	target := t.server.Target()
+	return &target, nil
+}
+
+type terminalSetup struct {
+	envRepo *envTestMockEnvRepo
+	handler *handlers.TerminalHandler
+	server  *httptest.Server
+	dir     string
+	env     *entities.Environment
+}
+
+// newTerminalSetup serves the terminal handler with the given role in the
+// request context, as the router does after authenticating the token
+func newTerminalSetup(t *testing.T, role string) *terminalSetup {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	sshServer := sshtest.NewServer(t)
+	manager := ssh.NewManager(ssh.Config{ConnectionTimeout: 5 * time.Second, CommandTimeout: 10 * time.Second, MaxConnections: 5})
+	t.Cleanup(func() { manager.Close() })
+
+	envRepo := new(envTestMockEnvRepo)
+	auditRepo := new(envTestMockAuditRepo)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	dir := t.TempDir()
+	service := terminal.NewService(envRepo, auditRepo, manager, terminalTargets{sshServer}, terminal.Config{RecordingDir: dir})
+	handler := handlers.NewTerminalHandler(service, logger)
+
+	env := sampleEnvForHandler(primitive.NewObjectID())
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil).Maybe()
+
+	upgrader := &websocket.Upgrader{}
+	router := mux.NewRouter()
+	router.HandleFunc("/ws/terminal/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := ctxutil.WithUserFull(r.Context(), "u1", "alice", role)
+		handler.Connect(w, r.WithContext(ctx), upgrader)
+	})
+	router.HandleFunc("/terminal-sessions/{id}/recording", handler.Recording)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &terminalSetup{envRepo: envRepo, handler: handler, server: server, dir: dir, env: env}
+}
+
+func (s *terminalSetup) url(path string) string {
+	return "ws" + strings.TrimPrefix(s.server.URL, "http") + path
+}
+
+func TestTerminalHandler_Session(t *testing.T) {
+	s := newTerminalSetup(t, "admin")
+
+	conn, resp, err := websocket.DefaultDialer.Dial(s.url("/ws/terminal/"+s.env.ID.Hex()+"?cols=100&rows=30"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	require.NoError(t, conn.WriteJSON(terminal.Event{Type: terminal.EventResize, Cols: 120, Rows: 40}))
+	require.NoError(t, conn.WriteJSON(terminal.Event{Type: terminal.EventInput, Data: "echo from-json\n"}))
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("echo from-binary\nexit\n")))
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var output strings.Builder
+	var closed map[string]string
+	for closed == nil {
+		messageType, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		if messageType == websocket.BinaryMessage {
+			output.Write(data)
+			continue
+		}
+		require.NoError(t, json.Unmarshal(data, &closed))
+	}
+	assert.Contains(t, output.String(), "from-json\n")
+	assert.Contains(t, output.String(), "from-binary\n")
+	assert.Equal(t, map[string]string{"type": "closed", "reason": terminal.ReasonShellExited}, closed)
+
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseNormalClosure))
+
+	entries, err := os.ReadDir(s.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	id := strings.TrimSuffix(entries[0].Name(), ".cast")
+
+	recording, err := http.Get(s.server.URL + "/terminal-sessions/" + id + "/recording")
+	require.NoError(t, err)
+	defer recording.Body.Close()
+	assert.Equal(t, http.StatusOK, recording.StatusCode)
+	assert.Equal(t, "application/x-asciicast", recording.Header.Get("Content-Type"))
+	body, err := io.ReadAll(recording.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"i","echo from-json\n"`)
+	assert.Contains(t, string(body), `"r","120x40"`)
+}
+
+func TestTerminalHandler_Forbidden(t *testing.T) {
+	s := newTerminalSetup(t, "user")
+
+	_, resp, err := websocket.DefaultDialer.Dial(s.url("/ws/terminal/"+s.env.ID.Hex()), nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	entries, _ := os.ReadDir(s.dir)
+	assert.Empty(t, entries)
+}
+
+func TestTerminalHandler_UpgradeFailure(t *testing.T) {
+	s := newTerminalSetup(t, "admin")
+
+	// A plain GET cannot be upgraded; the opened session is closed again
+	resp, err := http.Get(s.server.URL + "/ws/terminal/" + s.env.ID.Hex())
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	entries, err := os.ReadDir(s.dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the aborted session is still recorded")
+}
+
+func TestTerminalHandler_Recording_NotFound(t *testing.T) {
+	s := newTerminalSetup(t, "admin")
+
+	req := httptest.NewRequest("GET", "/terminal-sessions/ts-0123456789abcdef01234567/recording", nil)
+	req = muxSetVar(req, "id", "ts-0123456789abcdef01234567")
+	w := httptest.NewRecorder()
+	s.handler.Recording(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req = muxSetVar(httptest.NewRequest("GET", "/terminal-sessions/x/recording", nil), "id", filepath.Join("..", "x"))
+	w = httptest.NewRecorder()
+	s.handler.Recording(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
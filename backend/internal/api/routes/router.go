@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"app-env-manager/internal/api/adapter"
 	"app-env-manager/internal/api/handlers"
 	"app-env-manager/internal/api/middleware"
+	"app-env-manager/internal/ctxutil"
 	"app-env-manager/internal/websocket/hub"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
@@ -24,6 +26,11 @@ type Config struct {
 	LogHandler         *handlers.LogHandler
 	AuthHandler        *handlers.AuthHandler
 	UserHandler        *handlers.UserHandler
+	ApprovalHandler    *handlers.ApprovalHandler
+	BulkHandler        *handlers.BulkOperationHandler
+	RolloutHandler     *handlers.RolloutHandler
+	TerminalHandler    *handlers.TerminalHandler
+	RemoteLogHandler   *handlers.RemoteLogHandler
 	AuthService        interface{}
 	UserService        interface{}
 	WebSocketHub       *hub.Hub
@@ -39,9 +46,19 @@ func NewRouter(cfg Config) http.Handler {
 	// Login rate limiter: 10 attempts per minute per IP
 	loginRateLimiter := middleware.NewRateLimiter(10, time.Minute)
 
+	// Remote log rate limiter: 30 reads or follows per minute per IP, shared
+	// by the REST and WebSocket endpoints
+	remoteLogRateLimiter := middleware.NewRateLimiter(30, time.Minute)
+
 	// WebSocket endpoint – registered before the API sub-router so the
 	// gorilla/mux middleware chain does not interfere with the upgrade.
 	r.HandleFunc("/ws", HandleWebSocket(cfg.WebSocketHub, cfg.Logger, cfg.JWTSecret, cfg.AllowedOrigins)).Methods("GET")
+	r.HandleFunc("/ws/terminal/{id}", HandleTerminal(cfg.TerminalHandler, cfg.JWTSecret, cfg.AllowedOrigins)).Methods("GET")
+	r.Handle("/ws/remote-logs/{id}/{source}",
+		middleware.RateLimitMiddleware(remoteLogRateLimiter)(
+			HandleRemoteLogs(cfg.RemoteLogHandler, cfg.JWTSecret, cfg.AllowedOrigins),
+		),
+	).Methods("GET")
 
 	// Setup middleware for all API routes
 	apiRouter := r.PathPrefix("/api").Subrouter()
@@ -91,16 +108,49 @@ func NewRouter(cfg Config) http.Handler {
 	envRoutes.HandleFunc("/{id}", cfg.EnvironmentHandler.Get).Methods("GET")
 	envRoutes.HandleFunc("/{id}/versions", cfg.EnvironmentHandler.GetVersions).Methods("GET")
 	envRoutes.HandleFunc("/{id}/logs", adapter.GinHandlerAdapter(cfg.LogHandler.GetEnvironmentLogs)).Methods("GET")
+	envRoutes.Handle("/{id}/remote-logs/{source}",
+		middleware.RateLimitMiddleware(remoteLogRateLimiter)(http.HandlerFunc(cfg.RemoteLogHandler.Tail)),
+	).Methods("GET")
 
 	// Operator actions: any authenticated user
 	envRoutes.HandleFunc("/{id}/restart", cfg.EnvironmentHandler.Restart).Methods("POST")
 	envRoutes.HandleFunc("/{id}/upgrade", cfg.EnvironmentHandler.Upgrade).Methods("POST")
+	envRoutes.HandleFunc("/{id}/actions/{name}", cfg.EnvironmentHandler.RunAction).Methods("POST")
 	envRoutes.HandleFunc("/{id}/check-health", cfg.EnvironmentHandler.CheckHealth).Methods("POST")
 
 	// Mutating CRUD: admin only
 	envRoutes.Handle("", middleware.RequireAdmin(http.HandlerFunc(cfg.EnvironmentHandler.Create))).Methods("POST")
 	envRoutes.Handle("/{id}", middleware.RequireAdmin(http.HandlerFunc(cfg.EnvironmentHandler.Update))).Methods("PUT")
 	envRoutes.Handle("/{id}", middleware.RequireAdmin(http.HandlerFunc(cfg.EnvironmentHandler.Delete))).Methods("DELETE")
+	envRoutes.Handle("/{id}/exec", middleware.RequireAdmin(http.HandlerFunc(cfg.EnvironmentHandler.Exec))).Methods("POST")
+
+	// Approval routes: any authenticated user may list; the service enforces
+	// the approver role and the four-eyes rule on decisions
+	approvalRoutes := protected.PathPrefix("/approvals").Subrouter()
+	approvalRoutes.HandleFunc("", cfg.ApprovalHandler.List).Methods("GET")
+	approvalRoutes.HandleFunc("/{id}", cfg.ApprovalHandler.Get).Methods("GET")
+	approvalRoutes.HandleFunc("/{id}/approve", cfg.ApprovalHandler.Approve).Methods("POST")
+	approvalRoutes.HandleFunc("/{id}/reject", cfg.ApprovalHandler.Reject).Methods("POST")
+
+	// Bulk operation routes: operator actions, any authenticated user
+	bulkRoutes := protected.PathPrefix("/bulk-operations").Subrouter()
+	bulkRoutes.HandleFunc("", cfg.BulkHandler.List).Methods("GET")
+	bulkRoutes.HandleFunc("", cfg.BulkHandler.Create).Methods("POST")
+	bulkRoutes.HandleFunc("/{id}", cfg.BulkHandler.Get).Methods("GET")
+
+	// Rollout routes: operator actions, any authenticated user
+	rolloutRoutes := protected.PathPrefix("/rollouts").Subrouter()
+	rolloutRoutes.HandleFunc("", cfg.RolloutHandler.List).Methods("GET")
+	rolloutRoutes.HandleFunc("", cfg.RolloutHandler.Create).Methods("POST")
+	rolloutRoutes.HandleFunc("/{id}", cfg.RolloutHandler.Get).Methods("GET")
+	rolloutRoutes.HandleFunc("/{id}/pause", cfg.RolloutHandler.Pause).Methods("POST")
+	rolloutRoutes.HandleFunc("/{id}/resume", cfg.RolloutHandler.Resume).Methods("POST")
+	rolloutRoutes.HandleFunc("/{id}/abort", cfg.RolloutHandler.Abort).Methods("POST")
+
+	// Terminal recordings: admin only
+	terminalRoutes := protected.PathPrefix("/terminal-sessions").Subrouter()
+	terminalRoutes.Use(middleware.RequireAdmin)
+	terminalRoutes.HandleFunc("/{id}/recording", cfg.TerminalHandler.Recording).Methods("GET")
 
 	// Log routes
 	logRoutes := protected.PathPrefix("/logs").Subrouter()
@@ -134,22 +184,8 @@ func HealthCheck(w http.ResponseWriter, r *http.Request) {
 // upgrading the connection.
 func HandleWebSocket(wsHub *hub.Hub, logger *logrus.Logger, jwtSecret string, allowedOrigins []string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Authenticate via token query parameter (browser WebSocket API does
-		// not support custom request headers).
-		tokenString := r.URL.Query().Get("token")
-		if tokenString == "" {
-			http.Error(w, "Authentication required", http.StatusUnauthorized)
-			return
-		}
-
-		token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-			if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-		if err != nil || !token.Valid {
-			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		token, ok := authenticateWebSocket(w, r, jwtSecret)
+		if !ok {
 			return
 		}
 
@@ -167,12 +203,93 @@ func HandleWebSocket(wsHub *hub.Hub, logger *logrus.Logger, jwtSecret string, al
 
 		clientID := generateClientID()
 		client := hub.NewClient(clientID, conn, wsHub, logger)
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			client.Role, _ = claims["role"].(string)
+		}
 		wsHub.RegisterClient(client)
 		go client.WritePump()
 		go client.ReadPump()
 	}
 }
 
+// HandleTerminal handles browser terminal WebSocket requests. It
+// authenticates like HandleWebSocket and passes the user, including the role
+// the terminal service checks, in the request context.
+func HandleTerminal(handler *handlers.TerminalHandler, jwtSecret string, allowedOrigins []string) http.HandlerFunc {
+	upgrader := &websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return isOriginAllowed(r, allowedOrigins)
+		},
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := authenticateWebSocketUser(w, r, jwtSecret)
+		if !ok {
+			return
+		}
+		handler.Connect(w, r.WithContext(ctx), upgrader)
+	}
+}
+
+// HandleRemoteLogs handles remote log follow WebSocket requests. Any
+// authenticated user may follow a log source.
+func HandleRemoteLogs(handler *handlers.RemoteLogHandler, jwtSecret string, allowedOrigins []string) http.HandlerFunc {
+	upgrader := &websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return isOriginAllowed(r, allowedOrigins)
+		},
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, ok := authenticateWebSocketUser(w, r, jwtSecret)
+		if !ok {
+			return
+		}
+		handler.Follow(w, r.WithContext(ctx), upgrader)
+	}
+}
+
+// authenticateWebSocketUser authenticates like authenticateWebSocket and
+// returns the request context carrying the token's user and role
+func authenticateWebSocketUser(w http.ResponseWriter, r *http.Request, jwtSecret string) (context.Context, bool) {
+	token, ok := authenticateWebSocket(w, r, jwtSecret)
+	if !ok {
+		return nil, false
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	userID, _ := claims["userId"].(string)
+	if userID == "" {
+		http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+		return nil, false
+	}
+	username, _ := claims["username"].(string)
+	role, _ := claims["role"].(string)
+
+	return ctxutil.WithUserFull(r.Context(), userID, username, role), true
+}
+
+// authenticateWebSocket validates the JWT token supplied as the ?token= query
+// parameter (browser WebSocket API does not support custom request headers).
+// It writes the error response and returns false when the token is missing
+// or invalid.
+func authenticateWebSocket(w http.ResponseWriter, r *http.Request, jwtSecret string) (*jwt.Token, bool) {
+	tokenString := r.URL.Query().Get("token")
+	if tokenString == "" {
+		http.Error(w, "Authentication required", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(jwtSecret), nil
+	})
+	if err != nil || !token.Valid {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return nil, false
+	}
+	return token, true
+}
+
 // isOriginAllowed returns true when the request Origin header matches one of
 // the configured allowed origins. Connections without an Origin header
 // (e.g. server-to-server) are permitted.
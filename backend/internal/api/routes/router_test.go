@@ -11,12 +11,15 @@ import (
 
 	"app-env-manager/internal/api/handlers"
 	"app-env-manager/internal/api/routes"
+	"app-env-manager/internal/service/remotelog"
+	"app-env-manager/internal/service/terminal"
 	"app-env-manager/internal/websocket/hub"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock handlers
@@ -237,6 +240,62 @@ func TestHandleWebSocket_UpgradeError(t *testing.T) {
 	assert.NotEqual(t, http.StatusSwitchingProtocols, w.Code)
 }
 
+func TestHandleTerminal_Authentication(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	const testSecret = "test-secret"
+	service := terminal.NewService(nil, nil, nil, nil, terminal.Config{RecordingDir: t.TempDir()})
+	handler := routes.HandleTerminal(handlers.NewTerminalHandler(service, logger), testSecret, []string{"http://localhost"})
+
+	// No token
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ws/terminal/env1", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Token without a user
+	token, err := createTestToken(testSecret, "")
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ws/terminal/env1?token="+token, nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Authenticated, but the token carries no admin role
+	token, err = createTestToken(testSecret, "user-123")
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ws/terminal/env1?token="+token, nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHandleRemoteLogs_Authentication(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	const testSecret = "test-secret"
+	service := remotelog.NewService(nil, nil, nil, remotelog.Config{})
+	handler := routes.HandleRemoteLogs(handlers.NewRemoteLogHandler(service, logger), testSecret, []string{"http://localhost"})
+
+	// No token
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ws/remote-logs/env1/app", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Token without a user
+	token, err := createTestToken(testSecret, "")
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ws/remote-logs/env1/app?token="+token, nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Authenticated; the handler rejects the request before any lookup
+	token, err = createTestToken(testSecret, "user-123")
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", "/ws/remote-logs/env1/app?lines=abc&token="+token, nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
 func TestGenerateClientID(t *testing.T) {
 	// Verify that concurrent WebSocket connections each get a unique client ID
 	// by connecting multiple times and confirming each connection succeeds.
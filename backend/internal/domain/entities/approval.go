@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ApprovalStatus represents the state of an approval request
+type ApprovalStatus string
+
+const (
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusApproved ApprovalStatus = "approved"
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+	ApprovalStatusExpired  ApprovalStatus = "expired"
+)
+
+// ApprovalOperation identifies the operation awaiting approval
+type ApprovalOperation string
+
+const (
+	ApprovalOperationRestart      ApprovalOperation = "restart"
+	ApprovalOperationUpgrade      ApprovalOperation = "upgrade"
+	ApprovalOperationCustomAction ApprovalOperation = "custom_action"
+)
+
+// ApprovalRequest represents an operation on a protected environment that
+// must be approved by a second user before it is executed
+type ApprovalRequest struct {
+	ID              primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	EnvironmentID   primitive.ObjectID     `bson:"environmentId" json:"environmentId"`
+	EnvironmentName string                 `bson:"environmentName" json:"environmentName"`
+	Operation       ApprovalOperation      `bson:"operation" json:"operation"`
+	Parameters      map[string]interface{} `bson:"parameters,omitempty" json:"parameters,omitempty"`
+	Status          ApprovalStatus         `bson:"status" json:"status"`
+	RequestedBy     Actor                  `bson:"requestedBy" json:"requestedBy"`
+	DecidedBy       *Actor                 `bson:"decidedBy,omitempty" json:"decidedBy,omitempty"`
+	Comment         string                 `bson:"comment,omitempty" json:"comment,omitempty"`
+	OperationID     string                 `bson:"operationId,omitempty" json:"operationId,omitempty"`
+	CreatedAt       time.Time              `bson:"createdAt" json:"createdAt"`
+	ExpiresAt       time.Time              `bson:"expiresAt" json:"expiresAt"`
+	DecidedAt       *time.Time             `bson:"decidedAt,omitempty" json:"decidedAt,omitempty"`
+}
+
+// NewApprovalRequest creates a pending approval request that expires after ttl
+func NewApprovalRequest(env *Environment, operation ApprovalOperation, params map[string]interface{}, requester Actor, ttl time.Duration) *ApprovalRequest {
+	now := time.Now()
+	return &ApprovalRequest{
+		ID:              primitive.NewObjectID(),
+		EnvironmentID:   env.ID,
+		EnvironmentName: env.Name,
+		Operation:       operation,
+		Parameters:      params,
+		Status:          ApprovalStatusPending,
+		RequestedBy:     requester,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(ttl),
+	}
+}
+
+// IsPending reports whether the request is still awaiting a decision
+func (a *ApprovalRequest) IsPending() bool {
+	return a.Status == ApprovalStatusPending
+}
+
+// IsExpired reports whether the approval window has elapsed at the given time
+func (a *ApprovalRequest) IsExpired(now time.Time) bool {
+	return now.After(a.ExpiresAt)
+}
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNewApprovalRequest(t *testing.T) {
+	env := &Environment{ID: primitive.NewObjectID(), Name: "prod"}
+	requester := Actor{Type: "user", ID: "u1", Name: "alice"}
+
+	req := NewApprovalRequest(env, ApprovalOperationUpgrade, map[string]interface{}{"version": "2.0.0"}, requester, time.Hour)
+
+	assert.False(t, req.ID.IsZero())
+	assert.Equal(t, env.ID, req.EnvironmentID)
+	assert.Equal(t, "prod", req.EnvironmentName)
+	assert.Equal(t, ApprovalOperationUpgrade, req.Operation)
+	assert.Equal(t, ApprovalStatusPending, req.Status)
+	assert.Equal(t, requester, req.RequestedBy)
+	assert.Nil(t, req.DecidedBy)
+	assert.Equal(t, time.Hour, req.ExpiresAt.Sub(req.CreatedAt))
+	assert.True(t, req.IsPending())
+}
+
+func TestApprovalRequest_IsExpired(t *testing.T) {
+	now := time.Now()
+	req := &ApprovalRequest{ExpiresAt: now}
+
+	assert.False(t, req.IsExpired(now.Add(-time.Second)))
+	assert.True(t, req.IsExpired(now.Add(time.Second)))
+}
+
+func TestUserRole_AtLeast(t *testing.T) {
+	assert.True(t, UserRoleAdmin.AtLeast(UserRoleUser))
+	assert.True(t, UserRoleUser.AtLeast(UserRoleUser))
+	assert.False(t, UserRoleViewer.AtLeast(UserRoleUser))
+	assert.False(t, UserRole("unknown").AtLeast(UserRoleViewer))
+	assert.Equal(t, []UserRole{UserRoleUser, UserRoleAdmin}, RolesAtLeast(UserRoleUser))
+}
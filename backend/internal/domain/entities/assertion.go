@@ -0,0 +1,25 @@
+package entities
+
+// SuccessCriteria are assertions a response must satisfy to count as a
+// success. Every assertion must hold.
+type SuccessCriteria struct {
+	StatusCodes []int               `bson:"statusCodes,omitempty" json:"statusCodes,omitempty"` // replaces the default 2xx check
+	JSONPath    []JSONPathAssertion `bson:"jsonPath,omitempty" json:"jsonPath,omitempty"`
+	BodyRegex   string              `bson:"bodyRegex,omitempty" json:"bodyRegex,omitempty"`
+	Headers     map[string]string   `bson:"headers,omitempty" json:"headers,omitempty"` // required headers; an empty value only requires presence
+}
+
+// JSONPathAssertion checks the value at a JSONPath in the response body.
+// Equals compares the whole value; Contains matches a substring of a string
+// value or an element of an array value; In lists the allowed values. The
+// comparisons require a number.
+type JSONPathAssertion struct {
+	Path        string        `bson:"path" json:"path"`
+	Equals      interface{}   `bson:"equals,omitempty" json:"equals,omitempty"`
+	Contains    interface{}   `bson:"contains,omitempty" json:"contains,omitempty"`
+	In          []interface{} `bson:"in,omitempty" json:"in,omitempty"`
+	GreaterThan *float64      `bson:"greaterThan,omitempty" json:"greaterThan,omitempty"`
+	AtLeast     *float64      `bson:"atLeast,omitempty" json:"atLeast,omitempty"`
+	LessThan    *float64      `bson:"lessThan,omitempty" json:"lessThan,omitempty"`
+	AtMost      *float64      `bson:"atMost,omitempty" json:"atMost,omitempty"`
+}
@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuccessCriteria_JSON(t *testing.T) {
+	raw := `{"statusCodes":[200,409],"jsonPath":[{"path":"$.status","equals":"ok"},{"path":"$.tags","contains":"blue"}],"bodyRegex":"ready","headers":{"X-Deploy-Id":""}}`
+
+	var criteria SuccessCriteria
+	require.NoError(t, json.Unmarshal([]byte(raw), &criteria))
+
+	assert.Equal(t, []int{200, 409}, criteria.StatusCodes)
+	require.Len(t, criteria.JSONPath, 2)
+	assert.Equal(t, "ok", criteria.JSONPath[0].Equals)
+	assert.Nil(t, criteria.JSONPath[0].Contains)
+	assert.Equal(t, "blue", criteria.JSONPath[1].Contains)
+	assert.Equal(t, "ready", criteria.BodyRegex)
+	assert.Contains(t, criteria.Headers, "X-Deploy-Id")
+}
+
+func TestHTTPPolicy_SuccessOmittedByDefault(t *testing.T) {
+	data, err := json.Marshal(HTTPPolicy{})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "success")
+}
@@ -32,6 +32,13 @@ const (
 	EventTypeCredentialUpdate  EventType = "credential_update"
 	EventTypeConnectionFailed  EventType = "connection_failed"
 	EventTypeCommandExecuted   EventType = "command_executed"
+	EventTypeCommandDenied     EventType = "command_denied"
+	EventTypeApprovalRequested EventType = "approval_requested"
+	EventTypeApprovalDecided   EventType = "approval_decided"
+	EventTypeCustomAction      EventType = "custom_action"
+	EventTypeBulkOperation     EventType = "bulk_operation"
+	EventTypeDryRun            EventType = "dry_run"
+	EventTypeTerminalSession   EventType = "terminal_session"
 )
 
 // Severity represents the severity level
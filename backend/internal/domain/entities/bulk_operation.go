@@ -0,0 +1,137 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BulkOperationType identifies the operation run across environments
+type BulkOperationType string
+
+const (
+	BulkOperationRestart      BulkOperationType = "restart"
+	BulkOperationUpgrade      BulkOperationType = "upgrade"
+	BulkOperationCustomAction BulkOperationType = "custom_action"
+	BulkOperationHealthCheck  BulkOperationType = "health_check"
+)
+
+// RequiresApproval reports whether the operation, run on one environment
+// that requires approval, goes through the approval workflow. Only health
+// checks do not.
+func (t BulkOperationType) RequiresApproval() bool {
+	return t != BulkOperationHealthCheck
+}
+
+// BulkOperationStatus represents the aggregated state of a bulk operation
+type BulkOperationStatus string
+
+const (
+	BulkStatusPending         BulkOperationStatus = "pending"
+	BulkStatusRunning         BulkOperationStatus = "running"
+	BulkStatusCompleted       BulkOperationStatus = "completed"
+	BulkStatusPartiallyFailed BulkOperationStatus = "partially_failed"
+	BulkStatusFailed          BulkOperationStatus = "failed"
+	BulkStatusStopped         BulkOperationStatus = "stopped"
+)
+
+// ChildOperationStatus represents the state of one environment's operation
+type ChildOperationStatus string
+
+const (
+	ChildStatusPending   ChildOperationStatus = "pending"
+	ChildStatusRunning   ChildOperationStatus = "running"
+	ChildStatusSucceeded ChildOperationStatus = "succeeded"
+	ChildStatusFailed    ChildOperationStatus = "failed"
+	ChildStatusSkipped   ChildOperationStatus = "skipped"
+	ChildStatusCancelled ChildOperationStatus = "cancelled"
+)
+
+// BulkOperation is a parent operation that runs the same operation against
+// many environments with bounded parallelism
+type BulkOperation struct {
+	ID               primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	OperationID      string                 `bson:"operationId" json:"operationId"`
+	Operation        BulkOperationType      `bson:"operation" json:"operation"`
+	Parameters       map[string]interface{} `bson:"parameters,omitempty" json:"parameters,omitempty"`
+	Selector         map[string]string      `bson:"selector,omitempty" json:"selector,omitempty"`
+	MaxParallel      int                    `bson:"maxParallel" json:"maxParallel"`
+	FailureThreshold int                    `bson:"failureThreshold" json:"failureThreshold"` // 0 never stops early
+	Status           BulkOperationStatus    `bson:"status" json:"status"`
+	Children         []ChildOperation       `bson:"children" json:"children"`
+	Summary          BulkSummary            `bson:"summary" json:"summary"`
+	CreatedBy        Actor                  `bson:"createdBy" json:"createdBy"`
+	CreatedAt        time.Time              `bson:"createdAt" json:"createdAt"`
+	StartedAt        *time.Time             `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	CompletedAt      *time.Time             `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// ChildOperation tracks the operation on a single environment
+type ChildOperation struct {
+	OperationID     string               `bson:"operationId" json:"operationId"`
+	EnvironmentID   primitive.ObjectID   `bson:"environmentId" json:"environmentId"`
+	EnvironmentName string               `bson:"environmentName" json:"environmentName"`
+	Status          ChildOperationStatus `bson:"status" json:"status"`
+	Error           string               `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt       *time.Time           `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	CompletedAt     *time.Time           `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// BulkSummary counts child operations by outcome
+type BulkSummary struct {
+	Total     int `bson:"total" json:"total"`
+	Pending   int `bson:"pending" json:"pending"`
+	Running   int `bson:"running" json:"running"`
+	Succeeded int `bson:"succeeded" json:"succeeded"`
+	Failed    int `bson:"failed" json:"failed"`
+	Skipped   int `bson:"skipped" json:"skipped"`
+	Cancelled int `bson:"cancelled" json:"cancelled"`
+}
+
+// Summarize recounts the child operations into the summary
+func (b *BulkOperation) Summarize() BulkSummary {
+	summary := BulkSummary{Total: len(b.Children)}
+	for _, child := range b.Children {
+		switch child.Status {
+		case ChildStatusPending:
+			summary.Pending++
+		case ChildStatusRunning:
+			summary.Running++
+		case ChildStatusSucceeded:
+			summary.Succeeded++
+		case ChildStatusFailed:
+			summary.Failed++
+		case ChildStatusSkipped:
+			summary.Skipped++
+		case ChildStatusCancelled:
+			summary.Cancelled++
+		}
+	}
+	b.Summary = summary
+	return summary
+}
+
+// FinalStatus derives the aggregated status once no child is left running.
+// stopped reports whether the failure threshold halted scheduling.
+func (b *BulkOperation) FinalStatus(stopped bool) BulkOperationStatus {
+	summary := b.Summarize()
+	switch {
+	case stopped:
+		return BulkStatusStopped
+	case summary.Failed == 0:
+		return BulkStatusCompleted
+	case summary.Succeeded == 0:
+		return BulkStatusFailed
+	default:
+		return BulkStatusPartiallyFailed
+	}
+}
+
+// IsFinished reports whether the bulk operation has reached a terminal status
+func (b *BulkOperation) IsFinished() bool {
+	switch b.Status {
+	case BulkStatusCompleted, BulkStatusPartiallyFailed, BulkStatusFailed, BulkStatusStopped:
+		return true
+	}
+	return false
+}
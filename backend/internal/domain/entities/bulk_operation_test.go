@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func bulkWithChildren(statuses ...ChildOperationStatus) *BulkOperation {
+	op := &BulkOperation{}
+	for _, status := range statuses {
+		op.Children = append(op.Children, ChildOperation{Status: status})
+	}
+	return op
+}
+
+func TestBulkOperation_Summarize(t *testing.T) {
+	op := bulkWithChildren(ChildStatusPending, ChildStatusRunning, ChildStatusSucceeded,
+		ChildStatusSucceeded, ChildStatusFailed, ChildStatusSkipped, ChildStatusCancelled)
+
+	summary := op.Summarize()
+
+	assert.Equal(t, BulkSummary{Total: 7, Pending: 1, Running: 1, Succeeded: 2, Failed: 1, Skipped: 1, Cancelled: 1}, summary)
+	assert.Equal(t, summary, op.Summary)
+}
+
+func TestBulkOperation_FinalStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		op       *BulkOperation
+		stopped  bool
+		expected BulkOperationStatus
+	}{
+		{"all succeeded", bulkWithChildren(ChildStatusSucceeded, ChildStatusSkipped), false, BulkStatusCompleted},
+		{"all failed", bulkWithChildren(ChildStatusFailed, ChildStatusFailed), false, BulkStatusFailed},
+		{"mixed", bulkWithChildren(ChildStatusSucceeded, ChildStatusFailed), false, BulkStatusPartiallyFailed},
+		{"stopped", bulkWithChildren(ChildStatusFailed, ChildStatusCancelled), true, BulkStatusStopped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.op.FinalStatus(tt.stopped))
+		})
+	}
+}
+
+func TestBulkOperation_IsFinished(t *testing.T) {
+	assert.False(t, (&BulkOperation{Status: BulkStatusPending}).IsFinished())
+	assert.False(t, (&BulkOperation{Status: BulkStatusRunning}).IsFinished())
+	assert.True(t, (&BulkOperation{Status: BulkStatusCompleted}).IsFinished())
+	assert.True(t, (&BulkOperation{Status: BulkStatusStopped}).IsFinished())
+}
+
+func TestEnvironment_MatchesLabels(t *testing.T) {
+	env := &Environment{Labels: map[string]string{"tier": "test", "team": "payments"}}
+
+	assert.True(t, env.MatchesLabels(map[string]string{"tier": "test"}))
+	assert.True(t, env.MatchesLabels(map[string]string{"tier": "test", "team": "payments"}))
+	assert.False(t, env.MatchesLabels(map[string]string{"tier": "prod"}))
+	assert.False(t, env.MatchesLabels(nil))
+}
+
+func TestCommandConfig_FindAction(t *testing.T) {
+	cfg := CommandConfig{Actions: []CustomAction{
+		{Name: "clear-cache", Command: CommandDetails{Command: "redis-cli FLUSHALL"}},
+	}}
+
+	action, ok := cfg.FindAction("clear-cache")
+	assert.True(t, ok)
+	assert.Equal(t, "redis-cli FLUSHALL", action.Command.Command)
+
+	_, ok = cfg.FindAction("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,32 @@
+package entities
+
+// CommandRuleType is how a command rule's pattern is matched
+type CommandRuleType string
+
+const (
+	// CommandRuleExact matches the whole command line
+	CommandRuleExact CommandRuleType = "exact"
+	// CommandRulePrefix matches commands starting with the pattern
+	CommandRulePrefix CommandRuleType = "prefix"
+	// CommandRuleGlob matches the command's arguments one by one
+	CommandRuleGlob CommandRuleType = "glob"
+)
+
+// CommandRule allows SSH commands matching its pattern. Patterns may contain
+// {name} parameter slots; Params constrains a slot's value with a regular
+// expression.
+type CommandRule struct {
+	Name    string            `bson:"name" json:"name"`
+	Type    CommandRuleType   `bson:"type" json:"type"`
+	Pattern string            `bson:"pattern" json:"pattern"`
+	Params  map[string]string `bson:"params,omitempty" json:"params,omitempty"`
+}
+
+// DisplayName names the rule in audit entries and errors, falling back to
+// its pattern
+func (r CommandRule) DisplayName() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return r.Pattern
+}
@@ -0,0 +1,12 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandRule_DisplayName(t *testing.T) {
+	assert.Equal(t, "restart", CommandRule{Name: "restart", Pattern: "systemctl restart app"}.DisplayName())
+	assert.Equal(t, "systemctl restart app", CommandRule{Pattern: "systemctl restart app"}.DisplayName())
+}
@@ -0,0 +1,61 @@
+package entities
+
+// DockerTransport selects how the Docker Engine API is reached
+type DockerTransport string
+
+const (
+	// DockerTransportSSH forwards the host's Docker socket over SSH
+	DockerTransportSSH DockerTransport = "ssh"
+	// DockerTransportTLS connects to the host's Engine API port with TLS
+	DockerTransportTLS DockerTransport = "tls"
+)
+
+// Docker defaults
+const (
+	DefaultDockerSocket      = "/var/run/docker.sock"
+	DefaultDockerTLSPort     = 2376
+	DefaultDockerStopTimeout = 10  // seconds
+	DefaultDockerTimeout     = 300 // seconds
+)
+
+// DockerConfig connects an environment's host to its Docker Engine API.
+// Restarts restart the containers, upgrades pull the new image and recreate
+// them, and health checks report their state.
+type DockerConfig struct {
+	Transport   DockerTransport  `bson:"transport,omitempty" json:"transport,omitempty"`     // "ssh" (default) or "tls"
+	SocketPath  string           `bson:"socketPath,omitempty" json:"socketPath,omitempty"`   // For SSH: default /var/run/docker.sock
+	Port        int              `bson:"port,omitempty" json:"port,omitempty"`               // For TLS: default 2376 on the target host
+	TLS         *DockerTLSConfig `bson:"tls,omitempty" json:"tls,omitempty"`                 // For TLS: certificates; the client key is kept in metadata
+	APIVersion  string           `bson:"apiVersion,omitempty" json:"apiVersion,omitempty"`   // Engine API version, default 1.41
+	Containers  []string         `bson:"containers" json:"containers"`                       // Container names, in restart order
+	Image       string           `bson:"image,omitempty" json:"image,omitempty"`             // Upgrade image with {VERSION}; default the container's repository tagged with the version
+	StopTimeout int              `bson:"stopTimeout,omitempty" json:"stopTimeout,omitempty"` // seconds before containers are killed, default 10
+	Timeout     int              `bson:"timeout,omitempty" json:"timeout,omitempty"`         // seconds for a whole operation, default 300
+}
+
+// DockerTLSConfig holds PEM encoded certificates for the Engine API
+type DockerTLSConfig struct {
+	CACert             string `bson:"caCert,omitempty" json:"caCert,omitempty"`
+	ClientCert         string `bson:"clientCert,omitempty" json:"clientCert,omitempty"`
+	InsecureSkipVerify bool   `bson:"insecureSkipVerify,omitempty" json:"insecureSkipVerify,omitempty"` // test/dev only
+}
+
+// WithDefaults returns a copy with unset settings defaulted
+func (c DockerConfig) WithDefaults() DockerConfig {
+	if c.Transport == "" {
+		c.Transport = DockerTransportSSH
+	}
+	if c.SocketPath == "" {
+		c.SocketPath = DefaultDockerSocket
+	}
+	if c.Port == 0 {
+		c.Port = DefaultDockerTLSPort
+	}
+	if c.StopTimeout <= 0 {
+		c.StopTimeout = DefaultDockerStopTimeout
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultDockerTimeout
+	}
+	return c
+}
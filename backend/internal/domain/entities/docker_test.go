@@ -0,0 +1,31 @@
+package entities_test
+
+import (
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerConfig_WithDefaults(t *testing.T) {
+	config := entities.DockerConfig{Containers: []string{"app"}}.WithDefaults()
+
+	assert.Equal(t, entities.DockerTransportSSH, config.Transport)
+	assert.Equal(t, "/var/run/docker.sock", config.SocketPath)
+	assert.Equal(t, 2376, config.Port)
+	assert.Equal(t, 10, config.StopTimeout)
+	assert.Equal(t, 300, config.Timeout)
+	assert.Equal(t, []string{"app"}, config.Containers)
+}
+
+func TestDockerConfig_WithDefaults_KeepsSettings(t *testing.T) {
+	config := entities.DockerConfig{
+		Transport:   entities.DockerTransportTLS,
+		SocketPath:  "/run/user/1000/docker.sock",
+		Port:        12376,
+		StopTimeout: 30,
+		Timeout:     60,
+	}
+
+	assert.Equal(t, config, config.WithDefaults())
+}
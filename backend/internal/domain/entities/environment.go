@@ -1,6 +1,8 @@
 package entities
 
 import (
+	"net"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -8,19 +10,37 @@ import (
 
 // Environment represents an application environment
 type Environment struct {
-	ID             primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
-	Name           string                 `bson:"name" json:"name"`
-	Description    string                 `bson:"description" json:"description"`
-	EnvironmentURL string                 `bson:"environmentURL" json:"environmentURL"` // URL to access the environment
-	Target         Target                 `bson:"target" json:"target"`
-	Credentials    CredentialRef          `bson:"credentials" json:"credentials"`
-	HealthCheck    HealthCheckConfig      `bson:"healthCheck" json:"healthCheck"`
-	Status         Status                 `bson:"status" json:"status"`
-	SystemInfo     SystemInfo             `bson:"systemInfo" json:"systemInfo"`
-	Timestamps     Timestamps             `bson:"timestamps" json:"timestamps"`
-	Commands       CommandConfig          `bson:"commands" json:"commands"`
-	UpgradeConfig  UpgradeConfig          `bson:"upgradeConfig" json:"upgradeConfig"`
-	Metadata       map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	ID               primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Name             string                 `bson:"name" json:"name"`
+	Description      string                 `bson:"description" json:"description"`
+	EnvironmentURL   string                 `bson:"environmentURL" json:"environmentURL"` // URL to access the environment
+	Target           Target                 `bson:"target" json:"target"`
+	Credentials      CredentialRef          `bson:"credentials" json:"credentials"`
+	HealthCheck      HealthCheckConfig      `bson:"healthCheck" json:"healthCheck"`
+	Status           Status                 `bson:"status" json:"status"`
+	SystemInfo       SystemInfo             `bson:"systemInfo" json:"systemInfo"`
+	Timestamps       Timestamps             `bson:"timestamps" json:"timestamps"`
+	Commands         CommandConfig          `bson:"commands" json:"commands"`
+	UpgradeConfig    UpgradeConfig          `bson:"upgradeConfig" json:"upgradeConfig"`
+	Limits           *OperationLimits       `bson:"limits,omitempty" json:"limits,omitempty"` // Operation timeouts, rate limits and cooldowns
+	RequiresApproval bool                   `bson:"requiresApproval" json:"requiresApproval"` // Restart/upgrade need a second user's approval
+	Labels           map[string]string      `bson:"labels,omitempty" json:"labels,omitempty"`
+	LogSources       []LogSource            `bson:"logSources,omitempty" json:"logSources,omitempty"` // Remote logs users can tail
+	Metadata         map[string]interface{} `bson:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// MatchesLabels reports whether the environment carries every label in the
+// selector. An empty selector matches nothing.
+func (e *Environment) MatchesLabels(selector map[string]string) bool {
+	if len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if e.Labels[key] != value {
+			return false
+		}
+	}
+	return true
 }
 
 // Target represents the connection target
@@ -30,6 +50,15 @@ type Target struct {
 	Domain string `bson:"domain,omitempty" json:"domain,omitempty"`
 }
 
+// Address returns the host with the given port, or with the target's port
+// when it is not set
+func (t Target) Address(port int) string {
+	if port <= 0 {
+		port = t.Port
+	}
+	return net.JoinHostPort(t.Host, strconv.Itoa(port))
+}
+
 // CredentialRef references the credentials
 type CredentialRef struct {
 	Type     string             `bson:"type" json:"type"` // "key" or "password"
@@ -39,27 +68,61 @@ type CredentialRef struct {
 
 // HealthCheckConfig defines health check settings
 type HealthCheckConfig struct {
-	Enabled    bool                   `bson:"enabled" json:"enabled"`
-	Endpoint   string                 `bson:"endpoint" json:"endpoint"`
-	Method     string                 `bson:"method" json:"method"`
-	Interval   int                    `bson:"interval" json:"interval"` // seconds
-	Timeout    int                    `bson:"timeout" json:"timeout"`   // seconds
-	Validation ValidationConfig       `bson:"validation" json:"validation"`
-	Headers    map[string]string      `bson:"headers,omitempty" json:"headers,omitempty"`
+	Enabled                 bool                 `bson:"enabled" json:"enabled"`
+	Type                    HealthCheckType      `bson:"type,omitempty" json:"type,omitempty"` // "http" (default), "tcp", "ssh", "grpc", a protocol probe or "simulated"
+	Endpoint                string               `bson:"endpoint" json:"endpoint"`
+	Method                  string               `bson:"method" json:"method"`
+	Interval                int                  `bson:"interval" json:"interval"`                                                   // seconds
+	Timeout                 int                  `bson:"timeout" json:"timeout"`                                                     // seconds
+	DegradedAboveMs         int                  `bson:"degradedAboveMs,omitempty" json:"degradedAboveMs,omitempty"`                 // A healthy check slower than this is degraded
+	FailuresBeforeUnhealthy int                  `bson:"failuresBeforeUnhealthy,omitempty" json:"failuresBeforeUnhealthy,omitempty"` // Consecutive failed checks before a healthy environment changes, default 1
+	SuccessesBeforeHealthy  int                  `bson:"successesBeforeHealthy,omitempty" json:"successesBeforeHealthy,omitempty"`   // Consecutive healthy checks before a failing environment is healthy, default 1
+	Validation              ValidationConfig     `bson:"validation" json:"validation"`
+	Headers                 map[string]string    `bson:"headers,omitempty" json:"headers,omitempty"`
+	TCP                     *TCPCheckConfig      `bson:"tcp,omitempty" json:"tcp,omitempty"`                 // For tcp: port, payload and expected response
+	SSH                     *SSHCheckConfig      `bson:"ssh,omitempty" json:"ssh,omitempty"`                 // For ssh: command and healthy exit codes or output
+	GRPC                    *GRPCCheckConfig     `bson:"grpc,omitempty" json:"grpc,omitempty"`               // For grpc: port, service, metadata and TLS
+	Protocol                *ProtocolCheckConfig `bson:"protocol,omitempty" json:"protocol,omitempty"`       // For redis, smtp, ftp, postgres and mysql: address and expectations
+	Probes                  []HealthProbe        `bson:"probes,omitempty" json:"probes,omitempty"`           // Named checks run instead of the one above
+	Aggregation             *ProbeAggregation    `bson:"aggregation,omitempty" json:"aggregation,omitempty"` // How probe results combine; all must be healthy when unset
 }
 
+// HealthCheckType selects how an environment's health is probed
+type HealthCheckType string
+
+const (
+	HealthCheckTypeHTTP      HealthCheckType = "http"
+	HealthCheckTypeTCP       HealthCheckType = "tcp"       // connects to a port
+	HealthCheckTypeSSH       HealthCheckType = "ssh"       // runs a command on the host
+	HealthCheckTypeGRPC      HealthCheckType = "grpc"      // calls the gRPC health service
+	HealthCheckTypeRedis     HealthCheckType = "redis"     // sends PING
+	HealthCheckTypeSMTP      HealthCheckType = "smtp"      // reads the greeting
+	HealthCheckTypeFTP       HealthCheckType = "ftp"       // reads the greeting
+	HealthCheckTypePostgres  HealthCheckType = "postgres"  // starts a session up to authentication
+	HealthCheckTypeMySQL     HealthCheckType = "mysql"     // reads the handshake
+	HealthCheckTypeSimulated HealthCheckType = "simulated" // probed by the simulated driver
+)
+
 // ValidationConfig defines how to validate health check responses
 type ValidationConfig struct {
-	Type  string      `bson:"type" json:"type"`   // "statusCode" or "jsonRegex"
-	Value interface{} `bson:"value" json:"value"` // Expected status code or regex pattern
+	Type  string           `bson:"type" json:"type"`                       // "statusCode" or "jsonRegex"
+	Value interface{}      `bson:"value" json:"value"`                     // Expected status code or regex pattern
+	Rules []ValidationRule `bson:"rules,omitempty" json:"rules,omitempty"` // Every rule must hold, as well as the type when set
 }
 
 // Status represents the current environment status
 type Status struct {
-	Health       HealthStatus `bson:"health" json:"health"`
-	LastCheck    time.Time    `bson:"lastCheck" json:"lastCheck"`
-	Message      string       `bson:"message" json:"message"`
-	ResponseTime int64        `bson:"responseTime" json:"responseTime"` // milliseconds
+	Health               HealthStatus  `bson:"health" json:"health"`
+	LastCheck            time.Time     `bson:"lastCheck" json:"lastCheck"`
+	Message              string        `bson:"message" json:"message"`
+	ResponseTime         int64         `bson:"responseTime" json:"responseTime"`                                     // milliseconds
+	Probes               []ProbeStatus `bson:"probes,omitempty" json:"probes,omitempty"`                             // results of a multi-probe check
+	FailedProbe          string        `bson:"failedProbe,omitempty" json:"failedProbe,omitempty"`                   // the probe that made it unhealthy or degraded
+	ConsecutiveFailures  int           `bson:"consecutiveFailures,omitempty" json:"consecutiveFailures,omitempty"`   // unhealthy or degraded checks in a row
+	ConsecutiveUnhealthy int           `bson:"consecutiveUnhealthy,omitempty" json:"consecutiveUnhealthy,omitempty"` // unhealthy checks in a row
+	ConsecutiveSuccesses int           `bson:"consecutiveSuccesses,omitempty" json:"consecutiveSuccesses,omitempty"` // healthy checks in a row
+	Flapping             bool          `bson:"flapping,omitempty" json:"flapping,omitempty"`                         // health changed too often recently; changes are not logged
+	Transitions          []time.Time   `bson:"transitions,omitempty" json:"transitions,omitempty"`                   // recent health changes, for flap detection
 }
 
 // HealthStatus enum
@@ -67,10 +130,18 @@ type HealthStatus string
 
 const (
 	HealthStatusHealthy   HealthStatus = "healthy"
+	HealthStatusDegraded  HealthStatus = "degraded" // serving, but slow or partly failing
 	HealthStatusUnhealthy HealthStatus = "unhealthy"
 	HealthStatusUnknown   HealthStatus = "unknown"
 )
 
+// IsUp reports whether an environment with the health is serving. Degraded
+// environments are up: probes aggregate them as serving and rollouts proceed
+// past them.
+func (h HealthStatus) IsUp() bool {
+	return h == HealthStatusHealthy || h == HealthStatusDegraded
+}
+
 // SystemInfo contains system information
 type SystemInfo struct {
 	OSVersion   string    `bson:"osVersion" json:"osVersion"`
@@ -89,45 +160,86 @@ type Timestamps struct {
 
 // CommandConfig defines custom commands for environment operations
 type CommandConfig struct {
-	Type    CommandType   `bson:"type" json:"type"`        // "ssh" or "http"
-	Restart RestartConfig `bson:"restart" json:"restart"`
+	Type       CommandType       `bson:"type" json:"type"` // "ssh", "http", "docker", "kubernetes" or "simulated"
+	Restart    RestartConfig     `bson:"restart" json:"restart"`
+	Actions    []CustomAction    `bson:"actions,omitempty" json:"actions,omitempty"`
+	Allowlist  []CommandRule     `bson:"allowlist,omitempty" json:"allowlist,omitempty"`   // SSH commands allowed besides the global allowlist
+	Docker     *DockerConfig     `bson:"docker,omitempty" json:"docker,omitempty"`         // For Docker: Engine API connection and containers
+	Kubernetes *KubernetesConfig `bson:"kubernetes,omitempty" json:"kubernetes,omitempty"` // For Kubernetes: API server and workload
+	Simulation *SimulationConfig `bson:"simulation,omitempty" json:"simulation,omitempty"` // For simulated environments: latency, failures, versions and health
+}
+
+// CustomAction is a named operator-defined command run with the
+// environment's command type
+type CustomAction struct {
+	Name        string         `bson:"name" json:"name"`
+	Description string         `bson:"description,omitempty" json:"description,omitempty"`
+	Command     CommandDetails `bson:"command" json:"command"`
+}
+
+// FindAction returns the custom action with the given name
+func (c CommandConfig) FindAction(name string) (*CustomAction, bool) {
+	for i := range c.Actions {
+		if c.Actions[i].Name == name {
+			return &c.Actions[i], true
+		}
+	}
+	return nil, false
 }
 
 // CommandType enum
 type CommandType string
 
 const (
-	CommandTypeSSH  CommandType = "ssh"
-	CommandTypeHTTP CommandType = "http"
+	CommandTypeSSH        CommandType = "ssh"
+	CommandTypeHTTP       CommandType = "http"
+	CommandTypeDocker     CommandType = "docker"
+	CommandTypeKubernetes CommandType = "kubernetes"
+	CommandTypeSimulated  CommandType = "simulated"
 )
 
 // RestartConfig defines restart command configuration
 type RestartConfig struct {
 	Enabled bool                   `bson:"enabled" json:"enabled"`
 	Command string                 `bson:"command,omitempty" json:"command,omitempty"` // For SSH
+	Script  *ScriptConfig          `bson:"script,omitempty" json:"script,omitempty"`   // For SSH: uploaded script instead of a command
 	URL     string                 `bson:"url,omitempty" json:"url,omitempty"`         // For HTTP
 	Method  string                 `bson:"method,omitempty" json:"method,omitempty"`   // For HTTP
 	Headers map[string]string      `bson:"headers,omitempty" json:"headers,omitempty"` // For HTTP
 	Body    map[string]interface{} `bson:"body,omitempty" json:"body,omitempty"`       // For HTTP
+	HTTPPolicy `bson:",inline"` // For HTTP: timeout, retries and async job polling
+	Hooks   *Hooks                 `bson:"hooks,omitempty" json:"hooks,omitempty"`     // Steps run before and after the restart
 }
 
 // CommandDetails defines specific command details
 type CommandDetails struct {
 	Command string                 `bson:"command,omitempty" json:"command,omitempty"` // For SSH
+	Script  *ScriptConfig          `bson:"script,omitempty" json:"script,omitempty"`   // For SSH: uploaded script instead of a command
 	URL     string                 `bson:"url,omitempty" json:"url,omitempty"`         // For HTTP
 	Method  string                 `bson:"method,omitempty" json:"method,omitempty"`   // For HTTP
 	Headers map[string]string      `bson:"headers,omitempty" json:"headers,omitempty"` // For HTTP
 	Body    map[string]interface{} `bson:"body,omitempty" json:"body,omitempty"`       // For HTTP
+	HTTPPolicy `bson:",inline"` // For HTTP: timeout, retries and async job polling
+}
+
+// ScriptConfig is a script uploaded over SFTP and run with an explicit
+// interpreter. Operation parameters such as VERSION are passed as environment
+// variables rather than substituted into the script.
+type ScriptConfig struct {
+	Body        string            `bson:"body" json:"body"`
+	Interpreter string            `bson:"interpreter,omitempty" json:"interpreter,omitempty"` // absolute path, default /bin/sh
+	Env         map[string]string `bson:"env,omitempty" json:"env,omitempty"`
 }
 
 // UpgradeConfig defines configuration for version upgrades
 type UpgradeConfig struct {
 	Enabled             bool                   `bson:"enabled" json:"enabled"`
-	Type                CommandType            `bson:"type" json:"type"`                                                 // "ssh" or "http" for upgrade command
+	Type                CommandType            `bson:"type" json:"type"`                                                 // "ssh", "http", "docker", "kubernetes" or "simulated" for upgrade command
 	VersionListURL      string                 `bson:"versionListURL" json:"versionListURL"`                             // URL to fetch available versions
 	VersionListMethod   string                 `bson:"versionListMethod,omitempty" json:"versionListMethod,omitempty"`   // HTTP method for version list request
 	VersionListHeaders  map[string]string      `bson:"versionListHeaders,omitempty" json:"versionListHeaders,omitempty"` // Headers for version list request
 	VersionListBody     string                 `bson:"versionListBody,omitempty" json:"versionListBody,omitempty"`       // Body for version list request
 	JSONPathResponse    string                 `bson:"jsonPathResponse" json:"jsonPathResponse"`                         // JSONPath to extract version list from response
 	UpgradeCommand      CommandDetails         `bson:"upgradeCommand" json:"upgradeCommand"`                             // SSH command or HTTP details for upgrade
+	Hooks               *Hooks                 `bson:"hooks,omitempty" json:"hooks,omitempty"`                           // Steps run before and after the upgrade
 }
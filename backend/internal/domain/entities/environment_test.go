@@ -462,6 +462,20 @@ func (suite *EnvironmentTestSuite) TestEnvironment_MinimalEntity() {
 	assert.Nil(suite.T(), env.Metadata)
 }
 
+func (suite *EnvironmentTestSuite) TestTarget_Address() {
+	target := entities.Target{Host: "10.0.0.5", Port: 22}
+
+	assert.Equal(suite.T(), "10.0.0.5:22", target.Address(0))
+	assert.Equal(suite.T(), "10.0.0.5:5432", target.Address(5432))
+}
+
+func (suite *EnvironmentTestSuite) TestHealthStatus_IsUp() {
+	assert.True(suite.T(), entities.HealthStatusHealthy.IsUp())
+	assert.True(suite.T(), entities.HealthStatusDegraded.IsUp())
+	assert.False(suite.T(), entities.HealthStatusUnhealthy.IsUp())
+	assert.False(suite.T(), entities.HealthStatusUnknown.IsUp())
+}
+
 // Run the test suite
 func TestEnvironmentTestSuite(t *testing.T) {
 	suite.Run(t, new(EnvironmentTestSuite))
@@ -0,0 +1,234 @@
+package entities
+
+// TCPCheckConfig configures a "tcp" health check, for environments that
+// expose only a port such as a database or message broker
+type TCPCheckConfig struct {
+	Port            int    `bson:"port,omitempty" json:"port,omitempty"`                       // defaults to the target's port
+	Payload         string `bson:"payload,omitempty" json:"payload,omitempty"`                 // sent once connected
+	ResponsePattern string `bson:"responsePattern,omitempty" json:"responsePattern,omitempty"` // regex the banner or response must match
+}
+
+// Address returns the host and port the check dials on the target
+func (c *TCPCheckConfig) Address(target Target) string {
+	if c == nil {
+		return target.Address(0)
+	}
+	return target.Address(c.Port)
+}
+
+// SSHCheckConfig configures an "ssh" health check: a command run on the
+// environment's host, for services only reachable from inside it
+type SSHCheckConfig struct {
+	Command       string `bson:"command" json:"command"`                                 // subject to the command allowlist
+	ExitCodes     []int  `bson:"exitCodes,omitempty" json:"exitCodes,omitempty"`         // healthy exit codes, default 0
+	OutputPattern string `bson:"outputPattern,omitempty" json:"outputPattern,omitempty"` // regex the output must match
+}
+
+// HealthyExitCodes returns the exit codes that are healthy. A check with only
+// an output pattern accepts any exit code.
+func (c *SSHCheckConfig) HealthyExitCodes() []int {
+	if len(c.ExitCodes) > 0 {
+		return c.ExitCodes
+	}
+	if c.OutputPattern != "" {
+		return nil
+	}
+	return []int{0}
+}
+
+// GRPCCheckConfig configures a "grpc" health check, which calls the gRPC
+// Health Checking Protocol's grpc.health.v1.Health/Check
+type GRPCCheckConfig struct {
+	Port     int               `bson:"port,omitempty" json:"port,omitempty"`         // defaults to the target's port
+	Service  string            `bson:"service,omitempty" json:"service,omitempty"`   // empty checks the server as a whole
+	Metadata map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"` // sent as request headers
+	TLS      *GRPCTLSConfig    `bson:"tls,omitempty" json:"tls,omitempty"`           // plaintext when unset
+}
+
+// GRPCTLSConfig holds the TLS options of a gRPC health check
+type GRPCTLSConfig struct {
+	ServerName         string `bson:"serverName,omitempty" json:"serverName,omitempty"` // defaults to the target's host
+	CACert             string `bson:"caCert,omitempty" json:"caCert,omitempty"`         // PEM; the system roots when empty
+	InsecureSkipVerify bool   `bson:"insecureSkipVerify,omitempty" json:"insecureSkipVerify,omitempty"`
+}
+
+// Address returns the host and port the check dials on the target
+func (c *GRPCCheckConfig) Address(target Target) string {
+	if c == nil {
+		return target.Address(0)
+	}
+	return target.Address(c.Port)
+}
+
+// ProtocolCheckConfig configures the protocol probes, which speak just enough
+// of a dependency's wire protocol to tell that it is serving, without
+// credentials
+type ProtocolCheckConfig struct {
+	Host         string `bson:"host,omitempty" json:"host,omitempty"`                 // defaults to the target's host
+	Port         int    `bson:"port,omitempty" json:"port,omitempty"`                 // defaults to the protocol's port
+	ExpectedCode int    `bson:"expectedCode,omitempty" json:"expectedCode,omitempty"` // smtp and ftp greeting, default 220
+	User         string `bson:"user,omitempty" json:"user,omitempty"`                 // postgres startup user, default "postgres"
+	Database     string `bson:"database,omitempty" json:"database,omitempty"`         // postgres database, defaults to the user
+}
+
+// DefaultPort returns the well-known port of a protocol probe, or zero for
+// other check types
+func (t HealthCheckType) DefaultPort() int {
+	switch t {
+	case HealthCheckTypeRedis:
+		return 6379
+	case HealthCheckTypeSMTP:
+		return 25
+	case HealthCheckTypeFTP:
+		return 21
+	case HealthCheckTypePostgres:
+		return 5432
+	case HealthCheckTypeMySQL:
+		return 3306
+	}
+	return 0
+}
+
+// Address returns the host and port a protocol probe of checkType dials
+func (c *ProtocolCheckConfig) Address(target Target, checkType HealthCheckType) string {
+	port := checkType.DefaultPort()
+	if c != nil && c.Port > 0 {
+		port = c.Port
+	}
+	if c != nil && c.Host != "" {
+		target.Host = c.Host
+	}
+	return target.Address(port)
+}
+
+// GreetingCode returns the reply code a healthy SMTP or FTP server greets with
+func (c *ProtocolCheckConfig) GreetingCode() int {
+	if c == nil || c.ExpectedCode == 0 {
+		return 220
+	}
+	return c.ExpectedCode
+}
+
+// StartupUser returns the user and database a postgres probe starts a
+// session for
+func (c *ProtocolCheckConfig) StartupUser() (user, database string) {
+	user = "postgres"
+	if c != nil && c.User != "" {
+		user = c.User
+	}
+	database = user
+	if c != nil && c.Database != "" {
+		database = c.Database
+	}
+	return user, database
+}
+
+// HealthProbe is one named check of a multi-probe health check, such as an
+// environment's web frontend, API or worker endpoint
+type HealthProbe struct {
+	Name        string            `bson:"name" json:"name"`
+	Criticality ProbeCriticality  `bson:"criticality,omitempty" json:"criticality,omitempty"`
+	Weight      int               `bson:"weight,omitempty" json:"weight,omitempty"` // for the weighted policy, default 1
+	Check       HealthCheckConfig `bson:"check" json:"check"`                       // any check type; enabled, interval and probes are ignored
+}
+
+// ProbeCriticality decides how a probe's result counts
+type ProbeCriticality string
+
+const (
+	ProbeCriticalityNormal      ProbeCriticality = "normal"       // counted by the aggregation policy (default)
+	ProbeCriticalityCritical    ProbeCriticality = "critical"     // must be healthy whatever the policy
+	ProbeCriticalityNonCritical ProbeCriticality = "non_critical" // reported but not counted
+)
+
+// ProbeAggregation combines the results of the counted probes
+type ProbeAggregation struct {
+	Policy    AggregationPolicy `bson:"policy,omitempty" json:"policy,omitempty"`
+	Quorum    int               `bson:"quorum,omitempty" json:"quorum,omitempty"`       // healthy probes needed, default a majority
+	MinWeight float64           `bson:"minWeight,omitempty" json:"minWeight,omitempty"` // healthy share of the total weight needed, default 0.5
+}
+
+// AggregationPolicy selects how many counted probes must be healthy
+type AggregationPolicy string
+
+const (
+	AggregationPolicyAll      AggregationPolicy = "all" // default
+	AggregationPolicyAny      AggregationPolicy = "any"
+	AggregationPolicyQuorum   AggregationPolicy = "quorum"
+	AggregationPolicyWeighted AggregationPolicy = "weighted"
+)
+
+// ProbeStatus is the result of one probe of a multi-probe health check
+type ProbeStatus struct {
+	Name         string           `bson:"name" json:"name"`
+	Criticality  ProbeCriticality `bson:"criticality" json:"criticality"`
+	Health       HealthStatus     `bson:"health" json:"health"`
+	Message      string           `bson:"message" json:"message"`
+	ResponseTime int64            `bson:"responseTime" json:"responseTime"` // milliseconds
+}
+
+// EffectiveWeight returns the probe's weight for the weighted policy
+func (p HealthProbe) EffectiveWeight() int {
+	if p.Weight <= 0 {
+		return 1
+	}
+	return p.Weight
+}
+
+// EffectiveCriticality returns the probe's criticality, normal when unset
+func (p HealthProbe) EffectiveCriticality() ProbeCriticality {
+	if p.Criticality == "" {
+		return ProbeCriticalityNormal
+	}
+	return p.Criticality
+}
+
+// FailureThreshold returns how many failed checks in a row change a healthy
+// environment's health, at least 1
+func (c HealthCheckConfig) FailureThreshold() int {
+	if c.FailuresBeforeUnhealthy <= 0 {
+		return 1
+	}
+	return c.FailuresBeforeUnhealthy
+}
+
+// SuccessThreshold returns how many healthy checks in a row make a failing
+// environment healthy, at least 1
+func (c HealthCheckConfig) SuccessThreshold() int {
+	if c.SuccessesBeforeHealthy <= 0 {
+		return 1
+	}
+	return c.SuccessesBeforeHealthy
+}
+
+// ValidationRule is one rule an HTTP health check response must satisfy
+type ValidationRule struct {
+	Type         ValidationRuleType `bson:"type" json:"type"`
+	StatusCodes  []int              `bson:"statusCodes,omitempty" json:"statusCodes,omitempty"`   // statusCode: allowed codes
+	StatusRanges []string           `bson:"statusRanges,omitempty" json:"statusRanges,omitempty"` // statusCode: "2xx" or "200-299"
+	JSONPath     *JSONPathAssertion `bson:"jsonPath,omitempty" json:"jsonPath,omitempty"`         // jsonPath
+	Header       string             `bson:"header,omitempty" json:"header,omitempty"`             // header: its name
+	Value        string             `bson:"value,omitempty" json:"value,omitempty"`               // header: expected value; only presence when empty
+	Contains     string             `bson:"contains,omitempty" json:"contains,omitempty"`         // bodyContains
+	MaxMs        int                `bson:"maxMs,omitempty" json:"maxMs,omitempty"`               // responseTime: slowest healthy response
+	Severity     RuleSeverity       `bson:"severity,omitempty" json:"severity,omitempty"`
+}
+
+// RuleSeverity decides what a failing validation rule makes the environment
+type RuleSeverity string
+
+const (
+	RuleSeverityError   RuleSeverity = "error"   // unhealthy (default)
+	RuleSeverityWarning RuleSeverity = "warning" // degraded
+)
+
+// ValidationRuleType selects what a validation rule checks
+type ValidationRuleType string
+
+const (
+	ValidationRuleStatusCode   ValidationRuleType = "statusCode"
+	ValidationRuleJSONPath     ValidationRuleType = "jsonPath"
+	ValidationRuleHeader       ValidationRuleType = "header"
+	ValidationRuleBodyContains ValidationRuleType = "bodyContains"
+	ValidationRuleResponseTime ValidationRuleType = "responseTime"
+)
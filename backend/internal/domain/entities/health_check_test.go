@@ -0,0 +1,78 @@
+package entities_test
+
+import (
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTCPCheckConfig_Address(t *testing.T) {
+	target := entities.Target{Host: "db.internal", Port: 22}
+
+	var none *entities.TCPCheckConfig
+	assert.Equal(t, "db.internal:22", none.Address(target))
+	assert.Equal(t, "db.internal:5432", (&entities.TCPCheckConfig{Port: 5432}).Address(target))
+	assert.Equal(t, "[::1]:6379", (&entities.TCPCheckConfig{Port: 6379}).Address(entities.Target{Host: "::1"}))
+}
+
+func TestSSHCheckConfig_HealthyExitCodes(t *testing.T) {
+	assert.Equal(t, []int{0}, (&entities.SSHCheckConfig{Command: "systemctl is-active app"}).HealthyExitCodes())
+	assert.Equal(t, []int{0, 3}, (&entities.SSHCheckConfig{ExitCodes: []int{0, 3}}).HealthyExitCodes())
+	assert.Nil(t, (&entities.SSHCheckConfig{OutputPattern: "^active"}).HealthyExitCodes(), "only the output is checked")
+	assert.Equal(t, []int{0}, (&entities.SSHCheckConfig{ExitCodes: []int{0}, OutputPattern: "^active"}).HealthyExitCodes())
+}
+
+func TestGRPCCheckConfig_Address(t *testing.T) {
+	target := entities.Target{Host: "api.internal", Port: 22}
+
+	var none *entities.GRPCCheckConfig
+	assert.Equal(t, "api.internal:22", none.Address(target))
+	assert.Equal(t, "api.internal:50051", (&entities.GRPCCheckConfig{Port: 50051}).Address(target))
+}
+
+func TestProtocolCheckConfig_Address(t *testing.T) {
+	target := entities.Target{Host: "app.internal", Port: 22}
+
+	var none *entities.ProtocolCheckConfig
+	assert.Equal(t, "app.internal:6379", none.Address(target, entities.HealthCheckTypeRedis))
+	assert.Equal(t, "app.internal:5432", none.Address(target, entities.HealthCheckTypePostgres))
+	assert.Equal(t, "cache.internal:7000",
+		(&entities.ProtocolCheckConfig{Host: "cache.internal", Port: 7000}).Address(target, entities.HealthCheckTypeRedis))
+	assert.Equal(t, "app.internal:22", none.Address(target, entities.HealthCheckTypeTCP), "other types use the target's port")
+}
+
+func TestProtocolCheckConfig_Defaults(t *testing.T) {
+	var none *entities.ProtocolCheckConfig
+	assert.Equal(t, 220, none.GreetingCode())
+	assert.Equal(t, 230, (&entities.ProtocolCheckConfig{ExpectedCode: 230}).GreetingCode())
+
+	user, database := none.StartupUser()
+	assert.Equal(t, "postgres", user)
+	assert.Equal(t, "postgres", database)
+	user, database = (&entities.ProtocolCheckConfig{User: "monitor"}).StartupUser()
+	assert.Equal(t, "monitor", user)
+	assert.Equal(t, "monitor", database)
+	_, database = (&entities.ProtocolCheckConfig{User: "monitor", Database: "orders"}).StartupUser()
+	assert.Equal(t, "orders", database)
+}
+
+func TestHealthProbe_Defaults(t *testing.T) {
+	var probe entities.HealthProbe
+	assert.Equal(t, 1, probe.EffectiveWeight())
+	assert.Equal(t, entities.ProbeCriticalityNormal, probe.EffectiveCriticality())
+
+	probe = entities.HealthProbe{Weight: 3, Criticality: entities.ProbeCriticalityCritical}
+	assert.Equal(t, 3, probe.EffectiveWeight())
+	assert.Equal(t, entities.ProbeCriticalityCritical, probe.EffectiveCriticality())
+}
+
+func TestHealthCheckConfig_Thresholds(t *testing.T) {
+	var config entities.HealthCheckConfig
+	assert.Equal(t, 1, config.FailureThreshold())
+	assert.Equal(t, 1, config.SuccessThreshold())
+
+	config = entities.HealthCheckConfig{FailuresBeforeUnhealthy: 3, SuccessesBeforeHealthy: 2}
+	assert.Equal(t, 3, config.FailureThreshold())
+	assert.Equal(t, 2, config.SuccessThreshold())
+}
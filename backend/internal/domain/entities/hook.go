@@ -0,0 +1,84 @@
+package entities
+
+// HookPhase is when a hook runs relative to its operation
+type HookPhase string
+
+const (
+	HookPhaseBefore       HookPhase = "before"
+	HookPhaseAfterSuccess HookPhase = "after_success"
+	HookPhaseAfterFailure HookPhase = "after_failure"
+	HookPhaseAlways       HookPhase = "always"
+)
+
+// HookFailPolicy is what a failed hook does to its operation
+type HookFailPolicy string
+
+const (
+	// HookFailPolicyFail fails the operation and skips the phase's remaining
+	// hooks; a failed before hook also stops the operation from running
+	HookFailPolicyFail HookFailPolicy = "fail"
+	// HookFailPolicyIgnore records the failure and carries on
+	HookFailPolicyIgnore HookFailPolicy = "ignore"
+)
+
+// DefaultHookTimeout is how long a hook may run, in seconds
+const DefaultHookTimeout = 60
+
+// Hooks are steps run around an operation, e.g. draining a node from its
+// load balancer before a restart and putting it back afterwards. After
+// success or after failure hooks run once the operation finished, then the
+// always hooks.
+type Hooks struct {
+	Before       []Hook `bson:"before,omitempty" json:"before,omitempty"`
+	AfterSuccess []Hook `bson:"afterSuccess,omitempty" json:"afterSuccess,omitempty"`
+	AfterFailure []Hook `bson:"afterFailure,omitempty" json:"afterFailure,omitempty"`
+	Always       []Hook `bson:"always,omitempty" json:"always,omitempty"`
+}
+
+// Phase returns the hooks of a phase
+func (h *Hooks) Phase(phase HookPhase) []Hook {
+	if h == nil {
+		return nil
+	}
+	switch phase {
+	case HookPhaseBefore:
+		return h.Before
+	case HookPhaseAfterSuccess:
+		return h.AfterSuccess
+	case HookPhaseAfterFailure:
+		return h.AfterFailure
+	case HookPhaseAlways:
+		return h.Always
+	}
+	return nil
+}
+
+// Hook is one SSH or HTTP step run around an operation
+type Hook struct {
+	Name       string         `bson:"name" json:"name"`
+	Type       CommandType    `bson:"type" json:"type"` // "ssh" or "http"
+	Command    CommandDetails `bson:"command" json:"command"`
+	Timeout    int            `bson:"timeout,omitempty" json:"timeout,omitempty"`       // seconds, default 60
+	FailPolicy HookFailPolicy `bson:"failPolicy,omitempty" json:"failPolicy,omitempty"` // "fail" (default) or "ignore"
+}
+
+// WithDefaults returns a copy with unset settings defaulted
+func (h Hook) WithDefaults() Hook {
+	if h.Timeout <= 0 {
+		h.Timeout = DefaultHookTimeout
+	}
+	if h.FailPolicy == "" {
+		h.FailPolicy = HookFailPolicyFail
+	}
+	return h
+}
+
+// HookResult records a hook that ran as a sub-step of an operation
+type HookResult struct {
+	Name     string    `bson:"name" json:"name"`
+	Phase    HookPhase `bson:"phase" json:"phase"`
+	Success  bool      `bson:"success" json:"success"`
+	Ignored  bool      `bson:"ignored,omitempty" json:"ignored,omitempty"` // failed under the ignore policy
+	Error    string    `bson:"error,omitempty" json:"error,omitempty"`
+	Duration int64     `bson:"duration" json:"duration"` // milliseconds
+}
@@ -0,0 +1,37 @@
+package entities_test
+
+import (
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHook_WithDefaults(t *testing.T) {
+	hook := entities.Hook{Name: "drain"}.WithDefaults()
+
+	assert.Equal(t, entities.DefaultHookTimeout, hook.Timeout)
+	assert.Equal(t, entities.HookFailPolicyFail, hook.FailPolicy)
+
+	hook = entities.Hook{Timeout: 5, FailPolicy: entities.HookFailPolicyIgnore}.WithDefaults()
+	assert.Equal(t, 5, hook.Timeout)
+	assert.Equal(t, entities.HookFailPolicyIgnore, hook.FailPolicy)
+}
+
+func TestHooks_Phase(t *testing.T) {
+	hooks := &entities.Hooks{
+		Before:       []entities.Hook{{Name: "drain"}},
+		AfterSuccess: []entities.Hook{{Name: "enable"}},
+		AfterFailure: []entities.Hook{{Name: "page"}},
+		Always:       []entities.Hook{{Name: "notify"}},
+	}
+
+	assert.Equal(t, "drain", hooks.Phase(entities.HookPhaseBefore)[0].Name)
+	assert.Equal(t, "enable", hooks.Phase(entities.HookPhaseAfterSuccess)[0].Name)
+	assert.Equal(t, "page", hooks.Phase(entities.HookPhaseAfterFailure)[0].Name)
+	assert.Equal(t, "notify", hooks.Phase(entities.HookPhaseAlways)[0].Name)
+	assert.Nil(t, hooks.Phase("during"))
+
+	var none *entities.Hooks
+	assert.Nil(t, none.Phase(entities.HookPhaseBefore))
+}
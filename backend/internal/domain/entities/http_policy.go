@@ -0,0 +1,167 @@
+package entities
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults applied to HTTP command policies
+const (
+	DefaultHTTPCommandTimeout = 30 * time.Second
+	DefaultRetryBackoff       = time.Second
+	DefaultRetryMaxBackoff    = 30 * time.Second
+	DefaultAsyncPollInterval  = 5 * time.Second
+	DefaultAsyncTimeout       = 10 * time.Minute
+)
+
+// DefaultRetryableStatusCodes are retried when a policy lists none
+var DefaultRetryableStatusCodes = []int{429, 502, 503, 504}
+
+// HTTPPolicy holds the delivery settings shared by every HTTP command
+type HTTPPolicy struct {
+	TimeoutSeconds int              `bson:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"` // per request, default 30
+	Retry          *RetryPolicy     `bson:"retry,omitempty" json:"retry,omitempty"`
+	Async          *AsyncPolicy     `bson:"async,omitempty" json:"async,omitempty"`
+	Success        *SuccessCriteria `bson:"success,omitempty" json:"success,omitempty"` // default: any 2xx
+}
+
+// RequestTimeout returns the per-request timeout
+func (p HTTPPolicy) RequestTimeout() time.Duration {
+	if p.TimeoutSeconds <= 0 {
+		return DefaultHTTPCommandTimeout
+	}
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// RetryPolicy controls how a failed HTTP request is retried. Network errors
+// and the retryable status codes are retried with exponential backoff.
+type RetryPolicy struct {
+	MaxAttempts          int     `bson:"maxAttempts" json:"maxAttempts"` // including the first request
+	BackoffMs            int     `bson:"backoffMs,omitempty" json:"backoffMs,omitempty"`
+	MaxBackoffMs         int     `bson:"maxBackoffMs,omitempty" json:"maxBackoffMs,omitempty"`
+	Multiplier           float64 `bson:"multiplier,omitempty" json:"multiplier,omitempty"` // default 2
+	RetryableStatusCodes []int   `bson:"retryableStatusCodes,omitempty" json:"retryableStatusCodes,omitempty"`
+}
+
+// Attempts returns how many requests may be made in total
+func (p *RetryPolicy) Attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Backoff returns the delay before the given retry (1 for the first retry)
+func (p *RetryPolicy) Backoff(retry int) time.Duration {
+	backoff, maxBackoff, multiplier := DefaultRetryBackoff, DefaultRetryMaxBackoff, 2.0
+	if p != nil {
+		if p.BackoffMs > 0 {
+			backoff = time.Duration(p.BackoffMs) * time.Millisecond
+		}
+		if p.MaxBackoffMs > 0 {
+			maxBackoff = time.Duration(p.MaxBackoffMs) * time.Millisecond
+		}
+		if p.Multiplier >= 1 {
+			multiplier = p.Multiplier
+		}
+	}
+
+	delay := float64(backoff)
+	for i := 1; i < retry; i++ {
+		delay *= multiplier
+		if delay >= float64(maxBackoff) {
+			return maxBackoff
+		}
+	}
+	if delay > float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(delay)
+}
+
+// MaxBackoff returns the upper bound on any retry delay
+func (p *RetryPolicy) MaxBackoff() time.Duration {
+	if p == nil || p.MaxBackoffMs <= 0 {
+		return DefaultRetryMaxBackoff
+	}
+	return time.Duration(p.MaxBackoffMs) * time.Millisecond
+}
+
+// IsRetryable reports whether a response status should be retried
+func (p *RetryPolicy) IsRetryable(statusCode int) bool {
+	codes := DefaultRetryableStatusCodes
+	if p != nil && len(p.RetryableStatusCodes) > 0 {
+		codes = p.RetryableStatusCodes
+	}
+	for _, code := range codes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// AsyncPolicy turns an HTTP command into a job: the initial response points at
+// a status URL which is polled until the job succeeds or fails
+type AsyncPolicy struct {
+	StatusURLPath  string   `bson:"statusUrlPath,omitempty" json:"statusUrlPath,omitempty"` // JSONPath in the initial response; Location header when empty
+	PollIntervalMs int      `bson:"pollIntervalMs,omitempty" json:"pollIntervalMs,omitempty"`
+	TimeoutSeconds int      `bson:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"` // whole job, default 600
+	StatusPath     string   `bson:"statusPath,omitempty" json:"statusPath,omitempty"`         // JSONPath to the job status in poll responses
+	SuccessValues  []string `bson:"successValues,omitempty" json:"successValues,omitempty"`
+	FailureValues  []string `bson:"failureValues,omitempty" json:"failureValues,omitempty"`
+}
+
+// PollInterval returns the delay between polls
+func (p *AsyncPolicy) PollInterval() time.Duration {
+	if p.PollIntervalMs <= 0 {
+		return DefaultAsyncPollInterval
+	}
+	return time.Duration(p.PollIntervalMs) * time.Millisecond
+}
+
+// Timeout returns how long the job may take
+func (p *AsyncPolicy) Timeout() time.Duration {
+	if p.TimeoutSeconds <= 0 {
+		return DefaultAsyncTimeout
+	}
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// JobState classifies a polled status value
+type JobState int
+
+const (
+	JobRunning JobState = iota
+	JobSucceeded
+	JobFailed
+)
+
+// Classify maps a status value to a job state. Values are compared case
+// insensitively; anything not listed means the job is still running.
+func (p *AsyncPolicy) Classify(value interface{}) JobState {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		text = strconv.FormatBool(v)
+	default:
+		return JobRunning
+	}
+
+	for _, failure := range p.FailureValues {
+		if strings.EqualFold(failure, text) {
+			return JobFailed
+		}
+	}
+	for _, success := range p.SuccessValues {
+		if strings.EqualFold(success, text) {
+			return JobSucceeded
+		}
+	}
+	return JobRunning
+}
@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPPolicy_RequestTimeout(t *testing.T) {
+	assert.Equal(t, DefaultHTTPCommandTimeout, HTTPPolicy{}.RequestTimeout())
+	assert.Equal(t, 5*time.Second, HTTPPolicy{TimeoutSeconds: 5}.RequestTimeout())
+}
+
+func TestRetryPolicy_Attempts(t *testing.T) {
+	var nilPolicy *RetryPolicy
+	assert.Equal(t, 1, nilPolicy.Attempts())
+	assert.Equal(t, 1, (&RetryPolicy{}).Attempts())
+	assert.Equal(t, 4, (&RetryPolicy{MaxAttempts: 4}).Attempts())
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := &RetryPolicy{BackoffMs: 100, MaxBackoffMs: 500, Multiplier: 3}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 500*time.Millisecond, policy.Backoff(3), "capped at max backoff")
+
+	var nilPolicy *RetryPolicy
+	assert.Equal(t, DefaultRetryBackoff, nilPolicy.Backoff(1))
+	assert.Equal(t, 2*DefaultRetryBackoff, nilPolicy.Backoff(2))
+	assert.Equal(t, DefaultRetryMaxBackoff, nilPolicy.MaxBackoff())
+}
+
+func TestRetryPolicy_IsRetryable(t *testing.T) {
+	var nilPolicy *RetryPolicy
+	assert.True(t, nilPolicy.IsRetryable(503))
+	assert.False(t, nilPolicy.IsRetryable(500))
+
+	custom := &RetryPolicy{RetryableStatusCodes: []int{500}}
+	assert.True(t, custom.IsRetryable(500))
+	assert.False(t, custom.IsRetryable(503))
+}
+
+func TestAsyncPolicy_Defaults(t *testing.T) {
+	policy := &AsyncPolicy{}
+	assert.Equal(t, DefaultAsyncPollInterval, policy.PollInterval())
+	assert.Equal(t, DefaultAsyncTimeout, policy.Timeout())
+
+	policy = &AsyncPolicy{PollIntervalMs: 250, TimeoutSeconds: 60}
+	assert.Equal(t, 250*time.Millisecond, policy.PollInterval())
+	assert.Equal(t, time.Minute, policy.Timeout())
+}
+
+func TestAsyncPolicy_Classify(t *testing.T) {
+	policy := &AsyncPolicy{SuccessValues: []string{"DONE", "true"}, FailureValues: []string{"error", "-1"}}
+
+	assert.Equal(t, JobSucceeded, policy.Classify("done"))
+	assert.Equal(t, JobSucceeded, policy.Classify(true))
+	assert.Equal(t, JobFailed, policy.Classify("ERROR"))
+	assert.Equal(t, JobFailed, policy.Classify(float64(-1)))
+	assert.Equal(t, JobRunning, policy.Classify("running"))
+	assert.Equal(t, JobRunning, policy.Classify(nil))
+}
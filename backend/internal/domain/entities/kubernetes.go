@@ -0,0 +1,50 @@
+package entities
+
+// KubernetesKind is the kind of workload an environment runs as
+type KubernetesKind string
+
+const (
+	KubernetesKindDeployment  KubernetesKind = "Deployment"
+	KubernetesKindStatefulSet KubernetesKind = "StatefulSet"
+)
+
+// Kubernetes credential types. The secret itself is kept in metadata under
+// "kubeconfig" or "kubernetesToken".
+const (
+	CredentialTypeKubeconfig = "kubeconfig"
+	CredentialTypeToken      = "token"
+)
+
+// Kubernetes defaults
+const (
+	DefaultKubernetesNamespace      = "default"
+	DefaultKubernetesRolloutTimeout = 600 // seconds
+)
+
+// KubernetesConfig names the workload an environment runs as. Restarts are
+// rollout restarts, upgrades set a container's image, and both wait for the
+// rollout to complete.
+type KubernetesConfig struct {
+	Server             string         `bson:"server,omitempty" json:"server,omitempty"`                         // API server URL; taken from the kubeconfig when unset
+	Context            string         `bson:"context,omitempty" json:"context,omitempty"`                       // kubeconfig context, default the current context
+	CACert             string         `bson:"caCert,omitempty" json:"caCert,omitempty"`                         // PEM; taken from the kubeconfig when unset
+	InsecureSkipVerify bool           `bson:"insecureSkipVerify,omitempty" json:"insecureSkipVerify,omitempty"` // test/dev only
+	Namespace          string         `bson:"namespace,omitempty" json:"namespace,omitempty"`                   // default the kubeconfig context's, then "default"
+	Kind               KubernetesKind `bson:"kind,omitempty" json:"kind,omitempty"`                             // "Deployment" (default) or "StatefulSet"
+	Name               string         `bson:"name" json:"name"`
+	Container          string         `bson:"container,omitempty" json:"container,omitempty"`           // Container whose image is upgraded; required with several containers
+	Image              string         `bson:"image,omitempty" json:"image,omitempty"`                   // Upgrade image with {VERSION}; default the container's repository tagged with the version
+	RolloutTimeout     int            `bson:"rolloutTimeout,omitempty" json:"rolloutTimeout,omitempty"` // seconds to wait for the rollout, default 600
+}
+
+// WithDefaults returns a copy with unset settings defaulted. The namespace is
+// left alone since the kubeconfig may provide it.
+func (c KubernetesConfig) WithDefaults() KubernetesConfig {
+	if c.Kind == "" {
+		c.Kind = KubernetesKindDeployment
+	}
+	if c.RolloutTimeout <= 0 {
+		c.RolloutTimeout = DefaultKubernetesRolloutTimeout
+	}
+	return c
+}
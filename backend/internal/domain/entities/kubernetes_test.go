@@ -0,0 +1,28 @@
+package entities_test
+
+import (
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubernetesConfig_WithDefaults(t *testing.T) {
+	config := entities.KubernetesConfig{Name: "shop"}.WithDefaults()
+
+	assert.Equal(t, entities.KubernetesKindDeployment, config.Kind)
+	assert.Equal(t, 600, config.RolloutTimeout)
+	assert.Equal(t, "shop", config.Name)
+	assert.Empty(t, config.Namespace, "the kubeconfig may provide the namespace")
+}
+
+func TestKubernetesConfig_WithDefaults_KeepsSettings(t *testing.T) {
+	config := entities.KubernetesConfig{
+		Namespace:      "shop",
+		Kind:           entities.KubernetesKindStatefulSet,
+		Name:           "db",
+		RolloutTimeout: 60,
+	}
+
+	assert.Equal(t, config, config.WithDefaults())
+}
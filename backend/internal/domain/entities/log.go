@@ -37,6 +37,8 @@ const (
 	ActionTypeRestart  ActionType = "restart"
 	ActionTypeShutdown ActionType = "shutdown"
 	ActionTypeUpgrade  ActionType = "upgrade"
+	ActionTypeCustom   ActionType = "custom_action"
+	ActionTypeExec     ActionType = "exec"
 	ActionTypeLogin    ActionType = "login"
 	ActionTypeLogout   ActionType = "logout"
 )
@@ -0,0 +1,27 @@
+package entities
+
+// LogSourceType is how a remote log is read
+type LogSourceType string
+
+const (
+	LogSourceFile    LogSourceType = "file"     // a file read with tail
+	LogSourceJournal LogSourceType = "journald" // a systemd unit read with journalctl
+)
+
+// LogSource is a log on the environment's host that users can tail
+type LogSource struct {
+	Name string        `bson:"name" json:"name" validate:"required,max=64"`
+	Type LogSourceType `bson:"type" json:"type" validate:"required,oneof=file journald"`
+	Path string        `bson:"path,omitempty" json:"path,omitempty"` // Absolute file path, for file sources
+	Unit string        `bson:"unit,omitempty" json:"unit,omitempty"` // systemd unit, for journald sources
+}
+
+// LogSource returns the environment's log source with the given name
+func (e *Environment) LogSource(name string) (LogSource, bool) {
+	for _, source := range e.LogSources {
+		if source.Name == name {
+			return source, true
+		}
+	}
+	return LogSource{}, false
+}
@@ -0,0 +1,22 @@
+package entities_test
+
+import (
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironment_LogSource(t *testing.T) {
+	env := &entities.Environment{LogSources: []entities.LogSource{
+		{Name: "app", Type: entities.LogSourceFile, Path: "/var/log/app.log"},
+		{Name: "nginx", Type: entities.LogSourceJournal, Unit: "nginx.service"},
+	}}
+
+	source, ok := env.LogSource("nginx")
+	assert.True(t, ok)
+	assert.Equal(t, "nginx.service", source.Unit)
+
+	_, ok = env.LogSource("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,90 @@
+package entities
+
+import "time"
+
+// Defaults applied to operation limits
+const (
+	DefaultRestartTimeout      = 5 * time.Minute
+	DefaultUpgradeTimeout      = 10 * time.Minute
+	DefaultCustomActionTimeout = 5 * time.Minute
+	DefaultRateLimitWindow     = time.Hour
+)
+
+// OperationLimits bounds how long an environment's operations may run and how
+// often they may be started. Rate limits and cooldowns stop repeated restarts
+// during an incident from making it worse.
+type OperationLimits struct {
+	CommandTimeoutSeconds int             `bson:"commandTimeoutSeconds,omitempty" json:"commandTimeoutSeconds,omitempty"` // a single SSH command; the server's ssh.commandTimeout when unset
+	Restart               *OperationLimit `bson:"restart,omitempty" json:"restart,omitempty"`
+	Upgrade               *OperationLimit `bson:"upgrade,omitempty" json:"upgrade,omitempty"`
+	CustomAction          *OperationLimit `bson:"customAction,omitempty" json:"customAction,omitempty"` // shared by every custom action
+}
+
+// OperationLimit is the timeout and rate limit of one operation
+type OperationLimit struct {
+	TimeoutSeconds  int `bson:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`   // the operation's command, not its hooks
+	MaxRuns         int `bson:"maxRuns,omitempty" json:"maxRuns,omitempty"`                 // runs started per window; unlimited when 0
+	WindowSeconds   int `bson:"windowSeconds,omitempty" json:"windowSeconds,omitempty"`     // default 3600
+	CooldownSeconds int `bson:"cooldownSeconds,omitempty" json:"cooldownSeconds,omitempty"` // minimum time between runs
+}
+
+// Window returns the period MaxRuns is counted over
+func (l OperationLimit) Window() time.Duration {
+	if l.WindowSeconds <= 0 {
+		return DefaultRateLimitWindow
+	}
+	return time.Duration(l.WindowSeconds) * time.Second
+}
+
+// Cooldown returns the minimum time between runs
+func (l OperationLimit) Cooldown() time.Duration {
+	return time.Duration(l.CooldownSeconds) * time.Second
+}
+
+// Limited reports whether runs are rate limited at all
+func (l OperationLimit) Limited() bool {
+	return l.MaxRuns > 0 || l.CooldownSeconds > 0
+}
+
+// For returns the limit of a restart, upgrade or custom action, which is
+// empty when none is set
+func (l *OperationLimits) For(action ActionType) OperationLimit {
+	var limit *OperationLimit
+	if l != nil {
+		switch action {
+		case ActionTypeRestart:
+			limit = l.Restart
+		case ActionTypeUpgrade:
+			limit = l.Upgrade
+		case ActionTypeCustom:
+			limit = l.CustomAction
+		}
+	}
+	if limit == nil {
+		return OperationLimit{}
+	}
+	return *limit
+}
+
+// Timeout returns how long an operation's command may run
+func (l *OperationLimits) Timeout(action ActionType) time.Duration {
+	if seconds := l.For(action).TimeoutSeconds; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	switch action {
+	case ActionTypeUpgrade:
+		return DefaultUpgradeTimeout
+	case ActionTypeCustom:
+		return DefaultCustomActionTimeout
+	}
+	return DefaultRestartTimeout
+}
+
+// CommandTimeout returns how long a single SSH command may run, or zero to
+// use the server's default
+func (l *OperationLimits) CommandTimeout() time.Duration {
+	if l == nil || l.CommandTimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(l.CommandTimeoutSeconds) * time.Second
+}
@@ -0,0 +1,40 @@
+package entities_test
+
+import (
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationLimits_Timeout(t *testing.T) {
+	var none *entities.OperationLimits
+	assert.Equal(t, entities.DefaultRestartTimeout, none.Timeout(entities.ActionTypeRestart))
+	assert.Equal(t, entities.DefaultUpgradeTimeout, none.Timeout(entities.ActionTypeUpgrade))
+	assert.Equal(t, entities.DefaultCustomActionTimeout, none.Timeout(entities.ActionTypeCustom))
+
+	limits := &entities.OperationLimits{Upgrade: &entities.OperationLimit{TimeoutSeconds: 1800}}
+	assert.Equal(t, 30*time.Minute, limits.Timeout(entities.ActionTypeUpgrade))
+	assert.Equal(t, entities.DefaultRestartTimeout, limits.Timeout(entities.ActionTypeRestart))
+}
+
+func TestOperationLimits_For(t *testing.T) {
+	limits := &entities.OperationLimits{
+		Restart:      &entities.OperationLimit{MaxRuns: 3},
+		CustomAction: &entities.OperationLimit{CooldownSeconds: 60},
+	}
+
+	restart := limits.For(entities.ActionTypeRestart)
+	assert.True(t, restart.Limited())
+	assert.Equal(t, entities.DefaultRateLimitWindow, restart.Window())
+	assert.Equal(t, time.Minute, limits.For(entities.ActionTypeCustom).Cooldown())
+	assert.False(t, limits.For(entities.ActionTypeUpgrade).Limited())
+	assert.False(t, limits.For(entities.ActionTypeExec).Limited())
+}
+
+func TestOperationLimits_CommandTimeout(t *testing.T) {
+	var none *entities.OperationLimits
+	assert.Zero(t, none.CommandTimeout())
+	assert.Equal(t, 20*time.Minute, (&entities.OperationLimits{CommandTimeoutSeconds: 1200}).CommandTimeout())
+}
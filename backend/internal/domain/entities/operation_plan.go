@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OperationPlan is the rendered result of a dry run: the steps an operation
+// would execute and the pre-flight checks they were put through. Secrets in
+// the steps are masked.
+type OperationPlan struct {
+	OperationID     string                 `bson:"operationId" json:"operationId"`
+	EnvironmentID   primitive.ObjectID     `bson:"environmentId" json:"environmentId"`
+	EnvironmentName string                 `bson:"environmentName" json:"environmentName"`
+	Operation       string                 `bson:"operation" json:"operation"`
+	Parameters      map[string]interface{} `bson:"parameters,omitempty" json:"parameters,omitempty"`
+	DryRun          bool                   `bson:"dryRun" json:"dryRun"`
+	CommandType     CommandType            `bson:"commandType" json:"commandType"`
+	Steps           []PlanStep             `bson:"steps" json:"steps"`
+	Checks          []PlanCheck            `bson:"checks" json:"checks"`
+	Valid           bool                   `bson:"valid" json:"valid"`
+	CreatedAt       time.Time              `bson:"createdAt" json:"createdAt"`
+}
+
+// PlanStep is one command the operation would run
+type PlanStep struct {
+	Type       CommandType            `bson:"type" json:"type"`
+	Method     string                 `bson:"method,omitempty" json:"method,omitempty"`
+	URL        string                 `bson:"url,omitempty" json:"url,omitempty"`
+	Headers    map[string]string      `bson:"headers,omitempty" json:"headers,omitempty"`
+	Body       map[string]interface{} `bson:"body,omitempty" json:"body,omitempty"`
+	Host       string                 `bson:"host,omitempty" json:"host,omitempty"`
+	Port       int                    `bson:"port,omitempty" json:"port,omitempty"`
+	Username   string                 `bson:"username,omitempty" json:"username,omitempty"`
+	Auth       string                 `bson:"auth,omitempty" json:"auth,omitempty"`
+	Command    string                 `bson:"command,omitempty" json:"command,omitempty"`
+	Script     *ScriptConfig          `bson:"script,omitempty" json:"script,omitempty"`
+	Hook       HookPhase              `bson:"hook,omitempty" json:"hook,omitempty"` // phase of a hook step
+	HTTPPolicy `bson:",inline"`
+}
+
+// PlanCheck is the outcome of one pre-flight check
+type PlanCheck struct {
+	Name   string `bson:"name" json:"name"`
+	Target string `bson:"target,omitempty" json:"target,omitempty"`
+	Passed bool   `bson:"passed" json:"passed"`
+	Error  string `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// AddCheck records a check outcome; a failed check invalidates the plan
+func (p *OperationPlan) AddCheck(name, target string, err error) {
+	check := PlanCheck{Name: name, Target: target, Passed: err == nil}
+	if err != nil {
+		check.Error = err.Error()
+		p.Valid = false
+	}
+	p.Checks = append(p.Checks, check)
+}
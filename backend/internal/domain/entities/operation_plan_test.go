@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationPlan_AddCheck(t *testing.T) {
+	plan := &OperationPlan{Valid: true}
+
+	plan.AddCheck("url", "https://example.com", nil)
+	assert.True(t, plan.Valid)
+
+	plan.AddCheck("command", "rm -rf /; reboot", errors.New("dangerous character"))
+	assert.False(t, plan.Valid)
+	assert.Equal(t, []PlanCheck{
+		{Name: "url", Target: "https://example.com", Passed: true},
+		{Name: "command", Target: "rm -rf /; reboot", Passed: false, Error: "dangerous character"},
+	}, plan.Checks)
+}
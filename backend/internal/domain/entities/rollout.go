@@ -0,0 +1,113 @@
+package entities
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RolloutStatus represents the state of a rolling upgrade
+type RolloutStatus string
+
+const (
+	RolloutStatusPending     RolloutStatus = "pending"
+	RolloutStatusRunning     RolloutStatus = "running"
+	RolloutStatusPaused      RolloutStatus = "paused"
+	RolloutStatusRollingBack RolloutStatus = "rolling_back"
+	RolloutStatusCompleted   RolloutStatus = "completed"
+	RolloutStatusFailed      RolloutStatus = "failed"
+	RolloutStatusRolledBack  RolloutStatus = "rolled_back"
+	RolloutStatusAborted     RolloutStatus = "aborted"
+)
+
+// RolloutMemberStatus represents the state of one environment in a rollout
+type RolloutMemberStatus string
+
+const (
+	MemberStatusPending        RolloutMemberStatus = "pending"
+	MemberStatusUpgrading      RolloutMemberStatus = "upgrading"
+	MemberStatusVerifying      RolloutMemberStatus = "verifying"
+	MemberStatusSucceeded      RolloutMemberStatus = "succeeded"
+	MemberStatusFailed         RolloutMemberStatus = "failed"
+	MemberStatusSkipped        RolloutMemberStatus = "skipped"
+	MemberStatusRolledBack     RolloutMemberStatus = "rolled_back"
+	MemberStatusRollbackFailed RolloutMemberStatus = "rollback_failed"
+)
+
+// Rollout upgrades a group of environments to one version: an optional canary
+// first, then batches, each verified healthy for a soak period
+type Rollout struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Version           string             `bson:"version" json:"version"`
+	Selector          map[string]string  `bson:"selector,omitempty" json:"selector,omitempty"`
+	Canary            bool               `bson:"canary" json:"canary"`
+	BatchSize         int                `bson:"batchSize" json:"batchSize"`
+	SoakSeconds       int                `bson:"soakSeconds" json:"soakSeconds"`
+	RollbackOnFailure bool               `bson:"rollbackOnFailure" json:"rollbackOnFailure"`
+	Status            RolloutStatus      `bson:"status" json:"status"`
+	CurrentBatch      int                `bson:"currentBatch" json:"currentBatch"`
+	TotalBatches      int                `bson:"totalBatches" json:"totalBatches"`
+	Members           []RolloutMember    `bson:"members" json:"members"`
+	Message           string             `bson:"message,omitempty" json:"message,omitempty"`
+	CreatedBy         Actor              `bson:"createdBy" json:"createdBy"`
+	CreatedAt         time.Time          `bson:"createdAt" json:"createdAt"`
+	StartedAt         *time.Time         `bson:"startedAt,omitempty" json:"startedAt,omitempty"`
+	CompletedAt       *time.Time         `bson:"completedAt,omitempty" json:"completedAt,omitempty"`
+}
+
+// RolloutMember tracks one environment's progress through a rollout
+type RolloutMember struct {
+	EnvironmentID   primitive.ObjectID  `bson:"environmentId" json:"environmentId"`
+	EnvironmentName string              `bson:"environmentName" json:"environmentName"`
+	PreviousVersion string              `bson:"previousVersion" json:"previousVersion"`
+	Batch           int                 `bson:"batch" json:"batch"`
+	Status          RolloutMemberStatus `bson:"status" json:"status"`
+	Error           string              `bson:"error,omitempty" json:"error,omitempty"`
+	UpgradedAt      *time.Time          `bson:"upgradedAt,omitempty" json:"upgradedAt,omitempty"`
+	VerifiedAt      *time.Time          `bson:"verifiedAt,omitempty" json:"verifiedAt,omitempty"`
+}
+
+// AssignBatches numbers members into batches: the canary alone in batch 0
+// when enabled, then groups of BatchSize. It returns the number of batches.
+func (r *Rollout) AssignBatches() int {
+	size := r.BatchSize
+	if size <= 0 {
+		size = 1
+	}
+
+	batch, inBatch := 0, 0
+	for i := range r.Members {
+		if r.Canary && i == 1 || inBatch == size {
+			batch++
+			inBatch = 0
+		}
+		r.Members[i].Batch = batch
+		inBatch++
+	}
+
+	r.TotalBatches = 0
+	if len(r.Members) > 0 {
+		r.TotalBatches = batch + 1
+	}
+	return r.TotalBatches
+}
+
+// BatchMembers returns the indexes of the members in the given batch
+func (r *Rollout) BatchMembers(batch int) []int {
+	var indexes []int
+	for i := range r.Members {
+		if r.Members[i].Batch == batch {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// IsFinished reports whether the rollout has reached a terminal status
+func (r *Rollout) IsFinished() bool {
+	switch r.Status {
+	case RolloutStatusCompleted, RolloutStatusFailed, RolloutStatusRolledBack, RolloutStatusAborted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func rolloutWithMembers(n int, canary bool, batchSize int) *Rollout {
+	r := &Rollout{Canary: canary, BatchSize: batchSize, Members: make([]RolloutMember, n)}
+	r.AssignBatches()
+	return r
+}
+
+func batchesOf(r *Rollout) []int {
+	batches := make([]int, len(r.Members))
+	for i, m := range r.Members {
+		batches[i] = m.Batch
+	}
+	return batches
+}
+
+func TestRollout_AssignBatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		members  int
+		canary   bool
+		size     int
+		batches  []int
+		expected int
+	}{
+		{"no members", 0, true, 2, []int{}, 0},
+		{"batches of two", 5, false, 2, []int{0, 0, 1, 1, 2}, 3},
+		{"canary then batches", 5, true, 2, []int{0, 1, 1, 2, 2}, 3},
+		{"canary only", 1, true, 3, []int{0}, 1},
+		{"zero size means one", 3, false, 0, []int{0, 1, 2}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rolloutWithMembers(tt.members, tt.canary, tt.size)
+			assert.Equal(t, tt.expected, r.TotalBatches)
+			assert.Equal(t, tt.batches, batchesOf(r))
+		})
+	}
+}
+
+func TestRollout_BatchMembers(t *testing.T) {
+	r := rolloutWithMembers(4, true, 2)
+
+	assert.Equal(t, []int{0}, r.BatchMembers(0))
+	assert.Equal(t, []int{1, 2}, r.BatchMembers(1))
+	assert.Equal(t, []int{3}, r.BatchMembers(2))
+	assert.Nil(t, r.BatchMembers(3))
+}
+
+func TestRollout_IsFinished(t *testing.T) {
+	finished := []RolloutStatus{RolloutStatusCompleted, RolloutStatusFailed, RolloutStatusRolledBack, RolloutStatusAborted}
+	active := []RolloutStatus{RolloutStatusPending, RolloutStatusRunning, RolloutStatusPaused, RolloutStatusRollingBack}
+
+	for _, status := range finished {
+		assert.True(t, (&Rollout{Status: status}).IsFinished(), status)
+	}
+	for _, status := range active {
+		assert.False(t, (&Rollout{Status: status}).IsFinished(), status)
+	}
+}
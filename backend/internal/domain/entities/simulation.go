@@ -0,0 +1,18 @@
+package entities
+
+// Simulation limits
+const (
+	MaxSimulationLatencyMs = 60000 // latency plus jitter
+)
+
+// SimulationConfig shapes how a simulated environment behaves. Simulated
+// environments run nothing: operations wait, then succeed or fail at random,
+// so the tool can be demonstrated and developed against without real hosts.
+type SimulationConfig struct {
+	LatencyMs     int      `bson:"latencyMs,omitempty" json:"latencyMs,omitempty"`         // how long every operation and health probe takes
+	JitterMs      int      `bson:"jitterMs,omitempty" json:"jitterMs,omitempty"`           // random extra latency, up to this
+	FailureRate   float64  `bson:"failureRate,omitempty" json:"failureRate,omitempty"`     // chance from 0 to 1 that an operation fails
+	Versions      []string `bson:"versions,omitempty" json:"versions,omitempty"`           // versions offered for upgrade; none listed and any accepted when empty
+	UnhealthyRate float64  `bson:"unhealthyRate,omitempty" json:"unhealthyRate,omitempty"` // chance from 0 to 1 that a health probe reports unhealthy
+	FlapInterval  int      `bson:"flapInterval,omitempty" json:"flapInterval,omitempty"`   // seconds; when set, health alternates between healthy and unhealthy every interval
+}
@@ -16,6 +16,34 @@ const (
 	UserRoleViewer UserRole = "viewer"
 )
 
+// roleRank orders roles from least to most privileged
+var roleRank = map[UserRole]int{
+	UserRoleViewer: 1,
+	UserRoleUser:   2,
+	UserRoleAdmin:  3,
+}
+
+// AtLeast reports whether the role is at least as privileged as min.
+// Unknown roles never satisfy the check.
+func (r UserRole) AtLeast(min UserRole) bool {
+	rank, ok := roleRank[r]
+	if !ok {
+		return false
+	}
+	return rank >= roleRank[min]
+}
+
+// RolesAtLeast returns every known role that is at least as privileged as min
+func RolesAtLeast(min UserRole) []UserRole {
+	roles := make([]UserRole, 0, len(roleRank))
+	for _, role := range []UserRole{UserRoleViewer, UserRoleUser, UserRoleAdmin} {
+		if role.AtLeast(min) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
 // User represents a system user
 type User struct {
 	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
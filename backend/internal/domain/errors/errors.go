@@ -50,6 +50,71 @@ var (
 		Code:    "AUTH_UNAUTHORIZED",
 		Message: "Unauthorized access",
 	}
+
+	ErrForbidden = DomainError{
+		Code:    "AUTH_FORBIDDEN",
+		Message: "Insufficient permissions for this action",
+	}
+
+	ErrApprovalNotFound = DomainError{
+		Code:    "APPROVAL_NOT_FOUND",
+		Message: "Approval request not found",
+	}
+
+	ErrApprovalNotPending = DomainError{
+		Code:    "APPROVAL_NOT_PENDING",
+		Message: "Approval request has already been decided",
+	}
+
+	ErrApprovalExpired = DomainError{
+		Code:    "APPROVAL_EXPIRED",
+		Message: "Approval request has expired",
+	}
+
+	ErrSelfApproval = DomainError{
+		Code:    "APPROVAL_SELF",
+		Message: "Approval requests must be decided by a different user",
+	}
+
+	ErrBulkOperationNotFound = DomainError{
+		Code:    "BULK_OPERATION_NOT_FOUND",
+		Message: "Bulk operation not found",
+	}
+
+	ErrActionNotFound = DomainError{
+		Code:    "ACTION_NOT_FOUND",
+		Message: "Custom action not found",
+	}
+
+	ErrRolloutNotFound = DomainError{
+		Code:    "ROLLOUT_NOT_FOUND",
+		Message: "Rollout not found",
+	}
+
+	ErrRolloutNotActive = DomainError{
+		Code:    "ROLLOUT_NOT_ACTIVE",
+		Message: "Rollout is not running in this server",
+	}
+
+	ErrCommandNotAllowed = DomainError{
+		Code:    "COMMAND_NOT_ALLOWED",
+		Message: "Command is not allowed by the command allowlist",
+	}
+
+	ErrRecordingNotFound = DomainError{
+		Code:    "RECORDING_NOT_FOUND",
+		Message: "Terminal session recording not found",
+	}
+
+	ErrLogSourceNotFound = DomainError{
+		Code:    "LOG_SOURCE_NOT_FOUND",
+		Message: "Log source not found",
+	}
+
+	ErrTailLimitReached = DomainError{
+		Code:    "TAIL_LIMIT_REACHED",
+		Message: "Too many log tails are running on this host",
+	}
 )
 
 // NewValidationError creates a new validation error
@@ -75,6 +140,19 @@ func NewInternalError(err error) error {
 	}
 }
 
+// NewRateLimitError creates an error for an operation refused by its rate
+// limit or cooldown; it may be retried after retryAfter seconds
+func NewRateLimitError(operation string, retryAfter int) error {
+	return DomainError{
+		Code:    "OPERATION_RATE_LIMITED",
+		Message: "Operation was run too recently, try again later",
+		Details: map[string]interface{}{
+			"operation":  operation,
+			"retryAfter": retryAfter,
+		},
+	}
+}
+
 // IsNotFound checks if the error is a not found error
 func IsNotFound(err error) bool {
 	if domainErr, ok := err.(DomainError); ok {
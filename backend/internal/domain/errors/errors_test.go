@@ -101,6 +101,16 @@ func TestNewInternalError(t *testing.T) {
 	assert.Equal(t, originalErr.Error(), domainErr.Details["error"])
 }
 
+func TestNewRateLimitError(t *testing.T) {
+	err := errors.NewRateLimitError("restart", 120)
+
+	domainErr, ok := err.(errors.DomainError)
+	assert.True(t, ok)
+	assert.Equal(t, "OPERATION_RATE_LIMITED", domainErr.Code)
+	assert.Equal(t, "restart", domainErr.Details["operation"])
+	assert.Equal(t, 120, domainErr.Details["retryAfter"])
+}
+
 func TestIsNotFound(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"app-env-manager/internal/domain/entities"
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
 )
@@ -18,6 +19,10 @@ type Config struct {
 	Health   HealthConfig   `yaml:"health"`
 	Security SecurityConfig `yaml:"security"`
 	WebSocket WebSocketConfig `yaml:"websocket"`
+	Approval ApprovalConfig `yaml:"approval"`
+	Bulk     BulkConfig     `yaml:"bulk"`
+	Terminal TerminalConfig `yaml:"terminal"`
+	RemoteLogs RemoteLogsConfig `yaml:"remoteLogs"`
 }
 
 // ServerConfig contains server settings
@@ -39,18 +44,22 @@ type DatabaseConfig struct {
 
 // SSHConfig contains SSH settings
 type SSHConfig struct {
-	ConnectionTimeout time.Duration `yaml:"connectionTimeout"`
-	CommandTimeout    time.Duration `yaml:"commandTimeout"`
-	MaxConnections    int           `yaml:"maxConnections"`
-	EncryptionKey     string        `yaml:"-"` // Not in YAML, from env
+	ConnectionTimeout time.Duration          `yaml:"connectionTimeout"`
+	CommandTimeout    time.Duration          `yaml:"commandTimeout"`
+	MaxConnections    int                    `yaml:"maxConnections"`
+	EncryptionKey     string                 `yaml:"-"`                // Not in YAML, from env
+	CommandAllowlist  []entities.CommandRule `yaml:"commandAllowlist"` // Global allowlist for SSH commands
 }
 
 // HealthConfig contains health check settings
 type HealthConfig struct {
 	CheckInterval    time.Duration `yaml:"checkInterval"`
 	Timeout          time.Duration `yaml:"timeout"`
-	MaxRetries       int           `yaml:"maxRetries"`
+	MaxRetries       int           `yaml:"maxRetries"`      // Retries of an unhealthy check before its result counts
+	RetryBackoff     time.Duration `yaml:"retryBackoff"`    // Wait before the first retry, doubled before each next one
 	ConcurrentChecks int           `yaml:"concurrentChecks"`
+	FlapTransitions  int           `yaml:"flapTransitions"` // Health changes within FlapWindow that mark an environment flapping; 0 disables detection
+	FlapWindow       time.Duration `yaml:"flapWindow"`
 }
 
 // SecurityConfig contains security settings
@@ -72,6 +81,34 @@ type WebSocketConfig struct {
 	MaxMessageSize int64         `yaml:"maxMessageSize"`
 }
 
+// ApprovalConfig contains two-person approval settings
+type ApprovalConfig struct {
+	Expiry       time.Duration `yaml:"expiry"`       // How long a request may stay pending
+	ApproverRole string        `yaml:"approverRole"` // Minimum role allowed to approve
+}
+
+// BulkConfig contains bulk operation settings
+type BulkConfig struct {
+	MaxParallel int `yaml:"maxParallel"` // Upper bound on per-request parallelism
+}
+
+// TerminalConfig contains browser terminal settings
+type TerminalConfig struct {
+	MinRole      string        `yaml:"minRole"`      // Minimum role allowed to open a terminal
+	IdleTimeout  time.Duration `yaml:"idleTimeout"`  // Closes sessions without input
+	MaxDuration  time.Duration `yaml:"maxDuration"`  // Closes sessions regardless of activity
+	RecordingDir string        `yaml:"recordingDir"` // Where session recordings are stored
+}
+
+// RemoteLogsConfig contains remote log tailing settings
+type RemoteLogsConfig struct {
+	DefaultLines      int           `yaml:"defaultLines"`      // Lines returned when a request does not ask for a number
+	MaxLines          int           `yaml:"maxLines"`          // Upper bound on requested lines
+	MaxTailsPerHost   int           `yaml:"maxTailsPerHost"`   // Concurrent reads and follows per host
+	LinesPerSecond    int           `yaml:"linesPerSecond"`    // Followed lines beyond this rate are dropped
+	MaxFollowDuration time.Duration `yaml:"maxFollowDuration"` // Follow streams are closed after this long
+}
+
 // Load loads configuration from file and environment
 func Load(path string) (*Config, error) {
 	// Load environment variables
@@ -129,7 +166,10 @@ func defaultConfig() *Config {
 			CheckInterval:    30 * time.Second,
 			Timeout:          5 * time.Second,
 			MaxRetries:       3,
+			RetryBackoff:     500 * time.Millisecond,
 			ConcurrentChecks: 10,
+			FlapTransitions:  5,
+			FlapWindow:       15 * time.Minute,
 		},
 		Security: SecurityConfig{
 			TokenExpiration:   24 * time.Hour,
@@ -144,6 +184,26 @@ func defaultConfig() *Config {
 			WriteTimeout:   10 * time.Second,
 			MaxMessageSize: 512 * 1024, // 512KB
 		},
+		Approval: ApprovalConfig{
+			Expiry:       1 * time.Hour,
+			ApproverRole: "admin",
+		},
+		Bulk: BulkConfig{
+			MaxParallel: 10,
+		},
+		Terminal: TerminalConfig{
+			MinRole:      "admin",
+			IdleTimeout:  15 * time.Minute,
+			MaxDuration:  1 * time.Hour,
+			RecordingDir: "recordings",
+		},
+		RemoteLogs: RemoteLogsConfig{
+			DefaultLines:      100,
+			MaxLines:          5000,
+			MaxTailsPerHost:   3,
+			LinesPerSecond:    200,
+			MaxFollowDuration: 1 * time.Hour,
+		},
 	}
 }
 
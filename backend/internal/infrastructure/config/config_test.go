@@ -45,7 +45,10 @@ func TestLoad_DefaultConfig(t *testing.T) {
 	assert.Equal(t, 30*time.Second, cfg.Health.CheckInterval)
 	assert.Equal(t, 5*time.Second, cfg.Health.Timeout)
 	assert.Equal(t, 3, cfg.Health.MaxRetries)
+	assert.Equal(t, 500*time.Millisecond, cfg.Health.RetryBackoff)
 	assert.Equal(t, 10, cfg.Health.ConcurrentChecks)
+	assert.Equal(t, 5, cfg.Health.FlapTransitions)
+	assert.Equal(t, 15*time.Minute, cfg.Health.FlapWindow)
 
 	assert.Equal(t, "test-jwt-secret-for-testing-only", cfg.Security.JWTSecret)
 	assert.Equal(t, 24*time.Hour, cfg.Security.TokenExpiration)
@@ -58,6 +61,22 @@ func TestLoad_DefaultConfig(t *testing.T) {
 	assert.Equal(t, 60*time.Second, cfg.WebSocket.PongTimeout)
 	assert.Equal(t, 10*time.Second, cfg.WebSocket.WriteTimeout)
 	assert.Equal(t, int64(512*1024), cfg.WebSocket.MaxMessageSize)
+
+	assert.Equal(t, 1*time.Hour, cfg.Approval.Expiry)
+	assert.Equal(t, "admin", cfg.Approval.ApproverRole)
+
+	assert.Equal(t, 10, cfg.Bulk.MaxParallel)
+
+	assert.Equal(t, "admin", cfg.Terminal.MinRole)
+	assert.Equal(t, 15*time.Minute, cfg.Terminal.IdleTimeout)
+	assert.Equal(t, 1*time.Hour, cfg.Terminal.MaxDuration)
+	assert.Equal(t, "recordings", cfg.Terminal.RecordingDir)
+
+	assert.Equal(t, 100, cfg.RemoteLogs.DefaultLines)
+	assert.Equal(t, 5000, cfg.RemoteLogs.MaxLines)
+	assert.Equal(t, 3, cfg.RemoteLogs.MaxTailsPerHost)
+	assert.Equal(t, 200, cfg.RemoteLogs.LinesPerSecond)
+	assert.Equal(t, 1*time.Hour, cfg.RemoteLogs.MaxFollowDuration)
 }
 
 func TestLoad_FromYAMLFile(t *testing.T) {
@@ -88,7 +107,10 @@ health:
   checkInterval: 60s
   timeout: 10s
   maxRetries: 5
+  retryBackoff: 1s
   concurrentChecks: 20
+  flapTransitions: 4
+  flapWindow: 30m
 
 security:
   tokenExpiration: 48h
@@ -140,7 +162,10 @@ websocket:
 	assert.Equal(t, 60*time.Second, cfg.Health.CheckInterval)
 	assert.Equal(t, 10*time.Second, cfg.Health.Timeout)
 	assert.Equal(t, 5, cfg.Health.MaxRetries)
+	assert.Equal(t, time.Second, cfg.Health.RetryBackoff)
 	assert.Equal(t, 20, cfg.Health.ConcurrentChecks)
+	assert.Equal(t, 4, cfg.Health.FlapTransitions)
+	assert.Equal(t, 30*time.Minute, cfg.Health.FlapWindow)
 
 	assert.Equal(t, 48*time.Hour, cfg.Security.TokenExpiration)
 	assert.Equal(t, 12, cfg.Security.BCryptCost)
@@ -509,3 +534,98 @@ func TestLoad_AllowedHostsEnvOverride(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string{"host1.local", "host2.local"}, cfg.Security.AllowedHosts)
 }
+
+func TestLoad_CommandAllowlist(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+
+	configContent := `
+ssh:
+  commandAllowlist:
+    - name: restart-app
+      type: exact
+      pattern: sudo systemctl restart app
+    - name: upgrade
+      type: glob
+      pattern: sudo app-upgrade --version={version}
+      params:
+        version: '[0-9]+\.[0-9]+\.[0-9]+'
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	os.Setenv("JWT_SECRET", "test-jwt-secret")
+	os.Setenv("SSH_KEY_ENCRYPTION_KEY", "12345678901234567890123456789012")
+	defer func() {
+		os.Unsetenv("JWT_SECRET")
+		os.Unsetenv("SSH_KEY_ENCRYPTION_KEY")
+	}()
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	require.Len(t, cfg.SSH.CommandAllowlist, 2)
+	assert.Equal(t, "restart-app", cfg.SSH.CommandAllowlist[0].Name)
+	assert.Equal(t, "exact", string(cfg.SSH.CommandAllowlist[0].Type))
+	assert.Equal(t, "sudo app-upgrade --version={version}", cfg.SSH.CommandAllowlist[1].Pattern)
+	assert.Equal(t, `[0-9]+\.[0-9]+\.[0-9]+`, cfg.SSH.CommandAllowlist[1].Params["version"])
+}
+
+func TestLoad_Terminal(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+
+	configContent := `
+terminal:
+  minRole: user
+  idleTimeout: 5m
+  maxDuration: 30m
+  recordingDir: /var/lib/app-env-manager/recordings
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	os.Setenv("JWT_SECRET", "test-jwt-secret")
+	os.Setenv("SSH_KEY_ENCRYPTION_KEY", "12345678901234567890123456789012")
+	defer func() {
+		os.Unsetenv("JWT_SECRET")
+		os.Unsetenv("SSH_KEY_ENCRYPTION_KEY")
+	}()
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "user", cfg.Terminal.MinRole)
+	assert.Equal(t, 5*time.Minute, cfg.Terminal.IdleTimeout)
+	assert.Equal(t, 30*time.Minute, cfg.Terminal.MaxDuration)
+	assert.Equal(t, "/var/lib/app-env-manager/recordings", cfg.Terminal.RecordingDir)
+}
+
+func TestLoad_RemoteLogs(t *testing.T) {
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "config.yaml")
+
+	configContent := `
+remoteLogs:
+  defaultLines: 50
+  maxLines: 1000
+  maxTailsPerHost: 1
+  linesPerSecond: 20
+  maxFollowDuration: 10m
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	os.Setenv("JWT_SECRET", "test-jwt-secret")
+	os.Setenv("SSH_KEY_ENCRYPTION_KEY", "12345678901234567890123456789012")
+	defer func() {
+		os.Unsetenv("JWT_SECRET")
+		os.Unsetenv("SSH_KEY_ENCRYPTION_KEY")
+	}()
+
+	cfg, err := config.Load(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, 50, cfg.RemoteLogs.DefaultLines)
+	assert.Equal(t, 1000, cfg.RemoteLogs.MaxLines)
+	assert.Equal(t, 1, cfg.RemoteLogs.MaxTailsPerHost)
+	assert.Equal(t, 20, cfg.RemoteLogs.LinesPerSecond)
+	assert.Equal(t, 10*time.Minute, cfg.RemoteLogs.MaxFollowDuration)
+}
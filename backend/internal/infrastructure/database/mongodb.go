@@ -122,6 +122,42 @@ func (m *MongoDB) CreateIndexes(ctx context.Context) error {
 		return fmt.Errorf("failed to create credentials indexes: %w", err)
 	}
 
+	// Approval request indexes
+	approvalCollection := m.Collection("approvals")
+	approvalIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expiresAt", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "environmentId", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+	if _, err := approvalCollection.Indexes().CreateMany(ctx, approvalIndexes); err != nil {
+		return fmt.Errorf("failed to create approval indexes: %w", err)
+	}
+
+	// Bulk operation indexes
+	bulkCollection := m.Collection("bulk_operations")
+	bulkIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+	if _, err := bulkCollection.Indexes().CreateMany(ctx, bulkIndexes); err != nil {
+		return fmt.Errorf("failed to create bulk operation indexes: %w", err)
+	}
+
+	// Rollout indexes
+	rolloutCollection := m.Collection("rollouts")
+	rolloutIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "createdAt", Value: -1}},
+		},
+	}
+	if _, err := rolloutCollection.Indexes().CreateMany(ctx, rolloutIndexes); err != nil {
+		return fmt.Errorf("failed to create rollout indexes: %w", err)
+	}
+
 	return nil
 }
 
@@ -14,8 +14,8 @@ import (
 )
 
 // TestCreateIndexes_AllSuccess verifies that CreateIndexes returns nil when all
-// three collection index groups are created successfully. This covers the
-// credentials-collection branch and the final "return nil".
+// collection index groups are created successfully. This covers the
+// credentials-, approvals-, bulk-operations- and rollouts-collection branches and the final "return nil".
 func TestCreateIndexes_AllSuccess(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
@@ -26,7 +26,11 @@ func TestCreateIndexes_AllSuccess(t *testing.T) {
 		}
 
 		// The driver sends one createIndexes command per CreateMany call.
-		// We need three success responses: env, audit, credentials.
+		// We need six success responses: env, audit, credentials, approvals,
+		// bulk operations, rollouts.
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
 		mt.AddMockResponses(mtest.CreateSuccessResponse())
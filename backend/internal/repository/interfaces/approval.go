@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// ApprovalRepository defines the interface for approval request data access
+type ApprovalRepository interface {
+	Create(ctx context.Context, req *entities.ApprovalRequest) error
+	GetByID(ctx context.Context, id string) (*entities.ApprovalRequest, error)
+	List(ctx context.Context, filter ApprovalFilter) ([]*entities.ApprovalRequest, error)
+	// UpdateIfStatus replaces the request only while it is still in the
+	// expected status, returning ErrNotFound otherwise. This prevents two
+	// approvers from deciding the same request concurrently.
+	UpdateIfStatus(ctx context.Context, id string, expected entities.ApprovalStatus, req *entities.ApprovalRequest) error
+	// ExpirePending marks pending requests whose window ended before the given
+	// time as expired and returns how many were updated.
+	ExpirePending(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ApprovalFilter defines filtering options for approval requests
+type ApprovalFilter struct {
+	EnvironmentID string
+	Status        *entities.ApprovalStatus
+	RequesterID   string
+	Pagination    *Pagination
+}
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// BulkOperationRepository defines the interface for bulk operation data access
+type BulkOperationRepository interface {
+	Create(ctx context.Context, op *entities.BulkOperation) error
+	GetByID(ctx context.Context, id string) (*entities.BulkOperation, error)
+	List(ctx context.Context, filter BulkOperationFilter) ([]*entities.BulkOperation, error)
+	Update(ctx context.Context, op *entities.BulkOperation) error
+}
+
+// BulkOperationFilter defines filtering options for bulk operations
+type BulkOperationFilter struct {
+	Status     *entities.BulkOperationStatus
+	Pagination *Pagination
+}
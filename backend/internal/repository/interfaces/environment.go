@@ -28,6 +28,7 @@ type ListFilter struct {
 	
 	// Environment specific
 	Status     *entities.HealthStatus
+	Labels     map[string]string // all labels must match
 	
 	// Deprecated - for backward compatibility
 	Pagination *Pagination
@@ -0,0 +1,21 @@
+package interfaces
+
+import (
+	"context"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// RolloutRepository defines the interface for rollout data access
+type RolloutRepository interface {
+	Create(ctx context.Context, rollout *entities.Rollout) error
+	GetByID(ctx context.Context, id string) (*entities.Rollout, error)
+	List(ctx context.Context, filter RolloutFilter) ([]*entities.Rollout, error)
+	Update(ctx context.Context, rollout *entities.Rollout) error
+}
+
+// RolloutFilter defines filtering options for rollouts
+type RolloutFilter struct {
+	Status     *entities.RolloutStatus
+	Pagination *Pagination
+}
@@ -0,0 +1,152 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ApprovalRepository implements the approval repository interface for MongoDB
+type ApprovalRepository struct {
+	collection *mongo.Collection
+}
+
+// NewApprovalRepository creates a new approval repository
+func NewApprovalRepository(db *mongo.Database) *ApprovalRepository {
+	return &ApprovalRepository{
+		collection: db.Collection("approvals"),
+	}
+}
+
+// Create stores a new approval request
+func (r *ApprovalRepository) Create(ctx context.Context, req *entities.ApprovalRequest) error {
+	if req.ID.IsZero() {
+		req.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, req); err != nil {
+		return fmt.Errorf("failed to create approval request: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves an approval request by ID
+func (r *ApprovalRepository) GetByID(ctx context.Context, id string) (*entities.ApprovalRequest, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.NewValidationError("id", "invalid object ID")
+	}
+
+	var req entities.ApprovalRequest
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&req)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrApprovalNotFound
+		}
+		return nil, fmt.Errorf("failed to get approval request: %w", err)
+	}
+
+	return &req, nil
+}
+
+// List retrieves approval requests matching the filter, newest first
+func (r *ApprovalRepository) List(ctx context.Context, filter interfaces.ApprovalFilter) ([]*entities.ApprovalRequest, error) {
+	query := bson.M{}
+
+	if filter.EnvironmentID != "" {
+		objectID, err := primitive.ObjectIDFromHex(filter.EnvironmentID)
+		if err != nil {
+			return nil, errors.NewValidationError("environmentId", "invalid object ID")
+		}
+		query["environmentId"] = objectID
+	}
+
+	if filter.Status != nil {
+		validatedStatus, err := validateStringInput(string(*filter.Status))
+		if err != nil {
+			return nil, errors.NewValidationError("status", "invalid status filter")
+		}
+		query["status"] = validatedStatus
+	}
+
+	if filter.RequesterID != "" {
+		validatedRequester, err := validateStringInput(filter.RequesterID)
+		if err != nil {
+			return nil, errors.NewValidationError("requesterId", "invalid requester filter")
+		}
+		query["requestedBy.id"] = validatedRequester
+	}
+
+	findOptions := options.Find()
+	if filter.Pagination != nil {
+		findOptions.SetSkip(int64(filter.Pagination.GetOffset()))
+		findOptions.SetLimit(int64(filter.Pagination.GetLimit()))
+	}
+	findOptions.SetSort(bson.M{"createdAt": -1})
+
+	cursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var requests []*entities.ApprovalRequest
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, fmt.Errorf("failed to decode approval requests: %w", err)
+	}
+
+	return requests, nil
+}
+
+// UpdateIfStatus replaces an approval request while it is still in the expected status
+func (r *ApprovalRepository) UpdateIfStatus(ctx context.Context, id string, expected entities.ApprovalStatus, req *entities.ApprovalRequest) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.NewValidationError("id", "invalid object ID")
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":      req.Status,
+			"decidedBy":   req.DecidedBy,
+			"decidedAt":   req.DecidedAt,
+			"comment":     req.Comment,
+			"operationId": req.OperationID,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID, "status": expected}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update approval request: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return interfaces.ErrNotFound
+	}
+
+	return nil
+}
+
+// ExpirePending marks pending approval requests that expired before the given time
+func (r *ApprovalRepository) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{
+			"status":    entities.ApprovalStatusPending,
+			"expiresAt": bson.M{"$lt": before},
+		},
+		bson.M{"$set": bson.M{"status": entities.ApprovalStatusExpired}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire approval requests: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
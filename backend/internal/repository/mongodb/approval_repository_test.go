@@ -0,0 +1,130 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/repository/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestApprovalRepository_Create(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("assigns id", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		req := &entities.ApprovalRequest{Status: entities.ApprovalStatusPending}
+		err := repo.Create(context.Background(), req)
+
+		assert.NoError(t, err)
+		assert.False(t, req.ID.IsZero())
+	})
+}
+
+func TestApprovalRepository_GetByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.approvals", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "status", Value: "pending"},
+			{Key: "operation", Value: "restart"},
+		}))
+
+		req, err := repo.GetByID(context.Background(), id.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, entities.ApprovalStatusPending, req.Status)
+		assert.Equal(t, entities.ApprovalOperationRestart, req.Operation)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.approvals", mtest.FirstBatch))
+
+		_, err := repo.GetByID(context.Background(), primitive.NewObjectID().Hex())
+		assert.Equal(t, errors.ErrApprovalNotFound, err)
+	})
+
+	mt.Run("invalid id", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+
+		_, err := repo.GetByID(context.Background(), "bad")
+		assert.Error(t, err)
+	})
+}
+
+func TestApprovalRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("filters", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+		status := entities.ApprovalStatusPending
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.approvals", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "status", Value: "pending"}},
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "status", Value: "pending"}},
+		))
+
+		list, err := repo.List(context.Background(), interfaces.ApprovalFilter{
+			EnvironmentID: primitive.NewObjectID().Hex(),
+			Status:        &status,
+			RequesterID:   "u1",
+			Pagination:    &interfaces.Pagination{Page: 1, Limit: 10},
+		})
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+	})
+
+	mt.Run("invalid environment id", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+
+		_, err := repo.List(context.Background(), interfaces.ApprovalFilter{EnvironmentID: "bad"})
+		assert.Error(t, err)
+	})
+}
+
+func TestApprovalRepository_UpdateIfStatus(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("matched", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.UpdateIfStatus(context.Background(), primitive.NewObjectID().Hex(),
+			entities.ApprovalStatusPending, &entities.ApprovalRequest{Status: entities.ApprovalStatusApproved})
+		assert.NoError(t, err)
+	})
+
+	mt.Run("already decided", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.UpdateIfStatus(context.Background(), primitive.NewObjectID().Hex(),
+			entities.ApprovalStatusPending, &entities.ApprovalRequest{Status: entities.ApprovalStatusApproved})
+		assert.Equal(t, interfaces.ErrNotFound, err)
+	})
+}
+
+func TestApprovalRepository_ExpirePending(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := mongodb.NewApprovalRepository(mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 3}, {Key: "nModified", Value: 3}})
+
+		count, err := repo.ExpirePending(context.Background(), time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+}
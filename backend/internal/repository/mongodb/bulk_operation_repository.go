@@ -0,0 +1,114 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BulkOperationRepository implements the bulk operation repository interface for MongoDB
+type BulkOperationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewBulkOperationRepository creates a new bulk operation repository
+func NewBulkOperationRepository(db *mongo.Database) *BulkOperationRepository {
+	return &BulkOperationRepository{
+		collection: db.Collection("bulk_operations"),
+	}
+}
+
+// Create stores a new bulk operation
+func (r *BulkOperationRepository) Create(ctx context.Context, op *entities.BulkOperation) error {
+	if op.ID.IsZero() {
+		op.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, op); err != nil {
+		return fmt.Errorf("failed to create bulk operation: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a bulk operation by ID
+func (r *BulkOperationRepository) GetByID(ctx context.Context, id string) (*entities.BulkOperation, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.NewValidationError("id", "invalid object ID")
+	}
+
+	var op entities.BulkOperation
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&op)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrBulkOperationNotFound
+		}
+		return nil, fmt.Errorf("failed to get bulk operation: %w", err)
+	}
+
+	return &op, nil
+}
+
+// List retrieves bulk operations matching the filter, newest first
+func (r *BulkOperationRepository) List(ctx context.Context, filter interfaces.BulkOperationFilter) ([]*entities.BulkOperation, error) {
+	query := bson.M{}
+
+	if filter.Status != nil {
+		validatedStatus, err := validateStringInput(string(*filter.Status))
+		if err != nil {
+			return nil, errors.NewValidationError("status", "invalid status filter")
+		}
+		query["status"] = validatedStatus
+	}
+
+	findOptions := options.Find()
+	if filter.Pagination != nil {
+		findOptions.SetSkip(int64(filter.Pagination.GetOffset()))
+		findOptions.SetLimit(int64(filter.Pagination.GetLimit()))
+	}
+	findOptions.SetSort(bson.M{"createdAt": -1})
+
+	cursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bulk operations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ops []*entities.BulkOperation
+	if err := cursor.All(ctx, &ops); err != nil {
+		return nil, fmt.Errorf("failed to decode bulk operations: %w", err)
+	}
+
+	return ops, nil
+}
+
+// Update persists the progress of a bulk operation
+func (r *BulkOperationRepository) Update(ctx context.Context, op *entities.BulkOperation) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":      op.Status,
+			"children":    op.Children,
+			"summary":     op.Summary,
+			"startedAt":   op.StartedAt,
+			"completedAt": op.CompletedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": op.ID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update bulk operation: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrBulkOperationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/repository/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestBulkOperationRepository_Create(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("assigns id", func(mt *mtest.T) {
+		repo := mongodb.NewBulkOperationRepository(mt.DB)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		op := &entities.BulkOperation{Operation: entities.BulkOperationRestart}
+		require.NoError(t, repo.Create(context.Background(), op))
+		assert.False(t, op.ID.IsZero())
+	})
+}
+
+func TestBulkOperationRepository_GetByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := mongodb.NewBulkOperationRepository(mt.DB)
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.bulk_operations", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "operation", Value: "health_check"},
+			{Key: "status", Value: "running"},
+		}))
+
+		op, err := repo.GetByID(context.Background(), id.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, entities.BulkOperationHealthCheck, op.Operation)
+		assert.Equal(t, entities.BulkStatusRunning, op.Status)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongodb.NewBulkOperationRepository(mt.DB)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.bulk_operations", mtest.FirstBatch))
+
+		_, err := repo.GetByID(context.Background(), primitive.NewObjectID().Hex())
+		assert.Equal(t, errors.ErrBulkOperationNotFound, err)
+	})
+}
+
+func TestBulkOperationRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("with status", func(mt *mtest.T) {
+		repo := mongodb.NewBulkOperationRepository(mt.DB)
+		status := entities.BulkStatusCompleted
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.bulk_operations", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "status", Value: "completed"}},
+		))
+
+		ops, err := repo.List(context.Background(), interfaces.BulkOperationFilter{
+			Status:     &status,
+			Pagination: &interfaces.Pagination{Page: 1, Limit: 10},
+		})
+		require.NoError(t, err)
+		assert.Len(t, ops, 1)
+	})
+}
+
+func TestBulkOperationRepository_Update(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("matched", func(mt *mtest.T) {
+		repo := mongodb.NewBulkOperationRepository(mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.Update(context.Background(), &entities.BulkOperation{ID: primitive.NewObjectID()})
+		assert.NoError(t, err)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongodb.NewBulkOperationRepository(mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.Update(context.Background(), &entities.BulkOperation{ID: primitive.NewObjectID()})
+		assert.Equal(t, errors.ErrBulkOperationNotFound, err)
+	})
+}
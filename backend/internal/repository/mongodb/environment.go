@@ -130,6 +130,19 @@ func (r *EnvironmentRepository) List(ctx context.Context, filter interfaces.List
 		}
 		query["status.health"] = validatedStatus
 	}
+
+	// Apply label selector; every label must match
+	for key, value := range filter.Labels {
+		validatedKey, err := validateStringInput(key)
+		if err != nil || validatedKey != key {
+			return nil, errors.NewValidationError("labels", "invalid label key")
+		}
+		validatedValue, err := validateStringInput(value)
+		if err != nil {
+			return nil, errors.NewValidationError("labels", "invalid label value")
+		}
+		query["labels."+validatedKey] = validatedValue
+	}
 	
 	// Set up find options
 	findOptions := options.Find()
@@ -172,6 +185,10 @@ func (r *EnvironmentRepository) Update(ctx context.Context, id string, env *enti
 			"healthCheck":    env.HealthCheck,
 			"commands":       env.Commands,
 			"upgradeConfig":  env.UpgradeConfig,
+			"limits":         env.Limits,
+			"requiresApproval": env.RequiresApproval,
+			"labels":         env.Labels,
+			"logSources":     env.LogSources,
 			"systemInfo":     env.SystemInfo,
 			"metadata":       env.Metadata,
 			"timestamps":     env.Timestamps,
@@ -275,6 +275,26 @@ func TestEnvironmentRepository_List(t *testing.T) {
 		assert.Len(t, envs, 2)
 	})
 
+	mt.Run("with label selector", func(mt *mtest.T) {
+		repo := mongodb.NewEnvironmentRepository(mt.DB)
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.environments", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "labels", Value: bson.D{{Key: "tier", Value: "test"}}}},
+		))
+
+		envs, err := repo.List(context.Background(), interfaces.ListFilter{Labels: map[string]string{"tier": "test"}})
+		assert.NoError(t, err)
+		assert.Len(t, envs, 1)
+		assert.Equal(t, "test", envs[0].Labels["tier"])
+	})
+
+	mt.Run("with invalid label key", func(mt *mtest.T) {
+		repo := mongodb.NewEnvironmentRepository(mt.DB)
+
+		_, err := repo.List(context.Background(), interfaces.ListFilter{Labels: map[string]string{"$where": "1"}})
+		assert.Error(t, err)
+	})
+
 	mt.Run("with search", func(mt *mtest.T) {
 		repo := mongodb.NewEnvironmentRepository(mt.DB)
 		
@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RolloutRepository implements the rollout repository interface for MongoDB
+type RolloutRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRolloutRepository creates a new rollout repository
+func NewRolloutRepository(db *mongo.Database) *RolloutRepository {
+	return &RolloutRepository{
+		collection: db.Collection("rollouts"),
+	}
+}
+
+// Create stores a new rollout
+func (r *RolloutRepository) Create(ctx context.Context, rollout *entities.Rollout) error {
+	if rollout.ID.IsZero() {
+		rollout.ID = primitive.NewObjectID()
+	}
+
+	if _, err := r.collection.InsertOne(ctx, rollout); err != nil {
+		return fmt.Errorf("failed to create rollout: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a rollout by ID
+func (r *RolloutRepository) GetByID(ctx context.Context, id string) (*entities.Rollout, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.NewValidationError("id", "invalid object ID")
+	}
+
+	var rollout entities.Rollout
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&rollout)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.ErrRolloutNotFound
+		}
+		return nil, fmt.Errorf("failed to get rollout: %w", err)
+	}
+
+	return &rollout, nil
+}
+
+// List retrieves rollouts matching the filter, newest first
+func (r *RolloutRepository) List(ctx context.Context, filter interfaces.RolloutFilter) ([]*entities.Rollout, error) {
+	query := bson.M{}
+
+	if filter.Status != nil {
+		validatedStatus, err := validateStringInput(string(*filter.Status))
+		if err != nil {
+			return nil, errors.NewValidationError("status", "invalid status filter")
+		}
+		query["status"] = validatedStatus
+	}
+
+	findOptions := options.Find()
+	if filter.Pagination != nil {
+		findOptions.SetSkip(int64(filter.Pagination.GetOffset()))
+		findOptions.SetLimit(int64(filter.Pagination.GetLimit()))
+	}
+	findOptions.SetSort(bson.M{"createdAt": -1})
+
+	cursor, err := r.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rollouts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rollouts []*entities.Rollout
+	if err := cursor.All(ctx, &rollouts); err != nil {
+		return nil, fmt.Errorf("failed to decode rollouts: %w", err)
+	}
+
+	return rollouts, nil
+}
+
+// Update persists the progress of a rollout
+func (r *RolloutRepository) Update(ctx context.Context, rollout *entities.Rollout) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":       rollout.Status,
+			"currentBatch": rollout.CurrentBatch,
+			"members":      rollout.Members,
+			"message":      rollout.Message,
+			"startedAt":    rollout.StartedAt,
+			"completedAt":  rollout.CompletedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": rollout.ID}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update rollout: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.ErrRolloutNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/repository/mongodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestRolloutRepository_Create(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("assigns id", func(mt *mtest.T) {
+		repo := mongodb.NewRolloutRepository(mt.DB)
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		rollout := &entities.Rollout{Version: "2.0.0"}
+		require.NoError(t, repo.Create(context.Background(), rollout))
+		assert.False(t, rollout.ID.IsZero())
+	})
+}
+
+func TestRolloutRepository_GetByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		repo := mongodb.NewRolloutRepository(mt.DB)
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "test.rollouts", mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "version", Value: "2.0.0"},
+			{Key: "status", Value: "paused"},
+			{Key: "members", Value: bson.A{
+				bson.D{{Key: "environmentName", Value: "canary"}, {Key: "batch", Value: 0}, {Key: "status", Value: "succeeded"}},
+			}},
+		}))
+
+		rollout, err := repo.GetByID(context.Background(), id.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, "2.0.0", rollout.Version)
+		assert.Equal(t, entities.RolloutStatusPaused, rollout.Status)
+		require.Len(t, rollout.Members, 1)
+		assert.Equal(t, entities.MemberStatusSucceeded, rollout.Members[0].Status)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongodb.NewRolloutRepository(mt.DB)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.rollouts", mtest.FirstBatch))
+
+		_, err := repo.GetByID(context.Background(), primitive.NewObjectID().Hex())
+		assert.Equal(t, errors.ErrRolloutNotFound, err)
+	})
+}
+
+func TestRolloutRepository_List(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("with status", func(mt *mtest.T) {
+		repo := mongodb.NewRolloutRepository(mt.DB)
+		status := entities.RolloutStatusCompleted
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.rollouts", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "status", Value: "completed"}},
+		))
+
+		rollouts, err := repo.List(context.Background(), interfaces.RolloutFilter{
+			Status:     &status,
+			Pagination: &interfaces.Pagination{Page: 1, Limit: 10},
+		})
+		require.NoError(t, err)
+		assert.Len(t, rollouts, 1)
+	})
+}
+
+func TestRolloutRepository_Update(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("matched", func(mt *mtest.T) {
+		repo := mongodb.NewRolloutRepository(mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.Update(context.Background(), &entities.Rollout{ID: primitive.NewObjectID()})
+		assert.NoError(t, err)
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongodb.NewRolloutRepository(mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+
+		err := repo.Update(context.Background(), &entities.Rollout{ID: primitive.NewObjectID()})
+		assert.Equal(t, errors.ErrRolloutNotFound, err)
+	})
+}
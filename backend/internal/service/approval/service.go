@@ -0,0 +1,303 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OperationRunner executes environment operations once they are approved
+type OperationRunner interface {
+	RestartEnvironment(ctx context.Context, id string, force bool) error
+	UpgradeEnvironment(ctx context.Context, id string, version string) error
+	RunCustomAction(ctx context.Context, id string, name string) error
+}
+
+// Service handles the two-person approval workflow for protected environments
+type Service struct {
+	repo         interfaces.ApprovalRepository
+	envRepo      interfaces.EnvironmentRepository
+	auditRepo    interfaces.AuditLogRepository
+	logService   *log.Service
+	runner       OperationRunner
+	expiry       time.Duration
+	approverRole entities.UserRole
+}
+
+// NewService creates a new approval service
+func NewService(
+	repo interfaces.ApprovalRepository,
+	envRepo interfaces.EnvironmentRepository,
+	auditRepo interfaces.AuditLogRepository,
+	logService *log.Service,
+	runner OperationRunner,
+	expiry time.Duration,
+	approverRole entities.UserRole,
+) *Service {
+	if approverRole == "" {
+		approverRole = entities.UserRoleAdmin
+	}
+	return &Service{
+		repo:         repo,
+		envRepo:      envRepo,
+		auditRepo:    auditRepo,
+		logService:   logService,
+		runner:       runner,
+		expiry:       expiry,
+		approverRole: approverRole,
+	}
+}
+
+// ApproverRoles returns the roles allowed to decide approval requests
+func (s *Service) ApproverRoles() []string {
+	roles := entities.RolesAtLeast(s.approverRole)
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = string(role)
+	}
+	return names
+}
+
+// Submit files an approval request for an operation on a protected
+// environment. It returns nil without error when the environment does not
+// require approval, in which case the caller should run the operation directly.
+func (s *Service) Submit(ctx context.Context, envID string, operation entities.ApprovalOperation, params map[string]interface{}) (*entities.ApprovalRequest, error) {
+	env, err := s.envRepo.GetByID(ctx, envID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !env.RequiresApproval {
+		return nil, nil
+	}
+
+	requester, ok := actorFromContext(ctx)
+	if !ok {
+		return nil, errors.ErrUnauthorized
+	}
+
+	req := entities.NewApprovalRequest(env, operation, params, requester, s.expiry)
+	if err := s.repo.Create(ctx, req); err != nil {
+		return nil, err
+	}
+
+	_ = s.logService.LogEnvironmentAction(ctx, env, actionTypeFor(operation),
+		fmt.Sprintf("%s operation awaiting approval", capitalize(string(operation))),
+		map[string]interface{}{
+			"approvalId": req.ID.Hex(),
+			"parameters": params,
+			"expiresAt":  req.ExpiresAt,
+		})
+
+	s.logEvent(ctx, req, entities.EventTypeApprovalRequested, entities.SeverityInfo, requester)
+
+	return req, nil
+}
+
+// Get retrieves an approval request by ID
+func (s *Service) Get(ctx context.Context, id string) (*entities.ApprovalRequest, error) {
+	s.expireStale(ctx)
+	return s.repo.GetByID(ctx, id)
+}
+
+// List lists approval requests, expiring any whose window has elapsed first
+func (s *Service) List(ctx context.Context, filter interfaces.ApprovalFilter) ([]*entities.ApprovalRequest, error) {
+	s.expireStale(ctx)
+	return s.repo.List(ctx, filter)
+}
+
+// Approve approves a pending request on behalf of the user in ctx. The
+// returned request carries the operation ID under which it should be run.
+func (s *Service) Approve(ctx context.Context, id string, comment string) (*entities.ApprovalRequest, error) {
+	return s.decide(ctx, id, entities.ApprovalStatusApproved, comment)
+}
+
+// Reject rejects a pending request on behalf of the user in ctx
+func (s *Service) Reject(ctx context.Context, id string, comment string) (*entities.ApprovalRequest, error) {
+	return s.decide(ctx, id, entities.ApprovalStatusRejected, comment)
+}
+
+// Execute runs an approved operation. The context should carry the identity
+// of the original requester so the operation is attributed to them.
+func (s *Service) Execute(ctx context.Context, req *entities.ApprovalRequest) error {
+	if req.Status != entities.ApprovalStatusApproved {
+		return errors.ErrApprovalNotPending
+	}
+
+	envID := req.EnvironmentID.Hex()
+	switch req.Operation {
+	case entities.ApprovalOperationRestart:
+		force, _ := req.Parameters["force"].(bool)
+		return s.runner.RestartEnvironment(ctx, envID, force)
+	case entities.ApprovalOperationUpgrade:
+		version, _ := req.Parameters["version"].(string)
+		if version == "" {
+			return fmt.Errorf("approved upgrade has no target version")
+		}
+		return s.runner.UpgradeEnvironment(ctx, envID, version)
+	case entities.ApprovalOperationCustomAction:
+		action, _ := req.Parameters["action"].(string)
+		if action == "" {
+			return fmt.Errorf("approved custom action has no action name")
+		}
+		return s.runner.RunCustomAction(ctx, envID, action)
+	default:
+		return fmt.Errorf("unsupported approval operation: %s", req.Operation)
+	}
+}
+
+// decide records an approval decision after enforcing the four-eyes rules
+func (s *Service) decide(ctx context.Context, id string, status entities.ApprovalStatus, comment string) (*entities.ApprovalRequest, error) {
+	approver, ok := actorFromContext(ctx)
+	if !ok {
+		return nil, errors.ErrUnauthorized
+	}
+
+	if !entities.UserRole(ctxutil.RoleFromContext(ctx)).AtLeast(s.approverRole) {
+		return nil, errors.ErrForbidden
+	}
+
+	req, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.IsPending() {
+		return nil, errors.ErrApprovalNotPending
+	}
+
+	now := time.Now()
+	if req.IsExpired(now) {
+		req.Status = entities.ApprovalStatusExpired
+		_ = s.repo.UpdateIfStatus(ctx, id, entities.ApprovalStatusPending, req)
+		return nil, errors.ErrApprovalExpired
+	}
+
+	if approver.ID == req.RequestedBy.ID {
+		return nil, errors.ErrSelfApproval
+	}
+
+	req.Status = status
+	req.DecidedBy = &approver
+	req.DecidedAt = &now
+	req.Comment = comment
+	if status == entities.ApprovalStatusApproved {
+		req.OperationID = fmt.Sprintf("op-%s", primitive.NewObjectID().Hex())
+	}
+
+	if err := s.repo.UpdateIfStatus(ctx, id, entities.ApprovalStatusPending, req); err != nil {
+		if err == interfaces.ErrNotFound {
+			// Another approver decided the request first
+			return nil, errors.ErrApprovalNotPending
+		}
+		return nil, err
+	}
+
+	env := &entities.Environment{ID: req.EnvironmentID, Name: req.EnvironmentName}
+	_ = s.logService.LogEnvironmentAction(ctx, env, actionTypeFor(req.Operation),
+		fmt.Sprintf("%s operation %s by %s", capitalize(string(req.Operation)), status, approver.Name),
+		map[string]interface{}{
+			"approvalId":  req.ID.Hex(),
+			"requestedBy": req.RequestedBy.Name,
+			"decidedBy":   approver.Name,
+			"comment":     comment,
+			"operationId": req.OperationID,
+		})
+
+	severity := entities.SeverityInfo
+	if status == entities.ApprovalStatusRejected {
+		severity = entities.SeverityWarning
+	}
+	s.logEvent(ctx, req, entities.EventTypeApprovalDecided, severity, approver)
+
+	return req, nil
+}
+
+// expireStale marks pending requests whose approval window has elapsed
+func (s *Service) expireStale(ctx context.Context) {
+	_, _ = s.repo.ExpirePending(ctx, time.Now())
+}
+
+// logEvent records an approval audit entry naming both requester and approver
+func (s *Service) logEvent(ctx context.Context, req *entities.ApprovalRequest, eventType entities.EventType,
+	severity entities.Severity, actor entities.Actor) {
+
+	metadata := map[string]interface{}{
+		"approvalId":  req.ID.Hex(),
+		"operation":   string(req.Operation),
+		"parameters":  req.Parameters,
+		"requestedBy": req.RequestedBy,
+		"expiresAt":   req.ExpiresAt,
+	}
+	if req.DecidedBy != nil {
+		metadata["decidedBy"] = *req.DecidedBy
+		metadata["comment"] = req.Comment
+	}
+	if req.OperationID != "" {
+		metadata["operationId"] = req.OperationID
+	}
+
+	entry := &entities.AuditLog{
+		Timestamp:       time.Now(),
+		EnvironmentID:   req.EnvironmentID,
+		EnvironmentName: req.EnvironmentName,
+		Type:            eventType,
+		Severity:        severity,
+		Actor:           actor,
+		Action: entities.Action{
+			Operation: string(req.Operation),
+			Status:    string(req.Status),
+		},
+		Payload: entities.Payload{
+			Metadata: metadata,
+		},
+		Tags: []string{"approval"},
+	}
+
+	// Create audit log asynchronously, preserving user context
+	userID, username := ctxutil.UserFromContext(ctx)
+	go func() {
+		bgCtx, cancel := context.WithTimeout(ctxutil.WithUser(context.Background(), userID, username), 5*time.Second)
+		defer cancel()
+		_ = s.auditRepo.Create(bgCtx, entry)
+	}()
+}
+
+// actorFromContext builds an audit actor for the authenticated user in ctx
+func actorFromContext(ctx context.Context) (entities.Actor, bool) {
+	userID, username := ctxutil.UserFromContext(ctx)
+	if userID == "" {
+		return entities.Actor{}, false
+	}
+	return entities.Actor{Type: "user", ID: userID, Name: username}, true
+}
+
+// actionTypeFor maps an approval operation onto the log action type
+func actionTypeFor(operation entities.ApprovalOperation) entities.ActionType {
+	switch operation {
+	case entities.ApprovalOperationUpgrade:
+		return entities.ActionTypeUpgrade
+	case entities.ApprovalOperationCustomAction:
+		return entities.ActionTypeCustom
+	default:
+		return entities.ActionTypeRestart
+	}
+}
+
+// capitalize upper-cases the first letter of an operation name for messages
+// and spells out multi-word names ("custom_action" becomes "Custom action")
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	s = strings.ReplaceAll(s, "_", " ")
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,390 @@
+package approval_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/approval"
+	"app-env-manager/internal/service/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockApprovalRepo struct{ mock.Mock }
+
+func (m *mockApprovalRepo) Create(ctx context.Context, req *entities.ApprovalRequest) error {
+	return m.Called(ctx, req).Error(0)
+}
+
+func (m *mockApprovalRepo) GetByID(ctx context.Context, id string) (*entities.ApprovalRequest, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.ApprovalRequest), args.Error(1)
+}
+
+func (m *mockApprovalRepo) List(ctx context.Context, filter interfaces.ApprovalFilter) ([]*entities.ApprovalRequest, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.ApprovalRequest), args.Error(1)
+}
+
+func (m *mockApprovalRepo) UpdateIfStatus(ctx context.Context, id string, expected entities.ApprovalStatus, req *entities.ApprovalRequest) error {
+	return m.Called(ctx, id, expected, req).Error(0)
+}
+
+func (m *mockApprovalRepo) ExpirePending(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockEnvRepo struct{ mock.Mock }
+
+func (m *mockEnvRepo) Create(ctx context.Context, env *entities.Environment) error {
+	return m.Called(ctx, env).Error(0)
+}
+
+func (m *mockEnvRepo) GetByID(ctx context.Context, id string) (*entities.Environment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) GetByName(ctx context.Context, name string) (*entities.Environment, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) List(ctx context.Context, filter interfaces.ListFilter) ([]*entities.Environment, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) Update(ctx context.Context, id string, env *entities.Environment) error {
+	return m.Called(ctx, id, env).Error(0)
+}
+
+func (m *mockEnvRepo) UpdateStatus(ctx context.Context, id string, status entities.Status) error {
+	return m.Called(ctx, id, status).Error(0)
+}
+
+func (m *mockEnvRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockEnvRepo) Count(ctx context.Context, filter interfaces.ListFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockAuditRepo struct{ mock.Mock }
+
+func (m *mockAuditRepo) Create(ctx context.Context, entry *entities.AuditLog) error {
+	return m.Called(ctx, entry).Error(0)
+}
+
+func (m *mockAuditRepo) GetByID(ctx context.Context, id string) (*entities.AuditLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.AuditLog), args.Error(1)
+}
+
+func (m *mockAuditRepo) List(ctx context.Context, filter interfaces.AuditLogFilter) ([]*entities.AuditLog, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.AuditLog), args.Error(1)
+}
+
+func (m *mockAuditRepo) Count(ctx context.Context, filter interfaces.AuditLogFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockAuditRepo) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockLogRepo struct{ mock.Mock }
+
+func (m *mockLogRepo) Create(ctx context.Context, entry *entities.Log) error {
+	return m.Called(ctx, entry).Error(0)
+}
+
+func (m *mockLogRepo) List(ctx context.Context, filter interfaces.LogFilter) ([]*entities.Log, int64, error) {
+	args := m.Called(ctx, filter)
+	return nil, 0, args.Error(2)
+}
+
+func (m *mockLogRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*entities.Log, error) {
+	args := m.Called(ctx, id)
+	return nil, args.Error(1)
+}
+
+func (m *mockLogRepo) DeleteOld(ctx context.Context, olderThan time.Duration) (int64, error) {
+	args := m.Called(ctx, olderThan)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockLogRepo) GetEnvironmentLogs(ctx context.Context, envID primitive.ObjectID, limit int) ([]*entities.Log, error) {
+	args := m.Called(ctx, envID, limit)
+	return nil, args.Error(1)
+}
+
+func (m *mockLogRepo) Count(ctx context.Context, filter interfaces.LogFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type mockRunner struct{ mock.Mock }
+
+func (m *mockRunner) RestartEnvironment(ctx context.Context, id string, force bool) error {
+	return m.Called(ctx, id, force).Error(0)
+}
+
+func (m *mockRunner) UpgradeEnvironment(ctx context.Context, id string, version string) error {
+	return m.Called(ctx, id, version).Error(0)
+}
+
+func (m *mockRunner) RunCustomAction(ctx context.Context, id string, name string) error {
+	return m.Called(ctx, id, name).Error(0)
+}
+
+type fixture struct {
+	repo    *mockApprovalRepo
+	envRepo *mockEnvRepo
+	audit   *mockAuditRepo
+	runner  *mockRunner
+	service *approval.Service
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		repo:    new(mockApprovalRepo),
+		envRepo: new(mockEnvRepo),
+		audit:   new(mockAuditRepo),
+		runner:  new(mockRunner),
+	}
+	logRepo := new(mockLogRepo)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	f.audit.On("Create", mock.Anything, mock.Anything).Return(nil)
+	f.service = approval.NewService(f.repo, f.envRepo, f.audit, log.NewService(logRepo),
+		f.runner, time.Hour, entities.UserRoleAdmin)
+	return f
+}
+
+func userCtx(id, name string, role entities.UserRole) context.Context {
+	return ctxutil.WithUserFull(context.Background(), id, name, string(role))
+}
+
+func pendingRequest(requesterID string) *entities.ApprovalRequest {
+	env := &entities.Environment{ID: primitive.NewObjectID(), Name: "prod"}
+	return entities.NewApprovalRequest(env, entities.ApprovalOperationUpgrade,
+		map[string]interface{}{"version": "2.0.0"},
+		entities.Actor{Type: "user", ID: requesterID, Name: "alice"}, time.Hour)
+}
+
+func TestService_Submit_UnprotectedEnvironment(t *testing.T) {
+	f := newFixture()
+	env := &entities.Environment{ID: primitive.NewObjectID(), Name: "dev"}
+	f.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+
+	req, err := f.service.Submit(userCtx("u1", "alice", entities.UserRoleUser), env.ID.Hex(),
+		entities.ApprovalOperationRestart, nil)
+
+	assert.NoError(t, err)
+	assert.Nil(t, req)
+	f.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_Submit_ProtectedEnvironment(t *testing.T) {
+	f := newFixture()
+	env := &entities.Environment{ID: primitive.NewObjectID(), Name: "prod", RequiresApproval: true}
+	f.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	f.repo.On("Create", mock.Anything, mock.AnythingOfType("*entities.ApprovalRequest")).Return(nil)
+
+	req, err := f.service.Submit(userCtx("u1", "alice", entities.UserRoleUser), env.ID.Hex(),
+		entities.ApprovalOperationRestart, map[string]interface{}{"force": true})
+
+	require.NoError(t, err)
+	require.NotNil(t, req)
+	assert.Equal(t, entities.ApprovalStatusPending, req.Status)
+	assert.Equal(t, "u1", req.RequestedBy.ID)
+	assert.Equal(t, env.ID, req.EnvironmentID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), req.ExpiresAt, time.Minute)
+}
+
+func TestService_Submit_RequiresUser(t *testing.T) {
+	f := newFixture()
+	env := &entities.Environment{ID: primitive.NewObjectID(), RequiresApproval: true}
+	f.envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+
+	_, err := f.service.Submit(context.Background(), env.ID.Hex(), entities.ApprovalOperationRestart, nil)
+	assert.Equal(t, errors.ErrUnauthorized, err)
+}
+
+func TestService_Approve(t *testing.T) {
+	f := newFixture()
+	req := pendingRequest("u1")
+	id := req.ID.Hex()
+	f.repo.On("GetByID", mock.Anything, id).Return(req, nil)
+	f.repo.On("UpdateIfStatus", mock.Anything, id, entities.ApprovalStatusPending, req).Return(nil)
+
+	decided, err := f.service.Approve(userCtx("u2", "bob", entities.UserRoleAdmin), id, "lgtm")
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApprovalStatusApproved, decided.Status)
+	require.NotNil(t, decided.DecidedBy)
+	assert.Equal(t, "u2", decided.DecidedBy.ID)
+	assert.Equal(t, "lgtm", decided.Comment)
+	assert.NotEmpty(t, decided.OperationID)
+}
+
+func TestService_Reject(t *testing.T) {
+	f := newFixture()
+	req := pendingRequest("u1")
+	id := req.ID.Hex()
+	f.repo.On("GetByID", mock.Anything, id).Return(req, nil)
+	f.repo.On("UpdateIfStatus", mock.Anything, id, entities.ApprovalStatusPending, req).Return(nil)
+
+	decided, err := f.service.Reject(userCtx("u2", "bob", entities.UserRoleAdmin), id, "not now")
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.ApprovalStatusRejected, decided.Status)
+	assert.Empty(t, decided.OperationID)
+}
+
+func TestService_Decide_Rules(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(req *entities.ApprovalRequest)
+		wantErr error
+	}{
+		{
+			name:    "unauthenticated",
+			ctx:     context.Background(),
+			wantErr: errors.ErrUnauthorized,
+		},
+		{
+			name:    "role below approver role",
+			ctx:     userCtx("u2", "bob", entities.UserRoleUser),
+			wantErr: errors.ErrForbidden,
+		},
+		{
+			name:    "self approval",
+			ctx:     userCtx("u1", "alice", entities.UserRoleAdmin),
+			wantErr: errors.ErrSelfApproval,
+		},
+		{
+			name:    "already decided",
+			ctx:     userCtx("u2", "bob", entities.UserRoleAdmin),
+			setup:   func(req *entities.ApprovalRequest) { req.Status = entities.ApprovalStatusRejected },
+			wantErr: errors.ErrApprovalNotPending,
+		},
+		{
+			name:    "expired",
+			ctx:     userCtx("u2", "bob", entities.UserRoleAdmin),
+			setup:   func(req *entities.ApprovalRequest) { req.ExpiresAt = time.Now().Add(-time.Minute) },
+			wantErr: errors.ErrApprovalExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture()
+			req := pendingRequest("u1")
+			if tt.setup != nil {
+				tt.setup(req)
+			}
+			id := req.ID.Hex()
+			f.repo.On("GetByID", mock.Anything, id).Return(req, nil)
+			f.repo.On("UpdateIfStatus", mock.Anything, id, entities.ApprovalStatusPending, mock.Anything).Return(nil)
+
+			_, err := f.service.Approve(tt.ctx, id, "")
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestService_Approve_LostRace(t *testing.T) {
+	f := newFixture()
+	req := pendingRequest("u1")
+	id := req.ID.Hex()
+	f.repo.On("GetByID", mock.Anything, id).Return(req, nil)
+	f.repo.On("UpdateIfStatus", mock.Anything, id, entities.ApprovalStatusPending, req).Return(interfaces.ErrNotFound)
+
+	_, err := f.service.Approve(userCtx("u2", "bob", entities.UserRoleAdmin), id, "")
+	assert.Equal(t, errors.ErrApprovalNotPending, err)
+}
+
+func TestService_List_ExpiresStale(t *testing.T) {
+	f := newFixture()
+	filter := interfaces.ApprovalFilter{}
+	f.repo.On("ExpirePending", mock.Anything, mock.AnythingOfType("time.Time")).Return(int64(2), nil)
+	f.repo.On("List", mock.Anything, filter).Return([]*entities.ApprovalRequest{pendingRequest("u1")}, nil)
+
+	list, err := f.service.List(context.Background(), filter)
+
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	f.repo.AssertCalled(t, "ExpirePending", mock.Anything, mock.Anything)
+}
+
+func TestService_Execute(t *testing.T) {
+	f := newFixture()
+	req := pendingRequest("u1")
+
+	err := f.service.Execute(context.Background(), req)
+	assert.Equal(t, errors.ErrApprovalNotPending, err)
+
+	req.Status = entities.ApprovalStatusApproved
+	f.runner.On("UpgradeEnvironment", mock.Anything, req.EnvironmentID.Hex(), "2.0.0").Return(nil)
+	assert.NoError(t, f.service.Execute(context.Background(), req))
+
+	restart := pendingRequest("u1")
+	restart.Operation = entities.ApprovalOperationRestart
+	restart.Parameters = map[string]interface{}{"force": true}
+	restart.Status = entities.ApprovalStatusApproved
+	f.runner.On("RestartEnvironment", mock.Anything, restart.EnvironmentID.Hex(), true).Return(nil)
+	assert.NoError(t, f.service.Execute(context.Background(), restart))
+
+	action := pendingRequest("u1")
+	action.Operation = entities.ApprovalOperationCustomAction
+	action.Parameters = map[string]interface{}{"action": "flush-cache"}
+	action.Status = entities.ApprovalStatusApproved
+	f.runner.On("RunCustomAction", mock.Anything, action.EnvironmentID.Hex(), "flush-cache").Return(nil)
+	assert.NoError(t, f.service.Execute(context.Background(), action))
+
+	action.Parameters = nil
+	assert.Error(t, f.service.Execute(context.Background(), action))
+
+	f.runner.AssertExpectations(t)
+}
+
+func TestService_ApproverRoles(t *testing.T) {
+	f := newFixture()
+	assert.Equal(t, []string{"admin"}, f.service.ApproverRoles())
+}
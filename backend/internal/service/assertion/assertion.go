@@ -0,0 +1,395 @@
+// Package assertion evaluates success criteria against HTTP responses. It is
+// shared by every component that decides whether a response is a success.
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// maxActualLength bounds how much of an actual value is echoed in failures
+const maxActualLength = 200
+
+// Response is the part of an HTTP response assertions are evaluated against
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Failure describes the assertion a response did not satisfy
+type Failure struct {
+	Assertion string `json:"assertion"` // statusCode, header, bodyRegex or jsonPath
+	Target    string `json:"target,omitempty"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+}
+
+// Error implements error
+func (f *Failure) Error() string {
+	if f.Target != "" {
+		return fmt.Sprintf("%s %s: expected %s, got %s", f.Assertion, f.Target, f.Expected, f.Actual)
+	}
+	return fmt.Sprintf("%s: expected %s, got %s", f.Assertion, f.Expected, f.Actual)
+}
+
+// StatusMatches reports whether the status code satisfies the criteria. With
+// no expected status codes any 2xx matches.
+func StatusMatches(criteria *entities.SuccessCriteria, statusCode int) bool {
+	if criteria == nil || len(criteria.StatusCodes) == 0 {
+		return statusCode >= 200 && statusCode < 300
+	}
+	for _, code := range criteria.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate checks the response against every assertion and returns the first
+// failure as a *Failure. Nil criteria only require a 2xx status.
+func Evaluate(criteria *entities.SuccessCriteria, resp Response) error {
+	if !StatusMatches(criteria, resp.StatusCode) {
+		expected := "2xx"
+		if criteria != nil && len(criteria.StatusCodes) > 0 {
+			expected = formatCodes(criteria.StatusCodes)
+		}
+		return &Failure{Assertion: "statusCode", Expected: expected, Actual: strconv.Itoa(resp.StatusCode)}
+	}
+	if criteria == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(criteria.Headers))
+	for name := range criteria.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := CheckHeader(name, criteria.Headers[name], resp.Header); err != nil {
+			return err
+		}
+	}
+
+	if criteria.BodyRegex != "" {
+		re, err := regexp.Compile(criteria.BodyRegex)
+		if err != nil {
+			return &Failure{Assertion: "bodyRegex", Expected: "valid pattern", Actual: err.Error()}
+		}
+		if !re.Match(resp.Body) {
+			return &Failure{Assertion: "bodyRegex", Expected: "body matching " + strconv.Quote(criteria.BodyRegex), Actual: truncate(strconv.Quote(string(resp.Body)))}
+		}
+	}
+
+	if len(criteria.JSONPath) > 0 {
+		var data interface{}
+		if err := json.Unmarshal(resp.Body, &data); err != nil {
+			return &Failure{Assertion: "jsonPath", Expected: "JSON body", Actual: truncate(strconv.Quote(string(resp.Body)))}
+		}
+		for _, a := range criteria.JSONPath {
+			if err := evaluateJSONPath(a, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckStatus checks that the status code is one of the codes or in one of
+// the ranges, such as "2xx" or "200-299"
+func CheckStatus(codes []int, ranges []string, statusCode int) error {
+	for _, code := range codes {
+		if code == statusCode {
+			return nil
+		}
+	}
+	for _, r := range ranges {
+		low, high, err := parseStatusRange(r)
+		if err != nil {
+			return &Failure{Assertion: "statusCode", Expected: "valid range", Actual: err.Error()}
+		}
+		if statusCode >= low && statusCode <= high {
+			return nil
+		}
+	}
+	parts := make([]string, 0, len(codes)+len(ranges))
+	for _, code := range codes {
+		parts = append(parts, strconv.Itoa(code))
+	}
+	parts = append(parts, ranges...)
+	return &Failure{Assertion: "statusCode", Expected: "one of " + strings.Join(parts, ", "), Actual: strconv.Itoa(statusCode)}
+}
+
+// CheckHeader checks that the header is present and, unless expected is
+// empty, has the expected value
+func CheckHeader(name, expected string, header http.Header) error {
+	if _, present := header[http.CanonicalHeaderKey(name)]; !present {
+		return &Failure{Assertion: "header", Target: name, Expected: describeHeader(expected), Actual: "missing"}
+	}
+	if actual := header.Get(name); expected != "" && actual != expected {
+		return &Failure{Assertion: "header", Target: name, Expected: strconv.Quote(expected), Actual: truncate(strconv.Quote(actual))}
+	}
+	return nil
+}
+
+// CheckBodyContains checks that the body contains the text
+func CheckBodyContains(text string, body []byte) error {
+	if !strings.Contains(string(body), text) {
+		return &Failure{Assertion: "bodyContains", Expected: "body containing " + strconv.Quote(text), Actual: truncate(strconv.Quote(string(body)))}
+	}
+	return nil
+}
+
+// CheckJSONPath checks one JSONPath assertion against a JSON body
+func CheckJSONPath(a entities.JSONPathAssertion, body []byte) error {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return &Failure{Assertion: "jsonPath", Expected: "JSON body", Actual: truncate(strconv.Quote(string(body)))}
+	}
+	return evaluateJSONPath(a, data)
+}
+
+// Validate checks that the criteria are well formed
+func Validate(criteria *entities.SuccessCriteria) error {
+	if criteria == nil {
+		return nil
+	}
+	if criteria.BodyRegex != "" {
+		if _, err := regexp.Compile(criteria.BodyRegex); err != nil {
+			return fmt.Errorf("invalid bodyRegex: %w", err)
+		}
+	}
+	for _, a := range criteria.JSONPath {
+		if strings.TrimSpace(a.Path) == "" {
+			return fmt.Errorf("jsonPath assertion requires a path")
+		}
+		if !hasExpectation(a) {
+			return fmt.Errorf("jsonPath assertion %s requires equals, contains, in or a comparison", a.Path)
+		}
+	}
+	return nil
+}
+
+// evaluateJSONPath checks one JSONPath assertion against the decoded body
+func evaluateJSONPath(a entities.JSONPathAssertion, data interface{}) error {
+	actual, ok := Lookup(data, a.Path)
+	if !ok {
+		return &Failure{Assertion: "jsonPath", Target: a.Path, Expected: "value", Actual: "missing"}
+	}
+
+	if a.Equals != nil {
+		expected := normalize(a.Equals)
+		if !reflect.DeepEqual(expected, actual) {
+			return &Failure{Assertion: "jsonPath", Target: a.Path, Expected: format(expected), Actual: truncate(format(actual))}
+		}
+	}
+
+	if a.Contains != nil {
+		expected := normalize(a.Contains)
+		if !contains(actual, expected) {
+			return &Failure{Assertion: "jsonPath", Target: a.Path, Expected: "value containing " + format(expected), Actual: truncate(format(actual))}
+		}
+	}
+
+	if len(a.In) > 0 {
+		allowed := normalize(a.In).([]interface{})
+		if !contains(allowed, actual) {
+			return &Failure{Assertion: "jsonPath", Target: a.Path, Expected: "one of " + format(allowed), Actual: truncate(format(actual))}
+		}
+	}
+
+	for _, c := range comparisons(a) {
+		number, ok := actual.(float64)
+		if !ok {
+			return &Failure{Assertion: "jsonPath", Target: a.Path, Expected: "number " + c.describe(), Actual: truncate(format(actual))}
+		}
+		if !c.holds(number) {
+			return &Failure{Assertion: "jsonPath", Target: a.Path, Expected: c.describe(), Actual: format(number)}
+		}
+	}
+
+	return nil
+}
+
+// comparison is a numeric bound of a JSONPath assertion
+type comparison struct {
+	operator string
+	bound    float64
+}
+
+func (c comparison) holds(value float64) bool {
+	switch c.operator {
+	case ">":
+		return value > c.bound
+	case ">=":
+		return value >= c.bound
+	case "<":
+		return value < c.bound
+	}
+	return value <= c.bound
+}
+
+func (c comparison) describe() string {
+	return c.operator + " " + strconv.FormatFloat(c.bound, 'f', -1, 64)
+}
+
+// comparisons returns the numeric bounds set on the assertion
+func comparisons(a entities.JSONPathAssertion) []comparison {
+	var out []comparison
+	for _, c := range []struct {
+		operator string
+		bound    *float64
+	}{{">", a.GreaterThan}, {">=", a.AtLeast}, {"<", a.LessThan}, {"<=", a.AtMost}} {
+		if c.bound != nil {
+			out = append(out, comparison{c.operator, *c.bound})
+		}
+	}
+	return out
+}
+
+// hasExpectation reports whether the assertion checks anything beyond the
+// value being present
+func hasExpectation(a entities.JSONPathAssertion) bool {
+	return a.Equals != nil || a.Contains != nil || len(a.In) > 0 || len(comparisons(a)) > 0
+}
+
+// parseStatusRange parses "2xx" or "200-299" into its bounds
+func parseStatusRange(r string) (int, int, error) {
+	r = strings.TrimSpace(r)
+	if len(r) == 3 && strings.HasSuffix(strings.ToLower(r), "xx") && r[0] >= '1' && r[0] <= '5' {
+		low := int(r[0]-'0') * 100
+		return low, low + 99, nil
+	}
+	if low, high, ok := strings.Cut(r, "-"); ok {
+		l, errLow := strconv.Atoi(strings.TrimSpace(low))
+		h, errHigh := strconv.Atoi(strings.TrimSpace(high))
+		if errLow == nil && errHigh == nil && l <= h {
+			return l, h, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid status range %q", r)
+}
+
+// contains matches a substring of a string or an element of an array
+func contains(actual, expected interface{}) bool {
+	switch v := actual.(type) {
+	case string:
+		s, ok := expected.(string)
+		return ok && strings.Contains(v, s)
+	case []interface{}:
+		for _, item := range v {
+			if reflect.DeepEqual(item, expected) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Lookup resolves a JSONPath such as $.data.items[0].status in decoded JSON
+func Lookup(data interface{}, path string) (interface{}, bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	current := data
+	for _, segment := range splitPath(path) {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			value, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// splitPath splits "data.items[0].status" into its keys and indexes
+func splitPath(path string) []string {
+	var segments []string
+	for _, part := range strings.Split(path, ".") {
+		for part != "" {
+			open := strings.Index(part, "[")
+			if open < 0 {
+				segments = append(segments, part)
+				break
+			}
+			if open > 0 {
+				segments = append(segments, part[:open])
+			}
+			end := strings.Index(part[open:], "]")
+			if end < 0 {
+				segments = append(segments, part[open:])
+				break
+			}
+			segments = append(segments, strings.Trim(part[open+1:open+end], `"'`))
+			part = part[open+end+1:]
+		}
+	}
+	return segments
+}
+
+// normalize converts a configured value to the types encoding/json decodes
+// into, so stored integers compare equal to JSON numbers
+func normalize(value interface{}) interface{} {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return value
+	}
+	return out
+}
+
+// format renders a JSON value for failure messages
+func format(value interface{}) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(raw)
+}
+
+// formatCodes renders a list of status codes
+func formatCodes(codes []int) string {
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = strconv.Itoa(code)
+	}
+	return "one of " + strings.Join(parts, ", ")
+}
+
+// describeHeader renders the expectation for a required header
+func describeHeader(expected string) string {
+	if expected == "" {
+		return "present"
+	}
+	return strconv.Quote(expected)
+}
+
+// truncate shortens long actual values
+func truncate(s string) string {
+	if len(s) <= maxActualLength {
+		return s
+	}
+	return s[:maxActualLength] + "..."
+}
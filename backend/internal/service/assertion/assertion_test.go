@@ -0,0 +1,252 @@
+package assertion
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonResponse(status int, body string) Response {
+	return Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       []byte(body),
+	}
+}
+
+func requireFailure(t *testing.T, err error) *Failure {
+	t.Helper()
+	require.Error(t, err)
+	failure, ok := err.(*Failure)
+	require.True(t, ok, "expected *Failure, got %T", err)
+	return failure
+}
+
+func TestStatusMatches(t *testing.T) {
+	assert.True(t, StatusMatches(nil, 200))
+	assert.True(t, StatusMatches(nil, 204))
+	assert.False(t, StatusMatches(nil, 302))
+	assert.False(t, StatusMatches(&entities.SuccessCriteria{}, 500))
+
+	criteria := &entities.SuccessCriteria{StatusCodes: []int{200, 409}}
+	assert.True(t, StatusMatches(criteria, 409))
+	assert.False(t, StatusMatches(criteria, 201))
+}
+
+func TestEvaluate_NilCriteria(t *testing.T) {
+	assert.NoError(t, Evaluate(nil, jsonResponse(200, "")))
+
+	failure := requireFailure(t, Evaluate(nil, jsonResponse(500, "")))
+	assert.Equal(t, "statusCode", failure.Assertion)
+	assert.Equal(t, "statusCode: expected 2xx, got 500", failure.Error())
+}
+
+func TestEvaluate_StatusCodes(t *testing.T) {
+	criteria := &entities.SuccessCriteria{StatusCodes: []int{200, 202}}
+
+	failure := requireFailure(t, Evaluate(criteria, jsonResponse(204, "")))
+	assert.Equal(t, "one of 200, 202", failure.Expected)
+	assert.Equal(t, "204", failure.Actual)
+}
+
+func TestEvaluate_Headers(t *testing.T) {
+	resp := jsonResponse(200, "{}")
+	resp.Header.Set("X-Deploy-Id", "abc")
+
+	assert.NoError(t, Evaluate(&entities.SuccessCriteria{Headers: map[string]string{
+		"content-type": "application/json",
+		"X-Deploy-Id":  "",
+	}}, resp))
+
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{Headers: map[string]string{
+		"Content-Type": "text/plain",
+	}}, resp))
+	assert.Equal(t, "header", failure.Assertion)
+	assert.Equal(t, "Content-Type", failure.Target)
+	assert.Equal(t, `"application/json"`, failure.Actual)
+
+	failure = requireFailure(t, Evaluate(&entities.SuccessCriteria{Headers: map[string]string{
+		"X-Missing": "",
+	}}, resp))
+	assert.Equal(t, "present", failure.Expected)
+	assert.Equal(t, "missing", failure.Actual)
+}
+
+func TestEvaluate_BodyRegex(t *testing.T) {
+	resp := jsonResponse(200, "deployment finished: 3 replicas ready")
+
+	assert.NoError(t, Evaluate(&entities.SuccessCriteria{BodyRegex: `\d+ replicas ready`}, resp))
+
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{BodyRegex: `^OK$`}, resp))
+	assert.Equal(t, "bodyRegex", failure.Assertion)
+	assert.Contains(t, failure.Actual, "deployment finished")
+
+	failure = requireFailure(t, Evaluate(&entities.SuccessCriteria{BodyRegex: `(`}, resp))
+	assert.Equal(t, "valid pattern", failure.Expected)
+}
+
+func TestEvaluate_JSONPathEquals(t *testing.T) {
+	resp := jsonResponse(200, `{"status":"ok","replicas":3,"ready":true,"items":[{"name":"a"},{"name":"b"}]}`)
+
+	assert.NoError(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.status", Equals: "ok"},
+		{Path: "$.replicas", Equals: 3},
+		{Path: "$.replicas", Equals: int64(3)},
+		{Path: "$.ready", Equals: true},
+		{Path: "$.items[1].name", Equals: "b"},
+	}}, resp))
+
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.status", Equals: "ok"},
+		{Path: "$.replicas", Equals: 5},
+	}}, resp))
+	assert.Equal(t, "jsonPath $.replicas: expected 5, got 3", failure.Error())
+
+	failure = requireFailure(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.missing", Equals: "x"},
+	}}, resp))
+	assert.Equal(t, "missing", failure.Actual)
+}
+
+func TestEvaluate_JSONPathContains(t *testing.T) {
+	resp := jsonResponse(200, `{"message":"rollout complete","tags":["blue","green"]}`)
+
+	assert.NoError(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.message", Contains: "complete"},
+		{Path: "$.tags", Contains: "green"},
+	}}, resp))
+
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.tags", Contains: "red"},
+	}}, resp))
+	assert.Equal(t, `value containing "red"`, failure.Expected)
+	assert.Equal(t, `["blue","green"]`, failure.Actual)
+}
+
+func TestEvaluate_JSONPathIn(t *testing.T) {
+	resp := jsonResponse(200, `{"checks":{"db":{"status":"down"},"replicas":3}}`)
+
+	assert.NoError(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.checks.replicas", In: []interface{}{2, 3}},
+	}}, resp))
+
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.checks.db.status", In: []interface{}{"up", "degraded"}},
+	}}, resp))
+	assert.Equal(t, `one of ["up","degraded"]`, failure.Expected)
+	assert.Equal(t, `"down"`, failure.Actual)
+}
+
+func TestEvaluate_JSONPathComparisons(t *testing.T) {
+	resp := jsonResponse(200, `{"queue":{"depth":120,"name":"jobs"}}`)
+	bound := func(v float64) *float64 { return &v }
+
+	assert.NoError(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.queue.depth", GreaterThan: bound(100), AtMost: bound(120)},
+		{Path: "$.queue.depth", AtLeast: bound(120), LessThan: bound(121)},
+	}}, resp))
+
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.queue.depth", LessThan: bound(100.5)},
+	}}, resp))
+	assert.Equal(t, "jsonPath $.queue.depth: expected < 100.5, got 120", failure.Error())
+
+	failure = requireFailure(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.queue.name", GreaterThan: bound(0)},
+	}}, resp))
+	assert.Equal(t, "number > 0", failure.Expected)
+	assert.Equal(t, `"jobs"`, failure.Actual)
+}
+
+func TestCheckStatus(t *testing.T) {
+	assert.NoError(t, CheckStatus([]int{200, 409}, nil, 409))
+	assert.NoError(t, CheckStatus(nil, []string{"2xx"}, 204))
+	assert.NoError(t, CheckStatus(nil, []string{"300-308"}, 302))
+
+	failure := requireFailure(t, CheckStatus([]int{418}, []string{"2xx"}, 500))
+	assert.Equal(t, "statusCode: expected one of 418, 2xx, got 500", failure.Error())
+
+	failure = requireFailure(t, CheckStatus(nil, []string{"2yz"}, 200))
+	assert.Equal(t, "valid range", failure.Expected)
+	assert.Contains(t, failure.Actual, `invalid status range "2yz"`)
+	requireFailure(t, CheckStatus(nil, []string{"299-200"}, 250))
+}
+
+func TestCheckBodyContains(t *testing.T) {
+	assert.NoError(t, CheckBodyContains("ready", []byte("all replicas ready")))
+
+	failure := requireFailure(t, CheckBodyContains("ready", []byte("starting")))
+	assert.Equal(t, `bodyContains: expected body containing "ready", got "starting"`, failure.Error())
+}
+
+func TestCheckJSONPath(t *testing.T) {
+	assert.NoError(t, CheckJSONPath(entities.JSONPathAssertion{Path: "$.status", Equals: "up"}, []byte(`{"status":"up"}`)))
+
+	failure := requireFailure(t, CheckJSONPath(entities.JSONPathAssertion{Path: "$.status", Equals: "up"}, []byte("up")))
+	assert.Equal(t, "JSON body", failure.Expected)
+}
+
+func TestEvaluate_JSONPathNonJSONBody(t *testing.T) {
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{JSONPath: []entities.JSONPathAssertion{
+		{Path: "$.status", Equals: "ok"},
+	}}, jsonResponse(200, "not json")))
+
+	assert.Equal(t, "JSON body", failure.Expected)
+}
+
+func TestEvaluate_TruncatesActual(t *testing.T) {
+	resp := jsonResponse(200, strings.Repeat("x", 1000))
+
+	failure := requireFailure(t, Evaluate(&entities.SuccessCriteria{BodyRegex: "^ok$"}, resp))
+	assert.True(t, strings.HasSuffix(failure.Actual, "..."))
+	assert.LessOrEqual(t, len(failure.Actual), maxActualLength+3)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate(&entities.SuccessCriteria{
+		BodyRegex: "^ok",
+		JSONPath:  []entities.JSONPathAssertion{{Path: "$.status", Equals: "ok"}},
+	}))
+
+	assert.ErrorContains(t, Validate(&entities.SuccessCriteria{BodyRegex: "("}), "invalid bodyRegex")
+	assert.ErrorContains(t, Validate(&entities.SuccessCriteria{
+		JSONPath: []entities.JSONPathAssertion{{Equals: "ok"}},
+	}), "requires a path")
+	assert.ErrorContains(t, Validate(&entities.SuccessCriteria{
+		JSONPath: []entities.JSONPathAssertion{{Path: "$.status"}},
+	}), "requires equals, contains, in or a comparison")
+}
+
+func TestLookup(t *testing.T) {
+	data := map[string]interface{}{
+		"a": map[string]interface{}{"b": "c"},
+		"items": []interface{}{
+			map[string]interface{}{"name": "first"},
+			map[string]interface{}{"name": "second"},
+		},
+	}
+
+	value, ok := Lookup(data, "$.a.b")
+	assert.True(t, ok)
+	assert.Equal(t, "c", value)
+
+	value, ok = Lookup(data, "$.items[1].name")
+	assert.True(t, ok)
+	assert.Equal(t, "second", value)
+
+	value, ok = Lookup(data, "$")
+	assert.True(t, ok)
+	assert.Equal(t, data, value)
+
+	_, ok = Lookup(data, "$.items[5].name")
+	assert.False(t, ok)
+	_, ok = Lookup(data, "$.a.b.c")
+	assert.False(t, ok)
+	_, ok = Lookup(data, "$.missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,310 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Runner executes a single environment operation
+type Runner interface {
+	RestartEnvironment(ctx context.Context, id string, force bool) error
+	UpgradeEnvironment(ctx context.Context, id string, version string) error
+	RunCustomAction(ctx context.Context, id string, name string) error
+	CheckHealth(ctx context.Context, id string) error
+}
+
+// ProgressFunc is called whenever a child operation changes state and once
+// more with a nil child when the bulk operation finishes
+type ProgressFunc func(op *entities.BulkOperation, child *entities.ChildOperation)
+
+// Request describes a bulk operation. Exactly one of EnvironmentIDs or
+// Selector must be set.
+type Request struct {
+	EnvironmentIDs   []string                   `json:"environmentIds,omitempty"`
+	Selector         map[string]string          `json:"selector,omitempty"`
+	Operation        entities.BulkOperationType `json:"operation"`
+	Version          string                     `json:"version,omitempty"` // upgrade
+	Action           string                     `json:"action,omitempty"`  // custom_action
+	Force            bool                       `json:"force,omitempty"`   // restart
+	MaxParallel      int                        `json:"maxParallel,omitempty"`
+	FailureThreshold int                        `json:"failureThreshold,omitempty"`
+}
+
+// healthCheckTimeout bounds a single environment's health check; the other
+// operations are bounded by each environment's own timeouts
+const healthCheckTimeout = time.Minute
+
+// Service runs operations across many environments with bounded parallelism
+type Service struct {
+	repo        interfaces.BulkOperationRepository
+	envRepo     interfaces.EnvironmentRepository
+	runner      Runner
+	maxParallel int
+}
+
+// NewService creates a new bulk operation service. maxParallel caps the
+// parallelism any single request may ask for.
+func NewService(
+	repo interfaces.BulkOperationRepository,
+	envRepo interfaces.EnvironmentRepository,
+	runner Runner,
+	maxParallel int,
+) *Service {
+	if maxParallel <= 0 {
+		maxParallel = 1
+	}
+	return &Service{
+		repo:        repo,
+		envRepo:     envRepo,
+		runner:      runner,
+		maxParallel: maxParallel,
+	}
+}
+
+// Create validates the request, resolves the target environments and stores
+// a pending bulk operation. Call Run to execute it.
+func (s *Service) Create(ctx context.Context, req Request) (*entities.BulkOperation, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	envs, err := s.resolveTargets(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(envs) == 0 {
+		return nil, errors.NewValidationError("selector", "no environments matched")
+	}
+
+	userID, username := ctxutil.UserFromContext(ctx)
+	if userID == "" {
+		return nil, errors.ErrUnauthorized
+	}
+
+	parallel := req.MaxParallel
+	if parallel <= 0 || parallel > s.maxParallel {
+		parallel = s.maxParallel
+	}
+
+	op := &entities.BulkOperation{
+		ID:               primitive.NewObjectID(),
+		Operation:        req.Operation,
+		Parameters:       parametersFor(req),
+		Selector:         req.Selector,
+		MaxParallel:      parallel,
+		FailureThreshold: req.FailureThreshold,
+		Status:           entities.BulkStatusPending,
+		CreatedBy:        entities.Actor{Type: "user", ID: userID, Name: username},
+		CreatedAt:        time.Now(),
+	}
+	op.OperationID = fmt.Sprintf("op-%s", op.ID.Hex())
+
+	for _, env := range envs {
+		child := entities.ChildOperation{
+			OperationID:     fmt.Sprintf("op-%s", primitive.NewObjectID().Hex()),
+			EnvironmentID:   env.ID,
+			EnvironmentName: env.Name,
+			Status:          entities.ChildStatusPending,
+		}
+		// Protected environments must go through the approval workflow one by one
+		if env.RequiresApproval && req.Operation.RequiresApproval() {
+			child.Status = entities.ChildStatusSkipped
+			child.Error = "environment requires approval"
+		}
+		op.Children = append(op.Children, child)
+	}
+	op.Summarize()
+
+	if err := s.repo.Create(ctx, op); err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
+// Run executes every pending child operation, at most MaxParallel at a time.
+// Once FailureThreshold children have failed, no further children are started
+// and the remaining ones are cancelled.
+func (s *Service) Run(ctx context.Context, op *entities.BulkOperation, progress ProgressFunc) {
+	var mu sync.Mutex
+	notify := func(child *entities.ChildOperation) {
+		op.Summarize()
+		// Persist even after ctx is cancelled so the final state is recorded
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		_ = s.repo.Update(saveCtx, op)
+		cancel()
+		if progress != nil {
+			progress(op, child)
+		}
+	}
+
+	mu.Lock()
+	now := time.Now()
+	op.Status = entities.BulkStatusRunning
+	op.StartedAt = &now
+	notify(nil)
+	mu.Unlock()
+
+	sem := make(chan struct{}, op.MaxParallel)
+	var wg sync.WaitGroup
+	stopped := false
+
+	for i := range op.Children {
+		if op.Children[i].Status != entities.ChildStatusPending {
+			continue
+		}
+
+		sem <- struct{}{}
+
+		mu.Lock()
+		if op.FailureThreshold > 0 && op.Summary.Failed >= op.FailureThreshold {
+			stopped = true
+		}
+		if stopped || ctx.Err() != nil {
+			op.Children[i].Status = entities.ChildStatusCancelled
+			notify(&op.Children[i])
+			mu.Unlock()
+			<-sem
+			continue
+		}
+		started := time.Now()
+		op.Children[i].Status = entities.ChildStatusRunning
+		op.Children[i].StartedAt = &started
+		notify(&op.Children[i])
+		mu.Unlock()
+
+		wg.Add(1)
+		go func(child *entities.ChildOperation) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := s.execute(ctx, op, child.EnvironmentID.Hex())
+
+			mu.Lock()
+			defer mu.Unlock()
+			completed := time.Now()
+			child.CompletedAt = &completed
+			if err != nil {
+				child.Status = entities.ChildStatusFailed
+				child.Error = err.Error()
+			} else {
+				child.Status = entities.ChildStatusSucceeded
+			}
+			notify(child)
+		}(&op.Children[i])
+	}
+
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	completed := time.Now()
+	op.Status = op.FinalStatus(stopped)
+	op.CompletedAt = &completed
+	notify(nil)
+}
+
+// Get retrieves a bulk operation by ID
+func (s *Service) Get(ctx context.Context, id string) (*entities.BulkOperation, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List lists bulk operations
+func (s *Service) List(ctx context.Context, filter interfaces.BulkOperationFilter) ([]*entities.BulkOperation, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// execute runs the bulk operation against one environment
+func (s *Service) execute(ctx context.Context, op *entities.BulkOperation, envID string) error {
+	switch op.Operation {
+	case entities.BulkOperationRestart:
+		force, _ := op.Parameters["force"].(bool)
+		return s.runner.RestartEnvironment(ctx, envID, force)
+	case entities.BulkOperationUpgrade:
+		version, _ := op.Parameters["version"].(string)
+		return s.runner.UpgradeEnvironment(ctx, envID, version)
+	case entities.BulkOperationCustomAction:
+		action, _ := op.Parameters["action"].(string)
+		return s.runner.RunCustomAction(ctx, envID, action)
+	case entities.BulkOperationHealthCheck:
+		ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		defer cancel()
+		return s.runner.CheckHealth(ctx, envID)
+	default:
+		return fmt.Errorf("unsupported bulk operation: %s", op.Operation)
+	}
+}
+
+// validate checks the request shape before any environment is resolved
+func (s *Service) validate(req Request) error {
+	if (len(req.EnvironmentIDs) == 0) == (len(req.Selector) == 0) {
+		return errors.NewValidationError("environmentIds", "provide either environmentIds or selector")
+	}
+
+	switch req.Operation {
+	case entities.BulkOperationRestart, entities.BulkOperationHealthCheck:
+	case entities.BulkOperationUpgrade:
+		if req.Version == "" {
+			return errors.NewValidationError("version", "version is required for upgrade")
+		}
+	case entities.BulkOperationCustomAction:
+		if req.Action == "" {
+			return errors.NewValidationError("action", "action is required for custom_action")
+		}
+	default:
+		return errors.NewValidationError("operation", "operation must be one of restart, upgrade, custom_action, health_check")
+	}
+
+	if req.MaxParallel < 0 {
+		return errors.NewValidationError("maxParallel", "maxParallel must not be negative")
+	}
+	if req.FailureThreshold < 0 {
+		return errors.NewValidationError("failureThreshold", "failureThreshold must not be negative")
+	}
+
+	return nil
+}
+
+// resolveTargets loads the environments addressed by IDs or label selector
+func (s *Service) resolveTargets(ctx context.Context, req Request) ([]*entities.Environment, error) {
+	if len(req.Selector) > 0 {
+		return s.envRepo.List(ctx, interfaces.ListFilter{Labels: req.Selector})
+	}
+
+	seen := make(map[string]bool, len(req.EnvironmentIDs))
+	envs := make([]*entities.Environment, 0, len(req.EnvironmentIDs))
+	for _, id := range req.EnvironmentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		env, err := s.envRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, env)
+	}
+	return envs, nil
+}
+
+// parametersFor records the operation parameters on the bulk operation
+func parametersFor(req Request) map[string]interface{} {
+	switch req.Operation {
+	case entities.BulkOperationRestart:
+		return map[string]interface{}{"force": req.Force}
+	case entities.BulkOperationUpgrade:
+		return map[string]interface{}{"version": req.Version}
+	case entities.BulkOperationCustomAction:
+		return map[string]interface{}{"action": req.Action}
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package bulk_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/bulk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockBulkRepo struct{ mock.Mock }
+
+func (m *mockBulkRepo) Create(ctx context.Context, op *entities.BulkOperation) error {
+	return m.Called(ctx, op).Error(0)
+}
+
+func (m *mockBulkRepo) GetByID(ctx context.Context, id string) (*entities.BulkOperation, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.BulkOperation), args.Error(1)
+}
+
+func (m *mockBulkRepo) List(ctx context.Context, filter interfaces.BulkOperationFilter) ([]*entities.BulkOperation, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.BulkOperation), args.Error(1)
+}
+
+func (m *mockBulkRepo) Update(ctx context.Context, op *entities.BulkOperation) error {
+	return m.Called(ctx, op).Error(0)
+}
+
+type mockEnvRepo struct{ mock.Mock }
+
+func (m *mockEnvRepo) Create(ctx context.Context, env *entities.Environment) error {
+	return m.Called(ctx, env).Error(0)
+}
+
+func (m *mockEnvRepo) GetByID(ctx context.Context, id string) (*entities.Environment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) GetByName(ctx context.Context, name string) (*entities.Environment, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) List(ctx context.Context, filter interfaces.ListFilter) ([]*entities.Environment, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) Update(ctx context.Context, id string, env *entities.Environment) error {
+	return m.Called(ctx, id, env).Error(0)
+}
+
+func (m *mockEnvRepo) UpdateStatus(ctx context.Context, id string, status entities.Status) error {
+	return m.Called(ctx, id, status).Error(0)
+}
+
+func (m *mockEnvRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockEnvRepo) Count(ctx context.Context, filter interfaces.ListFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeRunner records calls and tracks the peak number of concurrent operations
+type fakeRunner struct {
+	mu       sync.Mutex
+	calls    []string
+	failing  map[string]bool
+	delay    time.Duration
+	inFlight int32
+	peak     int32
+}
+
+func (f *fakeRunner) run(op, id string) error {
+	current := atomic.AddInt32(&f.inFlight, 1)
+	defer atomic.AddInt32(&f.inFlight, -1)
+	for {
+		peak := atomic.LoadInt32(&f.peak)
+		if current <= peak || atomic.CompareAndSwapInt32(&f.peak, peak, current) {
+			break
+		}
+	}
+	time.Sleep(f.delay)
+
+	f.mu.Lock()
+	f.calls = append(f.calls, op+":"+id)
+	f.mu.Unlock()
+
+	if f.failing[id] {
+		return fmt.Errorf("%s failed", op)
+	}
+	return nil
+}
+
+func (f *fakeRunner) RestartEnvironment(ctx context.Context, id string, force bool) error {
+	return f.run("restart", id)
+}
+
+func (f *fakeRunner) UpgradeEnvironment(ctx context.Context, id string, version string) error {
+	return f.run("upgrade-"+version, id)
+}
+
+func (f *fakeRunner) RunCustomAction(ctx context.Context, id string, name string) error {
+	return f.run("action-"+name, id)
+}
+
+func (f *fakeRunner) CheckHealth(ctx context.Context, id string) error {
+	return f.run("health", id)
+}
+
+func userCtx() context.Context {
+	return ctxutil.WithUser(context.Background(), "u1", "alice")
+}
+
+func makeEnvs(n int) []*entities.Environment {
+	envs := make([]*entities.Environment, n)
+	for i := range envs {
+		envs[i] = &entities.Environment{ID: primitive.NewObjectID(), Name: fmt.Sprintf("env%d", i)}
+	}
+	return envs
+}
+
+func newService(envs []*entities.Environment, runner *fakeRunner, maxParallel int) (*bulk.Service, *mockEnvRepo) {
+	repo := new(mockBulkRepo)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	envRepo := new(mockEnvRepo)
+	for _, env := range envs {
+		envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	}
+	return bulk.NewService(repo, envRepo, runner, maxParallel), envRepo
+}
+
+func idsOf(envs []*entities.Environment) []string {
+	ids := make([]string, len(envs))
+	for i, env := range envs {
+		ids[i] = env.ID.Hex()
+	}
+	return ids
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	svc, _ := newService(nil, &fakeRunner{}, 5)
+
+	tests := []struct {
+		name string
+		req  bulk.Request
+	}{
+		{"no targets", bulk.Request{Operation: entities.BulkOperationRestart}},
+		{"both targets", bulk.Request{EnvironmentIDs: []string{"a"}, Selector: map[string]string{"a": "b"}, Operation: entities.BulkOperationRestart}},
+		{"unknown operation", bulk.Request{EnvironmentIDs: []string{"a"}, Operation: "reboot"}},
+		{"upgrade without version", bulk.Request{EnvironmentIDs: []string{"a"}, Operation: entities.BulkOperationUpgrade}},
+		{"action without name", bulk.Request{EnvironmentIDs: []string{"a"}, Operation: entities.BulkOperationCustomAction}},
+		{"negative threshold", bulk.Request{EnvironmentIDs: []string{"a"}, Operation: entities.BulkOperationRestart, FailureThreshold: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Create(userCtx(), tt.req)
+			require.Error(t, err)
+			_, ok := err.(errors.DomainError)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestService_Create_BySelector(t *testing.T) {
+	envs := makeEnvs(2)
+	envs[1].RequiresApproval = true
+	svc, envRepo := newService(nil, &fakeRunner{}, 5)
+	selector := map[string]string{"tier": "test"}
+	envRepo.On("List", mock.Anything, interfaces.ListFilter{Labels: selector}).Return(envs, nil)
+
+	op, err := svc.Create(userCtx(), bulk.Request{Selector: selector, Operation: entities.BulkOperationRestart, MaxParallel: 50})
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.BulkStatusPending, op.Status)
+	assert.Equal(t, 5, op.MaxParallel, "parallelism is capped")
+	require.Len(t, op.Children, 2)
+	assert.Equal(t, entities.ChildStatusPending, op.Children[0].Status)
+	assert.Equal(t, entities.ChildStatusSkipped, op.Children[1].Status, "protected environments are skipped")
+	assert.NotEqual(t, op.Children[0].OperationID, op.Children[1].OperationID)
+	assert.Equal(t, "u1", op.CreatedBy.ID)
+}
+
+func TestService_Create_SkipsProtectedEnvironments(t *testing.T) {
+	tests := []struct {
+		req     bulk.Request
+		skipped bool
+	}{
+		{bulk.Request{Operation: entities.BulkOperationRestart}, true},
+		{bulk.Request{Operation: entities.BulkOperationUpgrade, Version: "2.0.0"}, true},
+		{bulk.Request{Operation: entities.BulkOperationCustomAction, Action: "flush-cache"}, true},
+		{bulk.Request{Operation: entities.BulkOperationHealthCheck}, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.req.Operation), func(t *testing.T) {
+			envs := makeEnvs(1)
+			envs[0].RequiresApproval = true
+			svc, envRepo := newService(nil, &fakeRunner{}, 5)
+			selector := map[string]string{"tier": "test"}
+			envRepo.On("List", mock.Anything, interfaces.ListFilter{Labels: selector}).Return(envs, nil)
+			tt.req.Selector = selector
+
+			op, err := svc.Create(userCtx(), tt.req)
+
+			require.NoError(t, err)
+			require.Len(t, op.Children, 1)
+			assert.Equal(t, tt.skipped, op.Children[0].Status == entities.ChildStatusSkipped)
+		})
+	}
+}
+
+func TestService_Create_NoMatches(t *testing.T) {
+	svc, envRepo := newService(nil, &fakeRunner{}, 5)
+	envRepo.On("List", mock.Anything, mock.Anything).Return([]*entities.Environment{}, nil)
+
+	_, err := svc.Create(userCtx(), bulk.Request{Selector: map[string]string{"tier": "none"}, Operation: entities.BulkOperationHealthCheck})
+	assert.Error(t, err)
+}
+
+func TestService_Run_RespectsMaxParallel(t *testing.T) {
+	envs := makeEnvs(6)
+	runner := &fakeRunner{delay: 20 * time.Millisecond}
+	svc, _ := newService(envs, runner, 10)
+
+	op, err := svc.Create(userCtx(), bulk.Request{EnvironmentIDs: idsOf(envs), Operation: entities.BulkOperationHealthCheck, MaxParallel: 2})
+	require.NoError(t, err)
+
+	var updates int32
+	svc.Run(userCtx(), op, func(*entities.BulkOperation, *entities.ChildOperation) { atomic.AddInt32(&updates, 1) })
+
+	assert.Equal(t, entities.BulkStatusCompleted, op.Status)
+	assert.Equal(t, 6, op.Summary.Succeeded)
+	assert.LessOrEqual(t, atomic.LoadInt32(&runner.peak), int32(2))
+	assert.Len(t, runner.calls, 6)
+	assert.NotNil(t, op.CompletedAt)
+	// start + running/finished per child + finish
+	assert.Equal(t, int32(2+2*6), atomic.LoadInt32(&updates))
+}
+
+func TestService_Run_PartialFailure(t *testing.T) {
+	envs := makeEnvs(3)
+	runner := &fakeRunner{failing: map[string]bool{envs[1].ID.Hex(): true}}
+	svc, _ := newService(envs, runner, 10)
+
+	op, err := svc.Create(userCtx(), bulk.Request{EnvironmentIDs: idsOf(envs), Operation: entities.BulkOperationUpgrade, Version: "2.0.0"})
+	require.NoError(t, err)
+
+	svc.Run(userCtx(), op, nil)
+
+	assert.Equal(t, entities.BulkStatusPartiallyFailed, op.Status)
+	assert.Equal(t, 2, op.Summary.Succeeded)
+	assert.Equal(t, 1, op.Summary.Failed)
+	assert.Equal(t, entities.ChildStatusFailed, op.Children[1].Status)
+	assert.Contains(t, op.Children[1].Error, "upgrade-2.0.0 failed")
+}
+
+func TestService_Run_StopsAtFailureThreshold(t *testing.T) {
+	envs := makeEnvs(5)
+	failing := map[string]bool{}
+	for _, env := range envs {
+		failing[env.ID.Hex()] = true
+	}
+	runner := &fakeRunner{failing: failing}
+	svc, _ := newService(envs, runner, 10)
+
+	op, err := svc.Create(userCtx(), bulk.Request{
+		EnvironmentIDs:   idsOf(envs),
+		Operation:        entities.BulkOperationCustomAction,
+		Action:           "clear-cache",
+		MaxParallel:      1,
+		FailureThreshold: 2,
+	})
+	require.NoError(t, err)
+
+	svc.Run(userCtx(), op, nil)
+
+	assert.Equal(t, entities.BulkStatusStopped, op.Status)
+	assert.Equal(t, 2, op.Summary.Failed)
+	assert.Equal(t, 3, op.Summary.Cancelled)
+	assert.Len(t, runner.calls, 2)
+}
@@ -0,0 +1,356 @@
+// Package docker is a small Docker Engine API client covering what
+// environment operations need: restarting containers, pulling images,
+// recreating containers with a new image and inspecting their state.
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultAPIVersion is requested when no version is configured. It is
+// supported by every Engine still in maintenance.
+const DefaultAPIVersion = "1.41"
+
+// maxErrorBody bounds error responses read from the Engine
+const maxErrorBody = 64 * 1024
+
+var (
+	containerNamePattern = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	apiVersionPattern    = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+	tagPattern           = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+)
+
+// Client talks to one Docker Engine
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	apiVersion string
+}
+
+// NewClient creates a client for the Engine at baseURL, for example
+// https://host:2376. An empty API version means DefaultAPIVersion.
+func NewClient(httpClient *http.Client, baseURL, apiVersion string) (*Client, error) {
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
+	}
+	if !apiVersionPattern.MatchString(apiVersion) {
+		return nil, fmt.Errorf("invalid Docker API version %q", apiVersion)
+	}
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiVersion: apiVersion,
+	}, nil
+}
+
+// NewDialClient creates a client that reaches the Engine through dial, for
+// example a Unix socket forwarded over SSH. Connections are not reused.
+func NewDialClient(dial func(ctx context.Context) (net.Conn, error), apiVersion string) (*Client, error) {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dial(ctx)
+		},
+		DisableKeepAlives: true,
+	}
+	return NewClient(&http.Client{Transport: transport}, "http://docker", apiVersion)
+}
+
+// NewTLSClient creates a client for the Engine's TLS port at address
+// (host:port)
+func NewTLSClient(address string, tlsConfig *tls.Config, apiVersion string) (*Client, error) {
+	transport := &http.Transport{
+		TLSClientConfig:   tlsConfig,
+		DisableKeepAlives: true,
+	}
+	return NewClient(&http.Client{Transport: transport}, "https://"+address, apiVersion)
+}
+
+// TLSConfig builds a TLS configuration from PEM encoded certificates. The CA
+// is required unless verification is skipped; the client certificate and
+// key are optional but go together.
+func TLSConfig(caCert, clientCert, clientKey []byte, insecureSkipVerify bool) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+	if len(caCert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in the Docker CA certificate")
+		}
+		config.RootCAs = pool
+	} else if !insecureSkipVerify {
+		return nil, fmt.Errorf("a Docker CA certificate is required")
+	}
+	if len(clientCert) > 0 || len(clientKey) > 0 {
+		pair, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Docker client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+	return config, nil
+}
+
+// ValidateContainerName checks a container name against Docker's rules
+func ValidateContainerName(name string) error {
+	if !containerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid container name %q", name)
+	}
+	return nil
+}
+
+// ValidateTag checks an image tag against Docker's rules
+func ValidateTag(tag string) error {
+	if !tagPattern.MatchString(tag) {
+		return fmt.Errorf("invalid image tag %q", tag)
+	}
+	return nil
+}
+
+// Error is an error response from the Engine
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("docker API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the Engine
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// ContainerHealth is the result of a container's own health check
+type ContainerHealth struct {
+	Status        string `json:"Status"` // "starting", "healthy" or "unhealthy"
+	FailingStreak int    `json:"FailingStreak"`
+}
+
+// ContainerState is the runtime state of a container
+type ContainerState struct {
+	Status     string           `json:"Status"` // "created", "running", "restarting", "exited", ...
+	Running    bool             `json:"Running"`
+	Restarting bool             `json:"Restarting"`
+	ExitCode   int              `json:"ExitCode"`
+	Error      string           `json:"Error"`
+	Health     *ContainerHealth `json:"Health,omitempty"`
+}
+
+// Container is an inspected container. Config and HostConfig are kept as
+// returned so a container can be recreated without losing settings this
+// client does not model.
+type Container struct {
+	ID              string                 `json:"Id"`
+	Name            string                 `json:"Name"`
+	State           ContainerState         `json:"State"`
+	Config          map[string]interface{} `json:"Config"`
+	HostConfig      map[string]interface{} `json:"HostConfig"`
+	NetworkSettings struct {
+		Networks map[string]map[string]interface{} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// Image returns the image reference the container was created from
+func (c *Container) Image() string {
+	image, _ := c.Config["Image"].(string)
+	return image
+}
+
+// Ping checks that the Engine answers
+func (c *Client) Ping(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/_ping", nil, nil, nil)
+}
+
+// Inspect returns a container by name or ID
+func (c *Client) Inspect(ctx context.Context, name string) (*Container, error) {
+	var container Container
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(name)+"/json", nil, nil, &container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// Restart restarts a container, killing it after timeout seconds
+func (c *Client) Restart(ctx context.Context, name string, timeout int) error {
+	query := url.Values{"t": {strconv.Itoa(timeout)}}
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(name)+"/restart", query, nil, nil)
+}
+
+// Start starts a container. Starting a running container is not an error.
+func (c *Client) Start(ctx context.Context, name string) error {
+	err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(name)+"/start", nil, nil, nil)
+	if isNotModified(err) {
+		return nil
+	}
+	return err
+}
+
+// Stop stops a container, killing it after timeout seconds. Stopping a
+// stopped container is not an error.
+func (c *Client) Stop(ctx context.Context, name string, timeout int) error {
+	query := url.Values{"t": {strconv.Itoa(timeout)}}
+	err := c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(name)+"/stop", query, nil, nil)
+	if isNotModified(err) {
+		return nil
+	}
+	return err
+}
+
+// Rename renames a container
+func (c *Client) Rename(ctx context.Context, name, newName string) error {
+	query := url.Values{"name": {newName}}
+	return c.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(name)+"/rename", query, nil, nil)
+}
+
+// Remove removes a stopped container
+func (c *Client) Remove(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/containers/"+url.PathEscape(name), nil, nil, nil)
+}
+
+// Create creates a container from a create request body and returns its ID
+func (c *Client) Create(ctx context.Context, name string, body map[string]interface{}) (string, error) {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	query := url.Values{"name": {name}}
+	if err := c.do(ctx, http.MethodPost, "/containers/create", query, body, &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// ConnectNetwork attaches a container to a network
+func (c *Client) ConnectNetwork(ctx context.Context, network, container string, endpoint map[string]interface{}) error {
+	body := map[string]interface{}{"Container": container, "EndpointConfig": endpoint}
+	return c.do(ctx, http.MethodPost, "/networks/"+url.PathEscape(network)+"/connect", nil, body, nil)
+}
+
+// Pull pulls an image. The Engine reports pull failures inside a successful
+// progress stream, so the stream is read to the end.
+func (c *Client) Pull(ctx context.Context, image string) error {
+	repository, tag := SplitImage(image)
+	query := url.Values{"fromImage": {repository}}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+
+	resp, err := c.send(ctx, http.MethodPost, "/images/create", query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 4096), 1024*1024)
+	for scanner.Scan() {
+		var progress struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &progress) == nil && progress.Error != "" {
+			return fmt.Errorf("pull %s: %s", image, progress.Error)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("pull %s: %w", image, err)
+	}
+	return nil
+}
+
+// SplitImage splits an image reference into repository and tag. A digest
+// reference is returned whole with no tag.
+func SplitImage(image string) (string, string) {
+	if strings.Contains(image, "@") {
+		return image, ""
+	}
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[:colon], image[colon+1:]
+	}
+	return image, ""
+}
+
+// isNotModified reports a 304, which start and stop return when the
+// container is already in the requested state
+func isNotModified(err error) bool {
+	apiErr, ok := err.(*Error)
+	return ok && apiErr.StatusCode == http.StatusNotModified
+}
+
+// do sends a request and decodes a JSON response into out, if given
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// send sends a request and turns error statuses into *Error. The caller
+// closes the body of a successful response.
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s request: %w", path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	target := c.baseURL + "/v" + c.apiVersion + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("docker API request failed: %w", err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(data))
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+		message = apiErr.Message
+	}
+	return nil, &Error{StatusCode: resp.StatusCode, Message: message}
+}
@@ -0,0 +1,156 @@
+package docker_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+
+	"app-env-manager/internal/service/docker"
+	"app-env-manager/internal/service/docker/dockertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTLSClient connects to the fake Engine's TLS port
+func newTLSClient(t *testing.T, engine *dockertest.Server) *docker.Client {
+	t.Helper()
+	tlsConfig, err := docker.TLSConfig(engine.CACert(), nil, nil, false)
+	require.NoError(t, err)
+	client, err := docker.NewTLSClient(engine.Address(), tlsConfig, "")
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_PingAndInspect(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "registry.example.com/app:1.0.0")
+	engine.SetState("app", true, "healthy")
+	client := newTLSClient(t, engine)
+	ctx := context.Background()
+
+	require.NoError(t, client.Ping(ctx))
+
+	container, err := client.Inspect(ctx, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "/app", container.Name)
+	assert.Equal(t, "registry.example.com/app:1.0.0", container.Image())
+	assert.True(t, container.State.Running)
+	assert.Equal(t, "running", container.State.Status)
+	require.NotNil(t, container.State.Health)
+	assert.Equal(t, "healthy", container.State.Health.Status)
+}
+
+func TestClient_Inspect_NotFound(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	client := newTLSClient(t, engine)
+
+	_, err := client.Inspect(context.Background(), "missing")
+	require.Error(t, err)
+	assert.True(t, docker.IsNotFound(err))
+	assert.Contains(t, err.Error(), "No such container: missing")
+}
+
+func TestClient_Restart(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	engine.SetState("app", false, "")
+	client := newTLSClient(t, engine)
+
+	require.NoError(t, client.Restart(context.Background(), "app", 5))
+
+	container, _ := engine.Container("app")
+	assert.True(t, container.Running)
+	assert.Equal(t, 1, container.Restarts)
+	assert.Contains(t, engine.Requests(), "POST /containers/app/restart")
+}
+
+func TestClient_StartStop_AlreadyInState(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	client := newTLSClient(t, engine)
+	ctx := context.Background()
+
+	assert.NoError(t, client.Start(ctx, "app"), "304 for a running container")
+	require.NoError(t, client.Stop(ctx, "app", 1))
+	assert.NoError(t, client.Stop(ctx, "app", 1), "304 for a stopped container")
+}
+
+func TestClient_Pull(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.FailPull("app:bad", "manifest for app:bad not found")
+	client := newTLSClient(t, engine)
+	ctx := context.Background()
+
+	assert.NoError(t, client.Pull(ctx, "app:2.0.0"))
+
+	err := client.Pull(ctx, "app:bad")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest for app:bad not found")
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	socket := engine.ListenUnix(t)
+
+	client, err := docker.NewDialClient(func(ctx context.Context) (net.Conn, error) {
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, "unix", socket)
+	}, "1.43")
+	require.NoError(t, err)
+
+	container, err := client.Inspect(context.Background(), "app")
+	require.NoError(t, err)
+	assert.True(t, container.State.Running)
+}
+
+func TestNewClient_InvalidAPIVersion(t *testing.T) {
+	_, err := docker.NewClient(http.DefaultClient, "http://docker", "1.41/../../x")
+	assert.Error(t, err)
+}
+
+func TestTLSConfig(t *testing.T) {
+	engine := dockertest.NewServer(t)
+
+	_, err := docker.TLSConfig(nil, nil, nil, false)
+	assert.ErrorContains(t, err, "CA certificate is required")
+
+	_, err = docker.TLSConfig([]byte("not a certificate"), nil, nil, false)
+	assert.ErrorContains(t, err, "no certificates found")
+
+	_, err = docker.TLSConfig(engine.CACert(), []byte("cert"), nil, false)
+	assert.ErrorContains(t, err, "invalid Docker client certificate")
+
+	config, err := docker.TLSConfig(nil, nil, nil, true)
+	require.NoError(t, err)
+	assert.True(t, config.InsecureSkipVerify)
+}
+
+func TestSplitImage(t *testing.T) {
+	tests := []struct {
+		image, repository, tag string
+	}{
+		{"app", "app", ""},
+		{"app:1.0", "app", "1.0"},
+		{"registry.example.com:5000/team/app:2.1.0", "registry.example.com:5000/team/app", "2.1.0"},
+		{"registry.example.com:5000/team/app", "registry.example.com:5000/team/app", ""},
+		{"app@sha256:abcd", "app@sha256:abcd", ""},
+	}
+	for _, tt := range tests {
+		repository, tag := docker.SplitImage(tt.image)
+		assert.Equal(t, tt.repository, repository, tt.image)
+		assert.Equal(t, tt.tag, tag, tt.image)
+	}
+}
+
+func TestValidateNames(t *testing.T) {
+	assert.NoError(t, docker.ValidateContainerName("stack-app-1"))
+	assert.NoError(t, docker.ValidateContainerName("/app"))
+	assert.Error(t, docker.ValidateContainerName("../app"))
+	assert.Error(t, docker.ValidateContainerName(""))
+
+	assert.NoError(t, docker.ValidateTag("1.2.3-rc.1"))
+	assert.Error(t, docker.ValidateTag("1.0 && rm"))
+	assert.Error(t, docker.ValidateTag(".hidden"))
+}
@@ -0,0 +1,438 @@
+// Package dockertest provides a fake Docker Engine API for tests. It keeps
+// containers in memory and implements the endpoints the docker package uses,
+// over TLS and optionally on a Unix socket.
+package dockertest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Container is a fake container's state
+type Container struct {
+	ID       string
+	Name     string
+	Image    string
+	Running  bool
+	Health   string // "", "starting", "healthy" or "unhealthy"
+	Labels   map[string]string
+	Networks []string
+	Restarts int
+}
+
+// Server is a running fake Engine
+type Server struct {
+	tls *httptest.Server
+
+	mu         sync.Mutex
+	containers map[string]*Container // by ID
+	images     map[string]bool       // pulled references
+	failPull   map[string]string     // image reference -> error
+	failStart  map[string]bool       // image reference
+	requests   []string
+}
+
+// NewServer starts a fake Engine on a TLS port; it is stopped when the test
+// ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		containers: make(map[string]*Container),
+		images:     make(map[string]bool),
+		failPull:   make(map[string]string),
+		failStart:  make(map[string]bool),
+	}
+	s.tls = httptest.NewTLSServer(s.handler())
+	t.Cleanup(s.tls.Close)
+	return s
+}
+
+// ListenUnix also serves the Engine on a Unix socket and returns its path
+func (s *Server) ListenUnix(t testing.TB) string {
+	t.Helper()
+	// Socket paths are limited to about 100 bytes, too short for TempDir
+	dir, err := os.MkdirTemp("", "docker")
+	if err != nil {
+		t.Fatalf("create socket dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "docker.sock")
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("listen on %s: %v", path, err)
+	}
+	server := &http.Server{Handler: s.handler()}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return path
+}
+
+// Port returns the TLS port
+func (s *Server) Port() int {
+	return s.tls.Listener.Addr().(*net.TCPAddr).Port
+}
+
+// Address returns the TLS port's host:port
+func (s *Server) Address() string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(s.Port()))
+}
+
+// CACert returns the PEM encoded certificate the TLS port presents
+func (s *Server) CACert() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.tls.Certificate().Raw})
+}
+
+// AddContainer adds a running container on the "app_default" network
+func (s *Server) AddContainer(name, image string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := newID()
+	s.containers[id] = &Container{
+		ID:       id,
+		Name:     name,
+		Image:    image,
+		Running:  true,
+		Labels:   map[string]string{"com.docker.compose.service": name},
+		Networks: []string{"app_default"},
+	}
+	s.images[image] = true
+}
+
+// Container returns a container by name
+func (s *Server) Container(name string) (Container, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.find(name); c != nil {
+		snapshot := *c
+		snapshot.Networks = append([]string(nil), c.Networks...)
+		return snapshot, true
+	}
+	return Container{}, false
+}
+
+// Containers returns the names of all containers
+func (s *Server) Containers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for _, c := range s.containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// SetState sets a container's running state and health check status
+func (s *Server) SetState(name string, running bool, health string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.find(name); c != nil {
+		c.Running = running
+		c.Health = health
+	}
+}
+
+// FailPull makes pulling image fail with message
+func (s *Server) FailPull(image, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failPull[image] = message
+}
+
+// FailStart makes starting containers created from image fail
+func (s *Server) FailStart(image string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failStart[image] = true
+}
+
+// Requests returns the requests received so far as "METHOD /path", without
+// the API version
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{version}/_ping", s.ping)
+	mux.HandleFunc("GET /{version}/containers/{name}/json", s.inspect)
+	mux.HandleFunc("POST /{version}/containers/{name}/restart", s.restart)
+	mux.HandleFunc("POST /{version}/containers/{name}/start", s.start)
+	mux.HandleFunc("POST /{version}/containers/{name}/stop", s.stop)
+	mux.HandleFunc("POST /{version}/containers/{name}/rename", s.rename)
+	mux.HandleFunc("DELETE /{version}/containers/{name}", s.remove)
+	mux.HandleFunc("POST /{version}/containers/create", s.create)
+	mux.HandleFunc("POST /{version}/networks/{network}/connect", s.connect)
+	mux.HandleFunc("POST /{version}/images/create", s.pull)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		path := r.URL.Path
+		if i := strings.Index(path[1:], "/"); strings.HasPrefix(path, "/v") && i >= 0 {
+			path = path[i+1:]
+		}
+		s.requests = append(s.requests, r.Method+" "+path)
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) ping(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("OK"))
+}
+
+func (s *Server) inspect(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(r.PathValue("name"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: "+r.PathValue("name"))
+		return
+	}
+
+	status := "exited"
+	if c.Running {
+		status = "running"
+	}
+	state := map[string]interface{}{"Status": status, "Running": c.Running, "ExitCode": 0}
+	if c.Health != "" {
+		state["Health"] = map[string]interface{}{"Status": c.Health}
+	}
+	networks := map[string]interface{}{}
+	for _, network := range c.Networks {
+		networks[network] = map[string]interface{}{
+			"Aliases":   []string{c.Name, c.ID[:12]},
+			"NetworkID": "net-" + network,
+			"IPAddress": "172.18.0.2",
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"Id":    c.ID,
+		"Name":  "/" + c.Name,
+		"State": state,
+		"Config": map[string]interface{}{
+			"Image":    c.Image,
+			"Hostname": c.ID[:12],
+			"Labels":   c.Labels,
+			"Env":      []string{"APP_ENV=test"},
+		},
+		"HostConfig": map[string]interface{}{
+			"NetworkMode": c.Networks[0],
+			"Binds":       []string{"/srv/app:/data"},
+		},
+		"NetworkSettings": map[string]interface{}{"Networks": networks},
+	})
+}
+
+func (s *Server) restart(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(r.PathValue("name"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: "+r.PathValue("name"))
+		return
+	}
+	if s.failStart[c.Image] {
+		writeError(w, http.StatusInternalServerError, "container exited on start")
+		return
+	}
+	c.Running = true
+	c.Restarts++
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) start(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(r.PathValue("name"))
+	switch {
+	case c == nil:
+		writeError(w, http.StatusNotFound, "No such container: "+r.PathValue("name"))
+	case c.Running:
+		w.WriteHeader(http.StatusNotModified)
+	case s.failStart[c.Image]:
+		writeError(w, http.StatusInternalServerError, "container exited on start")
+	default:
+		c.Running = true
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) stop(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(r.PathValue("name"))
+	switch {
+	case c == nil:
+		writeError(w, http.StatusNotFound, "No such container: "+r.PathValue("name"))
+	case !c.Running:
+		w.WriteHeader(http.StatusNotModified)
+	default:
+		c.Running = false
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) rename(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(r.PathValue("name"))
+	newName := r.URL.Query().Get("name")
+	switch {
+	case c == nil:
+		writeError(w, http.StatusNotFound, "No such container: "+r.PathValue("name"))
+	case s.find(newName) != nil:
+		writeError(w, http.StatusConflict, "name "+newName+" is already in use")
+	default:
+		c.Name = newName
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) remove(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(r.PathValue("name"))
+	switch {
+	case c == nil:
+		writeError(w, http.StatusNotFound, "No such container: "+r.PathValue("name"))
+	case c.Running:
+		writeError(w, http.StatusConflict, "cannot remove a running container")
+	default:
+		delete(s.containers, c.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Image            string            `json:"Image"`
+		Hostname         string            `json:"Hostname"`
+		Labels           map[string]string `json:"Labels"`
+		NetworkingConfig struct {
+			EndpointsConfig map[string]json.RawMessage `json:"EndpointsConfig"`
+		} `json:"NetworkingConfig"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := r.URL.Query().Get("name")
+	switch {
+	case s.find(name) != nil:
+		writeError(w, http.StatusConflict, "name "+name+" is already in use")
+		return
+	case !s.images[body.Image]:
+		writeError(w, http.StatusNotFound, "No such image: "+body.Image)
+		return
+	case len(body.NetworkingConfig.EndpointsConfig) > 1:
+		writeError(w, http.StatusBadRequest, "Container cannot be connected to network endpoints")
+		return
+	}
+
+	c := &Container{ID: newID(), Name: name, Image: body.Image, Labels: body.Labels}
+	for network := range body.NetworkingConfig.EndpointsConfig {
+		c.Networks = append(c.Networks, network)
+	}
+	if len(c.Networks) == 0 {
+		c.Networks = []string{"bridge"}
+	}
+	s.containers[c.ID] = c
+	writeJSON(w, http.StatusCreated, map[string]interface{}{"Id": c.ID, "Warnings": []string{}})
+}
+
+func (s *Server) connect(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Container string `json:"Container"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.find(body.Container)
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: "+body.Container)
+		return
+	}
+	c.Networks = append(c.Networks, r.PathValue("network"))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) pull(w http.ResponseWriter, r *http.Request) {
+	image := r.URL.Query().Get("fromImage")
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		image += ":" + tag
+	}
+
+	s.mu.Lock()
+	message, fail := s.failPull[image]
+	if !fail {
+		s.images[image] = true
+	}
+	s.mu.Unlock()
+
+	// Like the Engine, report progress and failures in a 200 stream
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.Encode(map[string]string{"status": "Pulling from " + image})
+	if fail {
+		encoder.Encode(map[string]interface{}{"errorDetail": map[string]string{"message": message}, "error": message})
+		return
+	}
+	encoder.Encode(map[string]string{"status": "Status: Downloaded newer image for " + image})
+}
+
+// find returns a container by name or by an ID prefix of at least 12
+// characters; the caller holds mu
+func (s *Server) find(name string) *Container {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		return nil
+	}
+	for _, c := range s.containers {
+		if c.Name == name {
+			return c
+		}
+	}
+	if len(name) < 12 {
+		return nil
+	}
+	for _, c := range s.containers {
+		if strings.HasPrefix(c.ID, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
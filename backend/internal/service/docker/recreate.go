@@ -0,0 +1,163 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupSuffix names the previous container while its replacement starts
+const backupSuffix = "-pre-upgrade"
+
+// rollbackTimeout bounds restoring the previous container, which runs even
+// when the operation's context is done
+const rollbackTimeout = time.Minute
+
+// Recreate replaces a container with one created from image, keeping its
+// name, configuration, labels, mounts and networks. The image is pulled
+// before the container is touched. The previous container is renamed and
+// kept until the new one has started; if anything fails it is put back and
+// started again.
+func (c *Client) Recreate(ctx context.Context, name, image string, stopTimeout int) error {
+	name = strings.TrimPrefix(name, "/")
+	old, err := c.Inspect(ctx, name)
+	if err != nil {
+		return fmt.Errorf("inspect %s: %w", name, err)
+	}
+	if err := c.Pull(ctx, image); err != nil {
+		return err
+	}
+
+	backup := name + backupSuffix
+	// A backup left behind by an interrupted upgrade would block the rename
+	if err := c.Remove(ctx, backup); err != nil && !IsNotFound(err) {
+		return fmt.Errorf("remove stale %s: %w", backup, err)
+	}
+
+	wasRunning := old.State.Running
+	if err := c.Stop(ctx, old.ID, stopTimeout); err != nil {
+		return fmt.Errorf("stop %s: %w", name, err)
+	}
+	if err := c.Rename(ctx, old.ID, backup); err != nil {
+		c.restore(ctx, old.ID, "", name, wasRunning)
+		return fmt.Errorf("rename %s: %w", name, err)
+	}
+
+	primary, endpoints := networkEndpoints(old)
+	body := createBody(old, image)
+	if primary != "" {
+		body["NetworkingConfig"] = map[string]interface{}{
+			"EndpointsConfig": map[string]interface{}{primary: endpoints[primary]},
+		}
+	}
+
+	id, err := c.Create(ctx, name, body)
+	if err != nil {
+		c.restore(ctx, old.ID, "", name, wasRunning)
+		return fmt.Errorf("create %s from %s: %w", name, image, err)
+	}
+	for _, network := range sortedKeys(endpoints) {
+		if network == primary {
+			continue
+		}
+		if err := c.ConnectNetwork(ctx, network, id, endpoints[network]); err != nil {
+			c.restore(ctx, old.ID, id, name, wasRunning)
+			return fmt.Errorf("connect %s to %s: %w", name, network, err)
+		}
+	}
+	if err := c.Start(ctx, id); err != nil {
+		c.restore(ctx, old.ID, id, name, wasRunning)
+		return fmt.Errorf("start %s from %s: %w", name, image, err)
+	}
+
+	if err := c.Remove(ctx, old.ID); err != nil {
+		return fmt.Errorf("%s was upgraded but the previous container %s could not be removed: %w", name, backup, err)
+	}
+	return nil
+}
+
+// restore removes a failed replacement and puts the previous container
+// back. Errors are ignored: the original failure is what gets reported.
+func (c *Client) restore(ctx context.Context, oldID, newID, name string, start bool) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	if newID != "" {
+		c.Stop(ctx, newID, 0)
+		c.Remove(ctx, newID)
+	}
+	c.Rename(ctx, oldID, name)
+	if start {
+		c.Start(ctx, oldID)
+	}
+}
+
+// createBody copies the container's configuration for a new container from
+// image. A hostname Docker generated from the old container ID is dropped so
+// the new container gets its own.
+func createBody(old *Container, image string) map[string]interface{} {
+	body := make(map[string]interface{}, len(old.Config)+1)
+	for key, value := range old.Config {
+		body[key] = value
+	}
+	body["Image"] = image
+	if hostname, _ := body["Hostname"].(string); hostname != "" && strings.HasPrefix(old.ID, hostname) {
+		delete(body, "Hostname")
+	}
+	if old.HostConfig != nil {
+		body["HostConfig"] = old.HostConfig
+	}
+	return body
+}
+
+// networkEndpoints returns the endpoint settings to recreate the container's
+// user-defined networks with, and the network to create it on. Containers
+// using the host's or another container's network stack keep it through
+// HostConfig.NetworkMode and get no endpoints.
+func networkEndpoints(old *Container) (string, map[string]map[string]interface{}) {
+	mode, _ := old.HostConfig["NetworkMode"].(string)
+	if mode == "host" || mode == "none" || strings.HasPrefix(mode, "container:") {
+		return "", nil
+	}
+
+	endpoints := make(map[string]map[string]interface{}, len(old.NetworkSettings.Networks))
+	for network, settings := range old.NetworkSettings.Networks {
+		endpoint := map[string]interface{}{}
+		for _, key := range []string{"IPAMConfig", "Links", "Aliases", "DriverOpts"} {
+			if value, ok := settings[key]; ok && value != nil {
+				endpoint[key] = value
+			}
+		}
+		// The old container's short ID is added as an alias automatically
+		if aliases, ok := endpoint["Aliases"].([]interface{}); ok {
+			kept := make([]interface{}, 0, len(aliases))
+			for _, alias := range aliases {
+				if s, _ := alias.(string); s != "" && strings.HasPrefix(old.ID, s) {
+					continue
+				}
+				kept = append(kept, alias)
+			}
+			endpoint["Aliases"] = kept
+		}
+		endpoints[network] = endpoint
+	}
+	if len(endpoints) == 0 {
+		return "", nil
+	}
+
+	if _, ok := endpoints[mode]; ok {
+		return mode, endpoints
+	}
+	return sortedKeys(endpoints)[0], endpoints
+}
+
+func sortedKeys(m map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
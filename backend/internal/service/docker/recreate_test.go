@@ -0,0 +1,101 @@
+package docker_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/service/docker/dockertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecreate(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "registry.example.com/app:1.0.0")
+	engine.AddContainer("db", "postgres:16")
+	before, _ := engine.Container("app")
+	client := newTLSClient(t, engine)
+
+	require.NoError(t, client.Recreate(context.Background(), "app", "registry.example.com/app:2.0.0", 5))
+
+	after, ok := engine.Container("app")
+	require.True(t, ok)
+	assert.NotEqual(t, before.ID, after.ID)
+	assert.Equal(t, "registry.example.com/app:2.0.0", after.Image)
+	assert.True(t, after.Running)
+	assert.Equal(t, []string{"app_default"}, after.Networks)
+	assert.Equal(t, before.Labels, after.Labels)
+	assert.ElementsMatch(t, []string{"app", "db"}, engine.Containers(), "the previous container is removed")
+
+	db, _ := engine.Container("db")
+	assert.True(t, db.Running, "other containers are untouched")
+}
+
+func TestRecreate_PullFailure(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	engine.FailPull("app:2.0.0", "manifest unknown")
+	before, _ := engine.Container("app")
+	client := newTLSClient(t, engine)
+
+	err := client.Recreate(context.Background(), "app", "app:2.0.0", 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "manifest unknown")
+
+	after, _ := engine.Container("app")
+	assert.Equal(t, before, after)
+	assert.NotContains(t, engine.Requests(), "POST /containers/"+before.ID+"/stop")
+}
+
+func TestRecreate_StartFailureRollsBack(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	engine.FailStart("app:2.0.0")
+	before, _ := engine.Container("app")
+	client := newTLSClient(t, engine)
+
+	err := client.Recreate(context.Background(), "app", "app:2.0.0", 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start app from app:2.0.0")
+
+	after, ok := engine.Container("app")
+	require.True(t, ok)
+	assert.Equal(t, before.ID, after.ID)
+	assert.Equal(t, "app:1.0.0", after.Image)
+	assert.True(t, after.Running)
+	assert.Equal(t, []string{"app"}, engine.Containers(), "the failed replacement is removed")
+}
+
+func TestRecreate_StoppedContainerStaysStopped(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	engine.SetState("app", false, "")
+	engine.FailStart("app:2.0.0")
+	client := newTLSClient(t, engine)
+
+	require.Error(t, client.Recreate(context.Background(), "app", "app:2.0.0", 5))
+
+	after, _ := engine.Container("app")
+	assert.Equal(t, "app:1.0.0", after.Image)
+	assert.False(t, after.Running)
+}
+
+func TestRecreate_RemovesStaleBackup(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	engine.AddContainer("app-pre-upgrade", "app:0.9.0")
+	engine.SetState("app-pre-upgrade", false, "")
+	client := newTLSClient(t, engine)
+
+	require.NoError(t, client.Recreate(context.Background(), "app", "app:2.0.0", 5))
+	assert.Equal(t, []string{"app"}, engine.Containers())
+}
+
+func TestRecreate_NotFound(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	client := newTLSClient(t, engine)
+
+	err := client.Recreate(context.Background(), "missing", "app:2.0.0", 5)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "inspect missing")
+}
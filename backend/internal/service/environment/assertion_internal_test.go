@@ -0,0 +1,110 @@
+package environment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteHTTPCommand_JSONPathAssertionFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"error"}`))
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{URL: srv.URL, HTTPPolicy: entities.HTTPPolicy{Success: &entities.SuccessCriteria{
+		JSONPath: []entities.JSONPathAssertion{{Path: "$.status", Equals: "ok"}},
+	}}}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "Success assertion failed")
+	assert.Contains(t, msg, `jsonPath $.status: expected "ok", got "error"`)
+}
+
+func TestExecuteHTTPCommand_JSONPathAssertionPasses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok","replicas":3}`))
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{URL: srv.URL, HTTPPolicy: entities.HTTPPolicy{Success: &entities.SuccessCriteria{
+		JSONPath: []entities.JSONPathAssertion{
+			{Path: "$.status", Equals: "ok"},
+			{Path: "$.replicas", Equals: 3},
+		},
+	}}}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.True(t, ok, msg)
+}
+
+func TestExecuteHTTPCommand_ExpectedStatusCodes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte("already running"))
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{URL: srv.URL}
+	_, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+	assert.False(t, ok, "409 fails without success criteria")
+
+	cmd.Success = &entities.SuccessCriteria{StatusCodes: []int{200, 409}}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+	assert.True(t, ok, msg)
+	assert.Equal(t, "already running", msg)
+}
+
+func TestExecuteHTTPCommand_RequiredHeaderMissing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{URL: srv.URL, HTTPPolicy: entities.HTTPPolicy{Success: &entities.SuccessCriteria{
+		Headers: map[string]string{"X-Deploy-Id": ""},
+	}}}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "header X-Deploy-Id: expected present, got missing")
+}
+
+func TestExecuteHTTPCommand_AsyncAssertsFinalResponse(t *testing.T) {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/deploy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/jobs/7")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/jobs/7", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) < 2 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"version":"2.0.0"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{
+		URL: srv.URL + "/deploy",
+		HTTPPolicy: entities.HTTPPolicy{
+			Async: &entities.AsyncPolicy{PollIntervalMs: 1},
+			Success: &entities.SuccessCriteria{
+				JSONPath: []entities.JSONPathAssertion{{Path: "$.result.version", Equals: "2.1.0"}},
+			},
+		},
+	}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, `jsonPath $.result.version: expected "2.1.0", got "2.0.0"`)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&polls))
+}
@@ -0,0 +1,44 @@
+package environment_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CheckHealth_DegradedNotifiesListeners(t *testing.T) {
+	env := newProbeEnv(t, http.StatusOK, nil)
+	env.Status = entities.Status{Health: entities.HealthStatusHealthy}
+	svc, logs := newHookService(t, env)
+	var notified []entities.Status
+	svc.OnHealthChange(func(changed *entities.Environment, status entities.Status) {
+		assert.Equal(t, env.ID, changed.ID)
+		notified = append(notified, status)
+	})
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	require.Len(t, notified, 1)
+	assert.Equal(t, entities.HealthStatusDegraded, notified[0].Health)
+	assert.Equal(t, "worker", notified[0].FailedProbe)
+	require.Len(t, *logs, 1)
+	assert.Equal(t, entities.LogLevelWarning, (*logs)[0].Level)
+	assert.Equal(t, "degraded", (*logs)[0].Details["currentStatus"])
+}
+
+func TestService_CheckHealth_UnchangedDoesNotNotify(t *testing.T) {
+	env := newProbeEnv(t, http.StatusOK, nil)
+	env.Status = entities.Status{Health: entities.HealthStatusDegraded}
+	svc, logs := newHookService(t, env)
+	svc.OnHealthChange(func(*entities.Environment, entities.Status) {
+		t.Error("listener called although the health did not change")
+	})
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	assert.Empty(t, *logs)
+}
@@ -0,0 +1,180 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/docker"
+	"app-env-manager/internal/service/executor"
+)
+
+// validateDockerConfig checks an environment's Docker settings before the
+// Engine is contacted
+func validateDockerConfig(config *entities.DockerConfig) error {
+	if config == nil {
+		return fmt.Errorf("docker commands require a docker configuration")
+	}
+	if len(config.Containers) == 0 {
+		return fmt.Errorf("docker configuration lists no containers")
+	}
+	for _, name := range config.Containers {
+		if err := docker.ValidateContainerName(name); err != nil {
+			return err
+		}
+	}
+	switch config.Transport {
+	case "", entities.DockerTransportSSH:
+		if config.SocketPath != "" && !strings.HasPrefix(config.SocketPath, "/") {
+			return fmt.Errorf("docker socket path must be absolute")
+		}
+	case entities.DockerTransportTLS:
+		if config.TLS == nil || (config.TLS.CACert == "" && !config.TLS.InsecureSkipVerify) {
+			return fmt.Errorf("docker TLS transport requires a CA certificate")
+		}
+	default:
+		return fmt.Errorf("unknown docker transport %q", config.Transport)
+	}
+	return nil
+}
+
+// dockerClient connects to the Engine on the environment's own host, either
+// through its Docker socket forwarded over SSH or on its TLS port
+func (s *Service) dockerClient(env *entities.Environment) (*docker.Client, error) {
+	if err := validateDockerConfig(env.Commands.Docker); err != nil {
+		return nil, err
+	}
+	config := env.Commands.Docker.WithDefaults()
+
+	if config.Transport == entities.DockerTransportTLS {
+		var clientKey string
+		if key, ok := env.Metadata["dockerClientKey"].(string); ok {
+			clientKey = key
+		}
+		tlsConfig, err := docker.TLSConfig([]byte(config.TLS.CACert), []byte(config.TLS.ClientCert),
+			[]byte(clientKey), config.TLS.InsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		address := net.JoinHostPort(env.Target.Host, strconv.Itoa(config.Port))
+		return docker.NewTLSClient(address, tlsConfig, config.APIVersion)
+	}
+
+	target, err := s.buildSSHTarget(env)
+	if err != nil {
+		return nil, err
+	}
+	return docker.NewDialClient(func(ctx context.Context) (net.Conn, error) {
+		return s.sshManager.Dial(*target, "unix", config.SocketPath)
+	}, config.APIVersion)
+}
+
+// executeDockerRestart restarts the environment's containers in order
+func (s *Service) executeDockerRestart(ctx context.Context, env *entities.Environment) *executor.Result {
+	client, err := s.dockerClient(env)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+	config := env.Commands.Docker.WithDefaults()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.Timeout)*time.Second)
+	defer cancel()
+
+	for _, name := range config.Containers {
+		if err := client.Restart(ctx, name, config.StopTimeout); err != nil {
+			return executor.Failuref("restart %s: %v", name, err)
+		}
+	}
+	return executor.Success("")
+}
+
+// executeDockerUpgrade pulls the new image and recreates the environment's
+// containers from it in order. A container that fails to come up is rolled
+// back; containers already upgraded stay upgraded. The result's "images"
+// artifact maps each upgraded container to its new image.
+func (s *Service) executeDockerUpgrade(ctx context.Context, env *entities.Environment, version string) *executor.Result {
+	if err := docker.ValidateTag(version); err != nil {
+		return executor.Failure(err.Error())
+	}
+	client, err := s.dockerClient(env)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+	config := env.Commands.Docker.WithDefaults()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.Timeout)*time.Second)
+	defer cancel()
+
+	images := make(map[string]string, len(config.Containers))
+	result := executor.Success("")
+	result.Artifacts = map[string]interface{}{"images": images}
+	for _, name := range config.Containers {
+		image, err := dockerUpgradeImage(ctx, client, config, name, version)
+		if err == nil {
+			err = client.Recreate(ctx, name, image, config.StopTimeout)
+		}
+		if err != nil {
+			result.Status, result.Error = executor.StatusFailed, err.Error()
+			return result
+		}
+		images[name] = image
+	}
+	return result
+}
+
+// dockerUpgradeImage resolves the image a container is upgraded to: the
+// configured image with {VERSION} replaced, or else the container's current
+// repository tagged with the version
+func dockerUpgradeImage(ctx context.Context, client *docker.Client, config entities.DockerConfig,
+	name, version string) (string, error) {
+
+	if config.Image != "" {
+		return strings.ReplaceAll(config.Image, "{VERSION}", version), nil
+	}
+	container, err := client.Inspect(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("inspect %s: %w", name, err)
+	}
+	return imageForVersion(name, container.Image(), version)
+}
+
+// imageForVersion tags the repository of a container's current image with
+// version
+func imageForVersion(container, current, version string) (string, error) {
+	repository, _ := docker.SplitImage(current)
+	if strings.Contains(repository, "@") {
+		return "", fmt.Errorf("%s runs an image pinned by digest; configure an upgrade image", container)
+	}
+	return repository + ":" + version, nil
+}
+
+// checkDockerHealth reports the environment's containers as unhealthy when
+// any is not running or fails its own health check
+func (s *Service) checkDockerHealth(ctx context.Context, env *entities.Environment) (entities.HealthStatus, string) {
+	client, err := s.dockerClient(env)
+	if err != nil {
+		return entities.HealthStatusUnhealthy, err.Error()
+	}
+	config := env.Commands.Docker.WithDefaults()
+
+	var problems []string
+	for _, name := range config.Containers {
+		container, err := client.Inspect(ctx, name)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+		case !container.State.Running:
+			problems = append(problems, fmt.Sprintf("%s is %s", name, container.State.Status))
+		case container.State.Health != nil && container.State.Health.Status == "unhealthy":
+			problems = append(problems, fmt.Sprintf("%s is unhealthy", name))
+		}
+	}
+	if len(problems) > 0 {
+		return entities.HealthStatusUnhealthy, "Containers: " + strings.Join(problems, "; ")
+	}
+	return entities.HealthStatusHealthy, fmt.Sprintf("%d containers running", len(config.Containers))
+}
@@ -0,0 +1,463 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/service/assertion"
+	"app-env-manager/internal/service/docker"
+	"app-env-manager/internal/service/executor"
+	"app-env-manager/internal/service/kubernetes"
+	"app-env-manager/internal/service/ssh"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maskedValue replaces secrets in rendered plans
+const maskedValue = "REDACTED"
+
+// sensitiveKeys are substrings of header, body and query keys whose values
+// are masked in rendered plans
+var sensitiveKeys = []string{
+	"password", "passwd", "secret", "token", "apikey", "api-key", "api_key",
+	"authorization", "cookie", "credential", "private",
+}
+
+// PlanRestart renders the restart RestartEnvironment would run and checks it
+// without executing anything
+func (s *Service) PlanRestart(ctx context.Context, id string, force bool) (*entities.OperationPlan, error) {
+	env, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !env.Commands.Restart.Enabled {
+		return nil, fmt.Errorf("restart is not enabled for this environment")
+	}
+
+	plan := newPlan(env, "restart", map[string]interface{}{"force": force}, env.Commands.Type)
+	cmdType := env.Commands.Type
+	if cmdType == "" {
+		cmdType = entities.CommandTypeSSH
+	}
+	params := map[string]string{executor.ParamForce: strconv.FormatBool(force)}
+	hooks := hookRun{env: env, hooks: env.Commands.Restart.Hooks, operation: executor.OperationRestart, params: params}
+	s.planWithHooks(ctx, plan, hooks, func() {
+		s.planOperation(ctx, cmdType, &executor.Request{
+			Operation:   executor.OperationRestart,
+			Environment: env,
+			Command:     renderRestartCommand(env, force),
+			Params:      params,
+		}, plan)
+	})
+
+	s.recordPlan(ctx, env, entities.ActionTypeRestart, plan)
+	return plan, nil
+}
+
+// PlanUpgrade renders the upgrade UpgradeEnvironment would run and checks it
+// without executing anything
+func (s *Service) PlanUpgrade(ctx context.Context, id string, version string) (*entities.OperationPlan, error) {
+	env, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !env.UpgradeConfig.Enabled {
+		return nil, fmt.Errorf("upgrade is not enabled for this environment")
+	}
+
+	plan := newPlan(env, "upgrade", map[string]interface{}{
+		"version":        version,
+		"currentVersion": env.SystemInfo.AppVersion,
+	}, env.UpgradeConfig.Type)
+	params := map[string]string{
+		executor.ParamVersion:        version,
+		executor.ParamCurrentVersion: env.SystemInfo.AppVersion,
+	}
+	hooks := hookRun{env: env, hooks: env.UpgradeConfig.Hooks, operation: executor.OperationUpgrade, params: params}
+	s.planWithHooks(ctx, plan, hooks, func() {
+		if env.UpgradeConfig.Type == "" {
+			plan.AddCheck("command_type", "", fmt.Errorf("no command type specified for upgrade"))
+			return
+		}
+		s.planOperation(ctx, env.UpgradeConfig.Type, &executor.Request{
+			Operation:   executor.OperationUpgrade,
+			Environment: env,
+			Command:     renderUpgradeCommand(env, version),
+			Params:      params,
+		}, plan)
+	})
+
+	s.recordPlan(ctx, env, entities.ActionTypeUpgrade, plan)
+	return plan, nil
+}
+
+// PlanCustomAction renders the custom action RunCustomAction would run and
+// checks it without executing anything
+func (s *Service) PlanCustomAction(ctx context.Context, id string, name string) (*entities.OperationPlan, error) {
+	env, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	action, ok := env.Commands.FindAction(name)
+	if !ok {
+		return nil, errors.ErrActionNotFound
+	}
+
+	plan := newPlan(env, "custom_action", map[string]interface{}{"action": action.Name}, env.Commands.Type)
+	if env.Commands.Type == "" {
+		plan.AddCheck("command_type", "", fmt.Errorf("no command type specified for custom action"))
+	} else {
+		s.planOperation(ctx, env.Commands.Type, &executor.Request{
+			Operation:   executor.OperationCustomAction,
+			Environment: env,
+			Command:     action.Command,
+			Params:      map[string]string{executor.ParamAction: action.Name},
+		}, plan)
+	}
+
+	s.recordPlan(ctx, env, entities.ActionTypeCustom, plan)
+	return plan, nil
+}
+
+// newPlan starts a valid, empty plan for the environment
+func newPlan(env *entities.Environment, operation string, params map[string]interface{},
+	cmdType entities.CommandType) *entities.OperationPlan {
+
+	return &entities.OperationPlan{
+		OperationID:     fmt.Sprintf("op-%s", primitive.NewObjectID().Hex()),
+		EnvironmentID:   env.ID,
+		EnvironmentName: env.Name,
+		Operation:       operation,
+		Parameters:      params,
+		DryRun:          true,
+		CommandType:     cmdType,
+		Steps:           []entities.PlanStep{},
+		Checks:          []entities.PlanCheck{},
+		Valid:           true,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// planHTTP adds an HTTP request step and checks its URL against the SSRF rules
+func (s *Service) planHTTP(plan *entities.OperationPlan, cmd entities.CommandDetails) {
+	method := cmd.Method
+	if method == "" {
+		method = "POST"
+	}
+	plan.Steps = append(plan.Steps, entities.PlanStep{
+		Type:       entities.CommandTypeHTTP,
+		Method:     method,
+		URL:        maskURL(cmd.URL),
+		Headers:    maskHeaders(cmd.Headers),
+		Body:       maskBody(cmd.Body),
+		HTTPPolicy: cmd.HTTPPolicy,
+	})
+
+	var err error
+	if cmd.URL == "" {
+		err = fmt.Errorf("HTTP command URL is required")
+	} else {
+		err = s.validateURL(cmd.URL)
+	}
+	plan.AddCheck("url", maskURL(cmd.URL), err)
+	if cmd.Success != nil {
+		plan.AddCheck("assertions", "", assertion.Validate(cmd.Success))
+	}
+}
+
+// planSSH adds one step per command, validates each command and checks that
+// the host is reachable with the configured credentials and host key
+func (s *Service) planSSH(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment, commands []string) {
+	for _, command := range commands {
+		plan.Steps = append(plan.Steps, entities.PlanStep{
+			Type:     entities.CommandTypeSSH,
+			Host:     env.Target.Host,
+			Port:     env.Target.Port,
+			Username: env.Credentials.Username,
+			Auth:     env.Credentials.Type,
+			Command:  command,
+		})
+		match, err := s.sshManager.Authorize(command, env.Commands.Allowlist)
+		target := command
+		if match != nil {
+			target = fmt.Sprintf("%s (%s rule %s)", command, match.Scope, match.Rule.DisplayName())
+		}
+		plan.AddCheck("command", target, err)
+	}
+	if len(commands) == 0 {
+		plan.AddCheck("command", "", fmt.Errorf("command cannot be empty"))
+	}
+
+	s.planSSHConnection(ctx, plan, env)
+}
+
+// planSSHScript adds the script upload step with its merged environment,
+// validates the script and checks the host like planSSH
+func (s *Service) planSSHScript(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment,
+	script *entities.ScriptConfig, params map[string]string) {
+
+	rendered := scriptFor(script, params)
+	plan.Steps = append(plan.Steps, entities.PlanStep{
+		Type:     entities.CommandTypeSSH,
+		Host:     env.Target.Host,
+		Port:     env.Target.Port,
+		Username: env.Credentials.Username,
+		Auth:     env.Credentials.Type,
+		Script: &entities.ScriptConfig{
+			Body:        rendered.Body,
+			Interpreter: rendered.Interpreter,
+			Env:         maskHeaders(rendered.Env),
+		},
+	})
+	plan.AddCheck("script", rendered.Interpreter, ssh.ValidateScript(rendered))
+
+	s.planSSHConnection(ctx, plan, env)
+}
+
+// planSSHConnection checks the credentials and that the host is reachable
+// with them and the configured host key
+func (s *Service) planSSHConnection(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment) {
+	address := net.JoinHostPort(env.Target.Host, strconv.Itoa(env.Target.Port))
+	target, err := s.buildSSHTarget(env)
+	plan.AddCheck("credentials", env.Credentials.Type, err)
+	if err != nil {
+		return
+	}
+	plan.AddCheck("ssh_connection", address, s.sshManager.TestConnection(ctx, *target))
+}
+
+// planDockerRestart adds one restart request per container and checks that
+// the Engine answers
+func (s *Service) planDockerRestart(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment) {
+	client := s.planDockerConnection(ctx, plan, env)
+	if client == nil {
+		return
+	}
+	config := env.Commands.Docker.WithDefaults()
+	for _, name := range config.Containers {
+		step := dockerStep(env, config)
+		step.Method = "POST"
+		step.URL = fmt.Sprintf("/containers/%s/restart?t=%d", name, config.StopTimeout)
+		plan.Steps = append(plan.Steps, step)
+	}
+}
+
+// planDockerUpgrade adds one pull-and-recreate step per container with the
+// image it would be upgraded to and checks that the Engine answers
+func (s *Service) planDockerUpgrade(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment, version string) {
+	plan.AddCheck("version", version, docker.ValidateTag(version))
+	client := s.planDockerConnection(ctx, plan, env)
+	if client == nil {
+		return
+	}
+	config := env.Commands.Docker.WithDefaults()
+	for _, name := range config.Containers {
+		image, err := dockerUpgradeImage(ctx, client, config, name, version)
+		plan.AddCheck("image", name, err)
+		if err != nil {
+			continue
+		}
+		step := dockerStep(env, config)
+		step.Command = fmt.Sprintf("pull %s and recreate %s", image, name)
+		plan.Steps = append(plan.Steps, step)
+	}
+}
+
+// planDockerConnection checks the Docker configuration and that the Engine
+// answers, returning a client when it does
+func (s *Service) planDockerConnection(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment) *docker.Client {
+	client, err := s.dockerClient(env)
+	transport := ""
+	if env.Commands.Docker != nil {
+		transport = string(env.Commands.Docker.WithDefaults().Transport)
+	}
+	plan.AddCheck("docker_config", transport, err)
+	if err != nil {
+		return nil
+	}
+	err = client.Ping(ctx)
+	plan.AddCheck("docker_connection", env.Target.Host, err)
+	if err != nil {
+		return nil
+	}
+	return client
+}
+
+// dockerStep starts a plan step addressed to the environment's Engine
+func dockerStep(env *entities.Environment, config entities.DockerConfig) entities.PlanStep {
+	step := entities.PlanStep{Type: entities.CommandTypeDocker, Host: env.Target.Host}
+	if config.Transport == entities.DockerTransportTLS {
+		step.Port = config.Port
+		step.Auth = "tls"
+		return step
+	}
+	step.Port = env.Target.Port
+	step.Username = env.Credentials.Username
+	step.Auth = env.Credentials.Type
+	return step
+}
+
+// planKubernetesRestart adds the rollout restart patch and checks that the
+// workload can be read
+func (s *Service) planKubernetesRestart(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment) {
+	client, ref := s.planKubernetesConnection(ctx, plan, env)
+	if client == nil {
+		return
+	}
+	plan.Steps = append(plan.Steps, kubernetesStep(client, ref, kubernetes.RestartPatch(time.Now())))
+}
+
+// planKubernetesUpgrade adds the image patch with the container and image it
+// would set and checks that the workload can be read
+func (s *Service) planKubernetesUpgrade(ctx context.Context, plan *entities.OperationPlan, env *entities.Environment, version string) {
+	plan.AddCheck("version", version, docker.ValidateTag(version))
+	client, ref := s.planKubernetesConnection(ctx, plan, env)
+	if client == nil {
+		return
+	}
+	container, image, err := kubernetesUpgradeImage(ctx, client, ref, *env.Commands.Kubernetes, version)
+	plan.AddCheck("image", ref.String(), err)
+	if err != nil {
+		return
+	}
+	plan.Steps = append(plan.Steps, kubernetesStep(client, ref, kubernetes.ImagePatch(container, image)))
+}
+
+// planKubernetesConnection checks the Kubernetes configuration and that the
+// workload can be read with the credentials, returning a client when it can
+func (s *Service) planKubernetesConnection(ctx context.Context, plan *entities.OperationPlan,
+	env *entities.Environment) (*kubernetes.Client, kubernetes.WorkloadRef) {
+
+	client, ref, err := s.kubernetesWorkload(env)
+	plan.AddCheck("kubernetes_config", env.Credentials.Type, err)
+	if err != nil {
+		return nil, ref
+	}
+	_, err = client.Get(ctx, ref)
+	plan.AddCheck("kubernetes_connection", ref.Namespace+"/"+ref.String(), err)
+	if err != nil {
+		return nil, ref
+	}
+	return client, ref
+}
+
+// kubernetesStep is a strategic merge patch of the workload
+func kubernetesStep(client *kubernetes.Client, ref kubernetes.WorkloadRef, patch map[string]interface{}) entities.PlanStep {
+	path, _ := ref.Path()
+	return entities.PlanStep{
+		Type:   entities.CommandTypeKubernetes,
+		Method: "PATCH",
+		URL:    client.Server() + path,
+		Body:   patch,
+	}
+}
+
+// recordPlan logs the dry run to the logs screen and the audit trail
+func (s *Service) recordPlan(ctx context.Context, env *entities.Environment, action entities.ActionType, plan *entities.OperationPlan) {
+	message := fmt.Sprintf("Dry run of %s: plan is valid", plan.Operation)
+	severity := entities.SeverityInfo
+	if !plan.Valid {
+		message = fmt.Sprintf("Dry run of %s: plan has failed checks", plan.Operation)
+		severity = entities.SeverityWarning
+	}
+
+	_ = s.logService.LogEnvironmentAction(ctx, env, action, message, map[string]interface{}{
+		"operationId": plan.OperationID,
+		"dryRun":      true,
+		"valid":       plan.Valid,
+	})
+	s.logEvent(ctx, env, entities.EventTypeDryRun, severity, plan.Operation, message, map[string]interface{}{
+		"operationId": plan.OperationID,
+		"dryRun":      true,
+		"plan":        plan,
+	})
+}
+
+// isSensitiveKey reports whether a key names a secret
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// maskHeaders copies headers with secret values masked
+func maskHeaders(headers map[string]string) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+	masked := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if isSensitiveKey(key) {
+			value = maskedValue
+		}
+		masked[key] = value
+	}
+	return masked
+}
+
+// maskBody copies a request body with secret values masked at any depth
+func maskBody(body map[string]interface{}) map[string]interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	masked := make(map[string]interface{}, len(body))
+	for key, value := range body {
+		if isSensitiveKey(key) {
+			masked[key] = maskedValue
+			continue
+		}
+		masked[key] = maskValue(value)
+	}
+	return masked
+}
+
+// maskValue masks nested maps and lists within a body value
+func maskValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return maskBody(v)
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = maskValue(item)
+		}
+		return masked
+	default:
+		return value
+	}
+}
+
+// maskURL masks the userinfo password and secret query parameters
+func maskURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	if parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), maskedValue)
+		}
+	}
+	query := parsed.Query()
+	changed := false
+	for key := range query {
+		if isSensitiveKey(key) {
+			query.Set(key, maskedValue)
+			changed = true
+		}
+	}
+	if changed {
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String()
+}
@@ -0,0 +1,81 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+)
+
+// ExecResult is the outcome of an ad-hoc command
+type ExecResult struct {
+	Command      string            `json:"command"`
+	ExitCode     int               `json:"exitCode"`
+	Output       string            `json:"output"`
+	OutputSHA256 string            `json:"outputSha256"` // matches the audit entry
+	Duration     int64             `json:"duration"`     // milliseconds
+	Rule         string            `json:"rule"`
+	RuleScope    string            `json:"ruleScope"`
+	Params       map[string]string `json:"params,omitempty"`
+}
+
+// ExecCommand runs an ad-hoc command on the environment's host. Unlike
+// configured commands it always needs a matching allowlist rule, so nothing
+// runs on environments without an allowlist.
+func (s *Service) ExecCommand(ctx context.Context, id string, command string) (*ExecResult, error) {
+	env, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := s.sshManager.Authorize(command, env.Commands.Allowlist)
+	if err == nil && match == nil {
+		err = fmt.Errorf("no allowlist is configured for this environment")
+	}
+	if err != nil {
+		s.logEvent(ctx, env, entities.EventTypeCommandDenied, entities.SeverityWarning, "exec",
+			"Ad-hoc command rejected", map[string]interface{}{
+				"command": command,
+				"error":   err.Error(),
+			})
+		denied := errors.ErrCommandNotAllowed
+		denied.Details = map[string]interface{}{"reason": err.Error()}
+		return nil, denied
+	}
+
+	target, err := s.buildSSHTarget(env)
+	if err != nil {
+		return nil, errors.NewValidationError("credentials", err.Error())
+	}
+
+	start := time.Now()
+	result, err := s.executeSSHCommand(ctx, env, *target, command)
+	if err != nil {
+		_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeExec,
+			fmt.Sprintf("Ad-hoc command failed: %s", command), map[string]interface{}{"error": err.Error()})
+		failed := errors.ErrSSHConnectionFailed
+		failed.Details = map[string]interface{}{"error": err.Error()}
+		return nil, failed
+	}
+
+	duration := time.Since(start).Milliseconds()
+	_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeExec,
+		fmt.Sprintf("Ad-hoc command exited with code %d: %s", result.ExitCode, command), map[string]interface{}{
+			"exitCode": result.ExitCode,
+			"duration": duration,
+			"rule":     match.Rule.DisplayName(),
+		})
+
+	return &ExecResult{
+		Command:      command,
+		ExitCode:     result.ExitCode,
+		Output:       result.Output,
+		OutputSHA256: outputHash(result.Output),
+		Duration:     duration,
+		Rule:         match.Rule.DisplayName(),
+		RuleScope:    match.Scope,
+		Params:       match.Params,
+	}, nil
+}
@@ -0,0 +1,248 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/executor"
+	"app-env-manager/internal/service/health"
+	"app-env-manager/internal/service/simulation"
+)
+
+// registerBuiltinExecutors registers the drivers for the command types the
+// service supports out of the box
+func (s *Service) registerBuiltinExecutors() {
+	s.executors.Register(entities.CommandTypeSSH, &sshExecutor{s})
+	s.executors.Register(entities.CommandTypeHTTP, &httpExecutor{s})
+	s.executors.Register(entities.CommandTypeDocker, &dockerExecutor{s})
+	s.executors.Register(entities.CommandTypeKubernetes, &kubernetesExecutor{s})
+	s.executors.Register(entities.CommandTypeSimulated, simulation.NewSimulator())
+}
+
+// RegisterExecutor sets the driver for a command type, replacing a built-in
+// one of the same type. Register drivers before the service handles requests.
+func (s *Service) RegisterExecutor(cmdType entities.CommandType, e executor.Executor) {
+	s.executors.Register(cmdType, e)
+}
+
+// planOperation adds the steps and checks of an operation to a plan, or a
+// failed check when the driver cannot plan it
+func (s *Service) planOperation(ctx context.Context, cmdType entities.CommandType, req *executor.Request,
+	plan *entities.OperationPlan) {
+
+	e, ok := s.executors.Lookup(cmdType)
+	if !ok {
+		plan.AddCheck("command_type", string(cmdType), fmt.Errorf("unsupported command type: %q", cmdType))
+		return
+	}
+	planner, ok := e.(executor.Planner)
+	if !ok {
+		plan.AddCheck("command_type", string(cmdType), fmt.Errorf("dry runs are not supported for %s commands", cmdType))
+		return
+	}
+	planner.Plan(ctx, req, plan)
+}
+
+// driverHealthChecks are the health check types probed by a command driver
+// rather than the health checker
+var driverHealthChecks = map[entities.HealthCheckType]entities.CommandType{
+	entities.HealthCheckTypeSSH:       entities.CommandTypeSSH,
+	entities.HealthCheckTypeSimulated: entities.CommandTypeSimulated,
+}
+
+// probeHealth runs the health probe of the driver for cmdType as a health
+// check
+func (s *Service) probeHealth(ctx context.Context, cmdType entities.CommandType, env *entities.Environment) *health.CheckResult {
+	start := time.Now()
+	probe := s.executors.Execute(ctx, cmdType, &executor.Request{
+		Operation:   executor.OperationHealth,
+		Environment: env,
+	})
+	result := &health.CheckResult{
+		Status:       probe.Health,
+		Message:      probe.Output,
+		ResponseTime: time.Since(start).Milliseconds(),
+	}
+	if !probe.Succeeded() {
+		result.Status = entities.HealthStatusUnhealthy
+		result.Message = probe.Message()
+	}
+	health.DegradeIfSlow(result, env.HealthCheck.DegradedAboveMs)
+	return result
+}
+
+// resultDetails adds what a driver reported beyond success to log details
+func resultDetails(details map[string]interface{}, result *executor.Result) map[string]interface{} {
+	if result.ExitCode != 0 {
+		details["exitCode"] = result.ExitCode
+	}
+	if len(result.Artifacts) > 0 {
+		details["artifacts"] = result.Artifacts
+	}
+	return details
+}
+
+// sshExecutor runs commands and scripts on the environment's host
+type sshExecutor struct {
+	s *Service
+}
+
+func (e *sshExecutor) Execute(ctx context.Context, req *executor.Request) *executor.Result {
+	env, cmd := req.Environment, req.Command
+	switch {
+	case req.Operation == executor.OperationHealth && env.HealthCheck.Type == entities.HealthCheckTypeSSH:
+		return e.s.probeSSHHealth(ctx, env)
+	case req.Operation == executor.OperationHealth:
+		return executor.Unsupported(entities.CommandTypeSSH, req.Operation)
+	case cmd.Script != nil:
+		return e.s.executeSSHScript(ctx, env, cmd.Script, req.Params)
+	case req.Operation == executor.OperationCustomAction && cmd.Command == "":
+		return executor.Failure("custom action has no command")
+	}
+
+	target, err := e.s.buildSSHTarget(env)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+	if req.Operation != executor.OperationUpgrade {
+		result, err := e.s.executeSSHCommand(ctx, env, *target, cmd.Command)
+		if err != nil {
+			return executor.Failure(err.Error())
+		}
+		return sshResult(result.Output, result.ExitCode)
+	}
+
+	// Multi-line upgrade commands run one line at a time
+	var output strings.Builder
+	for _, line := range sshCommandLines(cmd.Command) {
+		result, err := e.s.executeSSHCommand(ctx, env, *target, line)
+		if err != nil {
+			return executor.Failuref("Command failed: %s - Error: %v", line, err)
+		}
+		output.WriteString(result.Output)
+		if result.ExitCode != 0 {
+			failed := sshResult(output.String(), result.ExitCode)
+			failed.Error = fmt.Sprintf("Command failed: %s - Output: %s", line, result.Output)
+			return failed
+		}
+	}
+	return executor.Success(output.String())
+}
+
+func (e *sshExecutor) Plan(ctx context.Context, req *executor.Request, plan *entities.OperationPlan) {
+	env, cmd := req.Environment, req.Command
+	switch {
+	case cmd.Script != nil:
+		e.s.planSSHScript(ctx, plan, env, cmd.Script, req.Params)
+	case req.Operation == executor.OperationUpgrade:
+		e.s.planSSH(ctx, plan, env, sshCommandLines(cmd.Command))
+	default:
+		e.s.planSSH(ctx, plan, env, []string{cmd.Command})
+	}
+}
+
+// sshResult is the result of a remote process; a non-zero exit code fails
+// with the process output as the message
+func sshResult(output string, exitCode int) *executor.Result {
+	result := executor.Success(output)
+	result.ExitCode = exitCode
+	if exitCode != 0 {
+		result.Status = executor.StatusFailed
+	}
+	return result
+}
+
+// sshCommandLines splits a multi-line command into its non-blank lines
+func sshCommandLines(command string) []string {
+	var lines []string
+	for _, line := range strings.Split(command, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// httpExecutor sends the command as an HTTP request
+type httpExecutor struct {
+	s *Service
+}
+
+func (e *httpExecutor) Execute(ctx context.Context, req *executor.Request) *executor.Result {
+	if req.Operation == executor.OperationHealth {
+		return executor.Unsupported(entities.CommandTypeHTTP, req.Operation)
+	}
+	message, ok := e.s.executeHTTPCommand(ctx, req.Command)
+	if !ok {
+		return executor.Failure(message)
+	}
+	return executor.Success(message)
+}
+
+func (e *httpExecutor) Plan(ctx context.Context, req *executor.Request, plan *entities.OperationPlan) {
+	e.s.planHTTP(plan, req.Command)
+}
+
+// dockerExecutor drives the environment's containers through the Docker
+// Engine API
+type dockerExecutor struct {
+	s *Service
+}
+
+func (e *dockerExecutor) Execute(ctx context.Context, req *executor.Request) *executor.Result {
+	env := req.Environment
+	switch req.Operation {
+	case executor.OperationRestart:
+		return e.s.executeDockerRestart(ctx, env)
+	case executor.OperationUpgrade:
+		return e.s.executeDockerUpgrade(ctx, env, req.Param(executor.ParamVersion))
+	case executor.OperationHealth:
+		health, message := e.s.checkDockerHealth(ctx, env)
+		return &executor.Result{Status: executor.StatusSucceeded, Health: health, Output: message}
+	}
+	return executor.Unsupported(entities.CommandTypeDocker, req.Operation)
+}
+
+func (e *dockerExecutor) Plan(ctx context.Context, req *executor.Request, plan *entities.OperationPlan) {
+	switch req.Operation {
+	case executor.OperationRestart:
+		e.s.planDockerRestart(ctx, plan, req.Environment)
+	case executor.OperationUpgrade:
+		e.s.planDockerUpgrade(ctx, plan, req.Environment, req.Param(executor.ParamVersion))
+	default:
+		plan.AddCheck("command_type", string(entities.CommandTypeDocker),
+			executor.UnsupportedError(entities.CommandTypeDocker, req.Operation))
+	}
+}
+
+// kubernetesExecutor rolls out the environment's workload through the
+// Kubernetes API
+type kubernetesExecutor struct {
+	s *Service
+}
+
+func (e *kubernetesExecutor) Execute(ctx context.Context, req *executor.Request) *executor.Result {
+	env := req.Environment
+	switch req.Operation {
+	case executor.OperationRestart:
+		return e.s.executeKubernetesRestart(ctx, env)
+	case executor.OperationUpgrade:
+		return e.s.executeKubernetesUpgrade(ctx, env, req.Param(executor.ParamVersion))
+	}
+	return executor.Unsupported(entities.CommandTypeKubernetes, req.Operation)
+}
+
+func (e *kubernetesExecutor) Plan(ctx context.Context, req *executor.Request, plan *entities.OperationPlan) {
+	switch req.Operation {
+	case executor.OperationRestart:
+		e.s.planKubernetesRestart(ctx, plan, req.Environment)
+	case executor.OperationUpgrade:
+		e.s.planKubernetesUpgrade(ctx, plan, req.Environment, req.Param(executor.ParamVersion))
+	default:
+		plan.AddCheck("command_type", string(entities.CommandTypeKubernetes),
+			executor.UnsupportedError(entities.CommandTypeKubernetes, req.Operation))
+	}
+}
@@ -0,0 +1,127 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/health"
+)
+
+// HealthPolicy controls how health check results change environments'
+// health. The zero policy applies each result at once, without retries or
+// flap detection.
+type HealthPolicy struct {
+	Retries         int           // retries of an unhealthy check before its result counts
+	RetryBackoff    time.Duration // wait before the first retry, doubled before each next one
+	FlapTransitions int           // health changes within FlapWindow that mark an environment flapping; 0 disables detection
+	FlapWindow      time.Duration
+}
+
+// SetHealthPolicy replaces the health policy. It is set before health checks
+// run.
+func (s *Service) SetHealthPolicy(policy HealthPolicy) {
+	s.healthPolicy = policy
+}
+
+// checkWithRetries checks an environment, retrying with backoff while the
+// check finds it unhealthy. It stops early when another attempt could not
+// finish before the context's deadline. It returns the last result and how
+// many checks were run.
+func (s *Service) checkWithRetries(ctx context.Context, env *entities.Environment) (*health.CheckResult, int, error) {
+	backoff := s.healthPolicy.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		result, err := s.runHealthCheck(ctx, env)
+		if err != nil || result.Status != entities.HealthStatusUnhealthy || attempt > s.healthPolicy.Retries {
+			return result, attempt, err
+		}
+		if !attemptFits(ctx, env, backoff, time.Since(start)) {
+			return result, attempt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, attempt, nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// attemptFits reports whether another check, started after the backoff, can
+// finish before the context's deadline. A check may take the environment's
+// health check timeout, or as long as the last one took when none is set.
+func attemptFits(ctx context.Context, env *entities.Environment, backoff, last time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return true
+	}
+	attempt := last
+	if env.HealthCheck.Timeout > 0 {
+		attempt = time.Duration(env.HealthCheck.Timeout) * time.Second
+	}
+	return time.Until(deadline) >= backoff+attempt
+}
+
+// nextStatus works out an environment's status after a check. A result that
+// differs from the current health replaces it once the environment's failure
+// or success threshold is reached in a row; until then the message counts
+// towards it. Degraded, unknown and unhealthy checks all count as failures,
+// but only unhealthy ones towards unhealthy: an unhealthy check short of its
+// own threshold makes the environment degraded once enough checks have
+// failed. An environment whose health is unknown takes any result at once.
+// Health changes are remembered for the flap window: enough of them mark the
+// environment flapping until none is left.
+func (p HealthPolicy) nextStatus(config entities.HealthCheckConfig, old entities.Status, result *health.CheckResult, now time.Time) entities.Status {
+	status := entities.Status{
+		Health:       old.Health,
+		LastCheck:    now,
+		Message:      result.Message,
+		ResponseTime: result.ResponseTime,
+		Probes:       result.Probes,
+		FailedProbe:  result.FailedProbe,
+	}
+
+	var streak, threshold int
+	var counted string
+	switch result.Status {
+	case entities.HealthStatusHealthy:
+		status.ConsecutiveSuccesses = old.ConsecutiveSuccesses + 1
+		streak, threshold, counted = status.ConsecutiveSuccesses, config.SuccessThreshold(), "successes"
+	case entities.HealthStatusUnhealthy:
+		status.ConsecutiveFailures = old.ConsecutiveFailures + 1
+		status.ConsecutiveUnhealthy = old.ConsecutiveUnhealthy + 1
+		streak, threshold, counted = status.ConsecutiveUnhealthy, config.FailureThreshold(), "failures"
+	default:
+		// Degraded or unknown
+		status.ConsecutiveFailures = old.ConsecutiveFailures + 1
+		streak, threshold, counted = status.ConsecutiveFailures, config.FailureThreshold(), "failures"
+	}
+
+	if result.Status != old.Health {
+		if old.Health == entities.HealthStatusUnknown || old.Health == "" || streak >= threshold {
+			status.Health = result.Status
+		} else {
+			status.Message = fmt.Sprintf("%s (%d of %d %s before %s)", result.Message, streak, threshold, counted, result.Status)
+			if result.Status == entities.HealthStatusUnhealthy && status.ConsecutiveFailures >= threshold {
+				status.Health = entities.HealthStatusDegraded
+			}
+		}
+	}
+
+	if p.FlapTransitions <= 0 {
+		return status
+	}
+	for _, at := range old.Transitions {
+		if now.Sub(at) < p.FlapWindow {
+			status.Transitions = append(status.Transitions, at)
+		}
+	}
+	if status.Health != old.Health {
+		status.Transitions = append(status.Transitions, now)
+	}
+	status.Flapping = len(status.Transitions) >= p.FlapTransitions || (old.Flapping && len(status.Transitions) > 0)
+	return status
+}
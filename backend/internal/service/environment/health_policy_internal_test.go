@@ -0,0 +1,115 @@
+package environment
+
+import (
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/health"
+	"github.com/stretchr/testify/assert"
+)
+
+// observe applies checks with the given results in turn, a minute apart,
+// and returns the status after each
+func observe(policy HealthPolicy, config entities.HealthCheckConfig, status entities.Status, results ...entities.HealthStatus) []entities.Status {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var statuses []entities.Status
+	for _, result := range results {
+		status = policy.nextStatus(config, status, &health.CheckResult{Status: result, Message: "checked"}, now)
+		statuses = append(statuses, status)
+		now = now.Add(time.Minute)
+	}
+	return statuses
+}
+
+func healths(statuses []entities.Status) []entities.HealthStatus {
+	var result []entities.HealthStatus
+	for _, status := range statuses {
+		result = append(result, status.Health)
+	}
+	return result
+}
+
+func TestHealthPolicy_NextStatus_Thresholds(t *testing.T) {
+	config := entities.HealthCheckConfig{FailuresBeforeUnhealthy: 3, SuccessesBeforeHealthy: 2}
+	healthy := entities.Status{Health: entities.HealthStatusHealthy}
+	h, u := entities.HealthStatusHealthy, entities.HealthStatusUnhealthy
+
+	statuses := observe(HealthPolicy{}, config, healthy, u, u, h, u, u, u, h, h)
+
+	assert.Equal(t, []entities.HealthStatus{h, h, h, h, h, u, u, h}, healths(statuses))
+	assert.Equal(t, "checked (1 of 3 failures before unhealthy)", statuses[0].Message)
+	assert.Equal(t, "checked (2 of 3 failures before unhealthy)", statuses[1].Message)
+	assert.Equal(t, "checked", statuses[2].Message, "a healthy check resets the failures")
+	assert.Equal(t, 3, statuses[5].ConsecutiveFailures)
+	assert.Equal(t, 3, statuses[5].ConsecutiveUnhealthy)
+	assert.Equal(t, "checked (1 of 2 successes before healthy)", statuses[6].Message)
+	assert.Equal(t, 2, statuses[7].ConsecutiveSuccesses)
+	assert.Zero(t, statuses[7].ConsecutiveFailures)
+	assert.Zero(t, statuses[7].ConsecutiveUnhealthy)
+}
+
+func TestHealthPolicy_NextStatus_DegradedThenUnhealthy(t *testing.T) {
+	config := entities.HealthCheckConfig{FailuresBeforeUnhealthy: 3}
+	healthy := entities.Status{Health: entities.HealthStatusHealthy}
+	h, u, d := entities.HealthStatusHealthy, entities.HealthStatusUnhealthy, entities.HealthStatusDegraded
+
+	statuses := observe(HealthPolicy{}, config, healthy, d, d, u, u, u)
+
+	// Three failures make the environment degraded; it is unhealthy after
+	// three unhealthy checks
+	assert.Equal(t, []entities.HealthStatus{h, h, d, d, u}, healths(statuses))
+	assert.Equal(t, "checked (2 of 3 failures before degraded)", statuses[1].Message, "degraded checks count as failures")
+	assert.Equal(t, "checked (1 of 3 failures before unhealthy)", statuses[2].Message, "but not towards unhealthy")
+	assert.Equal(t, 3, statuses[2].ConsecutiveFailures)
+	assert.Equal(t, 1, statuses[2].ConsecutiveUnhealthy)
+	assert.Equal(t, "checked (2 of 3 failures before unhealthy)", statuses[3].Message)
+}
+
+func TestHealthPolicy_NextStatus_UnknownCountsAsFailure(t *testing.T) {
+	config := entities.HealthCheckConfig{FailuresBeforeUnhealthy: 3}
+	healthy := entities.Status{Health: entities.HealthStatusHealthy}
+	h, u, n := entities.HealthStatusHealthy, entities.HealthStatusUnhealthy, entities.HealthStatusUnknown
+
+	statuses := observe(HealthPolicy{}, config, healthy, n, u, n)
+
+	assert.Equal(t, []entities.HealthStatus{h, h, n}, healths(statuses))
+	assert.Equal(t, "checked (1 of 3 failures before unknown)", statuses[0].Message, "an unknown check does not replace the health at once")
+	assert.Equal(t, "checked (1 of 3 failures before unhealthy)", statuses[1].Message)
+	assert.Equal(t, 3, statuses[2].ConsecutiveFailures)
+}
+
+func TestHealthPolicy_NextStatus_Defaults(t *testing.T) {
+	h, u := entities.HealthStatusHealthy, entities.HealthStatusUnhealthy
+
+	statuses := observe(HealthPolicy{}, entities.HealthCheckConfig{}, entities.Status{Health: h}, u, h)
+
+	assert.Equal(t, []entities.HealthStatus{u, h}, healths(statuses))
+	assert.Empty(t, statuses[1].Transitions, "flap detection is disabled")
+}
+
+func TestHealthPolicy_NextStatus_UnknownTakesAnyResult(t *testing.T) {
+	config := entities.HealthCheckConfig{FailuresBeforeUnhealthy: 3, SuccessesBeforeHealthy: 3}
+
+	statuses := observe(HealthPolicy{}, config, entities.Status{Health: entities.HealthStatusUnknown},
+		entities.HealthStatusUnhealthy)
+
+	assert.Equal(t, entities.HealthStatusUnhealthy, statuses[0].Health)
+}
+
+func TestHealthPolicy_NextStatus_Flapping(t *testing.T) {
+	policy := HealthPolicy{FlapTransitions: 3, FlapWindow: 5 * time.Minute}
+	h, u := entities.HealthStatusHealthy, entities.HealthStatusUnhealthy
+
+	statuses := observe(policy, entities.HealthCheckConfig{}, entities.Status{Health: h}, u, h, u, u, u, u, u, u)
+
+	var flapping []bool
+	for _, status := range statuses {
+		flapping = append(flapping, status.Flapping)
+	}
+	// Three changes in the first three minutes; the last leaves the window
+	// five minutes after it was made
+	assert.Equal(t, []bool{false, false, true, true, true, true, true, false}, flapping)
+	assert.Len(t, statuses[2].Transitions, 3)
+	assert.Empty(t, statuses[7].Transitions)
+}
@@ -0,0 +1,184 @@
+package environment_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newFailingEnv returns a healthy environment whose health endpoint fails
+// its first failures requests, and the number of requests made
+func newFailingEnv(t *testing.T, failures int32) (*entities.Environment, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	env := newSampleEnv(primitive.NewObjectID())
+	env.HealthCheck = entities.HealthCheckConfig{
+		Enabled:    true,
+		Endpoint:   server.URL,
+		Method:     "GET",
+		Timeout:    2,
+		Validation: entities.ValidationConfig{Type: "statusCode", Value: 200},
+	}
+	env.Status = entities.Status{Health: entities.HealthStatusHealthy}
+	return env, &requests
+}
+
+func TestService_CheckHealth_RetriesHideABlip(t *testing.T) {
+	env, requests := newFailingEnv(t, 1)
+	svc, logs := newHookService(t, env)
+	svc.SetHealthPolicy(environment.HealthPolicy{Retries: 2, RetryBackoff: time.Millisecond})
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(requests))
+	assert.Empty(t, *logs, "the health did not change")
+}
+
+func TestService_CheckHealth_RetriesExhausted(t *testing.T) {
+	env, requests := newFailingEnv(t, 10)
+	svc, logs := newHookService(t, env)
+	svc.SetHealthPolicy(environment.HealthPolicy{Retries: 2, RetryBackoff: time.Millisecond})
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(requests))
+	require.Len(t, *logs, 1)
+	assert.Equal(t, 3, (*logs)[0].Details["attempts"])
+	assert.Equal(t, "unhealthy", (*logs)[0].Details["currentStatus"])
+}
+
+func TestService_CheckHealth_RetriesStopAtDeadline(t *testing.T) {
+	env, requests := newFailingEnv(t, 10)
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+	svc.SetHealthPolicy(environment.HealthPolicy{Retries: 3, RetryBackoff: time.Millisecond})
+
+	// The check's 2s timeout outlasts the deadline, so a retry could not finish
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, svc.CheckHealth(ctx, env.ID.Hex()))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(requests))
+	assert.Equal(t, entities.HealthStatusUnhealthy, updatedStatus(t, repo).Health)
+}
+
+func TestService_CheckHealth_SavedAfterDeadline(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	env, _ := newFailingEnv(t, 0)
+	env.HealthCheck.Endpoint = server.URL
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+	svc.SetHealthPolicy(environment.HealthPolicy{Retries: 3, RetryBackoff: time.Millisecond})
+
+	// The first check runs into the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	require.NoError(t, svc.CheckHealth(ctx, env.ID.Hex()))
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	assert.Equal(t, entities.HealthStatusUnhealthy, updatedStatus(t, repo).Health)
+	for _, call := range repo.Calls {
+		if call.Method == "UpdateStatus" {
+			assert.NoError(t, call.Arguments.Get(0).(context.Context).Err(), "the status is saved after the deadline")
+		}
+	}
+}
+
+func TestService_CheckHealth_FailureThreshold(t *testing.T) {
+	env, _ := newFailingEnv(t, 10)
+	env.HealthCheck.FailuresBeforeUnhealthy = 2
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	status := updatedStatus(t, repo)
+	assert.Equal(t, entities.HealthStatusHealthy, status.Health)
+	assert.Equal(t, 1, status.ConsecutiveFailures)
+	assert.Contains(t, status.Message, "(1 of 2 failures before unhealthy)")
+}
+
+func TestService_ProbeHealth_IgnoresThreshold(t *testing.T) {
+	env, _ := newFailingEnv(t, 10)
+	env.HealthCheck.FailuresBeforeUnhealthy = 2
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+
+	result, err := svc.ProbeHealth(context.Background(), env.ID.Hex())
+	require.NoError(t, err)
+
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Equal(t, entities.HealthStatusHealthy, updatedStatus(t, repo).Health, "the saved health waits for the threshold")
+}
+
+func TestService_CheckHealth_FlappingSuppressesLogs(t *testing.T) {
+	env, _ := newFailingEnv(t, 10)
+	env.Status.Transitions = []time.Time{time.Now().Add(-2 * time.Minute), time.Now().Add(-time.Minute)}
+	svc, logs := newHookService(t, env)
+	svc.SetHealthPolicy(environment.HealthPolicy{FlapTransitions: 3, FlapWindow: 10 * time.Minute})
+	var notified []entities.Status
+	svc.OnHealthChange(func(_ *entities.Environment, status entities.Status) {
+		notified = append(notified, status)
+	})
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	require.Len(t, *logs, 1, "only the start of flapping is logged")
+	assert.Equal(t, entities.LogLevelWarning, (*logs)[0].Level)
+	assert.Equal(t, "Health is flapping: 3 changes in 10m0s; changes are not logged until it is stable", (*logs)[0].Message)
+	require.Len(t, notified, 1)
+	assert.True(t, notified[0].Flapping)
+	assert.Equal(t, entities.HealthStatusUnhealthy, notified[0].Health)
+}
+
+func TestService_CheckHealth_FlappingChangeIsNotLogged(t *testing.T) {
+	env, _ := newFailingEnv(t, 10)
+	env.Status.Flapping = true
+	env.Status.Transitions = []time.Time{time.Now().Add(-time.Minute)}
+	svc, logs := newHookService(t, env)
+	svc.SetHealthPolicy(environment.HealthPolicy{FlapTransitions: 3, FlapWindow: 10 * time.Minute})
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	assert.Empty(t, *logs)
+}
+
+func TestService_CheckHealth_StableAgain(t *testing.T) {
+	env, _ := newFailingEnv(t, 0)
+	env.Status.Flapping = true
+	env.Status.Transitions = []time.Time{time.Now().Add(-20 * time.Minute)}
+	svc, logs := newHookService(t, env)
+	svc.SetHealthPolicy(environment.HealthPolicy{FlapTransitions: 3, FlapWindow: 10 * time.Minute})
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	require.Len(t, *logs, 1)
+	assert.Equal(t, entities.LogLevelInfo, (*logs)[0].Level)
+	assert.Equal(t, "Health is stable: healthy", (*logs)[0].Message)
+}
@@ -0,0 +1,188 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/executor"
+)
+
+// hookRun is an operation's hooks and what is needed to run and record them
+type hookRun struct {
+	env         *entities.Environment
+	hooks       *entities.Hooks
+	operation   executor.Operation
+	action      entities.ActionType
+	operationID string
+	params      map[string]string
+}
+
+// withHooks runs an operation between its hooks: the before hooks, then the
+// operation unless a before hook failed it, then the after success or after
+// failure hooks and finally the always hooks. A failing hook under the fail
+// policy fails an operation that had succeeded.
+func (s *Service) withHooks(ctx context.Context, run hookRun,
+	operation func() *executor.Result) (*executor.Result, []entities.HookResult) {
+
+	if run.hooks == nil {
+		return operation(), nil
+	}
+
+	results, err := s.runHooks(ctx, run, entities.HookPhaseBefore)
+	var result *executor.Result
+	if err != nil {
+		result = executor.Failure(err.Error())
+	} else {
+		result = operation()
+	}
+
+	after := entities.HookPhaseAfterSuccess
+	if !result.Succeeded() {
+		after = entities.HookPhaseAfterFailure
+	}
+	for _, phase := range []entities.HookPhase{after, entities.HookPhaseAlways} {
+		phaseResults, err := s.runHooks(ctx, run, phase)
+		results = append(results, phaseResults...)
+		if err != nil && result.Succeeded() {
+			failed := *result
+			failed.Status = executor.StatusFailed
+			failed.Error = err.Error()
+			result = &failed
+		}
+	}
+	return result, results
+}
+
+// runHooks runs a phase's hooks in order, stopping at the first whose
+// failure fails the operation
+func (s *Service) runHooks(ctx context.Context, run hookRun, phase entities.HookPhase) ([]entities.HookResult, error) {
+	var results []entities.HookResult
+	for _, hook := range run.hooks.Phase(phase) {
+		hook = hook.WithDefaults()
+		start := time.Now()
+		outcome := s.runHook(ctx, run, phase, hook)
+		result := entities.HookResult{
+			Name:     hook.Name,
+			Phase:    phase,
+			Success:  outcome.Succeeded(),
+			Duration: time.Since(start).Milliseconds(),
+		}
+		if !result.Success {
+			result.Error = outcome.Message()
+			result.Ignored = hook.FailPolicy == entities.HookFailPolicyIgnore
+		}
+		results = append(results, result)
+		s.logHook(ctx, run, result)
+
+		if !result.Success && !result.Ignored {
+			return results, fmt.Errorf("%s hook %q failed: %s", phase, hook.Name, result.Error)
+		}
+	}
+	return results, nil
+}
+
+// runHook runs one hook with its driver and timeout
+func (s *Service) runHook(ctx context.Context, run hookRun, phase entities.HookPhase, hook entities.Hook) *executor.Result {
+	if err := validateHook(hook); err != nil {
+		return executor.Failure(err.Error())
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(hook.Timeout)*time.Second)
+	defer cancel()
+
+	result := s.executors.Execute(ctx, hook.Type, hookRequest(run, phase, hook))
+	if !result.Succeeded() && ctx.Err() == context.DeadlineExceeded {
+		return executor.Failuref("timed out after %ds", hook.Timeout)
+	}
+	return result
+}
+
+// hookRequest is the request a hook's driver runs; scripts receive the
+// operation's parameters along with the operation and phase
+func hookRequest(run hookRun, phase entities.HookPhase, hook entities.Hook) *executor.Request {
+	params := make(map[string]string, len(run.params)+2)
+	for name, value := range run.params {
+		params[name] = value
+	}
+	params[executor.ParamOperation] = string(run.operation)
+	params[executor.ParamHookPhase] = string(phase)
+	return &executor.Request{
+		Operation:   executor.OperationHook,
+		Environment: run.env,
+		Command:     hook.Command,
+		Params:      params,
+	}
+}
+
+// validateHook checks that a hook is an SSH or HTTP step with something to
+// run
+func validateHook(hook entities.Hook) error {
+	switch hook.Type {
+	case entities.CommandTypeSSH:
+		if hook.Command.Command == "" && hook.Command.Script == nil {
+			return fmt.Errorf("SSH hook has no command")
+		}
+	case entities.CommandTypeHTTP:
+		if hook.Command.URL == "" {
+			return fmt.Errorf("HTTP hook URL is required")
+		}
+	default:
+		return fmt.Errorf("hooks must be ssh or http steps, not %q", hook.Type)
+	}
+	return nil
+}
+
+// logHook records a hook as a sub-step of its operation
+func (s *Service) logHook(ctx context.Context, run hookRun, result entities.HookResult) {
+	details := map[string]interface{}{
+		"operationId": run.operationID,
+		"hook":        result.Name,
+		"phase":       string(result.Phase),
+		"duration":    result.Duration,
+	}
+	message := fmt.Sprintf("%s hook %q completed", result.Phase, result.Name)
+	if !result.Success {
+		details["error"] = result.Error
+		message = fmt.Sprintf("%s hook %q failed: %s", result.Phase, result.Name, result.Error)
+		if result.Ignored {
+			message += " (ignored)"
+		}
+	}
+	_ = s.logService.LogEnvironmentAction(ctx, run.env, run.action, message, details)
+}
+
+// hookDetails adds the hooks that ran to an operation's log details
+func hookDetails(details map[string]interface{}, results []entities.HookResult) map[string]interface{} {
+	if len(results) > 0 {
+		details["hooks"] = results
+	}
+	return details
+}
+
+// planHooks adds a phase's hook steps to a plan
+func (s *Service) planHooks(ctx context.Context, plan *entities.OperationPlan, run hookRun, phase entities.HookPhase) {
+	for _, hook := range run.hooks.Phase(phase) {
+		hook = hook.WithDefaults()
+		if err := validateHook(hook); err != nil {
+			plan.AddCheck("hook", hook.Name, err)
+			continue
+		}
+		first := len(plan.Steps)
+		s.planOperation(ctx, hook.Type, hookRequest(run, phase, hook), plan)
+		for i := first; i < len(plan.Steps); i++ {
+			plan.Steps[i].Hook = phase
+		}
+	}
+}
+
+// planWithHooks adds the operation's steps between its hooks' steps
+func (s *Service) planWithHooks(ctx context.Context, plan *entities.OperationPlan, run hookRun, operation func()) {
+	s.planHooks(ctx, plan, run, entities.HookPhaseBefore)
+	operation()
+	for _, phase := range []entities.HookPhase{
+		entities.HookPhaseAfterSuccess, entities.HookPhaseAfterFailure, entities.HookPhaseAlways,
+	} {
+		s.planHooks(ctx, plan, run, phase)
+	}
+}
@@ -0,0 +1,233 @@
+package environment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/assertion"
+)
+
+// httpResponse is a fully read HTTP command response
+type httpResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// assertionResponse exposes the response to the assertion engine
+func (r *httpResponse) assertionResponse() assertion.Response {
+	return assertion.Response{StatusCode: r.statusCode, Header: r.header, Body: r.body}
+}
+
+// newCommandClient creates the HTTP client used for commands. Every redirect
+// is checked against the SSRF rules.
+func (s *Service) newCommandClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// Validate each redirect URL
+			if err := s.validateURL(req.URL.String()); err != nil {
+				return fmt.Errorf("redirect URL validation failed: %w", err)
+			}
+			// Limit redirect chain
+			if len(via) >= 5 {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		},
+	}
+}
+
+// sendWithRetry sends the request, retrying network errors and retryable
+// status codes with backoff until the policy's attempts are used up. The last
+// response is returned even when its status is still retryable.
+func (s *Service) sendWithRetry(ctx context.Context, client *http.Client, method, rawURL string,
+	headers map[string]string, body []byte, policy *entities.RetryPolicy) (*httpResponse, error) {
+
+	attempts := policy.Attempts()
+	for attempt := 1; ; attempt++ {
+		resp, err := sendHTTPRequest(ctx, client, method, rawURL, headers, body)
+
+		retryable := (err != nil && ctx.Err() == nil) || (err == nil && policy.IsRetryable(resp.statusCode))
+		if !retryable || attempt >= attempts {
+			return resp, err
+		}
+
+		delay := policy.Backoff(attempt)
+		if resp != nil {
+			// Honour the server's Retry-After within the policy's bound
+			if after := retryAfter(resp.header); after > delay {
+				delay = after
+				if maxBackoff := policy.MaxBackoff(); delay > maxBackoff {
+					delay = maxBackoff
+				}
+			}
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return resp, err
+		}
+	}
+}
+
+// sendHTTPRequest sends one request and reads the whole response
+func sendHTTPRequest(ctx context.Context, client *http.Client, method, rawURL string,
+	headers map[string]string, body []byte) (*httpResponse, error) {
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create request: %v", err)
+	}
+
+	// Add headers
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	// Set default Content-Type if not provided and body exists
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read response: %v", err)
+	}
+
+	return &httpResponse{statusCode: resp.StatusCode, header: resp.Header, body: responseBody}, nil
+}
+
+// retryAfter parses a Retry-After header given in seconds
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// pollJob follows the status URL of an async command until the job succeeds,
+// fails or runs out of time
+func (s *Service) pollJob(ctx context.Context, client *http.Client, cmd entities.CommandDetails,
+	initial *httpResponse) (string, bool) {
+
+	policy := cmd.Async
+	statusURL, err := jobStatusURL(cmd.URL, policy, initial)
+	if err != nil {
+		return fmt.Sprintf("Async job: %v", err), false
+	}
+	// The status URL comes from the remote side, so it gets the same SSRF checks
+	if err := s.validateURL(statusURL); err != nil {
+		return fmt.Sprintf("Status URL validation failed: %v", err), false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, policy.Timeout())
+	defer cancel()
+
+	for {
+		select {
+		case <-time.After(policy.PollInterval()):
+		case <-ctx.Done():
+			return fmt.Sprintf("Async job did not finish within %s", policy.Timeout()), false
+		}
+
+		resp, err := s.sendWithRetry(ctx, client, http.MethodGet, statusURL, cmd.Headers, nil, cmd.Retry)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Sprintf("Async job did not finish within %s", policy.Timeout()), false
+			}
+			return fmt.Sprintf("Polling job status failed: %v", err), false
+		}
+		if resp.statusCode < 200 || resp.statusCode >= 300 {
+			if cmd.Retry.IsRetryable(resp.statusCode) {
+				// Status endpoint briefly unavailable, keep polling
+				continue
+			}
+			return fmt.Sprintf("Job status request failed with status %d: %s", resp.statusCode, string(resp.body)), false
+		}
+
+		switch jobState(policy, resp) {
+		case entities.JobSucceeded:
+			if err := assertion.Evaluate(cmd.Success, resp.assertionResponse()); err != nil {
+				return fmt.Sprintf("Success assertion failed: %v", err), false
+			}
+			return string(resp.body), true
+		case entities.JobFailed:
+			return fmt.Sprintf("Async job failed: %s", string(resp.body)), false
+		}
+	}
+}
+
+// jobStatusURL finds the job status URL in the initial response, resolved
+// against the command URL
+func jobStatusURL(commandURL string, policy *entities.AsyncPolicy, initial *httpResponse) (string, error) {
+	var raw string
+	if policy.StatusURLPath != "" {
+		var data interface{}
+		if err := json.Unmarshal(initial.body, &data); err != nil {
+			return "", fmt.Errorf("response is not JSON: %w", err)
+		}
+		value, ok := assertion.Lookup(data, policy.StatusURLPath)
+		if !ok {
+			return "", fmt.Errorf("status URL not found at %s", policy.StatusURLPath)
+		}
+		raw, _ = value.(string)
+	} else {
+		raw = initial.header.Get("Location")
+	}
+	if raw == "" {
+		return "", fmt.Errorf("response has no status URL")
+	}
+
+	base, err := url.Parse(commandURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid status URL: %w", err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// jobState classifies a poll response. Without a status path the job is done
+// once the status endpoint stops answering 202 Accepted.
+func jobState(policy *entities.AsyncPolicy, resp *httpResponse) entities.JobState {
+	if policy.StatusPath == "" {
+		if resp.statusCode == http.StatusAccepted {
+			return entities.JobRunning
+		}
+		return entities.JobSucceeded
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(resp.body, &data); err != nil {
+		return entities.JobRunning
+	}
+	value, ok := assertion.Lookup(data, policy.StatusPath)
+	if !ok {
+		return entities.JobRunning
+	}
+	return policy.Classify(value)
+}
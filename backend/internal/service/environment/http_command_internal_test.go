@@ -0,0 +1,251 @@
+package environment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHTTPCommandService() *Service {
+	return &Service{allowedHosts: []string{"127.0.0.1"}}
+}
+
+// fastRetry retries quickly so tests stay fast
+func fastRetry(attempts int) *entities.RetryPolicy {
+	return &entities.RetryPolicy{MaxAttempts: attempts, BackoffMs: 1, MaxBackoffMs: 5}
+}
+
+func TestExecuteHTTPCommand_RetriesTransientFailures(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{URL: srv.URL, HTTPPolicy: entities.HTTPPolicy{Retry: fastRetry(3)}}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.True(t, ok, msg)
+	assert.Equal(t, "ok", msg)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestExecuteHTTPCommand_GivesUpAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{URL: srv.URL, HTTPPolicy: entities.HTTPPolicy{Retry: fastRetry(2)}}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "status 502")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestExecuteHTTPCommand_DoesNotRetryOtherStatuses(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{URL: srv.URL, HTTPPolicy: entities.HTTPPolicy{Retry: fastRetry(5)}}
+	_, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestExecuteHTTPCommand_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	cmd := entities.CommandDetails{URL: srv.URL, HTTPPolicy: entities.HTTPPolicy{TimeoutSeconds: 1}}
+	start := time.Now()
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "Request failed")
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestExecuteHTTPCommand_AsyncLocationHeader(t *testing.T) {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/deploy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/jobs/42")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/jobs/42", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) < 3 {
+			_, _ = w.Write([]byte(`{"job":{"state":"running"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"job":{"state":"SUCCEEDED"}}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{
+		URL: srv.URL + "/deploy",
+		HTTPPolicy: entities.HTTPPolicy{Async: &entities.AsyncPolicy{
+			PollIntervalMs: 1,
+			StatusPath:     "$.job.state",
+			SuccessValues:  []string{"succeeded"},
+			FailureValues:  []string{"failed"},
+		}},
+	}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.True(t, ok, msg)
+	assert.Contains(t, msg, "SUCCEEDED")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&polls))
+}
+
+func TestExecuteHTTPCommand_AsyncJSONPathStatusURLFailure(t *testing.T) {
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/deploy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"links":{"status":"` + srv.URL + `/jobs/7"}}`))
+	})
+	mux.HandleFunc("/jobs/7", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"failed","reason":"disk full"}`))
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{
+		URL: srv.URL + "/deploy",
+		HTTPPolicy: entities.HTTPPolicy{Async: &entities.AsyncPolicy{
+			StatusURLPath:  "$.links.status",
+			PollIntervalMs: 1,
+			StatusPath:     "status",
+			SuccessValues:  []string{"done"},
+			FailureValues:  []string{"failed"},
+		}},
+	}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "Async job failed")
+	assert.Contains(t, msg, "disk full")
+}
+
+func TestExecuteHTTPCommand_AsyncWithoutStatusPath(t *testing.T) {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/deploy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/jobs/1")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/jobs/1", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) < 2 {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		_, _ = w.Write([]byte("finished"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{
+		URL:        srv.URL + "/deploy",
+		HTTPPolicy: entities.HTTPPolicy{Async: &entities.AsyncPolicy{PollIntervalMs: 1}},
+	}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.True(t, ok, msg)
+	assert.Equal(t, "finished", msg)
+}
+
+func TestExecuteHTTPCommand_AsyncMissingStatusURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{
+		URL:        srv.URL,
+		HTTPPolicy: entities.HTTPPolicy{Async: &entities.AsyncPolicy{PollIntervalMs: 1}},
+	}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "no status URL")
+}
+
+func TestExecuteHTTPCommand_AsyncStatusURLBlocked(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "http://169.254.169.254/latest/meta-data")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{
+		URL:        srv.URL,
+		HTTPPolicy: entities.HTTPPolicy{Async: &entities.AsyncPolicy{PollIntervalMs: 1}},
+	}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "Status URL validation failed")
+}
+
+func TestExecuteHTTPCommand_AsyncTimeout(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/deploy", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/jobs/1")
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("/jobs/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"running"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cmd := entities.CommandDetails{
+		URL: srv.URL + "/deploy",
+		HTTPPolicy: entities.HTTPPolicy{Async: &entities.AsyncPolicy{
+			PollIntervalMs: 50,
+			TimeoutSeconds: 1,
+			StatusPath:     "status",
+			SuccessValues:  []string{"done"},
+		}},
+	}
+	msg, ok := newHTTPCommandService().executeHTTPCommand(context.Background(), cmd)
+
+	assert.False(t, ok)
+	assert.Contains(t, msg, "did not finish within 1s")
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	assert.Equal(t, time.Duration(0), retryAfter(header))
+	header.Set("Retry-After", "3")
+	assert.Equal(t, 3*time.Second, retryAfter(header))
+	header.Set("Retry-After", "Wed, 21 Oct 2015 07:28:00 GMT")
+	assert.Equal(t, time.Duration(0), retryAfter(header))
+}
@@ -348,6 +348,25 @@ func TestBuildSSHTarget_PasswordCredentials(t *testing.T) {
 	assert.Equal(t, "s3cr3t", target.Password)
 }
 
+func TestBuildSSHTarget_CommandTimeout(t *testing.T) {
+	svc := &Service{}
+
+	env := &entities.Environment{
+		Target:      entities.Target{Host: "host.local", Port: 22},
+		Credentials: entities.CredentialRef{Type: "password", Username: "deploy"},
+		Metadata:    map[string]interface{}{"password": "s3cr3t"},
+	}
+
+	target, err := svc.buildSSHTarget(env)
+	assert.NoError(t, err)
+	assert.Zero(t, target.CommandTimeout, "the manager's default applies")
+
+	env.Limits = &entities.OperationLimits{CommandTimeoutSeconds: 900}
+	target, err = svc.buildSSHTarget(env)
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, target.CommandTimeout)
+}
+
 func TestBuildSSHTarget_MissingPassword(t *testing.T) {
 	svc := &Service{}
 
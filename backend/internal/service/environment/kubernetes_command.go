@@ -0,0 +1,187 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/docker"
+	"app-env-manager/internal/service/executor"
+	"app-env-manager/internal/service/kubernetes"
+)
+
+// kubernetesPollInterval is how often a rollout's progress is read
+const kubernetesPollInterval = 2 * time.Second
+
+// kubernetesWorkload connects to the environment's API server with its
+// kubeconfig or token and returns the workload its operations target
+func (s *Service) kubernetesWorkload(env *entities.Environment) (*kubernetes.Client, kubernetes.WorkloadRef, error) {
+	if env.Commands.Kubernetes == nil {
+		return nil, kubernetes.WorkloadRef{}, fmt.Errorf("kubernetes commands require a kubernetes configuration")
+	}
+	config := env.Commands.Kubernetes.WithDefaults()
+	if config.Kind != entities.KubernetesKindDeployment && config.Kind != entities.KubernetesKindStatefulSet {
+		return nil, kubernetes.WorkloadRef{}, fmt.Errorf("unsupported workload kind %q", config.Kind)
+	}
+	if err := kubernetes.ValidateName(config.Name); err != nil {
+		return nil, kubernetes.WorkloadRef{}, err
+	}
+
+	var conn kubernetes.Config
+	secret, _ := env.Metadata[kubernetesSecretKey(env.Credentials.Type)].(string)
+	switch env.Credentials.Type {
+	case entities.CredentialTypeKubeconfig:
+		if secret == "" {
+			return nil, kubernetes.WorkloadRef{}, fmt.Errorf("kubeconfig not found in environment configuration")
+		}
+		parsed, err := kubernetes.ParseKubeconfig([]byte(secret), config.Context)
+		if err != nil {
+			return nil, kubernetes.WorkloadRef{}, err
+		}
+		conn = parsed
+	case entities.CredentialTypeToken:
+		if secret == "" {
+			return nil, kubernetes.WorkloadRef{}, fmt.Errorf("Kubernetes token not found in environment configuration")
+		}
+		conn.Token = secret
+	default:
+		return nil, kubernetes.WorkloadRef{}, fmt.Errorf("unsupported credential type for kubernetes: %s", env.Credentials.Type)
+	}
+
+	// Settings on the environment win over the kubeconfig's
+	if config.Server != "" {
+		conn.Server = config.Server
+	}
+	if config.CACert != "" {
+		conn.CACert = []byte(config.CACert)
+	}
+	if config.InsecureSkipVerify {
+		conn.InsecureSkipVerify = true
+	}
+	if conn.Server == "" {
+		return nil, kubernetes.WorkloadRef{}, fmt.Errorf("no Kubernetes API server configured")
+	}
+	if err := s.validateURL(conn.Server); err != nil {
+		return nil, kubernetes.WorkloadRef{}, fmt.Errorf("Kubernetes API server: %w", err)
+	}
+
+	namespace := config.Namespace
+	if namespace == "" {
+		namespace = conn.Namespace
+	}
+	if namespace == "" {
+		namespace = entities.DefaultKubernetesNamespace
+	}
+	if err := kubernetes.ValidateName(namespace); err != nil {
+		return nil, kubernetes.WorkloadRef{}, err
+	}
+
+	client, err := kubernetes.NewClient(conn)
+	if err != nil {
+		return nil, kubernetes.WorkloadRef{}, err
+	}
+	return client, kubernetes.WorkloadRef{Kind: string(config.Kind), Namespace: namespace, Name: config.Name}, nil
+}
+
+// kubernetesSecretKey is the metadata key holding the secret for a
+// credential type
+func kubernetesSecretKey(credentialType string) string {
+	if credentialType == entities.CredentialTypeKubeconfig {
+		return "kubeconfig"
+	}
+	return "kubernetesToken"
+}
+
+// executeKubernetesRestart rolls every pod of the workload and waits for
+// the rollout to complete
+func (s *Service) executeKubernetesRestart(ctx context.Context, env *entities.Environment) *executor.Result {
+	client, ref, err := s.kubernetesWorkload(env)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+	ctx, cancel := context.WithTimeout(ctx, kubernetesRolloutTimeout(env))
+	defer cancel()
+
+	generation, err := client.RolloutRestart(ctx, ref, time.Now())
+	if err != nil {
+		return executor.Failuref("restart %s: %v", ref, err)
+	}
+	if err := client.WaitForRollout(ctx, ref, generation, kubernetesPollInterval); err != nil {
+		return executor.Failure(err.Error())
+	}
+	return kubernetesResult(ref, generation)
+}
+
+// executeKubernetesUpgrade sets the container's image to the version and
+// waits for the rollout to complete
+func (s *Service) executeKubernetesUpgrade(ctx context.Context, env *entities.Environment, version string) *executor.Result {
+	if err := docker.ValidateTag(version); err != nil {
+		return executor.Failure(err.Error())
+	}
+	client, ref, err := s.kubernetesWorkload(env)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+	ctx, cancel := context.WithTimeout(ctx, kubernetesRolloutTimeout(env))
+	defer cancel()
+
+	container, image, err := kubernetesUpgradeImage(ctx, client, ref, *env.Commands.Kubernetes, version)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+	generation, err := client.SetImage(ctx, ref, container, image)
+	if err != nil {
+		return executor.Failuref("set image of %s: %v", ref, err)
+	}
+	if err := client.WaitForRollout(ctx, ref, generation, kubernetesPollInterval); err != nil {
+		return executor.Failure(err.Error())
+	}
+	result := kubernetesResult(ref, generation)
+	result.Artifacts["container"] = container
+	result.Artifacts["image"] = image
+	return result
+}
+
+// kubernetesResult is a completed rollout of the workload
+func kubernetesResult(ref kubernetes.WorkloadRef, generation int64) *executor.Result {
+	result := executor.Success("")
+	result.Artifacts = map[string]interface{}{"workload": ref.String(), "generation": generation}
+	return result
+}
+
+// kubernetesUpgradeImage resolves the container to upgrade, the configured
+// one or the workload's only container, and the image it is upgraded to
+func kubernetesUpgradeImage(ctx context.Context, client *kubernetes.Client, ref kubernetes.WorkloadRef,
+	config entities.KubernetesConfig, version string) (string, string, error) {
+
+	workload, err := client.Get(ctx, ref)
+	if err != nil {
+		return "", "", fmt.Errorf("get %s: %w", ref, err)
+	}
+
+	var container kubernetes.Container
+	if config.Container != "" {
+		var ok bool
+		if container, ok = workload.Container(config.Container); !ok {
+			return "", "", fmt.Errorf("%s has no container %q", ref, config.Container)
+		}
+	} else {
+		containers := workload.Spec.Template.Spec.Containers
+		if len(containers) != 1 {
+			return "", "", fmt.Errorf("%s has %d containers; configure the container to upgrade", ref, len(containers))
+		}
+		container = containers[0]
+	}
+
+	if config.Image != "" {
+		return container.Name, strings.ReplaceAll(config.Image, "{VERSION}", version), nil
+	}
+	image, err := imageForVersion(container.Name, container.Image, version)
+	return container.Name, image, err
+}
+
+func kubernetesRolloutTimeout(env *entities.Environment) time.Duration {
+	return time.Duration(env.Commands.Kubernetes.WithDefaults().RolloutTimeout) * time.Second
+}
@@ -0,0 +1,176 @@
+package environment
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/service/executor"
+)
+
+// operationHistory remembers when operations were started on each environment
+// so rate limits and cooldowns can be enforced. It is kept in memory, so the
+// limits apply per server and are forgotten when it restarts.
+type operationHistory struct {
+	mu     sync.Mutex
+	starts map[string][]time.Time // by environment and operation, oldest first
+	now    func() time.Time
+}
+
+func newOperationHistory() *operationHistory {
+	return &operationHistory{starts: make(map[string][]time.Time), now: time.Now}
+}
+
+// check returns how long until the limit allows another run, zero when it
+// does now
+func (h *operationHistory) check(key string, limit entities.OperationLimit) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.wait(key, limit, h.now())
+}
+
+// reserve records a run unless the limit refuses it, in which case it
+// returns how long until the limit allows one. Runs without a limit are not
+// recorded.
+func (h *operationHistory) reserve(key string, limit entities.OperationLimit) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !limit.Limited() {
+		delete(h.starts, key)
+		return 0
+	}
+	now := h.now()
+	if wait := h.wait(key, limit, now); wait > 0 {
+		return wait
+	}
+	h.starts[key] = append(h.starts[key], now)
+	return 0
+}
+
+// wait forgets runs too old to matter and returns how long until the limit
+// allows another run. The caller holds the lock.
+func (h *operationHistory) wait(key string, limit entities.OperationLimit, now time.Time) time.Duration {
+	if !limit.Limited() {
+		return 0
+	}
+
+	window, cooldown := limit.Window(), limit.Cooldown()
+	horizon := window
+	if cooldown > horizon {
+		horizon = cooldown
+	}
+	starts := h.starts[key]
+	for len(starts) > 0 && now.Sub(starts[0]) >= horizon {
+		starts = starts[1:]
+	}
+	h.starts[key] = starts
+
+	var wait time.Duration
+	if cooldown > 0 && len(starts) > 0 {
+		wait = starts[len(starts)-1].Add(cooldown).Sub(now)
+	}
+	if limit.MaxRuns > 0 {
+		var inWindow []time.Time
+		for _, start := range starts {
+			if now.Sub(start) < window {
+				inWindow = append(inWindow, start)
+			}
+		}
+		// The oldest runs have to leave the window until one more fits
+		if excess := len(inWindow) - limit.MaxRuns; excess >= 0 {
+			if untilFree := inWindow[excess].Add(window).Sub(now); untilFree > wait {
+				wait = untilFree
+			}
+		}
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// historyKey identifies an environment's operation in the history
+func historyKey(env *entities.Environment, action entities.ActionType) string {
+	return env.ID.Hex() + "/" + string(action)
+}
+
+// rateLimitError is the error for an operation its limit refuses for wait
+func rateLimitError(action entities.ActionType, wait time.Duration) error {
+	if wait <= 0 {
+		return nil
+	}
+	return errors.NewRateLimitError(string(action), int(math.Ceil(wait.Seconds())))
+}
+
+// CheckOperationLimit returns a rate limit error when the environment's rate
+// limit or cooldown would refuse a restart, upgrade or custom action now. It
+// records nothing, so requests can be refused before they are queued for
+// approval.
+func (s *Service) CheckOperationLimit(ctx context.Context, id string, action entities.ActionType) error {
+	env, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return rateLimitError(action, s.history.check(historyKey(env, action), env.Limits.For(action)))
+}
+
+// Reservation is a run of an operation already recorded against the
+// environment's rate limit and cooldown
+type Reservation struct {
+	key string
+}
+
+// reservationKey is the context key of a Reservation
+type reservationKey struct{}
+
+// ReserveOperation records the start of a restart, upgrade or custom action
+// unless the environment's rate limit or cooldown refuses it. Checking and
+// recording is one step, so of two concurrent requests only one gets the
+// slot. The operation, run with WithReservation, does not record it again.
+func (s *Service) ReserveOperation(ctx context.Context, id string, action entities.ActionType) (*Reservation, error) {
+	env, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	key := historyKey(env, action)
+	if err := rateLimitError(action, s.history.reserve(key, env.Limits.For(action))); err != nil {
+		return nil, err
+	}
+	return &Reservation{key: key}, nil
+}
+
+// WithReservation returns a context whose operation uses the reservation
+// instead of recording its own run
+func WithReservation(ctx context.Context, reservation *Reservation) context.Context {
+	return context.WithValue(ctx, reservationKey{}, reservation)
+}
+
+// reserveOperation records the start of an operation unless the
+// environment's rate limit or cooldown refuses it, or the context carries
+// its reservation
+func (s *Service) reserveOperation(ctx context.Context, env *entities.Environment, action entities.ActionType) error {
+	key := historyKey(env, action)
+	if reservation, ok := ctx.Value(reservationKey{}).(*Reservation); ok && reservation.key == key {
+		return nil
+	}
+	return rateLimitError(action, s.history.reserve(key, env.Limits.For(action)))
+}
+
+// withTimeout runs an operation's command within the environment's timeout
+// for the operation
+func withTimeout(ctx context.Context, env *entities.Environment, action entities.ActionType,
+	run func(ctx context.Context) *executor.Result) *executor.Result {
+
+	timeout := env.Limits.Timeout(action)
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := run(opCtx)
+	if !result.Succeeded() && ctx.Err() == nil && opCtx.Err() == context.DeadlineExceeded {
+		return executor.Failuref("timed out after %ds", int(timeout.Seconds()))
+	}
+	return result
+}
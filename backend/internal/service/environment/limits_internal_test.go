@@ -0,0 +1,56 @@
+package environment
+
+import (
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestHistory returns a history whose clock is moved by advancing *now
+func newTestHistory() (*operationHistory, *time.Time) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	history := newOperationHistory()
+	history.now = func() time.Time { return now }
+	return history, &now
+}
+
+func TestOperationHistory_MaxRuns(t *testing.T) {
+	history, now := newTestHistory()
+	limit := entities.OperationLimit{MaxRuns: 3, WindowSeconds: 3600}
+
+	for i := 0; i < 3; i++ {
+		assert.Zero(t, history.reserve("env/restart", limit))
+		*now = now.Add(10 * time.Minute)
+	}
+
+	// The first run leaves the window an hour after it started
+	assert.Equal(t, 30*time.Minute, history.reserve("env/restart", limit))
+	assert.Equal(t, 30*time.Minute, history.check("env/restart", limit))
+	assert.Zero(t, history.check("env/upgrade", limit), "operations are limited separately")
+
+	*now = now.Add(30 * time.Minute)
+	assert.Zero(t, history.reserve("env/restart", limit))
+}
+
+func TestOperationHistory_Cooldown(t *testing.T) {
+	history, now := newTestHistory()
+	limit := entities.OperationLimit{CooldownSeconds: 300}
+
+	assert.Zero(t, history.reserve("env/restart", limit))
+	*now = now.Add(time.Minute)
+	assert.Equal(t, 4*time.Minute, history.reserve("env/restart", limit))
+
+	*now = now.Add(4 * time.Minute)
+	assert.Zero(t, history.reserve("env/restart", limit))
+}
+
+func TestOperationHistory_Unlimited(t *testing.T) {
+	history, _ := newTestHistory()
+
+	for i := 0; i < 10; i++ {
+		assert.Zero(t, history.reserve("env/restart", entities.OperationLimit{TimeoutSeconds: 60}))
+	}
+	assert.Empty(t, history.starts, "unlimited operations are not remembered")
+}
@@ -0,0 +1,55 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/health"
+)
+
+// checkProbes runs an environment's probes concurrently and aggregates
+// their results. The environment's response time threshold applies to the
+// slowest probe.
+func (s *Service) checkProbes(ctx context.Context, env *entities.Environment) *health.CheckResult {
+	probes := env.HealthCheck.Probes
+	results := make([]*health.CheckResult, len(probes))
+
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe entities.HealthProbe) {
+			defer wg.Done()
+			results[i] = s.checkProbe(ctx, env, probe)
+		}(i, probe)
+	}
+	wg.Wait()
+
+	result := health.Aggregate(env.HealthCheck.Aggregation, probes, results)
+	health.DegradeIfSlow(result, env.HealthCheck.DegradedAboveMs)
+	return result
+}
+
+// checkProbe runs one probe as the environment's health check
+func (s *Service) checkProbe(ctx context.Context, env *entities.Environment, probe entities.HealthProbe) *health.CheckResult {
+	probeEnv := *env
+	probeEnv.HealthCheck = probe.Check
+	probeEnv.HealthCheck.Enabled = true
+	probeEnv.HealthCheck.Probes = nil
+	if probeEnv.HealthCheck.Timeout == 0 {
+		probeEnv.HealthCheck.Timeout = env.HealthCheck.Timeout
+	}
+
+	if driver, ok := driverHealthChecks[probeEnv.HealthCheck.Type]; ok {
+		return s.probeHealth(ctx, driver, &probeEnv)
+	}
+	result, err := s.healthChecker.CheckHealth(ctx, &probeEnv)
+	if err != nil {
+		return &health.CheckResult{
+			Status:  entities.HealthStatusUnhealthy,
+			Message: fmt.Sprintf("Health check failed: %v", err),
+		}
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package environment_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newProbeEnv returns an environment checked by a healthy web probe, an API
+// probe answering status and a worker probe on a closed port
+func newProbeEnv(t *testing.T, status int, aggregation *entities.ProbeAggregation) *entities.Environment {
+	t.Helper()
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(web.Close)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(api.Close)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	workerPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	expect200 := entities.ValidationConfig{Type: "statusCode", Value: 200}
+
+	env := newSampleEnv(primitive.NewObjectID())
+	env.Target = entities.Target{Host: "127.0.0.1", Port: 22}
+	env.HealthCheck = entities.HealthCheckConfig{
+		Enabled: true,
+		Timeout: 2,
+		Probes: []entities.HealthProbe{
+			{Name: "web", Check: entities.HealthCheckConfig{Endpoint: web.URL, Method: "GET", Validation: expect200}},
+			{Name: "api", Check: entities.HealthCheckConfig{Endpoint: api.URL, Method: "GET", Validation: expect200}},
+			{Name: "worker", Criticality: entities.ProbeCriticalityNonCritical, Check: entities.HealthCheckConfig{
+				Type: entities.HealthCheckTypeTCP,
+				TCP:  &entities.TCPCheckConfig{Port: workerPort},
+			}},
+		},
+		Aggregation: aggregation,
+	}
+	return env
+}
+
+func TestService_CheckHealth_Probes(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiStatus   int
+		aggregation *entities.ProbeAggregation
+		health      entities.HealthStatus
+		failedProbe string
+	}{
+		{"worker down", http.StatusOK, nil, entities.HealthStatusDegraded, "worker"},
+		{"api down", http.StatusServiceUnavailable, nil, entities.HealthStatusUnhealthy, "api"},
+		{"api down, any", http.StatusServiceUnavailable,
+			&entities.ProbeAggregation{Policy: entities.AggregationPolicyAny}, entities.HealthStatusDegraded, "api"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newProbeEnv(t, tt.apiStatus, tt.aggregation)
+			repo := new(MockEnvironmentRepository)
+			svc := newDockerService(t, repo, env)
+
+			require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+			status := updatedStatus(t, repo)
+			assert.Equal(t, tt.health, status.Health)
+			assert.Equal(t, tt.failedProbe, status.FailedProbe)
+			require.Len(t, status.Probes, 3)
+			assert.Equal(t, "web", status.Probes[0].Name)
+			assert.Equal(t, entities.HealthStatusHealthy, status.Probes[0].Health)
+			assert.Equal(t, entities.ProbeCriticalityNormal, status.Probes[1].Criticality)
+			assert.Equal(t, entities.HealthStatusUnhealthy, status.Probes[2].Health, "the worker's port is closed")
+			assert.Equal(t, entities.ProbeCriticalityNonCritical, status.Probes[2].Criticality)
+		})
+	}
+}
+
+func TestService_CheckHealth_ProbesLogFailedProbe(t *testing.T) {
+	env := newProbeEnv(t, http.StatusServiceUnavailable, nil)
+	env.Status = entities.Status{Health: entities.HealthStatusHealthy}
+	svc, logs := newHookService(t, env)
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	require.Len(t, *logs, 1)
+	entry := (*logs)[0]
+	assert.Equal(t, "api", entry.Details["failedProbe"])
+	assert.Contains(t, entry.Message, `"api" is unhealthy`)
+	assert.Contains(t, entry.Message, strconv.Itoa(http.StatusServiceUnavailable))
+}
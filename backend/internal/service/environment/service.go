@@ -1,7 +1,6 @@
 package environment
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -9,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +16,8 @@ import (
 	"app-env-manager/internal/domain/entities"
 	"app-env-manager/internal/domain/errors"
 	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/assertion"
+	"app-env-manager/internal/service/executor"
 	"app-env-manager/internal/service/health"
 	"app-env-manager/internal/service/log"
 	"app-env-manager/internal/service/ssh"
@@ -30,8 +32,16 @@ type Service struct {
 	healthChecker *health.Checker
 	logService    *log.Service
 	allowedHosts  []string // hostnames exempt from SSRF checks
+	executors     *executor.Registry
+	history       *operationHistory // recent operation starts, for rate limits
+	healthChanged []HealthChangeListener
+	healthPolicy  HealthPolicy
 }
 
+// HealthChangeListener is told about an environment whose health changed,
+// with its new status
+type HealthChangeListener func(env *entities.Environment, status entities.Status)
+
 // NewService creates a new environment service
 func NewService(
 	repo interfaces.EnvironmentRepository,
@@ -41,14 +51,24 @@ func NewService(
 	logService *log.Service,
 	allowedHosts []string,
 ) *Service {
-	return &Service{
+	s := &Service{
 		repo:          repo,
 		auditRepo:     auditRepo,
 		sshManager:    sshManager,
 		healthChecker: healthChecker,
 		logService:    logService,
 		allowedHosts:  allowedHosts,
+		executors:     executor.NewRegistry(),
+		history:       newOperationHistory(),
 	}
+	s.registerBuiltinExecutors()
+	return s
+}
+
+// OnHealthChange registers a listener called after a health check changes an
+// environment's health. Listeners are registered before health checks run.
+func (s *Service) OnHealthChange(listener HealthChangeListener) {
+	s.healthChanged = append(s.healthChanged, listener)
 }
 
 // CreateEnvironmentRequest represents a request to create an environment
@@ -61,6 +81,10 @@ type CreateEnvironmentRequest struct {
 	HealthCheck    entities.HealthCheckConfig  `json:"healthCheck"`
 	Commands       entities.CommandConfig      `json:"commands"`
 	UpgradeConfig  entities.UpgradeConfig      `json:"upgradeConfig"`
+	Limits         *entities.OperationLimits   `json:"limits,omitempty"`
+	RequiresApproval bool                      `json:"requiresApproval"`
+	Labels         map[string]string           `json:"labels,omitempty"`
+	LogSources     []entities.LogSource        `json:"logSources,omitempty" validate:"dive"`
 	Metadata       map[string]interface{}      `json:"metadata,omitempty"`
 }
 
@@ -74,11 +98,19 @@ type UpdateEnvironmentRequest struct {
 	HealthCheck    *entities.HealthCheckConfig  `json:"healthCheck,omitempty"`
 	Commands       *entities.CommandConfig      `json:"commands,omitempty"`
 	UpgradeConfig  *entities.UpgradeConfig      `json:"upgradeConfig,omitempty"`
+	Limits         *entities.OperationLimits    `json:"limits,omitempty"`
+	RequiresApproval *bool                      `json:"requiresApproval,omitempty"`
+	Labels         map[string]string            `json:"labels,omitempty"`
+	LogSources     []entities.LogSource         `json:"logSources,omitempty" validate:"omitempty,dive"`
 	Metadata       map[string]interface{}       `json:"metadata,omitempty"`
 }
 
 // CreateEnvironment creates a new environment
 func (s *Service) CreateEnvironment(ctx context.Context, req CreateEnvironmentRequest) (*entities.Environment, error) {
+	if err := validateCommands(req.Commands); err != nil {
+		return nil, err
+	}
+
 	// Check for duplicates
 	existing, _ := s.repo.GetByName(ctx, req.Name)
 	if existing != nil {
@@ -95,6 +127,10 @@ func (s *Service) CreateEnvironment(ctx context.Context, req CreateEnvironmentRe
 		HealthCheck:    req.HealthCheck,
 		Commands:       req.Commands,
 		UpgradeConfig:  req.UpgradeConfig,
+		Limits:         req.Limits,
+		RequiresApproval: req.RequiresApproval,
+		Labels:         req.Labels,
+		LogSources:     req.LogSources,
 		Status: entities.Status{
 			Health:    entities.HealthStatusUnknown,
 			LastCheck: time.Now(),
@@ -130,6 +166,16 @@ func (s *Service) CreateEnvironment(ctx context.Context, req CreateEnvironmentRe
 	return env, nil
 }
 
+// validateCommands rejects command configurations whose allowlist rules do
+// not compile, so a bad rule is reported when it is saved rather than when a
+// command is first checked against it
+func validateCommands(commands entities.CommandConfig) error {
+	if err := ssh.ValidateRules(commands.Allowlist); err != nil {
+		return errors.NewValidationError("commands.allowlist", err.Error())
+	}
+	return nil
+}
+
 // GetEnvironment retrieves an environment by ID
 func (s *Service) GetEnvironment(ctx context.Context, id string) (*entities.Environment, error) {
 	return s.repo.GetByID(ctx, id)
@@ -142,6 +188,10 @@ func (s *Service) ListEnvironments(ctx context.Context, filter interfaces.ListFi
 
 // UpdateEnvironment updates an environment
 func (s *Service) UpdateEnvironment(ctx context.Context, id string, req CreateEnvironmentRequest) (*entities.Environment, error) {
+	if err := validateCommands(req.Commands); err != nil {
+		return nil, err
+	}
+
 	// Get existing environment
 	env, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -166,6 +216,10 @@ func (s *Service) UpdateEnvironment(ctx context.Context, id string, req CreateEn
 	env.HealthCheck = req.HealthCheck
 	env.Commands = req.Commands
 	env.UpgradeConfig = req.UpgradeConfig
+	env.Limits = req.Limits
+	env.RequiresApproval = req.RequiresApproval
+	env.Labels = req.Labels
+	env.LogSources = req.LogSources
 	env.Metadata = req.Metadata
 
 	// Update in repository
@@ -186,6 +240,12 @@ func (s *Service) UpdateEnvironment(ctx context.Context, id string, req CreateEn
 
 // UpdateEnvironmentPartial updates only provided fields of an environment
 func (s *Service) UpdateEnvironmentPartial(ctx context.Context, id string, req UpdateEnvironmentRequest) (*entities.Environment, error) {
+	if req.Commands != nil {
+		if err := validateCommands(*req.Commands); err != nil {
+			return nil, err
+		}
+	}
+
 	// Get existing environment
 	env, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -254,6 +314,26 @@ func (s *Service) UpdateEnvironmentPartial(ctx context.Context, id string, req U
 		env.UpgradeConfig = *req.UpgradeConfig
 	}
 
+	if req.Limits != nil {
+		changes["limits"] = "updated"
+		env.Limits = req.Limits
+	}
+
+	if req.RequiresApproval != nil {
+		changes["requiresApproval"] = map[string]bool{"from": env.RequiresApproval, "to": *req.RequiresApproval}
+		env.RequiresApproval = *req.RequiresApproval
+	}
+
+	if req.Labels != nil {
+		changes["labels"] = map[string]interface{}{"from": env.Labels, "to": req.Labels}
+		env.Labels = req.Labels
+	}
+
+	if req.LogSources != nil {
+		changes["logSources"] = "updated"
+		env.LogSources = req.LogSources
+	}
+
 	// Handle metadata separately - merge instead of replace
 	if req.Metadata != nil {
 		if env.Metadata == nil {
@@ -304,50 +384,117 @@ func (s *Service) DeleteEnvironment(ctx context.Context, id string) error {
 
 // CheckHealth performs a health check on an environment
 func (s *Service) CheckHealth(ctx context.Context, id string) error {
+	_, err := s.checkHealth(ctx, id)
+	return err
+}
+
+// ProbeHealth performs a health check on an environment like CheckHealth and
+// returns the check's own result. Unlike the environment's saved health it
+// does not wait for the failure or success threshold.
+func (s *Service) ProbeHealth(ctx context.Context, id string) (*health.CheckResult, error) {
+	return s.checkHealth(ctx, id)
+}
+
+// checkHealth checks an environment, saves its new status and returns the
+// check's result
+func (s *Service) checkHealth(ctx context.Context, id string) (*health.CheckResult, error) {
 	// Get environment
 	env, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Perform health check
-	result, err := s.healthChecker.CheckHealth(ctx, env)
+	// Perform health check, retrying an unhealthy one
+	result, attempts, err := s.checkWithRetries(ctx, env)
 	if err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+		return nil, err
 	}
 
-	// Update status
+	// Update status. The checks may have used up the context's deadline, so
+	// the result is saved and logged regardless.
+	ctx = context.WithoutCancel(ctx)
 	oldStatus := env.Status
-	newStatus := entities.Status{
-		Health:       result.Status,
-		LastCheck:    time.Now(),
-		Message:      result.Message,
-		ResponseTime: result.ResponseTime,
-	}
+	newStatus := s.healthPolicy.nextStatus(env.HealthCheck, oldStatus, result, time.Now())
 
 	if err := s.repo.UpdateStatus(ctx, id, newStatus); err != nil {
-		return fmt.Errorf("failed to update status: %w", err)
+		return nil, fmt.Errorf("failed to update status: %w", err)
 	}
 
-	// Only log health check if status changed
-	if oldStatus.Health != newStatus.Health {
-		_ = s.logService.LogHealthCheck(ctx, env, newStatus.Health, result.Message, map[string]interface{}{
+	changed := oldStatus.Health != newStatus.Health
+	switch {
+	case newStatus.Flapping && !oldStatus.Flapping:
+		_ = s.logService.LogHealthFlapping(ctx, env, true,
+			fmt.Sprintf("Health is flapping: %d changes in %s; changes are not logged until it is stable",
+				len(newStatus.Transitions), s.healthPolicy.FlapWindow),
+			map[string]interface{}{
+				"transitions":   len(newStatus.Transitions),
+				"currentStatus": string(newStatus.Health),
+			})
+	case oldStatus.Flapping && !newStatus.Flapping:
+		_ = s.logService.LogHealthFlapping(ctx, env, false,
+			fmt.Sprintf("Health is stable: %s", newStatus.Health),
+			map[string]interface{}{"currentStatus": string(newStatus.Health)})
+	case changed && !newStatus.Flapping:
+		// Only log health check if status changed
+		details := map[string]interface{}{
 			"statusCode": result.StatusCode,
 			"responseTime": result.ResponseTime,
 			"previousStatus": string(oldStatus.Health),
 			"currentStatus": string(newStatus.Health),
 			"statusChanged": true,
-		})
-		
-		// Update last healthy timestamp if now healthy
-		if newStatus.Health == entities.HealthStatusHealthy {
-			now := time.Now()
-			env.Timestamps.LastHealthyAt = &now
-			_ = s.repo.Update(ctx, id, env)
 		}
+		if result.FailedProbe != "" {
+			details["failedProbe"] = result.FailedProbe
+		}
+		if attempts > 1 {
+			details["attempts"] = attempts
+		}
+		_ = s.logService.LogHealthCheck(ctx, env, newStatus.Health, result.Message, details)
 	}
 
-	return nil
+	// Update last healthy timestamp if now healthy
+	if changed && newStatus.Health == entities.HealthStatusHealthy {
+		now := time.Now()
+		env.Timestamps.LastHealthyAt = &now
+		_ = s.repo.Update(ctx, id, env)
+	}
+
+	if changed || oldStatus.Flapping != newStatus.Flapping {
+		for _, listener := range s.healthChanged {
+			listener(env, newStatus)
+		}
+	}
+
+	return result, nil
+}
+
+// runHealthCheck checks an environment once: its probes, its driver's health
+// check or a network check
+func (s *Service) runHealthCheck(ctx context.Context, env *entities.Environment) (*health.CheckResult, error) {
+	if env.HealthCheck.Enabled && len(env.HealthCheck.Probes) > 0 {
+		return s.checkProbes(ctx, env), nil
+	}
+	if driver, ok := driverHealthChecks[env.HealthCheck.Type]; ok && env.HealthCheck.Enabled {
+		return s.probeHealth(ctx, driver, env), nil
+	}
+
+	result, err := s.healthChecker.CheckHealth(ctx, env)
+	if err != nil {
+		return nil, fmt.Errorf("health check failed: %w", err)
+	}
+
+	// Drivers that observe the environment themselves, e.g. its containers'
+	// state, decide when the health check could not or finds it unhealthy
+	probe := s.executors.Execute(ctx, env.Commands.Type, &executor.Request{
+		Operation:   executor.OperationHealth,
+		Environment: env,
+	})
+	if probe.Succeeded() &&
+		(result.Status == entities.HealthStatusUnknown || probe.Health == entities.HealthStatusUnhealthy) {
+		result.Status = probe.Health
+		result.Message = probe.Output
+	}
+	return result, nil
 }
 
 // RestartEnvironment restarts an environment
@@ -363,6 +510,10 @@ func (s *Service) RestartEnvironment(ctx context.Context, id string, force bool)
 		return fmt.Errorf("restart is not enabled for this environment")
 	}
 
+	if err := s.reserveOperation(ctx, env, entities.ActionTypeRestart); err != nil {
+		return err
+	}
+
 	// Log start of operation
 	operationID := primitive.NewObjectID()
 	
@@ -382,90 +533,40 @@ func (s *Service) RestartEnvironment(ctx context.Context, id string, force bool)
 			"restartConfig": env.Commands.Restart,
 		})
 
-	start := time.Now()
-	var errorMsg string
-	var success bool
-
 	// Log command type being used
 	fmt.Printf("Restart command type: %s\n", env.Commands.Type)
 	fmt.Printf("Restart config: %+v\n", env.Commands.Restart)
 
-	// Execute restart based on command type
-	switch env.Commands.Type {
-	case entities.CommandTypeHTTP:
-		// Execute HTTP command
-		fmt.Println("Executing HTTP restart command")
-		// Convert RestartConfig to CommandDetails
-		cmdDetails := entities.CommandDetails{
-			URL:     env.Commands.Restart.URL,
-			Method:  env.Commands.Restart.Method,
-			Headers: env.Commands.Restart.Headers,
-			Body:    env.Commands.Restart.Body,
-		}
-		errorMsg, success = s.executeHTTPCommand(ctx, cmdDetails)
-		fmt.Printf("HTTP command result - success: %v, error: %s\n", success, errorMsg)
-	case entities.CommandTypeSSH:
-		// Execute SSH command
-		command := env.Commands.Restart.Command
-		if command == "" {
-			// Default command if not specified
-			command = "sudo systemctl restart app"
-			if force {
-				command = "sudo systemctl restart app --force"
-			}
-		}
-		
-		target, err := s.buildSSHTarget(env)
-		if err != nil {
-			errorMsg = err.Error()
-			success = false
-		} else {
-			result, err := s.sshManager.Execute(ctx, *target, command)
-			if err != nil || (result != nil && result.ExitCode != 0) {
-				success = false
-				if err != nil {
-					errorMsg = err.Error()
-				} else if result != nil {
-					errorMsg = result.Output
-				}
-			} else {
-				success = true
-			}
-		}
-	default:
-		// Default to SSH with standard command
-		target, err := s.buildSSHTarget(env)
-		if err != nil {
-			errorMsg = err.Error()
-			success = false
-		} else {
-			command := "sudo systemctl restart app"
-			if force {
-				command = "sudo systemctl restart app --force"
-			}
-			result, err := s.sshManager.Execute(ctx, *target, command)
-			if err != nil || (result != nil && result.ExitCode != 0) {
-				success = false
-				if err != nil {
-					errorMsg = err.Error()
-				} else if result != nil {
-					errorMsg = result.Output
-				}
-			} else {
-				success = true
-			}
-		}
+	// Environments without a command type restart over SSH
+	cmdType := env.Commands.Type
+	if cmdType == "" {
+		cmdType = entities.CommandTypeSSH
 	}
 
+	start := time.Now()
+	params := map[string]string{executor.ParamForce: strconv.FormatBool(force)}
+	hooks := hookRun{env, env.Commands.Restart.Hooks, executor.OperationRestart, entities.ActionTypeRestart, operationID.Hex(), params}
+	result, hookResults := s.withHooks(ctx, hooks, func() *executor.Result {
+		return withTimeout(ctx, env, entities.ActionTypeRestart, func(ctx context.Context) *executor.Result {
+			return s.executors.Execute(ctx, cmdType, &executor.Request{
+				Operation:   executor.OperationRestart,
+				Environment: env,
+				Command:     renderRestartCommand(env, force),
+				Params:      params,
+			})
+		})
+	})
+	errorMsg, success := result.Message(), result.Succeeded()
+
 	duration := time.Since(start).Milliseconds()
 
 	if !success {
 		// Add to logs screen
-		_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeRestart, fmt.Sprintf("Restart operation failed: %s", errorMsg), map[string]interface{}{
+		_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeRestart, fmt.Sprintf("Restart operation failed: %s", errorMsg), hookDetails(resultDetails(map[string]interface{}{
 			"operationId": operationID.Hex(),
 			"duration": duration,
 			"error": errorMsg,
-		})
+		}, result), hookResults))
 		
 		// Also log to audit
 		s.logEvent(ctx, env, entities.EventTypeRestart, entities.SeverityError, "restart", 
@@ -485,10 +586,10 @@ func (s *Service) RestartEnvironment(ctx context.Context, id string, force bool)
 
 	// Log success
 	// Add to logs screen
-	_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeRestart, "Restart operation completed successfully", map[string]interface{}{
+	_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeRestart, "Restart operation completed successfully", hookDetails(resultDetails(map[string]interface{}{
 		"operationId": operationID.Hex(),
 		"duration": duration,
-	})
+	}, result), hookResults))
 	
 	// Also log to audit
 	s.logEvent(ctx, env, entities.EventTypeRestart, entities.SeverityInfo, "restart", "Restart completed successfully", 
@@ -511,6 +612,19 @@ func (s *Service) RestartEnvironment(ctx context.Context, id string, force bool)
 
 // UpgradeEnvironment upgrades an environment to a new version
 func (s *Service) UpgradeEnvironment(ctx context.Context, id string, version string) error {
+	return s.upgradeEnvironment(ctx, id, version, true)
+}
+
+// RollbackEnvironment returns an environment to the version it ran before an
+// upgrade. Unlike an upgrade it is not rate limited, since the upgrade it
+// undoes has just used the limit.
+func (s *Service) RollbackEnvironment(ctx context.Context, id string, version string) error {
+	return s.upgradeEnvironment(ctx, id, version, false)
+}
+
+// upgradeEnvironment upgrades an environment to version, within the upgrade
+// rate limit when limited is set
+func (s *Service) upgradeEnvironment(ctx context.Context, id string, version string, limited bool) error {
 	// Get environment
 	env, err := s.repo.GetByID(ctx, id)
 	if err != nil {
@@ -522,6 +636,12 @@ func (s *Service) UpgradeEnvironment(ctx context.Context, id string, version str
 		return fmt.Errorf("upgrade is not enabled for this environment")
 	}
 
+	if limited {
+		if err := s.reserveOperation(ctx, env, entities.ActionTypeUpgrade); err != nil {
+			return err
+		}
+	}
+
 	// Log start of operation
 	operationID := primitive.NewObjectID()
 	
@@ -541,86 +661,37 @@ func (s *Service) UpgradeEnvironment(ctx context.Context, id string, version str
 		})
 
 	start := time.Now()
-	var errorMsg string
-	var success bool
-
-	// Execute upgrade command
-	upgradeCmd := env.UpgradeConfig.UpgradeCommand
-	// Replace version placeholder in command
-	if upgradeCmd.Command != "" {
-		upgradeCmd.Command = strings.ReplaceAll(upgradeCmd.Command, "{VERSION}", version)
-	}
-	if upgradeCmd.URL != "" {
-		upgradeCmd.URL = strings.ReplaceAll(upgradeCmd.URL, "{VERSION}", version)
-	}
-	// Replace version placeholder in body
-	if upgradeCmd.Body != nil && len(upgradeCmd.Body) > 0 {
-		// Create a new map to avoid modifying the original
-		newBody := make(map[string]interface{})
-		for k, v := range upgradeCmd.Body {
-			// Replace {VERSION} in string values
-			if strVal, ok := v.(string); ok {
-				newBody[k] = strings.ReplaceAll(strVal, "{VERSION}", version)
-			} else {
-				// For non-string values, keep as is
-				newBody[k] = v
-			}
-		}
-		upgradeCmd.Body = newBody
-	}
-
-	switch env.UpgradeConfig.Type {
-	case entities.CommandTypeHTTP:
-		// Execute HTTP command
-		errorMsg, success = s.executeHTTPCommand(ctx, upgradeCmd)
-	case entities.CommandTypeSSH:
-		// Execute SSH command - support multi-line commands
-		if upgradeCmd.Command == "" {
-			upgradeCmd.Command = fmt.Sprintf("sudo app-upgrade --version=%s", version)
-		}
-		
-		target, err := s.buildSSHTarget(env)
-		if err != nil {
-			errorMsg = err.Error()
-			success = false
-		} else {
-			// Split multi-line commands and execute them sequentially
-			commands := strings.Split(upgradeCmd.Command, "\n")
-			for _, cmd := range commands {
-				cmd = strings.TrimSpace(cmd)
-				if cmd == "" {
-					continue
-				}
-				result, err := s.sshManager.Execute(ctx, *target, cmd)
-				if err != nil || (result != nil && result.ExitCode != 0) {
-					success = false
-					if err != nil {
-						errorMsg = fmt.Sprintf("Command failed: %s - Error: %v", cmd, err)
-					} else if result != nil {
-						errorMsg = fmt.Sprintf("Command failed: %s - Output: %s", cmd, result.Output)
-					}
-					break
-				}
-			}
-			if errorMsg == "" {
-				success = true
-			}
+	params := map[string]string{
+		executor.ParamVersion:        version,
+		executor.ParamCurrentVersion: env.SystemInfo.AppVersion,
+	}
+	hooks := hookRun{env, env.UpgradeConfig.Hooks, executor.OperationUpgrade, entities.ActionTypeUpgrade, operationID.Hex(), params}
+	result, hookResults := s.withHooks(ctx, hooks, func() *executor.Result {
+		if env.UpgradeConfig.Type == "" {
+			return executor.Failure("No command type specified for upgrade")
 		}
-	default:
-		errorMsg = "No command type specified for upgrade"
-		success = false
-	}
+		// Execute upgrade command with the version placeholder resolved
+		return withTimeout(ctx, env, entities.ActionTypeUpgrade, func(ctx context.Context) *executor.Result {
+			return s.executors.Execute(ctx, env.UpgradeConfig.Type, &executor.Request{
+				Operation:   executor.OperationUpgrade,
+				Environment: env,
+				Command:     renderUpgradeCommand(env, version),
+				Params:      params,
+			})
+		})
+	})
+	errorMsg, success := result.Message(), result.Succeeded()
 
 	duration := time.Since(start).Milliseconds()
 
 	if !success {
 		// Add to logs screen
-		_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeUpgrade, fmt.Sprintf("Upgrade operation failed: %s", errorMsg), map[string]interface{}{
+		_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeUpgrade, fmt.Sprintf("Upgrade operation failed: %s", errorMsg), hookDetails(resultDetails(map[string]interface{}{
 			"operationId": operationID.Hex(),
 			"duration": duration,
 			"targetVersion": version,
 			"error": errorMsg,
-		})
+		}, result), hookResults))
 		
 		// Also log to audit
 		s.logEvent(ctx, env, entities.EventTypeUpgrade, entities.SeverityError, "upgrade", 
@@ -643,12 +714,12 @@ func (s *Service) UpgradeEnvironment(ctx context.Context, id string, version str
 
 	// Log success
 	// Add to logs screen
-	_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeUpgrade, "Upgrade operation completed successfully", map[string]interface{}{
+	_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeUpgrade, "Upgrade operation completed successfully", hookDetails(resultDetails(map[string]interface{}{
 		"operationId": operationID.Hex(),
 		"duration": duration,
 		"newVersion": version,
 		"previousVersion": env.SystemInfo.AppVersion,
-	})
+	}, result), hookResults))
 	
 	// Also log to audit
 	s.logEvent(ctx, env, entities.EventTypeUpgrade, entities.SeverityInfo, "upgrade", "Upgrade completed successfully", 
@@ -669,6 +740,74 @@ func (s *Service) UpgradeEnvironment(ctx context.Context, id string, version str
 	return nil
 }
 
+// RunCustomAction runs a named custom action defined on the environment
+func (s *Service) RunCustomAction(ctx context.Context, id string, name string) error {
+	// Get environment
+	env, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	action, ok := env.Commands.FindAction(name)
+	if !ok {
+		return errors.ErrActionNotFound
+	}
+
+	if err := s.reserveOperation(ctx, env, entities.ActionTypeCustom); err != nil {
+		return err
+	}
+
+	operationID := primitive.NewObjectID()
+	details := map[string]interface{}{
+		"operationId": operationID.Hex(),
+		"action":      action.Name,
+		"commandType": env.Commands.Type,
+	}
+
+	_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeCustom,
+		fmt.Sprintf("Custom action %q initiated", action.Name), details)
+	s.logEvent(ctx, env, entities.EventTypeCustomAction, entities.SeverityInfo, action.Name, "Custom action initiated", details)
+
+	start := time.Now()
+	var result *executor.Result
+	if env.Commands.Type == "" {
+		result = executor.Failure("No command type specified for custom action")
+	} else {
+		result = withTimeout(ctx, env, entities.ActionTypeCustom, func(ctx context.Context) *executor.Result {
+			return s.executors.Execute(ctx, env.Commands.Type, &executor.Request{
+				Operation:   executor.OperationCustomAction,
+				Environment: env,
+				Command:     action.Command,
+				Params:      map[string]string{executor.ParamAction: action.Name},
+			})
+		})
+	}
+	errorMsg, success := result.Message(), result.Succeeded()
+
+	duration := time.Since(start).Milliseconds()
+	details = resultDetails(map[string]interface{}{
+		"operationId": operationID.Hex(),
+		"action":      action.Name,
+		"duration":    duration,
+	}, result)
+
+	if !success {
+		details["error"] = errorMsg
+		_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeCustom,
+			fmt.Sprintf("Custom action %q failed: %s", action.Name, errorMsg), details)
+		s.logEvent(ctx, env, entities.EventTypeCustomAction, entities.SeverityError, action.Name,
+			fmt.Sprintf("Custom action failed: %s", errorMsg), details)
+		return fmt.Errorf("custom action %s failed: %s", action.Name, errorMsg)
+	}
+
+	_ = s.logService.LogEnvironmentAction(ctx, env, entities.ActionTypeCustom,
+		fmt.Sprintf("Custom action %q completed successfully", action.Name), details)
+	s.logEvent(ctx, env, entities.EventTypeCustomAction, entities.SeverityInfo, action.Name,
+		"Custom action completed successfully", details)
+
+	return nil
+}
+
 // GetAvailableVersions fetches available versions for upgrade
 func (s *Service) GetAvailableVersions(ctx context.Context, id string) ([]string, string, error) {
 	// Get environment
@@ -684,6 +823,16 @@ func (s *Service) GetAvailableVersions(ctx context.Context, id string) ([]string
 
 	// Check if version list URL is configured
 	if env.UpgradeConfig.VersionListURL == "" {
+		// Some drivers know the versions themselves
+		if driver, ok := s.executors.Lookup(env.UpgradeConfig.Type); ok {
+			if lister, ok := driver.(executor.VersionLister); ok {
+				versions, err := lister.Versions(ctx, env)
+				if err != nil {
+					return nil, "", err
+				}
+				return versions, env.SystemInfo.AppVersion, nil
+			}
+		}
 		return nil, "", fmt.Errorf("version list URL is not configured")
 	}
 
@@ -1007,7 +1156,8 @@ func validateURLStrict(rawURL string) error {
 	return nil
 }
 
-// executeHTTPCommand executes an HTTP command
+// executeHTTPCommand executes an HTTP command, retrying it according to its
+// retry policy and, for async commands, polling the job until it finishes
 func (s *Service) executeHTTPCommand(ctx context.Context, cmd entities.CommandDetails) (string, bool) {
 	if cmd.URL == "" {
 		return "HTTP command URL is required", false
@@ -1018,22 +1168,7 @@ func (s *Service) executeHTTPCommand(ctx context.Context, cmd entities.CommandDe
 		return fmt.Sprintf("URL validation failed: %v", err), false
 	}
 
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		// Disable following redirects to prevent redirect-based SSRF
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Validate each redirect URL
-			if err := s.validateURL(req.URL.String()); err != nil {
-				return fmt.Errorf("redirect URL validation failed: %w", err)
-			}
-			// Limit redirect chain
-			if len(via) >= 5 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
-	}
+	client := s.newCommandClient(cmd.RequestTimeout())
 
 	// Build request
 	method := cmd.Method
@@ -1041,58 +1176,110 @@ func (s *Service) executeHTTPCommand(ctx context.Context, cmd entities.CommandDe
 		method = "POST"
 	}
 
-	var body io.Reader
+	var body []byte
 	if cmd.Body != nil && len(cmd.Body) > 0 {
 		// Marshal the body map to JSON
 		jsonBody, err := json.Marshal(cmd.Body)
 		if err != nil {
 			return fmt.Sprintf("Failed to marshal request body: %v", err), false
 		}
-		body = bytes.NewReader(jsonBody)
+		body = jsonBody
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, cmd.URL, body)
+	resp, err := s.sendWithRetry(ctx, client, method, cmd.URL, cmd.Headers, body, cmd.Retry)
 	if err != nil {
-		return fmt.Sprintf("Failed to create request: %v", err), false
+		return err.Error(), false
 	}
 
-	// Add headers
-	for key, value := range cmd.Headers {
-		req.Header.Set(key, value)
+	// Async jobs are judged by their final status response, so the initial
+	// response only has to be accepted
+	if cmd.Async != nil {
+		if resp.statusCode < 200 || resp.statusCode >= 300 {
+			return fmt.Sprintf("Request failed with status %d: %s", resp.statusCode, string(resp.body)), false
+		}
+		return s.pollJob(ctx, client, cmd, resp)
 	}
-	
-	// Set default Content-Type if not provided and body exists
-	if body != nil && req.Header.Get("Content-Type") == "" {
-		req.Header.Set("Content-Type", "application/json")
+
+	// Check status code
+	if !assertion.StatusMatches(cmd.Success, resp.statusCode) {
+		return fmt.Sprintf("Request failed with status %d: %s", resp.statusCode, string(resp.body)), false
+	}
+	if err := assertion.Evaluate(cmd.Success, resp.assertionResponse()); err != nil {
+		return fmt.Sprintf("Success assertion failed: %v", err), false
 	}
 
-	// Execute request
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Sprintf("Request failed: %v", err), false
+	return string(resp.body), true
+}
+
+// renderRestartCommand resolves the restart command that would run for the
+// environment. HTTP environments use the configured request; other types use
+// the configured command or script, and environments without a type or
+// command the default systemctl restart.
+func renderRestartCommand(env *entities.Environment, force bool) entities.CommandDetails {
+	if env.Commands.Type == entities.CommandTypeHTTP {
+		return entities.CommandDetails{
+			URL:        env.Commands.Restart.URL,
+			Method:     env.Commands.Restart.Method,
+			Headers:    env.Commands.Restart.Headers,
+			Body:       env.Commands.Restart.Body,
+			HTTPPolicy: env.Commands.Restart.HTTPPolicy,
+		}
 	}
-	defer resp.Body.Close()
 
-	// Read response
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Sprintf("Failed to read response: %v", err), false
+	command := "sudo systemctl restart app"
+	if force {
+		command = "sudo systemctl restart app --force"
+	}
+	// Environments without a command type always run the default command
+	if env.Commands.Type != "" && env.Commands.Restart.Command != "" {
+		command = env.Commands.Restart.Command
+	}
+	if env.Commands.Type != "" && env.Commands.Restart.Script != nil {
+		return entities.CommandDetails{Script: env.Commands.Restart.Script}
 	}
+	return entities.CommandDetails{Command: command}
+}
 
-	// Check status code
-	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		return string(responseBody), true
+// renderUpgradeCommand resolves the {VERSION} placeholder in the upgrade
+// command without modifying the environment's configuration
+func renderUpgradeCommand(env *entities.Environment, version string) entities.CommandDetails {
+	upgradeCmd := env.UpgradeConfig.UpgradeCommand
+	if upgradeCmd.Command != "" {
+		upgradeCmd.Command = strings.ReplaceAll(upgradeCmd.Command, "{VERSION}", version)
 	}
+	if upgradeCmd.URL != "" {
+		upgradeCmd.URL = strings.ReplaceAll(upgradeCmd.URL, "{VERSION}", version)
+	}
+	if len(upgradeCmd.Body) > 0 {
+		// Create a new map to avoid modifying the original
+		newBody := make(map[string]interface{}, len(upgradeCmd.Body))
+		for k, v := range upgradeCmd.Body {
+			if strVal, ok := v.(string); ok {
+				newBody[k] = strings.ReplaceAll(strVal, "{VERSION}", version)
+			} else {
+				newBody[k] = v
+			}
+		}
+		upgradeCmd.Body = newBody
+	}
+	if env.UpgradeConfig.Type == entities.CommandTypeSSH && upgradeCmd.Command == "" && upgradeCmd.Script == nil {
+		upgradeCmd.Command = fmt.Sprintf("sudo app-upgrade --version=%s", version)
+	}
+	return upgradeCmd
+}
 
-	return fmt.Sprintf("Request failed with status %d: %s", resp.StatusCode, string(responseBody)), false
+// SSHTarget returns the SSH target for the environment's stored credentials
+func (s *Service) SSHTarget(env *entities.Environment) (*ssh.Target, error) {
+	return s.buildSSHTarget(env)
 }
 
 // buildSSHTarget builds an SSH target from environment
 func (s *Service) buildSSHTarget(env *entities.Environment) (*ssh.Target, error) {
 	target := &ssh.Target{
-		Host:     env.Target.Host,
-		Port:     env.Target.Port,
-		Username: env.Credentials.Username,
+		Host:           env.Target.Host,
+		Port:           env.Target.Port,
+		Username:       env.Credentials.Username,
+		CommandTimeout: env.Limits.CommandTimeout(),
 	}
 
 	// Load credentials based on type
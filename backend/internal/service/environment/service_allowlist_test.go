@@ -0,0 +1,196 @@
+package environment_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/service/environment"
+	"app-env-manager/internal/service/health"
+	"app-env-manager/internal/service/log"
+	"app-env-manager/internal/service/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newAllowlistService builds a service with a global allowlist and an audit
+// repository the test can inspect
+func newAllowlistService(repo *MockEnvironmentRepository, logRepo *MockLogRepository,
+	auditRepo *MockAuditLogRepository, global []entities.CommandRule) *environment.Service {
+
+	sshMgr := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: time.Second,
+		CommandTimeout:    time.Second,
+		MaxConnections:    1,
+		Allowlist:         global,
+	})
+	logSvc := log.NewService(logRepo)
+	return environment.NewService(repo, auditRepo, sshMgr, health.NewChecker(time.Second), logSvc, nil)
+}
+
+func newAllowlistEnv(id primitive.ObjectID, command string) *entities.Environment {
+	env := newSampleEnv(id)
+	env.Target = entities.Target{Host: "127.0.0.1", Port: 1}
+	env.Credentials = entities.CredentialRef{Type: "password", Username: "deploy"}
+	env.Metadata = map[string]interface{}{"password": "secret", "insecureSkipHostKeyVerification": true}
+	env.Commands = entities.CommandConfig{
+		Type:    entities.CommandTypeSSH,
+		Restart: entities.RestartConfig{Enabled: true, Command: command},
+	}
+	return env
+}
+
+// captureAudit forwards every audit entry, which the service writes
+// asynchronously, to the returned channel
+func captureAudit(auditRepo *MockAuditLogRepository) <-chan *entities.AuditLog {
+	entries := make(chan *entities.AuditLog, 20)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		entries <- args.Get(1).(*entities.AuditLog)
+	}).Return(nil)
+	return entries
+}
+
+// waitForAudit returns the first audit entry of the given type
+func waitForAudit(t *testing.T, entries <-chan *entities.AuditLog, eventType entities.EventType) *entities.AuditLog {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-entries:
+			if entry.Type == eventType {
+				return entry
+			}
+		case <-timeout:
+			t.Fatalf("no %s audit entry", eventType)
+			return nil
+		}
+	}
+}
+
+// auditMetadata returns the metadata logEvent stores in the payload
+func auditMetadata(entry *entities.AuditLog) map[string]interface{} {
+	metadata, _ := entry.Payload.Metadata["metadata"].(map[string]interface{})
+	return metadata
+}
+
+func TestService_RestartEnvironment_DeniedByAllowlist(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	auditRepo := new(MockAuditLogRepository)
+	svc := newAllowlistService(repo, logRepo, auditRepo, []entities.CommandRule{
+		{Name: "status", Type: entities.CommandRuleExact, Pattern: "systemctl status app"},
+	})
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newAllowlistEnv(id, "sudo reboot"), nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	entries := captureAudit(auditRepo)
+
+	err := svc.RestartEnvironment(context.Background(), id.Hex(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "command not allowed by allowlist")
+
+	denied := waitForAudit(t, entries, entities.EventTypeCommandDenied)
+	assert.Equal(t, entities.SeverityWarning, denied.Severity)
+	assert.Equal(t, "sudo reboot", auditMetadata(denied)["command"])
+}
+
+func TestService_RestartEnvironment_RecordsMatchedRule(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	auditRepo := new(MockAuditLogRepository)
+	svc := newAllowlistService(repo, logRepo, auditRepo, nil)
+
+	id := primitive.NewObjectID()
+	env := newAllowlistEnv(id, "sudo systemctl restart app-worker")
+	env.Commands.Allowlist = []entities.CommandRule{{
+		Name: "restart-any", Type: entities.CommandRuleExact, Pattern: "sudo systemctl restart {service}",
+	}}
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	entries := captureAudit(auditRepo)
+
+	// Nothing listens on port 1, so the command is allowed but cannot run
+	err := svc.RestartEnvironment(context.Background(), id.Hex(), false)
+	require.Error(t, err)
+
+	metadata := auditMetadata(waitForAudit(t, entries, entities.EventTypeCommandExecuted))
+	assert.Equal(t, "restart-any", metadata["rule"])
+	assert.Equal(t, ssh.ScopeEnvironment, metadata["ruleScope"])
+	assert.Equal(t, map[string]string{"service": "app-worker"}, metadata["params"])
+	assert.Contains(t, metadata["error"], "failed to get SSH connection")
+}
+
+func TestService_PlanRestart_ReportsAllowlistRule(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	auditRepo := new(MockAuditLogRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	svc := newAllowlistService(repo, logRepo, auditRepo, []entities.CommandRule{
+		{Name: "restart", Type: entities.CommandRuleExact, Pattern: "sudo systemctl restart app"},
+	})
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newAllowlistEnv(id, "sudo systemctl restart app"), nil).Once()
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanRestart(context.Background(), id.Hex(), false)
+	require.NoError(t, err)
+	check, ok := checkByName(plan, "command")
+	require.True(t, ok)
+	assert.True(t, check.Passed)
+	assert.Contains(t, check.Target, "global rule restart")
+
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newAllowlistEnv(id, "sudo systemctl stop app"), nil).Once()
+	plan, err = svc.PlanRestart(context.Background(), id.Hex(), false)
+	require.NoError(t, err)
+	check, _ = checkByName(plan, "command")
+	assert.False(t, check.Passed)
+	assert.Contains(t, check.Error, "not allowed")
+}
+
+func TestService_SaveEnvironment_RejectsInvalidAllowlist(t *testing.T) {
+	commands := entities.CommandConfig{
+		Allowlist: []entities.CommandRule{{Name: "bad", Type: entities.CommandRuleExact, Pattern: ""}},
+	}
+	id := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name string
+		save func(svc *environment.Service) error
+	}{
+		{"create", func(svc *environment.Service) error {
+			_, err := svc.CreateEnvironment(context.Background(), environment.CreateEnvironmentRequest{Name: "env", Commands: commands})
+			return err
+		}},
+		{"update", func(svc *environment.Service) error {
+			_, err := svc.UpdateEnvironment(context.Background(), id, environment.CreateEnvironmentRequest{Name: "env", Commands: commands})
+			return err
+		}},
+		{"partial update", func(svc *environment.Service) error {
+			_, err := svc.UpdateEnvironmentPartial(context.Background(), id, environment.UpdateEnvironmentRequest{Commands: &commands})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockEnvironmentRepository)
+			svc := newTestService(repo, new(MockLogRepository))
+
+			err := tt.save(svc)
+
+			require.Error(t, err)
+			assert.Equal(t, "VALIDATION_ERROR", err.(errors.DomainError).Code)
+			assert.Contains(t, err.(errors.DomainError).Details["reason"], "bad")
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -815,3 +815,31 @@ func TestService_UpdateEnvironmentPartial_NameChange(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Equal(t, "new-name", result.Name)
 }
+
+// TestService_RestartEnvironment_SSHScript tests that a configured restart
+// script is run instead of a command.
+func TestService_RestartEnvironment_SSHScript(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Credentials = entities.CredentialRef{Type: "password", Username: "deploy"}
+	env.Metadata = map[string]interface{}{"password": "secret"}
+	env.Commands = entities.CommandConfig{
+		Type: entities.CommandTypeSSH,
+		Restart: entities.RestartConfig{
+			Enabled: true,
+			Script:  &entities.ScriptConfig{Body: "systemctl restart app", Interpreter: "bash"},
+		},
+	}
+
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil).Maybe()
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	err := svc.RestartEnvironment(context.Background(), id.Hex(), false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid script")
+	}
+}
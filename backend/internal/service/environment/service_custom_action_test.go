@@ -0,0 +1,93 @@
+package environment_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newActionEnv(id primitive.ObjectID, url string) *entities.Environment {
+	env := newSampleEnv(id)
+	env.Commands = entities.CommandConfig{
+		Type: entities.CommandTypeHTTP,
+		Actions: []entities.CustomAction{
+			{Name: "clear-cache", Command: entities.CommandDetails{URL: url, Method: "POST"}},
+		},
+	}
+	return env
+}
+
+func TestService_RunCustomAction_HTTPSuccess(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestServiceWithAllowedHosts(repo, logRepo, []string{"127.0.0.1"})
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newActionEnv(id, srv.URL), nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	err := svc.RunCustomAction(context.Background(), id.Hex(), "clear-cache")
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestService_RunCustomAction_HTTPFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestServiceWithAllowedHosts(repo, logRepo, []string{"127.0.0.1"})
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newActionEnv(id, srv.URL), nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	err := svc.RunCustomAction(context.Background(), id.Hex(), "clear-cache")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "clear-cache")
+}
+
+func TestService_RunCustomAction_Unknown(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newActionEnv(id, "http://example.com"), nil)
+
+	err := svc.RunCustomAction(context.Background(), id.Hex(), "missing")
+	assert.Equal(t, errors.ErrActionNotFound, err)
+}
+
+func TestService_RunCustomAction_SSHWithoutCommand(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	env := newActionEnv(id, "")
+	env.Commands.Type = entities.CommandTypeSSH
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	err := svc.RunCustomAction(context.Background(), id.Hex(), "clear-cache")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no command")
+}
@@ -0,0 +1,294 @@
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/docker/dockertest"
+	"app-env-manager/internal/service/environment"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newDockerTLSEnv returns an environment whose Engine is the fake's TLS port
+func newDockerTLSEnv(id primitive.ObjectID, engine *dockertest.Server, containers ...string) *entities.Environment {
+	env := newSampleEnv(id)
+	env.Target = entities.Target{Host: "127.0.0.1", Port: 22}
+	env.Commands = entities.CommandConfig{
+		Type:    entities.CommandTypeDocker,
+		Restart: entities.RestartConfig{Enabled: true},
+		Docker: &entities.DockerConfig{
+			Transport:  entities.DockerTransportTLS,
+			Port:       engine.Port(),
+			TLS:        &entities.DockerTLSConfig{CACert: string(engine.CACert())},
+			Containers: containers,
+		},
+	}
+	env.UpgradeConfig = entities.UpgradeConfig{Enabled: true, Type: entities.CommandTypeDocker}
+	return env
+}
+
+// newDockerService returns a service whose repository accepts updates
+func newDockerService(t *testing.T, repo *MockEnvironmentRepository, env *entities.Environment) *environment.Service {
+	t.Helper()
+	logRepo := new(MockLogRepository)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	repo.On("Update", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	repo.On("UpdateStatus", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	return newTestService(repo, logRepo)
+}
+
+func TestService_RestartEnvironment_DockerOverSSH(t *testing.T) {
+	server := sshtest.NewServer(t)
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	engine.AddContainer("worker", "app:1.0.0")
+	engine.SetState("worker", false, "")
+
+	id := primitive.NewObjectID()
+	env := newExecEnv(id, server)
+	env.Commands = entities.CommandConfig{
+		Type:    entities.CommandTypeDocker,
+		Restart: entities.RestartConfig{Enabled: true},
+		Docker: &entities.DockerConfig{
+			SocketPath: engine.ListenUnix(t),
+			Containers: []string{"app", "worker"},
+		},
+	}
+	svc := newDockerService(t, new(MockEnvironmentRepository), env)
+
+	require.NoError(t, svc.RestartEnvironment(context.Background(), id.Hex(), false))
+
+	for _, name := range []string{"app", "worker"} {
+		container, _ := engine.Container(name)
+		assert.Equal(t, 1, container.Restarts, name)
+		assert.True(t, container.Running, name)
+	}
+	assert.Empty(t, server.Commands(), "no commands are run on the host")
+}
+
+func TestService_RestartEnvironment_DockerMissingContainer(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+
+	id := primitive.NewObjectID()
+	svc := newDockerService(t, new(MockEnvironmentRepository), newDockerTLSEnv(id, engine, "app", "missing"))
+
+	err := svc.RestartEnvironment(context.Background(), id.Hex(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "restart missing")
+}
+
+func TestService_RestartEnvironment_DockerInvalidConfig(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	tests := []struct {
+		name   string
+		modify func(config *entities.DockerConfig)
+		want   string
+	}{
+		{"no containers", func(c *entities.DockerConfig) { c.Containers = nil }, "lists no containers"},
+		{"bad name", func(c *entities.DockerConfig) { c.Containers = []string{"../app"} }, "invalid container name"},
+		{"no CA", func(c *entities.DockerConfig) { c.TLS = nil }, "requires a CA certificate"},
+		{"relative socket", func(c *entities.DockerConfig) {
+			c.Transport = entities.DockerTransportSSH
+			c.SocketPath = "docker.sock"
+		}, "must be absolute"},
+		{"unknown transport", func(c *entities.DockerConfig) { c.Transport = "tcp" }, "unknown docker transport"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := primitive.NewObjectID()
+			env := newDockerTLSEnv(id, engine, "app")
+			tt.modify(env.Commands.Docker)
+			svc := newDockerService(t, new(MockEnvironmentRepository), env)
+
+			err := svc.RestartEnvironment(context.Background(), id.Hex(), false)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+	assert.Empty(t, engine.Requests())
+}
+
+func TestService_UpgradeEnvironment_DockerImageTemplate(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "registry.example.com/app:1.0.0")
+
+	id := primitive.NewObjectID()
+	env := newDockerTLSEnv(id, engine, "app")
+	env.Commands.Docker.Image = "registry.example.com/app-next:{VERSION}"
+	svc := newDockerService(t, new(MockEnvironmentRepository), env)
+
+	require.NoError(t, svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0.0"))
+
+	container, _ := engine.Container("app")
+	assert.Equal(t, "registry.example.com/app-next:2.0.0", container.Image)
+	assert.True(t, container.Running)
+	assert.Equal(t, "2.0.0", env.SystemInfo.AppVersion)
+}
+
+func TestService_UpgradeEnvironment_DockerCurrentRepository(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "registry.example.com:5000/app:1.0.0")
+	engine.AddContainer("worker", "registry.example.com:5000/worker:1.0.0")
+
+	id := primitive.NewObjectID()
+	svc := newDockerService(t, new(MockEnvironmentRepository), newDockerTLSEnv(id, engine, "app", "worker"))
+
+	require.NoError(t, svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0.0"))
+
+	app, _ := engine.Container("app")
+	worker, _ := engine.Container("worker")
+	assert.Equal(t, "registry.example.com:5000/app:2.0.0", app.Image)
+	assert.Equal(t, "registry.example.com:5000/worker:2.0.0", worker.Image)
+}
+
+func TestService_UpgradeEnvironment_DockerInvalidTag(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+
+	id := primitive.NewObjectID()
+	svc := newDockerService(t, new(MockEnvironmentRepository), newDockerTLSEnv(id, engine, "app"))
+
+	err := svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0; rm -rf /")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid image tag")
+	assert.Empty(t, engine.Requests())
+}
+
+func TestService_UpgradeEnvironment_DockerRollsBack(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+	engine.FailStart("app:2.0.0")
+
+	id := primitive.NewObjectID()
+	env := newDockerTLSEnv(id, engine, "app")
+	env.SystemInfo.AppVersion = "1.0.0"
+	svc := newDockerService(t, new(MockEnvironmentRepository), env)
+
+	err := svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start app from app:2.0.0")
+
+	container, _ := engine.Container("app")
+	assert.Equal(t, "app:1.0.0", container.Image)
+	assert.True(t, container.Running)
+	assert.Equal(t, "1.0.0", env.SystemInfo.AppVersion)
+}
+
+func TestService_CheckHealth_DockerContainers(t *testing.T) {
+	tests := []struct {
+		name    string
+		running bool
+		health  string
+		want    entities.HealthStatus
+		message string
+	}{
+		{"running", true, "", entities.HealthStatusHealthy, "1 containers running"},
+		{"healthy", true, "healthy", entities.HealthStatusHealthy, "1 containers running"},
+		{"unhealthy", true, "unhealthy", entities.HealthStatusUnhealthy, "app is unhealthy"},
+		{"stopped", false, "", entities.HealthStatusUnhealthy, "app is exited"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := dockertest.NewServer(t)
+			engine.AddContainer("app", "app:1.0.0")
+			engine.SetState("app", tt.running, tt.health)
+
+			id := primitive.NewObjectID()
+			repo := new(MockEnvironmentRepository)
+			svc := newDockerService(t, repo, newDockerTLSEnv(id, engine, "app"))
+
+			require.NoError(t, svc.CheckHealth(context.Background(), id.Hex()))
+
+			status := updatedStatus(t, repo)
+			assert.Equal(t, tt.want, status.Health)
+			assert.Contains(t, status.Message, tt.message)
+		})
+	}
+}
+
+func TestService_CheckHealth_DockerUnreachable(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	id := primitive.NewObjectID()
+	env := newDockerTLSEnv(id, engine, "app")
+	env.Commands.Docker.Port = 1
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+
+	require.NoError(t, svc.CheckHealth(context.Background(), id.Hex()))
+	assert.Equal(t, entities.HealthStatusUnhealthy, updatedStatus(t, repo).Health)
+}
+
+func TestService_RunCustomAction_DockerUnsupported(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	id := primitive.NewObjectID()
+	env := newDockerTLSEnv(id, engine, "app")
+	env.Commands.Actions = []entities.CustomAction{{Name: "prune"}}
+	svc := newDockerService(t, new(MockEnvironmentRepository), env)
+
+	err := svc.RunCustomAction(context.Background(), id.Hex(), "prune")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported for docker commands")
+}
+
+func TestService_PlanRestart_Docker(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "app:1.0.0")
+
+	id := primitive.NewObjectID()
+	env := newDockerTLSEnv(id, engine, "app", "worker")
+	svc := newDockerService(t, new(MockEnvironmentRepository), env)
+
+	plan, err := svc.PlanRestart(context.Background(), id.Hex(), false)
+	require.NoError(t, err)
+	assert.True(t, plan.Valid)
+	require.Len(t, plan.Steps, 2)
+	assert.Equal(t, entities.CommandTypeDocker, plan.Steps[0].Type)
+	assert.Equal(t, "POST", plan.Steps[0].Method)
+	assert.Equal(t, "/containers/app/restart?t=10", plan.Steps[0].URL)
+	assert.Equal(t, engine.Port(), plan.Steps[0].Port)
+	assert.Equal(t, []string{"GET /_ping"}, engine.Requests(), "nothing is restarted")
+}
+
+func TestService_PlanUpgrade_Docker(t *testing.T) {
+	engine := dockertest.NewServer(t)
+	engine.AddContainer("app", "registry.example.com/app:1.0.0")
+
+	id := primitive.NewObjectID()
+	svc := newDockerService(t, new(MockEnvironmentRepository), newDockerTLSEnv(id, engine, "app", "missing"))
+
+	plan, err := svc.PlanUpgrade(context.Background(), id.Hex(), "2.0.0")
+	require.NoError(t, err)
+	assert.False(t, plan.Valid, "the missing container fails its image check")
+	require.Len(t, plan.Steps, 1)
+	assert.Equal(t, "pull registry.example.com/app:2.0.0 and recreate app", plan.Steps[0].Command)
+
+	checks := map[string]bool{}
+	for _, check := range plan.Checks {
+		checks[check.Name+" "+check.Target] = check.Passed
+	}
+	assert.True(t, checks["docker_connection 127.0.0.1"])
+	assert.True(t, checks["image app"])
+	assert.False(t, checks["image missing"])
+
+	container, _ := engine.Container("app")
+	assert.Equal(t, "registry.example.com/app:1.0.0", container.Image, "nothing is upgraded")
+}
+
+// updatedStatus returns the status the service last stored
+func updatedStatus(t *testing.T, repo *MockEnvironmentRepository) entities.Status {
+	t.Helper()
+	for i := len(repo.Calls) - 1; i >= 0; i-- {
+		if repo.Calls[i].Method == "UpdateStatus" {
+			return repo.Calls[i].Arguments.Get(2).(entities.Status)
+		}
+	}
+	t.Fatal("status was not updated")
+	return entities.Status{}
+}
@@ -0,0 +1,295 @@
+package environment_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// closedPort returns a local port nothing is listening on
+func closedPort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+	return port
+}
+
+func checkByName(plan *entities.OperationPlan, name string) (entities.PlanCheck, bool) {
+	for _, check := range plan.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return entities.PlanCheck{}, false
+}
+
+func TestService_PlanRestart_HTTPDoesNotExecute(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestServiceWithAllowedHosts(repo, logRepo, []string{"127.0.0.1"})
+
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Commands = entities.CommandConfig{
+		Type: entities.CommandTypeHTTP,
+		Restart: entities.RestartConfig{
+			Enabled: true,
+			URL:     srv.URL + "/restart?token=abc&mode=fast",
+			Headers: map[string]string{"Authorization": "Bearer abc", "X-Trace": "1"},
+			Body:    map[string]interface{}{"nested": map[string]interface{}{"password": "hunter2"}, "mode": "fast"},
+		},
+	}
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	plan, err := svc.PlanRestart(context.Background(), id.Hex(), false)
+
+	require.NoError(t, err)
+	assert.False(t, called, "dry run must not call the endpoint")
+	assert.True(t, plan.DryRun)
+	assert.True(t, plan.Valid)
+	require.Len(t, plan.Steps, 1)
+	step := plan.Steps[0]
+	assert.Equal(t, "POST", step.Method)
+	assert.Contains(t, step.URL, "token=REDACTED")
+	assert.Contains(t, step.URL, "mode=fast")
+	assert.Equal(t, "REDACTED", step.Headers["Authorization"])
+	assert.Equal(t, "1", step.Headers["X-Trace"])
+	assert.Equal(t, "REDACTED", step.Body["nested"].(map[string]interface{})["password"])
+	assert.Equal(t, "hunter2", env.Commands.Restart.Body["nested"].(map[string]interface{})["password"],
+		"masking must not modify the environment")
+	logRepo.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_PlanRestart_SSRFBlocked(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Commands = entities.CommandConfig{
+		Type:    entities.CommandTypeHTTP,
+		Restart: entities.RestartConfig{Enabled: true, URL: "http://169.254.169.254/latest"},
+	}
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanRestart(context.Background(), id.Hex(), false)
+
+	require.NoError(t, err)
+	assert.False(t, plan.Valid)
+	check, ok := checkByName(plan, "url")
+	require.True(t, ok)
+	assert.False(t, check.Passed)
+}
+
+func TestService_PlanRestart_NotEnabled(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newSampleEnv(id), nil)
+
+	_, err := svc.PlanRestart(context.Background(), id.Hex(), false)
+	assert.Error(t, err)
+}
+
+func TestService_PlanUpgrade_SSHRendersCommands(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Target = entities.Target{Host: "127.0.0.1", Port: closedPort(t)}
+	env.Credentials = entities.CredentialRef{Type: "password", Username: "deploy"}
+	env.Metadata = map[string]interface{}{"password": "secret", "insecureSkipHostKeyVerification": true}
+	env.UpgradeConfig = entities.UpgradeConfig{
+		Enabled: true,
+		Type:    entities.CommandTypeSSH,
+		UpgradeCommand: entities.CommandDetails{
+			Command: "app-upgrade --version={VERSION}\n\nrm -rf /tmp/cache; reboot",
+		},
+	}
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanUpgrade(context.Background(), id.Hex(), "2.0.0")
+
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 2)
+	assert.Equal(t, "app-upgrade --version=2.0.0", plan.Steps[0].Command)
+	assert.Equal(t, "deploy", plan.Steps[0].Username)
+	assert.Equal(t, "password", plan.Steps[0].Auth)
+	assert.False(t, plan.Valid)
+
+	var commandChecks []entities.PlanCheck
+	for _, check := range plan.Checks {
+		if check.Name == "command" {
+			commandChecks = append(commandChecks, check)
+		}
+	}
+	require.Len(t, commandChecks, 2)
+	assert.True(t, commandChecks[0].Passed)
+	assert.False(t, commandChecks[1].Passed, "chained commands are rejected")
+
+	credentials, ok := checkByName(plan, "credentials")
+	require.True(t, ok)
+	assert.True(t, credentials.Passed)
+	connection, ok := checkByName(plan, "ssh_connection")
+	require.True(t, ok)
+	assert.False(t, connection.Passed)
+}
+
+func TestService_PlanUpgrade_MissingCredentials(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Credentials = entities.CredentialRef{Type: "key", Username: "deploy"}
+	env.UpgradeConfig = entities.UpgradeConfig{Enabled: true, Type: entities.CommandTypeSSH}
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanUpgrade(context.Background(), id.Hex(), "2.0.0")
+
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 1)
+	assert.Equal(t, "sudo app-upgrade --version=2.0.0", plan.Steps[0].Command)
+	credentials, ok := checkByName(plan, "credentials")
+	require.True(t, ok)
+	assert.False(t, credentials.Passed)
+	_, ok = checkByName(plan, "ssh_connection")
+	assert.False(t, ok, "connection is not attempted without credentials")
+}
+
+func TestService_PlanCustomAction(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestServiceWithAllowedHosts(repo, logRepo, []string{"127.0.0.1"})
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newActionEnv(id, "http://127.0.0.1:9/cache"), nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanCustomAction(context.Background(), id.Hex(), "clear-cache")
+	require.NoError(t, err)
+	assert.Equal(t, "custom_action", plan.Operation)
+	assert.True(t, plan.Valid)
+
+	_, err = svc.PlanCustomAction(context.Background(), id.Hex(), "missing")
+	assert.Equal(t, errors.ErrActionNotFound, err)
+}
+
+func TestService_PlanCustomAction_InvalidAssertions(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestServiceWithAllowedHosts(repo, logRepo, []string{"127.0.0.1"})
+
+	id := primitive.NewObjectID()
+	env := newActionEnv(id, "http://127.0.0.1:9/cache")
+	env.Commands.Actions[0].Command.Success = &entities.SuccessCriteria{BodyRegex: "("}
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanCustomAction(context.Background(), id.Hex(), "clear-cache")
+	require.NoError(t, err)
+	assert.False(t, plan.Valid)
+
+	check, ok := checkByName(plan, "assertions")
+	require.True(t, ok)
+	assert.False(t, check.Passed)
+	assert.Contains(t, check.Error, "invalid bodyRegex")
+}
+
+func TestService_PlanUpgrade_SSHScript(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Target = entities.Target{Host: "127.0.0.1", Port: closedPort(t)}
+	env.Credentials = entities.CredentialRef{Type: "password", Username: "deploy"}
+	env.Metadata = map[string]interface{}{"password": "secret", "insecureSkipHostKeyVerification": true}
+	env.UpgradeConfig = entities.UpgradeConfig{
+		Enabled: true,
+		Type:    entities.CommandTypeSSH,
+		UpgradeCommand: entities.CommandDetails{Script: &entities.ScriptConfig{
+			Body:        "set -e\napp-upgrade --version=\"$VERSION\" | tee /var/log/upgrade.log\n",
+			Interpreter: "/bin/bash",
+			Env:         map[string]string{"API_TOKEN": "abc123"},
+		}},
+	}
+	env.SystemInfo.AppVersion = "1.0.0"
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanUpgrade(context.Background(), id.Hex(), "2.0.0")
+	require.NoError(t, err)
+
+	require.Len(t, plan.Steps, 1)
+	step := plan.Steps[0]
+	require.NotNil(t, step.Script)
+	assert.Equal(t, "/bin/bash", step.Script.Interpreter)
+	assert.Equal(t, "2.0.0", step.Script.Env["VERSION"])
+	assert.Equal(t, "1.0.0", step.Script.Env["CURRENT_VERSION"])
+	assert.Equal(t, "REDACTED", step.Script.Env["API_TOKEN"])
+
+	check, ok := checkByName(plan, "script")
+	require.True(t, ok)
+	assert.True(t, check.Passed, "pipes are fine inside scripts")
+	_, ok = checkByName(plan, "command")
+	assert.False(t, ok)
+	connection, ok := checkByName(plan, "ssh_connection")
+	require.True(t, ok)
+	assert.False(t, connection.Passed)
+}
+
+func TestService_PlanCustomAction_InvalidScript(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Commands = entities.CommandConfig{
+		Type: entities.CommandTypeSSH,
+		Actions: []entities.CustomAction{{
+			Name:    "rotate-logs",
+			Command: entities.CommandDetails{Script: &entities.ScriptConfig{Body: "logrotate -f /etc/logrotate.conf", Interpreter: "sh"}},
+		}},
+	}
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	plan, err := svc.PlanCustomAction(context.Background(), id.Hex(), "rotate-logs")
+	require.NoError(t, err)
+	assert.False(t, plan.Valid)
+
+	check, ok := checkByName(plan, "script")
+	require.True(t, ok)
+	assert.Contains(t, check.Error, "interpreter must be an absolute path")
+	assert.Equal(t, "rotate-logs", plan.Steps[0].Script.Env["ACTION"])
+}
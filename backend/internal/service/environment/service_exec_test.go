@@ -0,0 +1,135 @@
+package environment_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newExecEnv returns an environment that connects to the test SSH server
+func newExecEnv(id primitive.ObjectID, server *sshtest.Server, rules ...entities.CommandRule) *entities.Environment {
+	env := newSampleEnv(id)
+	env.Target = entities.Target{Host: "127.0.0.1", Port: server.Port()}
+	env.Credentials = entities.CredentialRef{Type: "password", Username: sshtest.Username}
+	env.Metadata = map[string]interface{}{"password": sshtest.Password, "hostKey": string(server.HostKey())}
+	env.Commands = entities.CommandConfig{Type: entities.CommandTypeSSH, Allowlist: rules}
+	return env
+}
+
+func TestService_ExecCommand_Success(t *testing.T) {
+	server := sshtest.NewServer(t)
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	auditRepo := new(MockAuditLogRepository)
+	svc := newAllowlistService(repo, logRepo, auditRepo, nil)
+	entries := captureAudit(auditRepo)
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newExecEnv(id, server, entities.CommandRule{
+		Name: "echo", Type: entities.CommandRuleGlob, Pattern: "echo {word}",
+	}), nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	ctx := ctxutil.WithUser(context.Background(), "u1", "alice")
+	result, err := svc.ExecCommand(ctx, id.Hex(), "echo hello")
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte("hello\n"))
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "hello\n", result.Output)
+	assert.Equal(t, hex.EncodeToString(sum[:]), result.OutputSHA256)
+	assert.Equal(t, "echo", result.Rule)
+	assert.Equal(t, "environment", result.RuleScope)
+	assert.Equal(t, map[string]string{"word": "hello"}, result.Params)
+
+	entry := waitForAudit(t, entries, entities.EventTypeCommandExecuted)
+	assert.Equal(t, "alice", entry.Actor.Name)
+	metadata := auditMetadata(entry)
+	assert.Equal(t, "echo hello", metadata["command"])
+	assert.Equal(t, 0, metadata["exitCode"])
+	assert.Equal(t, result.OutputSHA256, metadata["outputSha256"])
+	assert.NotContains(t, metadata, "output", "output itself is not stored")
+}
+
+func TestService_ExecCommand_NonZeroExit(t *testing.T) {
+	server := sshtest.NewServer(t)
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	auditRepo := new(MockAuditLogRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	svc := newAllowlistService(repo, logRepo, auditRepo, []entities.CommandRule{
+		{Name: "ls", Type: entities.CommandRulePrefix, Pattern: "ls "},
+	})
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newExecEnv(id, server), nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	result, err := svc.ExecCommand(context.Background(), id.Hex(), "ls /does-not-exist")
+	require.NoError(t, err)
+	assert.NotEqual(t, 0, result.ExitCode)
+	assert.Equal(t, "global", result.RuleScope)
+}
+
+func TestService_ExecCommand_RequiresAllowlist(t *testing.T) {
+	server := sshtest.NewServer(t)
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	auditRepo := new(MockAuditLogRepository)
+	svc := newAllowlistService(repo, logRepo, auditRepo, nil)
+	entries := captureAudit(auditRepo)
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newExecEnv(id, server), nil)
+
+	_, err := svc.ExecCommand(context.Background(), id.Hex(), "df -h")
+	require.Error(t, err)
+	domainErr, ok := err.(errors.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, "COMMAND_NOT_ALLOWED", domainErr.Code)
+	assert.Contains(t, domainErr.Details["reason"], "no allowlist")
+
+	denied := waitForAudit(t, entries, entities.EventTypeCommandDenied)
+	assert.Equal(t, "df -h", auditMetadata(denied)["command"])
+	assert.Empty(t, server.Commands(), "nothing ran on the host")
+}
+
+func TestService_ExecCommand_NotAllowed(t *testing.T) {
+	server := sshtest.NewServer(t)
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	auditRepo := new(MockAuditLogRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	svc := newAllowlistService(repo, logRepo, auditRepo, nil)
+
+	id := primitive.NewObjectID()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(newExecEnv(id, server, entities.CommandRule{
+		Type: entities.CommandRuleExact, Pattern: "df -h",
+	}), nil)
+
+	_, err := svc.ExecCommand(context.Background(), id.Hex(), "rm -rf /tmp/data")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCommandNotAllowed.Code, err.(errors.DomainError).Code)
+	assert.Empty(t, server.Commands())
+}
+
+func TestService_ExecCommand_NotFound(t *testing.T) {
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	svc := newTestService(repo, logRepo)
+
+	repo.On("GetByID", mock.Anything, "missing").Return(nil, errors.ErrEnvironmentNotFound)
+
+	_, err := svc.ExecCommand(context.Background(), "missing", "df -h")
+	assert.Equal(t, errors.ErrEnvironmentNotFound, err)
+}
@@ -0,0 +1,192 @@
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/environment"
+	"app-env-manager/internal/service/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// commandTypeEcho is a command type no built-in driver handles
+const commandTypeEcho entities.CommandType = "echo"
+
+// echoExecutor records its requests and answers with a fixed result
+type echoExecutor struct {
+	requests []*executor.Request
+	result   *executor.Result
+}
+
+func (e *echoExecutor) Execute(ctx context.Context, req *executor.Request) *executor.Result {
+	e.requests = append(e.requests, req)
+	if e.result != nil {
+		return e.result
+	}
+	return executor.Success("echoed " + string(req.Operation))
+}
+
+// planningEchoExecutor also supports dry runs
+type planningEchoExecutor struct {
+	echoExecutor
+}
+
+func (e *planningEchoExecutor) Plan(ctx context.Context, req *executor.Request, plan *entities.OperationPlan) {
+	plan.Steps = append(plan.Steps, entities.PlanStep{Type: commandTypeEcho, Command: req.Command.Command})
+	plan.AddCheck("echo", "", nil)
+}
+
+// newEchoService returns a service with the echo driver registered and an
+// environment using it
+func newEchoService(t *testing.T, e executor.Executor) (*environment.Service, *MockEnvironmentRepository, *entities.Environment) {
+	t.Helper()
+	id := primitive.NewObjectID()
+	env := newSampleEnv(id)
+	env.Commands = entities.CommandConfig{
+		Type:    commandTypeEcho,
+		Restart: entities.RestartConfig{Enabled: true, Command: "restart app"},
+		Actions: []entities.CustomAction{
+			{Name: "flush", Command: entities.CommandDetails{Command: "flush cache"}},
+		},
+	}
+	env.UpgradeConfig = entities.UpgradeConfig{
+		Enabled:        true,
+		Type:           commandTypeEcho,
+		UpgradeCommand: entities.CommandDetails{Command: "deploy {VERSION}"},
+	}
+
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+	svc.RegisterExecutor(commandTypeEcho, e)
+	return svc, repo, env
+}
+
+func TestService_RegisterExecutor_Operations(t *testing.T) {
+	echo := &echoExecutor{}
+	svc, _, env := newEchoService(t, echo)
+	env.SystemInfo.AppVersion = "1.0.0"
+	ctx := context.Background()
+
+	require.NoError(t, svc.RestartEnvironment(ctx, env.ID.Hex(), true))
+	require.NoError(t, svc.UpgradeEnvironment(ctx, env.ID.Hex(), "2.0.0"))
+	require.NoError(t, svc.RunCustomAction(ctx, env.ID.Hex(), "flush"))
+
+	require.Len(t, echo.requests, 3)
+	restart, upgrade, action := echo.requests[0], echo.requests[1], echo.requests[2]
+
+	assert.Equal(t, executor.OperationRestart, restart.Operation)
+	assert.Equal(t, "restart app", restart.Command.Command)
+	assert.Equal(t, "true", restart.Param(executor.ParamForce))
+	assert.Same(t, env, restart.Environment)
+
+	assert.Equal(t, executor.OperationUpgrade, upgrade.Operation)
+	assert.Equal(t, "deploy 2.0.0", upgrade.Command.Command)
+	assert.Equal(t, "2.0.0", upgrade.Param(executor.ParamVersion))
+	assert.Equal(t, "1.0.0", upgrade.Param(executor.ParamCurrentVersion))
+
+	assert.Equal(t, executor.OperationCustomAction, action.Operation)
+	assert.Equal(t, "flush cache", action.Command.Command)
+	assert.Equal(t, "flush", action.Param(executor.ParamAction))
+}
+
+func TestService_RegisterExecutor_Failure(t *testing.T) {
+	echo := &echoExecutor{result: &executor.Result{
+		Status:   executor.StatusFailed,
+		Output:   "disk full",
+		ExitCode: 28,
+	}}
+	svc, _, env := newEchoService(t, echo)
+
+	err := svc.RestartEnvironment(context.Background(), env.ID.Hex(), false)
+
+	require.Error(t, err)
+	assert.Equal(t, "restart failed: disk full", err.Error())
+}
+
+func TestService_RegisterExecutor_Unsupported(t *testing.T) {
+	echo := &echoExecutor{result: executor.Unsupported(commandTypeEcho, executor.OperationCustomAction)}
+	svc, _, env := newEchoService(t, echo)
+
+	err := svc.RunCustomAction(context.Background(), env.ID.Hex(), "flush")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "custom actions are not supported for echo commands")
+}
+
+func TestService_UnregisteredCommandType(t *testing.T) {
+	svc, _, env := newEchoService(t, &echoExecutor{})
+	env.Commands.Type = "ftp"
+	env.UpgradeConfig.Type = "ftp"
+	ctx := context.Background()
+
+	err := svc.RestartEnvironment(ctx, env.ID.Hex(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported command type: "ftp"`)
+
+	err = svc.UpgradeEnvironment(ctx, env.ID.Hex(), "2.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported command type: "ftp"`)
+
+	plan, err := svc.PlanRestart(ctx, env.ID.Hex(), false)
+	require.NoError(t, err)
+	assert.False(t, plan.Valid)
+	check, ok := checkByName(plan, "command_type")
+	require.True(t, ok)
+	assert.Contains(t, check.Error, "unsupported command type")
+}
+
+func TestService_RegisterExecutor_HealthProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *executor.Result
+		want    entities.HealthStatus
+		message string
+	}{
+		{"observed", &executor.Result{Status: executor.StatusSucceeded, Health: entities.HealthStatusHealthy, Output: "up"},
+			entities.HealthStatusHealthy, "up"},
+		{"unsupported", executor.Unsupported(commandTypeEcho, executor.OperationHealth),
+			entities.HealthStatusUnknown, "Health check disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			echo := &echoExecutor{result: tt.result}
+			svc, repo, env := newEchoService(t, echo)
+
+			require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+			require.Len(t, echo.requests, 1)
+			assert.Equal(t, executor.OperationHealth, echo.requests[0].Operation)
+			status := updatedStatus(t, repo)
+			assert.Equal(t, tt.want, status.Health)
+			assert.Equal(t, tt.message, status.Message)
+		})
+	}
+}
+
+func TestService_RegisterExecutor_Plan(t *testing.T) {
+	planner := &planningEchoExecutor{}
+	svc, _, env := newEchoService(t, planner)
+
+	plan, err := svc.PlanUpgrade(context.Background(), env.ID.Hex(), "2.0.0")
+
+	require.NoError(t, err)
+	assert.True(t, plan.Valid)
+	require.Len(t, plan.Steps, 1)
+	assert.Equal(t, "deploy 2.0.0", plan.Steps[0].Command)
+	assert.Empty(t, planner.requests, "dry runs must not execute")
+}
+
+func TestService_RegisterExecutor_PlanUnsupported(t *testing.T) {
+	svc, _, env := newEchoService(t, &echoExecutor{})
+
+	plan, err := svc.PlanCustomAction(context.Background(), env.ID.Hex(), "flush")
+
+	require.NoError(t, err)
+	assert.False(t, plan.Valid)
+	check, ok := checkByName(plan, "command_type")
+	require.True(t, ok)
+	assert.Equal(t, "dry runs are not supported for echo commands", check.Error)
+}
@@ -0,0 +1,193 @@
+package environment_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/environment"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newHookEnv returns an SSH environment on the test server that restarts
+// with "echo restarted" and may run any echo command or false
+func newHookEnv(server *sshtest.Server, hooks *entities.Hooks) *entities.Environment {
+	env := newExecEnv(primitive.NewObjectID(), server,
+		entities.CommandRule{Type: entities.CommandRuleGlob, Pattern: "echo {word}"},
+		entities.CommandRule{Type: entities.CommandRuleExact, Pattern: "false"})
+	env.Commands.Restart = entities.RestartConfig{Enabled: true, Command: "echo restarted", Hooks: hooks}
+	return env
+}
+
+// newHookService returns a service for env whose log entries are captured
+func newHookService(t *testing.T, env *entities.Environment) (*environment.Service, *[]*entities.Log) {
+	t.Helper()
+	repo := new(MockEnvironmentRepository)
+	repo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	repo.On("Update", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	repo.On("UpdateStatus", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	logRepo := new(MockLogRepository)
+	var logs []*entities.Log
+	logRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		logs = append(logs, args.Get(1).(*entities.Log))
+	}).Return(nil).Maybe()
+	auditRepo := new(MockAuditLogRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return newAllowlistService(repo, logRepo, auditRepo, nil), &logs
+}
+
+func sshHook(name, command string) entities.Hook {
+	return entities.Hook{Name: name, Type: entities.CommandTypeSSH, Command: entities.CommandDetails{Command: command}}
+}
+
+func TestService_RestartEnvironment_HooksRunInOrder(t *testing.T) {
+	server := sshtest.NewServer(t)
+	env := newHookEnv(server, &entities.Hooks{
+		Before:       []entities.Hook{sshHook("drain", "echo drain")},
+		AfterSuccess: []entities.Hook{sshHook("enable", "echo enable")},
+		AfterFailure: []entities.Hook{sshHook("page", "echo page")},
+		Always:       []entities.Hook{sshHook("notify", "echo notify")},
+	})
+	svc, logs := newHookService(t, env)
+
+	require.NoError(t, svc.RestartEnvironment(context.Background(), env.ID.Hex(), false))
+
+	assert.Equal(t, []string{"echo drain", "echo restarted", "echo enable", "echo notify"}, server.Commands())
+
+	var messages []string
+	for _, entry := range *logs {
+		messages = append(messages, entry.Message)
+	}
+	assert.Contains(t, messages, `before hook "drain" completed`)
+	assert.Contains(t, messages, `always hook "notify" completed`)
+
+	last := (*logs)[len(*logs)-1]
+	assert.Equal(t, "Restart operation completed successfully", last.Message)
+	hooks := last.Details["hooks"].([]entities.HookResult)
+	require.Len(t, hooks, 3)
+	assert.Equal(t, entities.HookPhaseAfterSuccess, hooks[1].Phase)
+	assert.True(t, hooks[1].Success)
+}
+
+func TestService_RestartEnvironment_BeforeHookFails(t *testing.T) {
+	server := sshtest.NewServer(t)
+	env := newHookEnv(server, &entities.Hooks{
+		Before:       []entities.Hook{sshHook("drain", "false"), sshHook("skipped", "echo skipped")},
+		AfterSuccess: []entities.Hook{sshHook("enable", "echo enable")},
+		AfterFailure: []entities.Hook{sshHook("page", "echo page")},
+		Always:       []entities.Hook{sshHook("notify", "echo notify")},
+	})
+	svc, _ := newHookService(t, env)
+
+	err := svc.RestartEnvironment(context.Background(), env.ID.Hex(), false)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `before hook "drain" failed`)
+	assert.Equal(t, []string{"false", "echo page", "echo notify"}, server.Commands(),
+		"the restart and remaining before hooks do not run")
+	assert.Nil(t, env.Timestamps.LastRestartAt)
+}
+
+func TestService_RestartEnvironment_IgnoredHookFailure(t *testing.T) {
+	server := sshtest.NewServer(t)
+	drain := sshHook("drain", "false")
+	drain.FailPolicy = entities.HookFailPolicyIgnore
+	env := newHookEnv(server, &entities.Hooks{Before: []entities.Hook{drain}})
+	svc, logs := newHookService(t, env)
+
+	require.NoError(t, svc.RestartEnvironment(context.Background(), env.ID.Hex(), false))
+
+	assert.Equal(t, []string{"false", "echo restarted"}, server.Commands())
+	hooks := (*logs)[len(*logs)-1].Details["hooks"].([]entities.HookResult)
+	require.Len(t, hooks, 1)
+	assert.False(t, hooks[0].Success)
+	assert.True(t, hooks[0].Ignored)
+}
+
+func TestService_RestartEnvironment_AfterHookFailsOperation(t *testing.T) {
+	server := sshtest.NewServer(t)
+	env := newHookEnv(server, &entities.Hooks{
+		AfterSuccess: []entities.Hook{sshHook("enable", "false")},
+		AfterFailure: []entities.Hook{sshHook("page", "echo page")},
+		Always:       []entities.Hook{sshHook("notify", "echo notify")},
+	})
+	svc, _ := newHookService(t, env)
+
+	err := svc.RestartEnvironment(context.Background(), env.ID.Hex(), false)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `after_success hook "enable" failed`)
+	assert.Equal(t, []string{"echo restarted", "false", "echo notify"}, server.Commands())
+}
+
+func TestService_UpgradeEnvironment_HTTPHookTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(5 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	env := newSimulatedEnv(nil)
+	env.UpgradeConfig.Hooks = &entities.Hooks{Before: []entities.Hook{{
+		Name:    "drain",
+		Type:    entities.CommandTypeHTTP,
+		Command: entities.CommandDetails{URL: srv.URL + "/drain"},
+		Timeout: 1,
+	}}}
+	repo := new(MockEnvironmentRepository)
+	repo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	logRepo := new(MockLogRepository)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	svc := newTestServiceWithAllowedHosts(repo, logRepo, []string{"127.0.0.1"})
+
+	err := svc.UpgradeEnvironment(context.Background(), env.ID.Hex(), "2.0.0")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `before hook "drain" failed: timed out after 1s`)
+	assert.Equal(t, "1.0.0", env.SystemInfo.AppVersion)
+}
+
+func TestService_RestartEnvironment_InvalidHook(t *testing.T) {
+	env := newSimulatedEnv(nil)
+	env.Commands.Restart.Hooks = &entities.Hooks{Always: []entities.Hook{{Name: "cleanup", Type: entities.CommandTypeDocker}}}
+	svc, _ := newSimulatedService(t, env)
+
+	err := svc.RestartEnvironment(context.Background(), env.ID.Hex(), false)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `always hook "cleanup" failed: hooks must be ssh or http steps, not "docker"`)
+}
+
+func TestService_PlanRestart_Hooks(t *testing.T) {
+	server := sshtest.NewServer(t)
+	env := newHookEnv(server, &entities.Hooks{
+		Before: []entities.Hook{sshHook("drain", "echo drain")},
+		Always: []entities.Hook{sshHook("notify", "rm -rf /"), {Name: "empty", Type: entities.CommandTypeHTTP}},
+	})
+	svc, _ := newHookService(t, env)
+
+	plan, err := svc.PlanRestart(context.Background(), env.ID.Hex(), false)
+
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 3)
+	assert.Equal(t, entities.HookPhaseBefore, plan.Steps[0].Hook)
+	assert.Equal(t, "echo drain", plan.Steps[0].Command)
+	assert.Empty(t, plan.Steps[1].Hook)
+	assert.Equal(t, "echo restarted", plan.Steps[1].Command)
+	assert.Equal(t, entities.HookPhaseAlways, plan.Steps[2].Hook)
+
+	assert.False(t, plan.Valid, "the notify hook is not allowed and the empty hook has no URL")
+	check, ok := checkByName(plan, "hook")
+	require.True(t, ok)
+	assert.Equal(t, "empty", check.Target)
+	assert.NotContains(t, server.Commands(), "echo drain", "dry runs do not run hooks")
+}
@@ -0,0 +1,273 @@
+package environment_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/environment"
+	"app-env-manager/internal/service/kubernetes"
+	"app-env-manager/internal/service/kubernetes/kubetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newKubernetesEnv returns an environment whose workload is a Deployment on
+// the fake API server, authenticated with a token
+func newKubernetesEnv(id primitive.ObjectID, api *kubetest.Server, name string) *entities.Environment {
+	env := newSampleEnv(id)
+	env.Credentials = entities.CredentialRef{Type: entities.CredentialTypeToken}
+	env.Metadata = map[string]interface{}{"kubernetesToken": kubetest.Token}
+	env.Commands = entities.CommandConfig{
+		Type:    entities.CommandTypeKubernetes,
+		Restart: entities.RestartConfig{Enabled: true},
+		Kubernetes: &entities.KubernetesConfig{
+			Server:    api.URL(),
+			CACert:    string(api.CACert()),
+			Namespace: "shop",
+			Name:      name,
+		},
+	}
+	env.UpgradeConfig = entities.UpgradeConfig{Enabled: true, Type: entities.CommandTypeKubernetes}
+	return env
+}
+
+// newKubernetesService returns a service that trusts the fake API server's
+// loopback address
+func newKubernetesService(t *testing.T, repo *MockEnvironmentRepository, env *entities.Environment) *environment.Service {
+	t.Helper()
+	logRepo := new(MockLogRepository)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	repo.On("Update", mock.Anything, env.ID.Hex(), mock.Anything).Return(nil).Maybe()
+	return newTestServiceWithAllowedHosts(repo, logRepo, []string{"127.0.0.1"})
+}
+
+func TestService_RestartEnvironment_Kubernetes(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 3, "app", "registry.example.com/web:1.0.0")
+
+	id := primitive.NewObjectID()
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), newKubernetesEnv(id, api, "web"))
+
+	require.NoError(t, svc.RestartEnvironment(context.Background(), id.Hex(), false))
+
+	workload, _ := api.Workload("Deployment", "shop", "web")
+	assert.NotEmpty(t, workload.Annotations[kubernetes.RestartedAtAnnotation])
+	assert.Equal(t, int64(2), workload.Generation)
+	assert.Equal(t, "registry.example.com/web:1.0.0", workload.Images["app"])
+	assert.Equal(t, "GET /apis/apps/v1/namespaces/shop/deployments/web", api.Requests()[1],
+		"the rollout is waited for")
+}
+
+func TestService_RestartEnvironment_KubernetesKubeconfig(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddStatefulSet("data", "db", 2, "postgres", "postgres:15")
+
+	id := primitive.NewObjectID()
+	env := newKubernetesEnv(id, api, "db")
+	env.Credentials.Type = entities.CredentialTypeKubeconfig
+	env.Metadata = map[string]interface{}{"kubeconfig": fmt.Sprintf(`
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: deployer
+  user:
+    token: %s
+contexts:
+- name: test
+  context: {cluster: test, user: deployer, namespace: data}
+`, api.URL(), base64.StdEncoding.EncodeToString(api.CACert()), kubetest.Token)}
+	env.Commands.Kubernetes = &entities.KubernetesConfig{Kind: entities.KubernetesKindStatefulSet, Name: "db"}
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), env)
+
+	require.NoError(t, svc.RestartEnvironment(context.Background(), id.Hex(), false))
+
+	workload, _ := api.Workload("StatefulSet", "data", "db")
+	assert.NotEmpty(t, workload.Annotations[kubernetes.RestartedAtAnnotation])
+}
+
+func TestService_RestartEnvironment_KubernetesErrors(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 1, "app", "web:1.0.0")
+
+	tests := []struct {
+		name   string
+		modify func(env *entities.Environment)
+		want   string
+	}{
+		{"no config", func(env *entities.Environment) { env.Commands.Kubernetes = nil }, "require a kubernetes configuration"},
+		{"bad name", func(env *entities.Environment) { env.Commands.Kubernetes.Name = "Web" }, "invalid Kubernetes name"},
+		{"bad kind", func(env *entities.Environment) { env.Commands.Kubernetes.Kind = "DaemonSet" }, "unsupported workload kind"},
+		{"no token", func(env *entities.Environment) { env.Metadata = nil }, "token not found"},
+		{"ssh credentials", func(env *entities.Environment) { env.Credentials.Type = "password" }, "unsupported credential type"},
+		{"no server", func(env *entities.Environment) { env.Commands.Kubernetes.Server = "" }, "no Kubernetes API server"},
+		{"wrong token", func(env *entities.Environment) { env.Metadata["kubernetesToken"] = "wrong" }, "401"},
+		{"missing workload", func(env *entities.Environment) { env.Commands.Kubernetes.Name = "api" }, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := primitive.NewObjectID()
+			env := newKubernetesEnv(id, api, "web")
+			tt.modify(env)
+			svc := newKubernetesService(t, new(MockEnvironmentRepository), env)
+
+			err := svc.RestartEnvironment(context.Background(), id.Hex(), false)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestService_RestartEnvironment_KubernetesServerNotAllowed(t *testing.T) {
+	api := kubetest.NewServer(t)
+	id := primitive.NewObjectID()
+	env := newKubernetesEnv(id, api, "web")
+
+	repo := new(MockEnvironmentRepository)
+	logRepo := new(MockLogRepository)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("GetByID", mock.Anything, id.Hex()).Return(env, nil)
+	svc := newTestService(repo, logRepo)
+
+	err := svc.RestartEnvironment(context.Background(), id.Hex(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "localhost addresses are not allowed")
+	assert.Empty(t, api.Requests())
+}
+
+func TestService_UpgradeEnvironment_Kubernetes(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 2, "app", "registry.example.com/web:1.0.0", "proxy", "envoy:1.30")
+
+	id := primitive.NewObjectID()
+	env := newKubernetesEnv(id, api, "web")
+	env.Commands.Kubernetes.Container = "app"
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), env)
+
+	require.NoError(t, svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0.0"))
+
+	workload, _ := api.Workload("Deployment", "shop", "web")
+	assert.Equal(t, "registry.example.com/web:2.0.0", workload.Images["app"])
+	assert.Equal(t, "envoy:1.30", workload.Images["proxy"])
+	assert.Equal(t, "2.0.0", env.SystemInfo.AppVersion)
+}
+
+func TestService_UpgradeEnvironment_KubernetesImageTemplate(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 1, "app", "web:1.0.0")
+
+	id := primitive.NewObjectID()
+	env := newKubernetesEnv(id, api, "web")
+	env.Commands.Kubernetes.Image = "registry.example.com/web-next:{VERSION}"
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), env)
+
+	require.NoError(t, svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0.0"))
+
+	workload, _ := api.Workload("Deployment", "shop", "web")
+	assert.Equal(t, "registry.example.com/web-next:2.0.0", workload.Images["app"])
+}
+
+func TestService_UpgradeEnvironment_KubernetesAmbiguousContainer(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 1, "app", "web:1.0.0", "proxy", "envoy:1.30")
+
+	id := primitive.NewObjectID()
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), newKubernetesEnv(id, api, "web"))
+
+	err := svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "deployment/web has 2 containers")
+}
+
+func TestService_UpgradeEnvironment_KubernetesRolloutFails(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 2, "app", "web:1.0.0")
+	api.FailRollout("Deployment", "shop", "web")
+
+	id := primitive.NewObjectID()
+	env := newKubernetesEnv(id, api, "web")
+	env.SystemInfo.AppVersion = "1.0.0"
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), env)
+
+	err := svc.UpgradeEnvironment(context.Background(), id.Hex(), "2.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rollout of deployment/web failed: progress deadline exceeded")
+	assert.Equal(t, "1.0.0", env.SystemInfo.AppVersion)
+}
+
+func TestService_UpgradeEnvironment_KubernetesRolloutTimeout(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddStatefulSet("shop", "db", 2, "postgres", "postgres:15")
+	api.FailRollout("StatefulSet", "shop", "db")
+
+	id := primitive.NewObjectID()
+	env := newKubernetesEnv(id, api, "db")
+	env.Commands.Kubernetes.Kind = entities.KubernetesKindStatefulSet
+	env.Commands.Kubernetes.RolloutTimeout = 1
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), env)
+
+	err := svc.UpgradeEnvironment(context.Background(), id.Hex(), "16")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rollout of statefulset/db did not complete")
+}
+
+func TestService_RunCustomAction_KubernetesUnsupported(t *testing.T) {
+	api := kubetest.NewServer(t)
+	id := primitive.NewObjectID()
+	env := newKubernetesEnv(id, api, "web")
+	env.Commands.Actions = []entities.CustomAction{{Name: "migrate"}}
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), env)
+
+	err := svc.RunCustomAction(context.Background(), id.Hex(), "migrate")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported for kubernetes commands")
+}
+
+func TestService_PlanUpgrade_Kubernetes(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 1, "app", "registry.example.com/web:1.0.0")
+
+	id := primitive.NewObjectID()
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), newKubernetesEnv(id, api, "web"))
+
+	plan, err := svc.PlanUpgrade(context.Background(), id.Hex(), "2.0.0")
+	require.NoError(t, err)
+	assert.True(t, plan.Valid)
+	require.Len(t, plan.Steps, 1)
+	step := plan.Steps[0]
+	assert.Equal(t, entities.CommandTypeKubernetes, step.Type)
+	assert.Equal(t, "PATCH", step.Method)
+	assert.Equal(t, api.URL()+"/apis/apps/v1/namespaces/shop/deployments/web", step.URL)
+	assert.Equal(t, kubernetes.ImagePatch("app", "registry.example.com/web:2.0.0"), step.Body)
+
+	workload, _ := api.Workload("Deployment", "shop", "web")
+	assert.Zero(t, workload.Patches, "nothing is patched")
+}
+
+func TestService_PlanRestart_KubernetesMissingWorkload(t *testing.T) {
+	api := kubetest.NewServer(t)
+	id := primitive.NewObjectID()
+	svc := newKubernetesService(t, new(MockEnvironmentRepository), newKubernetesEnv(id, api, "web"))
+
+	plan, err := svc.PlanRestart(context.Background(), id.Hex(), false)
+	require.NoError(t, err)
+	assert.False(t, plan.Valid)
+	assert.Empty(t, plan.Steps)
+
+	var failed []string
+	for _, check := range plan.Checks {
+		if !check.Passed {
+			failed = append(failed, check.Name)
+		}
+	}
+	assert.Equal(t, []string{"kubernetes_connection"}, failed)
+}
@@ -0,0 +1,94 @@
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/service/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RestartEnvironment_RateLimited(t *testing.T) {
+	env := newSimulatedEnv(nil)
+	env.Limits = &entities.OperationLimits{Restart: &entities.OperationLimit{MaxRuns: 2}}
+	svc, _ := newSimulatedService(t, env)
+	ctx := context.Background()
+
+	require.NoError(t, svc.CheckOperationLimit(ctx, env.ID.Hex(), entities.ActionTypeRestart))
+	require.NoError(t, svc.RestartEnvironment(ctx, env.ID.Hex(), false))
+	require.NoError(t, svc.RestartEnvironment(ctx, env.ID.Hex(), false))
+
+	err := svc.CheckOperationLimit(ctx, env.ID.Hex(), entities.ActionTypeRestart)
+	require.Error(t, err)
+	domainErr, ok := err.(errors.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, "OPERATION_RATE_LIMITED", domainErr.Code)
+	assert.Equal(t, 3600, domainErr.Details["retryAfter"])
+
+	err = svc.RestartEnvironment(ctx, env.ID.Hex(), false)
+	require.Error(t, err)
+	assert.Equal(t, "OPERATION_RATE_LIMITED", err.(errors.DomainError).Code)
+
+	assert.NoError(t, svc.RunCustomAction(ctx, env.ID.Hex(), "flush"), "other operations are not limited")
+}
+
+func TestService_ReserveOperation(t *testing.T) {
+	env := newSimulatedEnv(nil)
+	env.Limits = &entities.OperationLimits{Restart: &entities.OperationLimit{MaxRuns: 1}}
+	svc, _ := newSimulatedService(t, env)
+	ctx := context.Background()
+
+	reservation, err := svc.ReserveOperation(ctx, env.ID.Hex(), entities.ActionTypeRestart)
+	require.NoError(t, err)
+
+	_, err = svc.ReserveOperation(ctx, env.ID.Hex(), entities.ActionTypeRestart)
+	require.Error(t, err, "the reservation took the only slot")
+	assert.Equal(t, "OPERATION_RATE_LIMITED", err.(errors.DomainError).Code)
+
+	require.NoError(t, svc.RestartEnvironment(environment.WithReservation(ctx, reservation), env.ID.Hex(), false),
+		"the reserved restart is not counted again")
+	assert.Error(t, svc.RestartEnvironment(ctx, env.ID.Hex(), false))
+}
+
+func TestService_RunCustomAction_Cooldown(t *testing.T) {
+	env := newSimulatedEnv(nil)
+	env.Limits = &entities.OperationLimits{CustomAction: &entities.OperationLimit{CooldownSeconds: 120}}
+	svc, _ := newSimulatedService(t, env)
+	ctx := context.Background()
+
+	require.NoError(t, svc.RunCustomAction(ctx, env.ID.Hex(), "flush"))
+
+	err := svc.RunCustomAction(ctx, env.ID.Hex(), "flush")
+	require.Error(t, err)
+	assert.Equal(t, 120, err.(errors.DomainError).Details["retryAfter"])
+}
+
+func TestService_UpgradeEnvironment_Timeout(t *testing.T) {
+	env := newSimulatedEnv(&entities.SimulationConfig{LatencyMs: 5000})
+	env.Limits = &entities.OperationLimits{Upgrade: &entities.OperationLimit{TimeoutSeconds: 1}}
+	svc, _ := newSimulatedService(t, env)
+
+	err := svc.UpgradeEnvironment(context.Background(), env.ID.Hex(), "2.0.0")
+
+	require.Error(t, err)
+	assert.Equal(t, "upgrade failed: timed out after 1s", err.Error())
+	assert.Equal(t, "1.0.0", env.SystemInfo.AppVersion)
+}
+
+func TestService_RollbackEnvironment_NotRateLimited(t *testing.T) {
+	env := newSimulatedEnv(nil)
+	env.Limits = &entities.OperationLimits{Upgrade: &entities.OperationLimit{MaxRuns: 1, CooldownSeconds: 600}}
+	svc, _ := newSimulatedService(t, env)
+	ctx := context.Background()
+
+	require.NoError(t, svc.UpgradeEnvironment(ctx, env.ID.Hex(), "2.0.0"))
+	err := svc.UpgradeEnvironment(ctx, env.ID.Hex(), "1.0.0")
+	require.Error(t, err)
+	assert.Equal(t, "OPERATION_RATE_LIMITED", err.(errors.DomainError).Code)
+
+	require.NoError(t, svc.RollbackEnvironment(ctx, env.ID.Hex(), "1.0.0"))
+	assert.Equal(t, "1.0.0", env.SystemInfo.AppVersion)
+}
@@ -0,0 +1,120 @@
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newSimulatedEnv returns an environment with no reachable target that is
+// driven and probed by the simulator
+func newSimulatedEnv(config *entities.SimulationConfig) *entities.Environment {
+	env := newSampleEnv(primitive.NewObjectID())
+	env.Target = entities.Target{Host: "demo.invalid", Port: 22}
+	env.Commands = entities.CommandConfig{
+		Type:       entities.CommandTypeSimulated,
+		Restart:    entities.RestartConfig{Enabled: true},
+		Actions:    []entities.CustomAction{{Name: "flush"}},
+		Simulation: config,
+	}
+	env.UpgradeConfig = entities.UpgradeConfig{Enabled: true, Type: entities.CommandTypeSimulated}
+	env.HealthCheck = entities.HealthCheckConfig{Enabled: true, Type: entities.HealthCheckTypeSimulated}
+	env.SystemInfo.AppVersion = "1.0.0"
+	return env
+}
+
+func newSimulatedService(t *testing.T, env *entities.Environment) (*environment.Service, *MockEnvironmentRepository) {
+	t.Helper()
+	repo := new(MockEnvironmentRepository)
+	return newDockerService(t, repo, env), repo
+}
+
+func TestService_Simulated_Operations(t *testing.T) {
+	env := newSimulatedEnv(&entities.SimulationConfig{Versions: []string{"1.0.0", "2.0.0"}})
+	svc, _ := newSimulatedService(t, env)
+	ctx := context.Background()
+
+	require.NoError(t, svc.RestartEnvironment(ctx, env.ID.Hex(), false))
+	require.NoError(t, svc.RunCustomAction(ctx, env.ID.Hex(), "flush"))
+	require.NoError(t, svc.UpgradeEnvironment(ctx, env.ID.Hex(), "2.0.0"))
+	assert.Equal(t, "2.0.0", env.SystemInfo.AppVersion)
+
+	err := svc.UpgradeEnvironment(ctx, env.ID.Hex(), "3.0.0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "version 3.0.0 is not available")
+}
+
+func TestService_Simulated_Failure(t *testing.T) {
+	env := newSimulatedEnv(&entities.SimulationConfig{FailureRate: 1})
+	svc, _ := newSimulatedService(t, env)
+
+	err := svc.RestartEnvironment(context.Background(), env.ID.Hex(), false)
+
+	require.Error(t, err)
+	assert.Equal(t, "restart failed: simulated restart failed", err.Error())
+}
+
+func TestService_Simulated_HealthCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *entities.SimulationConfig
+		want    entities.HealthStatus
+		message string
+	}{
+		{"healthy", nil, entities.HealthStatusHealthy, "Simulated environment is healthy"},
+		{"unhealthy", &entities.SimulationConfig{UnhealthyRate: 1}, entities.HealthStatusUnhealthy, "Simulated health check failed"},
+		{"invalid", &entities.SimulationConfig{UnhealthyRate: 2}, entities.HealthStatusUnhealthy,
+			"simulated unhealthy rate must be between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newSimulatedEnv(tt.config)
+			svc, repo := newSimulatedService(t, env)
+
+			require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+			status := updatedStatus(t, repo)
+			assert.Equal(t, tt.want, status.Health)
+			assert.Equal(t, tt.message, status.Message)
+		})
+	}
+}
+
+func TestService_Simulated_HealthProbeOfCommandType(t *testing.T) {
+	// With the health check disabled the driver's own probe decides
+	env := newSimulatedEnv(&entities.SimulationConfig{UnhealthyRate: 1})
+	env.HealthCheck = entities.HealthCheckConfig{}
+	svc, repo := newSimulatedService(t, env)
+
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+
+	assert.Equal(t, entities.HealthStatusUnhealthy, updatedStatus(t, repo).Health)
+}
+
+func TestService_Simulated_AvailableVersions(t *testing.T) {
+	env := newSimulatedEnv(&entities.SimulationConfig{Versions: []string{"1.0.0", "1.1.0"}})
+	svc, _ := newSimulatedService(t, env)
+
+	versions, current, err := svc.GetAvailableVersions(context.Background(), env.ID.Hex())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0"}, versions)
+	assert.Equal(t, "1.0.0", current)
+}
+
+func TestService_Simulated_PlanRestart(t *testing.T) {
+	env := newSimulatedEnv(nil)
+	svc, _ := newSimulatedService(t, env)
+
+	plan, err := svc.PlanRestart(context.Background(), env.ID.Hex(), false)
+
+	require.NoError(t, err)
+	assert.True(t, plan.Valid)
+	require.Len(t, plan.Steps, 1)
+	assert.Equal(t, "simulate restart", plan.Steps[0].Command)
+}
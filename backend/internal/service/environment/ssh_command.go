@@ -0,0 +1,60 @@
+package environment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/ssh"
+)
+
+// executeSSHCommand runs a command allowed by the environment's or the global
+// allowlist and records it in the audit log with the rule it matched
+func (s *Service) executeSSHCommand(ctx context.Context, env *entities.Environment, target ssh.Target,
+	command string) (*ssh.ExecutionResult, error) {
+
+	result, match, err := s.sshManager.ExecuteAuthorized(ctx, target, command, env.Commands.Allowlist)
+
+	metadata := map[string]interface{}{"command": command}
+	if match != nil {
+		metadata["rule"] = match.Rule.DisplayName()
+		metadata["ruleType"] = match.Rule.Type
+		metadata["rulePattern"] = match.Rule.Pattern
+		metadata["ruleScope"] = match.Scope
+		if len(match.Params) > 0 {
+			metadata["params"] = match.Params
+		}
+	}
+
+	switch {
+	case errors.Is(err, ssh.ErrCommandNotAllowed):
+		s.logEvent(ctx, env, entities.EventTypeCommandDenied, entities.SeverityWarning, "ssh_command",
+			"Command rejected by allowlist", metadata)
+	case err != nil:
+		metadata["error"] = err.Error()
+		s.logEvent(ctx, env, entities.EventTypeCommandExecuted, entities.SeverityError, "ssh_command",
+			fmt.Sprintf("Command failed: %v", err), metadata)
+	default:
+		metadata["exitCode"] = result.ExitCode
+		metadata["outputSha256"] = outputHash(result.Output)
+		metadata["outputBytes"] = len(result.Output)
+		severity := entities.SeverityInfo
+		if result.ExitCode != 0 {
+			severity = entities.SeverityWarning
+		}
+		s.logEvent(ctx, env, entities.EventTypeCommandExecuted, severity, "ssh_command",
+			fmt.Sprintf("Command exited with code %d", result.ExitCode), metadata)
+	}
+
+	return result, err
+}
+
+// outputHash fingerprints command output so the audit log can reference it
+// without storing it
+func outputHash(output string) string {
+	sum := sha256.Sum256([]byte(output))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/executor"
+)
+
+// probeSSHHealth runs the environment's SSH health check command over a
+// pooled connection and maps its exit code and output to a health status.
+// Unlike operations, checks are not audited: the scheduler runs them on
+// every interval.
+func (s *Service) probeSSHHealth(ctx context.Context, env *entities.Environment) *executor.Result {
+	config := env.HealthCheck.SSH
+	if config == nil || config.Command == "" {
+		return executor.Failure("SSH health check has no command")
+	}
+
+	var pattern *regexp.Regexp
+	if config.OutputPattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(config.OutputPattern); err != nil {
+			return executor.Failuref("invalid output pattern: %v", err)
+		}
+	}
+
+	target, err := s.buildSSHTarget(env)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+
+	if env.HealthCheck.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(env.HealthCheck.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	result, _, err := s.sshManager.ExecuteAuthorized(ctx, *target, config.Command, env.Commands.Allowlist)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+
+	probe := &executor.Result{
+		Status:   executor.StatusSucceeded,
+		Health:   entities.HealthStatusHealthy,
+		Output:   fmt.Sprintf("Command exited with %d", result.ExitCode),
+		ExitCode: result.ExitCode,
+	}
+	if codes := config.HealthyExitCodes(); codes != nil && !containsCode(codes, result.ExitCode) {
+		probe.Health = entities.HealthStatusUnhealthy
+		probe.Output = fmt.Sprintf("Command exited with %d, expected %v", result.ExitCode, codes)
+	} else if pattern != nil && !pattern.MatchString(result.Output) {
+		probe.Health = entities.HealthStatusUnhealthy
+		probe.Output = "Command output does not match expected pattern"
+	}
+	return probe
+}
+
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
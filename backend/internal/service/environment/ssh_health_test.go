@@ -0,0 +1,80 @@
+package environment_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newSSHHealthEnv returns an environment on the test server checked with an
+// SSH command; echo commands and exit with a code are allowed
+func newSSHHealthEnv(server *sshtest.Server, check *entities.SSHCheckConfig) *entities.Environment {
+	env := newExecEnv(primitive.NewObjectID(), server,
+		entities.CommandRule{Type: entities.CommandRuleGlob, Pattern: "echo {word}"},
+		entities.CommandRule{Type: entities.CommandRuleGlob, Pattern: "exit {int}"})
+	env.HealthCheck = entities.HealthCheckConfig{Enabled: true, Type: entities.HealthCheckTypeSSH, Timeout: 5, SSH: check}
+	return env
+}
+
+func checkSSHHealth(t *testing.T, env *entities.Environment) entities.Status {
+	t.Helper()
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+	require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+	return updatedStatus(t, repo)
+}
+
+func TestService_CheckHealth_SSH(t *testing.T) {
+	server := sshtest.NewServer(t)
+
+	tests := []struct {
+		name    string
+		check   *entities.SSHCheckConfig
+		health  entities.HealthStatus
+		message string
+	}{
+		{"exit zero", &entities.SSHCheckConfig{Command: "echo active"},
+			entities.HealthStatusHealthy, "Command exited with 0"},
+		{"failing exit code", &entities.SSHCheckConfig{Command: "exit 3"},
+			entities.HealthStatusUnhealthy, "Command exited with 3, expected [0]"},
+		{"listed exit code", &entities.SSHCheckConfig{Command: "exit 3", ExitCodes: []int{0, 3}},
+			entities.HealthStatusHealthy, "Command exited with 3"},
+		{"output matches", &entities.SSHCheckConfig{Command: "echo active", OutputPattern: "^active"},
+			entities.HealthStatusHealthy, "Command exited with 0"},
+		{"output does not match", &entities.SSHCheckConfig{Command: "echo inactive", OutputPattern: "^active"},
+			entities.HealthStatusUnhealthy, "Command output does not match expected pattern"},
+		{"command not allowed", &entities.SSHCheckConfig{Command: "rm -rf /"},
+			entities.HealthStatusUnhealthy, "not allowed"},
+		{"no command", &entities.SSHCheckConfig{},
+			entities.HealthStatusUnhealthy, "SSH health check has no command"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := checkSSHHealth(t, newSSHHealthEnv(server, tt.check))
+
+			assert.Equal(t, tt.health, status.Health)
+			assert.Contains(t, status.Message, tt.message)
+		})
+	}
+}
+
+func TestService_CheckHealth_SSHReusesConnection(t *testing.T) {
+	server := sshtest.NewServer(t)
+	env := newSSHHealthEnv(server, &entities.SSHCheckConfig{Command: "echo active"})
+	repo := new(MockEnvironmentRepository)
+	svc := newDockerService(t, repo, env)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, svc.CheckHealth(context.Background(), env.ID.Hex()))
+	}
+
+	assert.Equal(t, []string{"echo active", "echo active", "echo active"}, server.Commands())
+	assert.Equal(t, 1, server.Connections(), "checks share the pooled connection")
+	repo.AssertCalled(t, "UpdateStatus", mock.Anything, env.ID.Hex(), mock.Anything)
+}
@@ -0,0 +1,40 @@
+package environment
+
+import (
+	"context"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/executor"
+	"app-env-manager/internal/service/ssh"
+)
+
+// executeSSHScript uploads and runs a script on the environment's host.
+// Operation parameters are added to the script's environment and win over
+// configured variables of the same name.
+func (s *Service) executeSSHScript(ctx context.Context, env *entities.Environment, script *entities.ScriptConfig,
+	params map[string]string) *executor.Result {
+
+	target, err := s.buildSSHTarget(env)
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+
+	result, err := s.sshManager.ExecuteScript(ctx, *target, scriptFor(script, params))
+	if err != nil {
+		return executor.Failure(err.Error())
+	}
+	return sshResult(result.Output, result.ExitCode)
+}
+
+// scriptFor builds the SSH script with the operation parameters merged into
+// its environment, leaving the configuration untouched
+func scriptFor(script *entities.ScriptConfig, params map[string]string) ssh.Script {
+	env := make(map[string]string, len(script.Env)+len(params))
+	for name, value := range script.Env {
+		env[name] = value
+	}
+	for name, value := range params {
+		env[name] = value
+	}
+	return ssh.Script{Body: script.Body, Interpreter: script.Interpreter, Env: env}
+}
@@ -0,0 +1,48 @@
+package environment
+
+import (
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptFor_MergesParams(t *testing.T) {
+	script := &entities.ScriptConfig{
+		Body:        "echo $VERSION",
+		Interpreter: "/bin/bash",
+		Env:         map[string]string{"VERSION": "configured", "REGION": "eu"},
+	}
+
+	rendered := scriptFor(script, map[string]string{"VERSION": "2.0.0"})
+
+	assert.Equal(t, "echo $VERSION", rendered.Body)
+	assert.Equal(t, "/bin/bash", rendered.Interpreter)
+	assert.Equal(t, map[string]string{"VERSION": "2.0.0", "REGION": "eu"}, rendered.Env)
+	assert.Equal(t, "configured", script.Env["VERSION"], "configuration is not modified")
+}
+
+func TestRenderRestartCommand_Script(t *testing.T) {
+	script := &entities.ScriptConfig{Body: "systemctl restart app"}
+	env := &entities.Environment{Commands: entities.CommandConfig{
+		Type:    entities.CommandTypeSSH,
+		Restart: entities.RestartConfig{Enabled: true, Command: "ignored", Script: script},
+	}}
+
+	cmd := renderRestartCommand(env, false)
+	assert.Equal(t, script, cmd.Script)
+	assert.Empty(t, cmd.Command)
+}
+
+func TestRenderUpgradeCommand_ScriptKeepsBody(t *testing.T) {
+	env := &entities.Environment{UpgradeConfig: entities.UpgradeConfig{
+		Type: entities.CommandTypeSSH,
+		UpgradeCommand: entities.CommandDetails{
+			Script: &entities.ScriptConfig{Body: "app-upgrade --version=\"$VERSION\""},
+		},
+	}}
+
+	cmd := renderUpgradeCommand(env, "2.0.0")
+	assert.Empty(t, cmd.Command, "no default command for scripts")
+	assert.Equal(t, "app-upgrade --version=\"$VERSION\"", cmd.Script.Body)
+}
@@ -0,0 +1,187 @@
+// Package executor defines how environment operations are carried out for a
+// command type. Each driver implements Executor and is registered under its
+// command type; the environment service dispatches restarts, upgrades,
+// custom actions, health probes and dry runs through the registry.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// Operation is what an executor is asked to do
+type Operation string
+
+const (
+	OperationRestart      Operation = "restart"
+	OperationUpgrade      Operation = "upgrade"
+	OperationCustomAction Operation = "custom_action"
+	OperationHealth       Operation = "health"
+	OperationHook         Operation = "hook" // a step run before or after another operation
+)
+
+// Operation parameters, passed to scripts as environment variables
+const (
+	ParamForce          = "FORCE"
+	ParamVersion        = "VERSION"
+	ParamCurrentVersion = "CURRENT_VERSION"
+	ParamAction         = "ACTION"
+	ParamOperation      = "OPERATION"  // the operation a hook runs around
+	ParamHookPhase      = "HOOK_PHASE" // when the hook runs
+)
+
+// Request is one operation on an environment
+type Request struct {
+	Operation   Operation
+	Environment *entities.Environment
+	// Command is the rendered restart, upgrade or custom action command with
+	// {VERSION} resolved; drivers with their own configuration may ignore it
+	Command entities.CommandDetails
+	Params  map[string]string
+}
+
+// Param returns an operation parameter, or "" when it is not set
+func (r *Request) Param(name string) string {
+	return r.Params[name]
+}
+
+// Status is the outcome of a request
+type Status string
+
+const (
+	StatusSucceeded   Status = "succeeded"
+	StatusFailed      Status = "failed"
+	StatusUnsupported Status = "unsupported" // the driver does not implement the operation
+)
+
+// Result is the outcome of a request
+type Result struct {
+	Status   Status
+	Output   string // command output or response body
+	ExitCode int    // exit code of a remote process, zero when none ran
+	Error    string // why the request failed or is unsupported
+	// Health is the health a health probe observed
+	Health entities.HealthStatus
+	// Artifacts are driver specific details worth recording, e.g. the
+	// image a container was recreated from
+	Artifacts map[string]interface{}
+}
+
+// Succeeded reports whether the request succeeded
+func (r *Result) Succeeded() bool {
+	return r.Status == StatusSucceeded
+}
+
+// Message is the error of a failed request, or its output when there is no
+// error
+func (r *Result) Message() string {
+	if r.Error != "" {
+		return r.Error
+	}
+	return r.Output
+}
+
+// Success returns a successful result with output
+func Success(output string) *Result {
+	return &Result{Status: StatusSucceeded, Output: output}
+}
+
+// Failure returns a failed result
+func Failure(message string) *Result {
+	return &Result{Status: StatusFailed, Error: message}
+}
+
+// Failuref returns a failed result with a formatted message
+func Failuref(format string, args ...interface{}) *Result {
+	return Failure(fmt.Sprintf(format, args...))
+}
+
+// Unsupported returns the result of an operation a driver does not implement
+func Unsupported(cmdType entities.CommandType, operation Operation) *Result {
+	return &Result{Status: StatusUnsupported, Error: UnsupportedError(cmdType, operation).Error()}
+}
+
+// UnsupportedError describes an operation a driver does not implement
+func UnsupportedError(cmdType entities.CommandType, operation Operation) error {
+	var what string
+	switch operation {
+	case OperationCustomAction:
+		what = "custom actions are"
+	case OperationHealth:
+		what = "health probes are"
+	default:
+		what = fmt.Sprintf("%ss are", operation)
+	}
+	return fmt.Errorf("%s not supported for %s commands", what, cmdType)
+}
+
+// Executor carries out operations for one command type. Execute never
+// returns nil; failures are reported in the result.
+type Executor interface {
+	Execute(ctx context.Context, req *Request) *Result
+}
+
+// Planner is implemented by executors that support dry runs. Plan adds the
+// steps the request would take and the checks it passes to plan without
+// changing anything.
+type Planner interface {
+	Plan(ctx context.Context, req *Request, plan *entities.OperationPlan)
+}
+
+// VersionLister is implemented by executors that know the versions an
+// environment can be upgraded to without a version list URL
+type VersionLister interface {
+	Versions(ctx context.Context, env *entities.Environment) ([]string, error)
+}
+
+// Registry holds the executor for each command type
+type Registry struct {
+	mu        sync.RWMutex
+	executors map[entities.CommandType]Executor
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{executors: make(map[entities.CommandType]Executor)}
+}
+
+// Register sets the executor for a command type, replacing any previous one
+func (r *Registry) Register(cmdType entities.CommandType, executor Executor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executors[cmdType] = executor
+}
+
+// Lookup returns the executor for a command type
+func (r *Registry) Lookup(cmdType entities.CommandType) (Executor, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	executor, ok := r.executors[cmdType]
+	return executor, ok
+}
+
+// Types returns the registered command types in order
+func (r *Registry) Types() []entities.CommandType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	types := make([]entities.CommandType, 0, len(r.executors))
+	for cmdType := range r.executors {
+		types = append(types, cmdType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// Execute runs the request with the executor for cmdType; an unregistered
+// type fails
+func (r *Registry) Execute(ctx context.Context, cmdType entities.CommandType, req *Request) *Result {
+	executor, ok := r.Lookup(cmdType)
+	if !ok {
+		return Failuref("unsupported command type: %q", cmdType)
+	}
+	return executor.Execute(ctx, req)
+}
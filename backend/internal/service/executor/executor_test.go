@@ -0,0 +1,105 @@
+package executor_test
+
+import (
+	"context"
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoExecutor answers every request with its operation
+type echoExecutor struct {
+	requests []*executor.Request
+}
+
+func (e *echoExecutor) Execute(ctx context.Context, req *executor.Request) *executor.Result {
+	e.requests = append(e.requests, req)
+	return executor.Success(string(req.Operation))
+}
+
+func TestRegistry_Execute(t *testing.T) {
+	registry := executor.NewRegistry()
+	echo := &echoExecutor{}
+	registry.Register("echo", echo)
+
+	result := registry.Execute(context.Background(), "echo", &executor.Request{
+		Operation: executor.OperationUpgrade,
+		Params:    map[string]string{executor.ParamVersion: "2.0.0"},
+	})
+
+	assert.True(t, result.Succeeded())
+	assert.Equal(t, "upgrade", result.Message())
+	require.Len(t, echo.requests, 1)
+	assert.Equal(t, "2.0.0", echo.requests[0].Param(executor.ParamVersion))
+	assert.Empty(t, echo.requests[0].Param(executor.ParamForce))
+}
+
+func TestRegistry_ExecuteUnregistered(t *testing.T) {
+	result := executor.NewRegistry().Execute(context.Background(), "ftp", &executor.Request{
+		Operation: executor.OperationRestart,
+	})
+
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, executor.StatusFailed, result.Status)
+	assert.Equal(t, `unsupported command type: "ftp"`, result.Message())
+}
+
+func TestRegistry_RegisterReplaces(t *testing.T) {
+	registry := executor.NewRegistry()
+	first, second := &echoExecutor{}, &echoExecutor{}
+	registry.Register(entities.CommandTypeSSH, first)
+	registry.Register(entities.CommandTypeSSH, second)
+
+	registry.Execute(context.Background(), entities.CommandTypeSSH, &executor.Request{Operation: executor.OperationRestart})
+
+	assert.Empty(t, first.requests)
+	assert.Len(t, second.requests, 1)
+}
+
+func TestRegistry_Types(t *testing.T) {
+	registry := executor.NewRegistry()
+	assert.Empty(t, registry.Types())
+
+	registry.Register(entities.CommandTypeSSH, &echoExecutor{})
+	registry.Register(entities.CommandTypeDocker, &echoExecutor{})
+	registry.Register(entities.CommandTypeHTTP, &echoExecutor{})
+
+	assert.Equal(t, []entities.CommandType{
+		entities.CommandTypeDocker, entities.CommandTypeHTTP, entities.CommandTypeSSH,
+	}, registry.Types())
+
+	_, ok := registry.Lookup(entities.CommandTypeKubernetes)
+	assert.False(t, ok)
+}
+
+func TestResult_Message(t *testing.T) {
+	assert.Equal(t, "output", executor.Success("output").Message())
+	assert.Equal(t, "boom 2", executor.Failuref("boom %d", 2).Message())
+
+	failed := &executor.Result{Status: executor.StatusFailed, Output: "exit status 1", ExitCode: 1}
+	assert.False(t, failed.Succeeded())
+	assert.Equal(t, "exit status 1", failed.Message())
+}
+
+func TestUnsupported(t *testing.T) {
+	tests := []struct {
+		operation executor.Operation
+		want      string
+	}{
+		{executor.OperationCustomAction, "custom actions are not supported for docker commands"},
+		{executor.OperationHealth, "health probes are not supported for docker commands"},
+		{executor.OperationUpgrade, "upgrades are not supported for docker commands"},
+		{executor.OperationRestart, "restarts are not supported for docker commands"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.operation), func(t *testing.T) {
+			result := executor.Unsupported(entities.CommandTypeDocker, tt.operation)
+			assert.Equal(t, executor.StatusUnsupported, result.Status)
+			assert.False(t, result.Succeeded())
+			assert.Equal(t, tt.want, result.Message())
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package health
+
+import (
+	"fmt"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// defaultMinWeight is the share of the total weight that must be up for the
+// weighted policy when none is set
+const defaultMinWeight = 0.5
+
+// Aggregate combines the results of an environment's probes, in the same
+// order, under its aggregation policy. Healthy and degraded probes are up. A
+// critical probe that is down makes the environment unhealthy whatever the
+// policy; non-critical probes are not counted. An environment whose policy
+// is met is degraded while any probe is not healthy. The response time is
+// the slowest probe's.
+func Aggregate(aggregation *entities.ProbeAggregation, probes []entities.HealthProbe, results []*CheckResult) *CheckResult {
+	aggregate := &CheckResult{Status: entities.HealthStatusHealthy}
+
+	var critical, counted []entities.ProbeStatus
+	up, weight, upWeight := 0, 0, 0
+	for i, probe := range probes {
+		status := entities.ProbeStatus{
+			Name:         probe.Name,
+			Criticality:  probe.EffectiveCriticality(),
+			Health:       results[i].Status,
+			Message:      results[i].Message,
+			ResponseTime: results[i].ResponseTime,
+		}
+		aggregate.Probes = append(aggregate.Probes, status)
+		if status.ResponseTime > aggregate.ResponseTime {
+			aggregate.ResponseTime = status.ResponseTime
+		}
+
+		switch status.Criticality {
+		case entities.ProbeCriticalityCritical:
+			critical = append(critical, status)
+		case entities.ProbeCriticalityNonCritical:
+		default:
+			counted = append(counted, status)
+			weight += probe.EffectiveWeight()
+			if status.Health.IsUp() {
+				up++
+				upWeight += probe.EffectiveWeight()
+			}
+		}
+	}
+
+	for _, status := range critical {
+		if !status.Health.IsUp() {
+			aggregate.Status = entities.HealthStatusUnhealthy
+			aggregate.Message = fmt.Sprintf("Critical probe %q is %s: %s", status.Name, status.Health, status.Message)
+			aggregate.FailedProbe = status.Name
+			return aggregate
+		}
+	}
+
+	var policy entities.AggregationPolicy
+	if aggregation != nil {
+		policy = aggregation.Policy
+	}
+	var met bool
+	var required string
+	switch policy {
+	case entities.AggregationPolicyAll, "":
+		met, required = up == len(counted), "all"
+	case entities.AggregationPolicyAny:
+		met, required = up > 0 || len(counted) == 0, "one"
+	case entities.AggregationPolicyQuorum:
+		quorum := aggregation.Quorum
+		if quorum <= 0 {
+			quorum = len(counted)/2 + 1
+		}
+		met, required = up >= quorum || len(counted) == 0, fmt.Sprintf("%d", quorum)
+	case entities.AggregationPolicyWeighted:
+		minWeight := aggregation.MinWeight
+		if minWeight <= 0 {
+			minWeight = defaultMinWeight
+		}
+		met = weight == 0 || float64(upWeight)/float64(weight) >= minWeight
+		required = fmt.Sprintf("%g%% of the weight", minWeight*100)
+	default:
+		aggregate.Status = entities.HealthStatusUnhealthy
+		aggregate.Message = fmt.Sprintf("Unknown aggregation policy %q", policy)
+		return aggregate
+	}
+
+	if !met {
+		aggregate.Status = entities.HealthStatusUnhealthy
+		aggregate.Message = fmt.Sprintf("%d of %d probes up, %s required", up, len(counted), required)
+		// Blame the first counted probe that is down
+		for _, status := range counted {
+			if !status.Health.IsUp() {
+				aggregate.Message += fmt.Sprintf("; %q is %s: %s", status.Name, status.Health, status.Message)
+				aggregate.FailedProbe = status.Name
+				break
+			}
+		}
+		return aggregate
+	}
+
+	aggregate.Message = fmt.Sprintf("%d of %d probes healthy", countHealthy(aggregate.Probes), len(probes))
+	// Blame the first probe that is not healthy for the degradation
+	for _, status := range aggregate.Probes {
+		if status.Health != entities.HealthStatusHealthy {
+			aggregate.Status = entities.HealthStatusDegraded
+			aggregate.Message += fmt.Sprintf("; %q is %s: %s", status.Name, status.Health, status.Message)
+			aggregate.FailedProbe = status.Name
+			break
+		}
+	}
+	return aggregate
+}
+
+func countHealthy(statuses []entities.ProbeStatus) int {
+	count := 0
+	for _, status := range statuses {
+		if status.Health == entities.HealthStatusHealthy {
+			count++
+		}
+	}
+	return count
+}
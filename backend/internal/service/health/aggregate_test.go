@@ -0,0 +1,111 @@
+package health_test
+
+import (
+	"testing"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func probe(name string, criticality entities.ProbeCriticality, weight int) entities.HealthProbe {
+	return entities.HealthProbe{Name: name, Criticality: criticality, Weight: weight}
+}
+
+func probeResult(status entities.HealthStatus, responseTime int64) *health.CheckResult {
+	return &health.CheckResult{Status: status, Message: string(status) + " probe", ResponseTime: responseTime}
+}
+
+func TestAggregate(t *testing.T) {
+	healthy := probeResult(entities.HealthStatusHealthy, 10)
+	unhealthy := probeResult(entities.HealthStatusUnhealthy, 30)
+	unknown := probeResult(entities.HealthStatusUnknown, 5)
+	degraded := probeResult(entities.HealthStatusDegraded, 20)
+	three := []entities.HealthProbe{probe("web", "", 0), probe("api", "", 0), probe("worker", "", 0)}
+
+	tests := []struct {
+		name        string
+		aggregation *entities.ProbeAggregation
+		probes      []entities.HealthProbe
+		results     []*health.CheckResult
+		status      entities.HealthStatus
+		message     string
+		failedProbe string
+	}{
+		{"all healthy", nil, three, []*health.CheckResult{healthy, healthy, healthy},
+			entities.HealthStatusHealthy, "3 of 3 probes healthy", ""},
+		{"all with one unhealthy", nil, three, []*health.CheckResult{healthy, unhealthy, healthy},
+			entities.HealthStatusUnhealthy, `2 of 3 probes up, all required; "api" is unhealthy: unhealthy probe`, "api"},
+		{"unknown is not up", &entities.ProbeAggregation{Policy: entities.AggregationPolicyAll}, three,
+			[]*health.CheckResult{healthy, healthy, unknown},
+			entities.HealthStatusUnhealthy, `2 of 3 probes up, all required; "worker" is unknown: unknown probe`, "worker"},
+		{"any", &entities.ProbeAggregation{Policy: entities.AggregationPolicyAny}, three,
+			[]*health.CheckResult{unhealthy, unhealthy, healthy},
+			entities.HealthStatusDegraded, `1 of 3 probes healthy; "web" is unhealthy: unhealthy probe`, "web"},
+		{"any with none up", &entities.ProbeAggregation{Policy: entities.AggregationPolicyAny}, three,
+			[]*health.CheckResult{unhealthy, unhealthy, unhealthy},
+			entities.HealthStatusUnhealthy, `0 of 3 probes up, one required; "web" is unhealthy: unhealthy probe`, "web"},
+		{"default quorum met", &entities.ProbeAggregation{Policy: entities.AggregationPolicyQuorum}, three,
+			[]*health.CheckResult{unhealthy, healthy, healthy},
+			entities.HealthStatusDegraded, `2 of 3 probes healthy; "web" is unhealthy: unhealthy probe`, "web"},
+		{"default quorum missed", &entities.ProbeAggregation{Policy: entities.AggregationPolicyQuorum}, three,
+			[]*health.CheckResult{unhealthy, unhealthy, healthy},
+			entities.HealthStatusUnhealthy, `1 of 3 probes up, 2 required; "web" is unhealthy: unhealthy probe`, "web"},
+		{"quorum above the probes", &entities.ProbeAggregation{Policy: entities.AggregationPolicyQuorum, Quorum: 4}, three,
+			[]*health.CheckResult{healthy, healthy, healthy},
+			entities.HealthStatusUnhealthy, "3 of 3 probes up, 4 required", ""},
+		{"weighted met", &entities.ProbeAggregation{Policy: entities.AggregationPolicyWeighted},
+			[]entities.HealthProbe{probe("web", "", 3), probe("api", "", 1), probe("worker", "", 0)},
+			[]*health.CheckResult{healthy, unhealthy, unhealthy},
+			entities.HealthStatusDegraded, `1 of 3 probes healthy; "api" is unhealthy: unhealthy probe`, "api"},
+		{"weighted missed", &entities.ProbeAggregation{Policy: entities.AggregationPolicyWeighted, MinWeight: 0.8},
+			[]entities.HealthProbe{probe("web", "", 2), probe("api", "", 1), probe("worker", "", 1)},
+			[]*health.CheckResult{healthy, unhealthy, healthy},
+			entities.HealthStatusUnhealthy, `2 of 3 probes up, 80% of the weight required; "api" is unhealthy: unhealthy probe`, "api"},
+		{"critical overrides the policy", &entities.ProbeAggregation{Policy: entities.AggregationPolicyAny},
+			[]entities.HealthProbe{probe("web", "", 0), probe("db", entities.ProbeCriticalityCritical, 0)},
+			[]*health.CheckResult{healthy, unhealthy},
+			entities.HealthStatusUnhealthy, `Critical probe "db" is unhealthy: unhealthy probe`, "db"},
+		{"non-critical degrades", nil,
+			[]entities.HealthProbe{probe("web", "", 0), probe("metrics", entities.ProbeCriticalityNonCritical, 0)},
+			[]*health.CheckResult{healthy, unhealthy},
+			entities.HealthStatusDegraded, `1 of 2 probes healthy; "metrics" is unhealthy: unhealthy probe`, "metrics"},
+		{"degraded is up", nil, three, []*health.CheckResult{healthy, degraded, healthy},
+			entities.HealthStatusDegraded, `2 of 3 probes healthy; "api" is degraded: degraded probe`, "api"},
+		{"degraded critical", nil,
+			[]entities.HealthProbe{probe("web", "", 0), probe("db", entities.ProbeCriticalityCritical, 0)},
+			[]*health.CheckResult{healthy, degraded},
+			entities.HealthStatusDegraded, `1 of 2 probes healthy; "db" is degraded: degraded probe`, "db"},
+		{"unknown policy", &entities.ProbeAggregation{Policy: "most"}, three,
+			[]*health.CheckResult{healthy, healthy, healthy},
+			entities.HealthStatusUnhealthy, `Unknown aggregation policy "most"`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := health.Aggregate(tt.aggregation, tt.probes, tt.results)
+
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+			assert.Equal(t, tt.failedProbe, result.FailedProbe)
+			require.Len(t, result.Probes, len(tt.probes))
+		})
+	}
+}
+
+func TestAggregate_ProbeStatuses(t *testing.T) {
+	probes := []entities.HealthProbe{probe("web", "", 0), probe("db", entities.ProbeCriticalityCritical, 0)}
+
+	result := health.Aggregate(nil, probes, []*health.CheckResult{
+		probeResult(entities.HealthStatusHealthy, 10),
+		probeResult(entities.HealthStatusHealthy, 40),
+	})
+
+	assert.Equal(t, int64(40), result.ResponseTime, "the slowest probe")
+	assert.Equal(t, []entities.ProbeStatus{
+		{Name: "web", Criticality: entities.ProbeCriticalityNormal, Health: entities.HealthStatusHealthy,
+			Message: "healthy probe", ResponseTime: 10},
+		{Name: "db", Criticality: entities.ProbeCriticalityCritical, Health: entities.HealthStatusHealthy,
+			Message: "healthy probe", ResponseTime: 40},
+	}, result.Probes)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/assertion"
 )
 
 // Checker performs health checks on environments
@@ -24,6 +25,8 @@ type CheckResult struct {
 	Message      string
 	ResponseTime int64 // milliseconds
 	StatusCode   int
+	Probes       []entities.ProbeStatus // of a multi-probe check
+	FailedProbe  string                 // the probe that made a multi-probe check unhealthy or degraded
 }
 
 // NewChecker creates a new health checker
@@ -44,6 +47,26 @@ func NewChecker(timeout time.Duration) *Checker {
 
 // CheckHealth performs a health check on an environment
 func (c *Checker) CheckHealth(ctx context.Context, env *entities.Environment) (*CheckResult, error) {
+	result, err := c.check(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	DegradeIfSlow(result, env.HealthCheck.DegradedAboveMs)
+	return result, nil
+}
+
+// DegradeIfSlow marks a healthy result degraded when it took longer than
+// thresholdMs milliseconds. A zero threshold disables it.
+func DegradeIfSlow(result *CheckResult, thresholdMs int) {
+	if thresholdMs <= 0 || result.Status != entities.HealthStatusHealthy || result.ResponseTime <= int64(thresholdMs) {
+		return
+	}
+	result.Status = entities.HealthStatusDegraded
+	result.Message = fmt.Sprintf("%s; response time %dms is above %dms", result.Message, result.ResponseTime, thresholdMs)
+}
+
+// check runs the environment's health check of its type
+func (c *Checker) check(ctx context.Context, env *entities.Environment) (*CheckResult, error) {
 	if !env.HealthCheck.Enabled {
 		return &CheckResult{
 			Status:  entities.HealthStatusUnknown,
@@ -51,6 +74,16 @@ func (c *Checker) CheckHealth(ctx context.Context, env *entities.Environment) (*
 		}, nil
 	}
 
+	switch env.HealthCheck.Type {
+	case entities.HealthCheckTypeTCP:
+		return c.checkTCP(ctx, env), nil
+	case entities.HealthCheckTypeGRPC:
+		return c.checkGRPC(ctx, env), nil
+	case entities.HealthCheckTypeRedis, entities.HealthCheckTypeSMTP, entities.HealthCheckTypeFTP,
+		entities.HealthCheckTypePostgres, entities.HealthCheckTypeMySQL:
+		return c.checkProtocol(ctx, env), nil
+	}
+
 	start := time.Now()
 	
 	// Build request
@@ -84,12 +117,8 @@ func (c *Checker) CheckHealth(ctx context.Context, env *entities.Environment) (*
 	}
 
 	// Validate response
-	valid, message := c.validator.Validate(env.HealthCheck.Validation, resp.StatusCode, body)
-	
-	status := entities.HealthStatusHealthy
-	if !valid {
-		status = entities.HealthStatusUnhealthy
-	}
+	status, message := c.validator.ValidateResponse(env.HealthCheck.Validation,
+		assertion.Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: body}, responseTime)
 
 	return &CheckResult{
 		Status:       status,
@@ -223,3 +223,60 @@ func TestChecker_CheckHealth_HTTPSuccessDefaultStatusCode(t *testing.T) {
 	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
 	assert.Equal(t, http.StatusOK, result.StatusCode)
 }
+
+func TestChecker_CheckHealth_DegradedAboveThreshold(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	checker := health.NewChecker(5 * time.Second)
+	env := &entities.Environment{
+		ID:   primitive.NewObjectID(),
+		Name: "Test Environment",
+		HealthCheck: entities.HealthCheckConfig{
+			Enabled:         true,
+			Endpoint:        server.URL,
+			Method:          "GET",
+			Timeout:         5,
+			DegradedAboveMs: 20,
+			Validation: entities.ValidationConfig{
+				Type:  "statusCode",
+				Value: 200,
+			},
+		},
+	}
+
+	result, err := checker.CheckHealth(context.Background(), env)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusDegraded, result.Status)
+	assert.Contains(t, result.Message, "Status code 200 matches expected 200; response time")
+	assert.Contains(t, result.Message, "is above 20ms")
+}
+
+func TestDegradeIfSlow(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    entities.HealthStatus
+		threshold int
+		expected  entities.HealthStatus
+		message   string
+	}{
+		{"slow", entities.HealthStatusHealthy, 100, entities.HealthStatusDegraded, "ok; response time 150ms is above 100ms"},
+		{"fast enough", entities.HealthStatusHealthy, 150, entities.HealthStatusHealthy, "ok"},
+		{"no threshold", entities.HealthStatusHealthy, 0, entities.HealthStatusHealthy, "ok"},
+		{"already unhealthy", entities.HealthStatusUnhealthy, 100, entities.HealthStatusUnhealthy, "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &health.CheckResult{Status: tt.status, Message: "ok", ResponseTime: 150}
+
+			health.DegradeIfSlow(result, tt.threshold)
+
+			assert.Equal(t, tt.expected, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// checkGRPC calls the gRPC Health Checking Protocol's Check on the
+// environment and maps the serving status to a health status
+func (c *Checker) checkGRPC(ctx context.Context, env *entities.Environment) *CheckResult {
+	config := env.HealthCheck.GRPC
+	if config == nil {
+		config = &entities.GRPCCheckConfig{}
+	}
+
+	creds, err := grpcCredentials(config.TLS, env.Target.Host)
+	if err != nil {
+		return &CheckResult{
+			Status:  entities.HealthStatusUnhealthy,
+			Message: fmt.Sprintf("Invalid TLS configuration: %v", err),
+		}
+	}
+	conn, err := grpc.NewClient(config.Address(env.Target), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return &CheckResult{
+			Status:  entities.HealthStatusUnhealthy,
+			Message: fmt.Sprintf("Connection failed: %v", err),
+		}
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(env))
+	defer cancel()
+	if len(config.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(config.Metadata))
+	}
+
+	start := time.Now()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: config.Service})
+	responseTime := time.Since(start).Milliseconds()
+	if err != nil {
+		return &CheckResult{
+			Status:       entities.HealthStatusUnhealthy,
+			Message:      grpcErrorMessage(config.Service, err),
+			ResponseTime: responseTime,
+		}
+	}
+
+	result := &CheckResult{
+		Message:      fmt.Sprintf("Serving status %s", resp.GetStatus()),
+		ResponseTime: responseTime,
+	}
+	switch resp.GetStatus() {
+	case healthpb.HealthCheckResponse_SERVING:
+		result.Status = entities.HealthStatusHealthy
+	case healthpb.HealthCheckResponse_NOT_SERVING:
+		result.Status = entities.HealthStatusUnhealthy
+	default:
+		result.Status = entities.HealthStatusUnknown
+	}
+	return result
+}
+
+// grpcCredentials returns the transport credentials for the TLS options,
+// plaintext when there are none
+func grpcCredentials(options *entities.GRPCTLSConfig, host string) (credentials.TransportCredentials, error) {
+	if options == nil {
+		return insecure.NewCredentials(), nil
+	}
+
+	config := &tls.Config{
+		ServerName:         options.ServerName,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+	if options.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(options.CACert)) {
+			return nil, fmt.Errorf("caCert holds no PEM certificates")
+		}
+		config.RootCAs = pool
+	}
+	return credentials.NewTLS(config), nil
+}
+
+// grpcErrorMessage explains a failed Check call
+func grpcErrorMessage(service string, err error) string {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Sprintf("Service %q is unknown to the server", service)
+	case codes.Unimplemented:
+		return "Server does not implement the gRPC health checking protocol"
+	case codes.DeadlineExceeded:
+		return "Health check timed out"
+	}
+	return fmt.Sprintf("Health check failed: %v", err)
+}
@@ -0,0 +1,181 @@
+package health_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// startGRPCServer serves the standard health service in-process and returns
+// it with the server's port
+func startGRPCServer(t *testing.T, opts ...grpc.ServerOption) (*grpchealth.Server, int) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(opts...)
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return healthServer, listener.Addr().(*net.TCPAddr).Port
+}
+
+func newGRPCEnv(port int, config *entities.GRPCCheckConfig) *entities.Environment {
+	return &entities.Environment{
+		Target: entities.Target{Host: "127.0.0.1", Port: 22},
+		HealthCheck: entities.HealthCheckConfig{
+			Enabled: true,
+			Type:    entities.HealthCheckTypeGRPC,
+			Timeout: 2,
+			GRPC:    config,
+		},
+	}
+}
+
+func TestChecker_CheckHealth_GRPCServingStatus(t *testing.T) {
+	healthServer, port := startGRPCServer(t)
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthServer.SetServingStatus("billing", healthpb.HealthCheckResponse_UNKNOWN)
+	checker := health.NewChecker(5 * time.Second)
+
+	tests := []struct {
+		service string
+		status  entities.HealthStatus
+		message string
+	}{
+		{"", entities.HealthStatusHealthy, "Serving status SERVING"},
+		{"orders", entities.HealthStatusUnhealthy, "Serving status NOT_SERVING"},
+		{"billing", entities.HealthStatusUnknown, "Serving status UNKNOWN"},
+		{"missing", entities.HealthStatusUnhealthy, `Service "missing" is unknown to the server`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			result, err := checker.CheckHealth(context.Background(),
+				newGRPCEnv(port, &entities.GRPCCheckConfig{Port: port, Service: tt.service}))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+}
+
+func TestChecker_CheckHealth_GRPCMetadata(t *testing.T) {
+	requireToken := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if tokens := md.Get("authorization"); len(tokens) == 0 || tokens[0] != "Bearer secret" {
+			return nil, status.Error(codes.Unauthenticated, "missing token")
+		}
+		return handler(ctx, req)
+	}
+	_, port := startGRPCServer(t, grpc.UnaryInterceptor(requireToken))
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(), newGRPCEnv(port, &entities.GRPCCheckConfig{Port: port}))
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "missing token")
+
+	result, err = checker.CheckHealth(context.Background(), newGRPCEnv(port, &entities.GRPCCheckConfig{
+		Port:     port,
+		Metadata: map[string]string{"Authorization": "Bearer secret"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
+}
+
+func TestChecker_CheckHealth_GRPCTLS(t *testing.T) {
+	cert, caPEM := selfSignedCert(t, "grpc.internal")
+	_, port := startGRPCServer(t, grpc.Creds(credentials.NewServerTLSFromCert(&cert)))
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(), newGRPCEnv(port, &entities.GRPCCheckConfig{
+		Port: port,
+		TLS:  &entities.GRPCTLSConfig{ServerName: "grpc.internal", CACert: caPEM},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
+
+	// The certificate is not issued for the target's address
+	result, err = checker.CheckHealth(context.Background(), newGRPCEnv(port, &entities.GRPCCheckConfig{
+		Port: port,
+		TLS:  &entities.GRPCTLSConfig{CACert: caPEM},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+
+	// A plaintext check cannot talk to a TLS server
+	result, err = checker.CheckHealth(context.Background(), newGRPCEnv(port, &entities.GRPCCheckConfig{Port: port}))
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+}
+
+func TestChecker_CheckHealth_GRPCInvalidCACert(t *testing.T) {
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(), newGRPCEnv(1, &entities.GRPCCheckConfig{
+		TLS: &entities.GRPCTLSConfig{CACert: "not a certificate"},
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "Invalid TLS configuration")
+}
+
+func TestChecker_CheckHealth_GRPCUnreachable(t *testing.T) {
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(),
+		newGRPCEnv(0, &entities.GRPCCheckConfig{Port: closedTCPPort(t)}))
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+}
+
+// selfSignedCert returns a certificate for host and its PEM encoding
+func selfSignedCert(t *testing.T, host string) (tls.Certificate, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: host},
+		DNSNames:              []string{host},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
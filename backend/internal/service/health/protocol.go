@@ -0,0 +1,238 @@
+package health
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// protocolProbe speaks just enough of a protocol on conn to tell whether the
+// server is serving, and says why
+type protocolProbe func(conn net.Conn, config *entities.ProtocolCheckConfig) (healthy bool, message string)
+
+var protocolProbes = map[entities.HealthCheckType]protocolProbe{
+	entities.HealthCheckTypeRedis:    probeRedis,
+	entities.HealthCheckTypeSMTP:     probeGreeting,
+	entities.HealthCheckTypeFTP:      probeGreeting,
+	entities.HealthCheckTypePostgres: probePostgres,
+	entities.HealthCheckTypeMySQL:    probeMySQL,
+}
+
+// checkProtocol dials the dependency and runs its protocol probe. The
+// response time covers the connection and the probe.
+func (c *Checker) checkProtocol(ctx context.Context, env *entities.Environment) *CheckResult {
+	config := env.HealthCheck.Protocol
+	address := config.Address(env.Target, env.HealthCheck.Type)
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(env))
+	defer cancel()
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return &CheckResult{
+			Status:       entities.HealthStatusUnhealthy,
+			Message:      fmt.Sprintf("Connection failed: %v", err),
+			ResponseTime: time.Since(start).Milliseconds(),
+		}
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	healthy, message := protocolProbes[env.HealthCheck.Type](conn, config)
+	result := &CheckResult{
+		Status:       entities.HealthStatusHealthy,
+		Message:      message,
+		ResponseTime: time.Since(start).Milliseconds(),
+	}
+	if !healthy {
+		result.Status = entities.HealthStatusUnhealthy
+	}
+	return result
+}
+
+// probeRedis sends PING and expects PONG. A server that requires
+// authentication first is serving too.
+func probeRedis(conn net.Conn, _ *entities.ProtocolCheckConfig) (bool, string) {
+	if _, err := conn.Write([]byte("*1\r\n$4\r\nPING\r\n")); err != nil {
+		return false, fmt.Sprintf("Failed to send PING: %v", err)
+	}
+	reply, err := textproto.NewReader(newResponseReader(conn)).ReadLine()
+	if err != nil {
+		return false, fmt.Sprintf("No reply to PING: %v", err)
+	}
+
+	switch {
+	case reply == "+PONG":
+		return true, "Redis replied PONG"
+	case strings.HasPrefix(reply, "-NOAUTH"):
+		return true, "Redis is serving and requires authentication"
+	case strings.HasPrefix(reply, "-"):
+		return false, fmt.Sprintf("Redis replied with an error: %s", truncate([]byte(reply[1:]), 128))
+	}
+	return false, fmt.Sprintf("Unexpected reply to PING: %q", truncate([]byte(reply), 128))
+}
+
+// probeGreeting reads an SMTP or FTP server's greeting, which may span
+// several lines, and says goodbye
+func probeGreeting(conn net.Conn, config *entities.ProtocolCheckConfig) (bool, string) {
+	expected := config.GreetingCode()
+	code, message, err := textproto.NewReader(newResponseReader(conn)).ReadResponse(expected)
+	if err != nil {
+		if _, ok := err.(*textproto.Error); ok {
+			return false, fmt.Sprintf("Greeting %d, expected %d: %s", code, expected, truncate([]byte(message), 128))
+		}
+		return false, fmt.Sprintf("No greeting: %v", err)
+	}
+	_, _ = conn.Write([]byte("QUIT\r\n"))
+	return true, fmt.Sprintf("Greeting %d %s", code, truncate([]byte(message), 128))
+}
+
+// Wire constants of the PostgreSQL frontend/backend protocol
+const (
+	postgresSSLRequestCode = 80877103
+	postgresProtocol3      = 196608
+)
+
+// probePostgres asks for TLS, starts a session and reads the server's first
+// reply. Being asked to authenticate, or refused for reasons such as an
+// unknown user, means the server accepts connections; refusals because it is
+// starting, shutting down or out of connections do not.
+func probePostgres(conn net.Conn, config *entities.ProtocolCheckConfig) (bool, string) {
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], postgresSSLRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return false, fmt.Sprintf("Failed to send SSLRequest: %v", err)
+	}
+	answer := make([]byte, 1)
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return false, fmt.Sprintf("No reply to SSLRequest: %v", err)
+	}
+
+	var session net.Conn = conn
+	switch answer[0] {
+	case 'S':
+		// The probe sends no secrets, so the certificate is not verified
+		session = tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	case 'N':
+	default:
+		return false, fmt.Sprintf("Unexpected reply to SSLRequest: %q", answer)
+	}
+
+	user, database := config.StartupUser()
+	var params bytes.Buffer
+	for _, param := range []string{"user", user, "database", database, "application_name", "app-env-manager"} {
+		params.WriteString(param)
+		params.WriteByte(0)
+	}
+	params.WriteByte(0)
+	startup := make([]byte, 8, 8+params.Len())
+	binary.BigEndian.PutUint32(startup[0:4], uint32(8+params.Len()))
+	binary.BigEndian.PutUint32(startup[4:8], postgresProtocol3)
+	if _, err := session.Write(append(startup, params.Bytes()...)); err != nil {
+		return false, fmt.Sprintf("Failed to send startup message: %v", err)
+	}
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(session, header); err != nil {
+		return false, fmt.Sprintf("No reply to startup message: %v", err)
+	}
+	switch header[0] {
+	case 'R':
+		return true, "PostgreSQL is accepting connections"
+	case 'E':
+		length := int(binary.BigEndian.Uint32(header[1:5])) - 4
+		if length < 0 || length > maxTCPResponse {
+			return false, fmt.Sprintf("Invalid error response length %d", length)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(session, body); err != nil {
+			return false, fmt.Sprintf("Failed to read error response: %v", err)
+		}
+		code, message := postgresError(body)
+		// Class 53 is insufficient resources and class 57 operator
+		// intervention, such as the database system starting up
+		if strings.HasPrefix(code, "53") || strings.HasPrefix(code, "57") {
+			return false, fmt.Sprintf("PostgreSQL refused the connection: %s (%s)", message, code)
+		}
+		return true, fmt.Sprintf("PostgreSQL is accepting connections: %s (%s)", message, code)
+	}
+	return false, fmt.Sprintf("Unexpected reply to startup message: %q", header[:1])
+}
+
+// postgresError returns the SQLSTATE code and message of an ErrorResponse
+// body
+func postgresError(body []byte) (code, message string) {
+	for len(body) > 1 {
+		field := body[0]
+		end := bytes.IndexByte(body[1:], 0)
+		if end < 0 {
+			break
+		}
+		value := string(body[1 : 1+end])
+		body = body[2+end:]
+		switch field {
+		case 'C':
+			code = value
+		case 'M':
+			message = value
+		}
+	}
+	return code, message
+}
+
+// probeMySQL reads the handshake a MySQL or MariaDB server sends on connect
+func probeMySQL(conn net.Conn, _ *entities.ProtocolCheckConfig) (bool, string) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return false, fmt.Sprintf("No handshake: %v", err)
+	}
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length == 0 || length > maxTCPResponse {
+		return false, fmt.Sprintf("Invalid handshake length %d", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return false, fmt.Sprintf("Failed to read handshake: %v", err)
+	}
+
+	switch payload[0] {
+	case 10:
+		version := payload[1:]
+		if end := bytes.IndexByte(version, 0); end >= 0 {
+			version = version[:end]
+		}
+		return true, fmt.Sprintf("MySQL server version %s", truncate(version, 64))
+	case 0xff:
+		if len(payload) < 3 {
+			return false, fmt.Sprintf("MySQL refused the connection")
+		}
+		code := binary.LittleEndian.Uint16(payload[1:3])
+		message := payload[3:]
+		if len(message) >= 6 && message[0] == '#' {
+			message = message[6:]
+		}
+		return false, fmt.Sprintf("MySQL refused the connection: %s (%d)", truncate(message, 128), code)
+	}
+	return false, fmt.Sprintf("Unsupported MySQL protocol version %d", payload[0])
+}
+
+// newResponseReader reads at most maxTCPResponse bytes of a reply
+func newResponseReader(conn net.Conn) *bufio.Reader {
+	return bufio.NewReader(io.LimitReader(conn, maxTCPResponse))
+}
@@ -0,0 +1,279 @@
+package health_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProtocolEnv(checkType entities.HealthCheckType, port int, config *entities.ProtocolCheckConfig) *entities.Environment {
+	if config == nil {
+		config = &entities.ProtocolCheckConfig{}
+	}
+	config.Port = port
+	return &entities.Environment{
+		Target: entities.Target{Host: "127.0.0.1", Port: 22},
+		HealthCheck: entities.HealthCheckConfig{
+			Enabled:  true,
+			Type:     checkType,
+			Timeout:  1,
+			Protocol: config,
+		},
+	}
+}
+
+func checkProtocol(t *testing.T, env *entities.Environment) *health.CheckResult {
+	t.Helper()
+	result, err := health.NewChecker(5*time.Second).CheckHealth(context.Background(), env)
+	require.NoError(t, err)
+	return result
+}
+
+// replyToPING serves a Redis that answers PING with reply
+func replyToPING(t *testing.T, reply string) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		request := make([]byte, len("*1\r\n$4\r\nPING\r\n"))
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		assert.Equal(t, "*1\r\n$4\r\nPING\r\n", string(request))
+		conn.Write([]byte(reply))
+	}
+}
+
+func TestChecker_CheckHealth_Redis(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		status  entities.HealthStatus
+		message string
+	}{
+		{"pong", "+PONG\r\n", entities.HealthStatusHealthy, "Redis replied PONG"},
+		{"requires auth", "-NOAUTH Authentication required.\r\n", entities.HealthStatusHealthy,
+			"Redis is serving and requires authentication"},
+		{"loading", "-LOADING Redis is loading the dataset in memory\r\n", entities.HealthStatusUnhealthy,
+			"Redis replied with an error: LOADING Redis is loading the dataset in memory"},
+		{"not redis", "HTTP/1.1 400 Bad Request\r\n", entities.HealthStatusUnhealthy,
+			`Unexpected reply to PING: "HTTP/1.1 400 Bad Request"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := startTCPServer(t, replyToPING(t, tt.reply))
+
+			result := checkProtocol(t, newProtocolEnv(entities.HealthCheckTypeRedis, port, nil))
+
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+}
+
+func TestChecker_CheckHealth_SMTPGreeting(t *testing.T) {
+	quit := make(chan string, 1)
+	port := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("220-mail.example.com ESMTP\r\n220 ready\r\n"))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		quit <- line
+	})
+
+	result := checkProtocol(t, newProtocolEnv(entities.HealthCheckTypeSMTP, port, nil))
+
+	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
+	assert.Equal(t, "Greeting 220 mail.example.com ESMTP\nready", result.Message)
+	assert.Equal(t, "QUIT\r\n", <-quit)
+}
+
+func TestChecker_CheckHealth_GreetingCode(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkType entities.HealthCheckType
+		greeting  string
+		expected  int
+		status    entities.HealthStatus
+		message   string
+	}{
+		{"smtp refused", entities.HealthCheckTypeSMTP, "554 No SMTP service here\r\n", 0,
+			entities.HealthStatusUnhealthy, "Greeting 554, expected 220: No SMTP service here"},
+		{"ftp not ready", entities.HealthCheckTypeFTP, "120 Service ready in 5 minutes\r\n", 0,
+			entities.HealthStatusUnhealthy, "Greeting 120, expected 220: Service ready in 5 minutes"},
+		{"ftp ready", entities.HealthCheckTypeFTP, "220 (vsFTPd 3.0.5)\r\n", 0,
+			entities.HealthStatusHealthy, "Greeting 220 (vsFTPd 3.0.5)"},
+		{"expected code", entities.HealthCheckTypeSMTP, "421 Try again later\r\n", 421,
+			entities.HealthStatusHealthy, "Greeting 421 Try again later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := startTCPServer(t, func(conn net.Conn) {
+				conn.Write([]byte(tt.greeting))
+				io.Copy(io.Discard, conn)
+			})
+
+			result := checkProtocol(t, newProtocolEnv(tt.checkType, port, &entities.ProtocolCheckConfig{ExpectedCode: tt.expected}))
+
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+}
+
+func TestChecker_CheckHealth_NoGreeting(t *testing.T) {
+	port := startTCPServer(t, func(conn net.Conn) {
+		io.Copy(io.Discard, conn)
+	})
+
+	result := checkProtocol(t, newProtocolEnv(entities.HealthCheckTypeFTP, port, nil))
+
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "No greeting")
+}
+
+// fakePostgres answers the SSLRequest with sslAnswer, reads the startup
+// message into params and sends reply
+func fakePostgres(t *testing.T, sslAnswer byte, reply []byte, params chan<- map[string]string) func(conn net.Conn) {
+	cert, _ := selfSignedCert(t, "db.internal")
+	return func(conn net.Conn) {
+		request := make([]byte, 8)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		assert.Equal(t, uint32(80877103), binary.BigEndian.Uint32(request[4:8]))
+		conn.Write([]byte{sslAnswer})
+
+		var session net.Conn = conn
+		if sslAnswer == 'S' {
+			session = tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+		}
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(session, header); err != nil {
+			return
+		}
+		assert.Equal(t, uint32(196608), binary.BigEndian.Uint32(header[4:8]))
+		body := make([]byte, binary.BigEndian.Uint32(header[0:4])-8)
+		io.ReadFull(session, body)
+		fields := strings.Split(strings.TrimRight(string(body), "\x00"), "\x00")
+		received := make(map[string]string)
+		for i := 0; i+1 < len(fields); i += 2 {
+			received[fields[i]] = fields[i+1]
+		}
+		params <- received
+		session.Write(reply)
+	}
+}
+
+// postgresMessage frames a backend message
+func postgresMessage(kind byte, body []byte) []byte {
+	message := []byte{kind, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(message[1:5], uint32(4+len(body)))
+	return append(message, body...)
+}
+
+func postgresErrorResponse(code, message string) []byte {
+	return postgresMessage('E', []byte("SFATAL\x00C"+code+"\x00M"+message+"\x00\x00"))
+}
+
+func TestChecker_CheckHealth_Postgres(t *testing.T) {
+	md5Request := postgresMessage('R', []byte{0, 0, 0, 5, 1, 2, 3, 4})
+	tests := []struct {
+		name      string
+		sslAnswer byte
+		reply     []byte
+		status    entities.HealthStatus
+		message   string
+	}{
+		{"authentication requested", 'N', md5Request, entities.HealthStatusHealthy,
+			"PostgreSQL is accepting connections"},
+		{"over TLS", 'S', md5Request, entities.HealthStatusHealthy,
+			"PostgreSQL is accepting connections"},
+		{"unknown role", 'N', postgresErrorResponse("28000", `role "monitor" does not exist`), entities.HealthStatusHealthy,
+			`PostgreSQL is accepting connections: role "monitor" does not exist (28000)`},
+		{"starting up", 'N', postgresErrorResponse("57P03", "the database system is starting up"), entities.HealthStatusUnhealthy,
+			"PostgreSQL refused the connection: the database system is starting up (57P03)"},
+		{"too many connections", 'N', postgresErrorResponse("53300", "sorry, too many clients already"), entities.HealthStatusUnhealthy,
+			"PostgreSQL refused the connection: sorry, too many clients already (53300)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := make(chan map[string]string, 1)
+			port := startTCPServer(t, fakePostgres(t, tt.sslAnswer, tt.reply, params))
+
+			result := checkProtocol(t, newProtocolEnv(entities.HealthCheckTypePostgres, port,
+				&entities.ProtocolCheckConfig{User: "monitor"}))
+
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+			received := <-params
+			assert.Equal(t, "monitor", received["user"])
+			assert.Equal(t, "monitor", received["database"])
+		})
+	}
+}
+
+func TestChecker_CheckHealth_NotPostgres(t *testing.T) {
+	port := startTCPServer(t, func(conn net.Conn) {
+		io.ReadFull(conn, make([]byte, 8))
+		conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n"))
+	})
+
+	result := checkProtocol(t, newProtocolEnv(entities.HealthCheckTypePostgres, port, nil))
+
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Equal(t, `Unexpected reply to SSLRequest: "H"`, result.Message)
+}
+
+// mysqlPacket frames a packet with sequence number 0
+func mysqlPacket(payload []byte) []byte {
+	return append([]byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), 0}, payload...)
+}
+
+func TestChecker_CheckHealth_MySQL(t *testing.T) {
+	var handshake bytes.Buffer
+	handshake.WriteByte(10)
+	handshake.WriteString("8.0.36\x00")
+	handshake.Write([]byte{1, 0, 0, 0, 'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 0})
+
+	tests := []struct {
+		name    string
+		packet  []byte
+		status  entities.HealthStatus
+		message string
+	}{
+		{"handshake", mysqlPacket(handshake.Bytes()), entities.HealthStatusHealthy, "MySQL server version 8.0.36"},
+		{"too many connections", mysqlPacket([]byte("\xff\x10\x04#08004Too many connections")),
+			entities.HealthStatusUnhealthy, "MySQL refused the connection: Too many connections (1040)"},
+		{"host blocked", mysqlPacket([]byte("\xff\x69\x04Host '10.0.0.5' is blocked")),
+			entities.HealthStatusUnhealthy, "MySQL refused the connection: Host '10.0.0.5' is blocked (1129)"},
+		{"old protocol", mysqlPacket([]byte("\x095.0.0\x00")),
+			entities.HealthStatusUnhealthy, "Unsupported MySQL protocol version 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := startTCPServer(t, func(conn net.Conn) {
+				conn.Write(tt.packet)
+			})
+
+			result := checkProtocol(t, newProtocolEnv(entities.HealthCheckTypeMySQL, port, nil))
+
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.message, result.Message)
+		})
+	}
+}
+
+func TestChecker_CheckHealth_ProtocolUnreachable(t *testing.T) {
+	result := checkProtocol(t, newProtocolEnv(entities.HealthCheckTypeRedis, closedTCPPort(t), nil))
+
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "Connection failed")
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/assertion"
+)
+
+// ValidateResponse validates a health check response against the validation
+// type, when set, and every rule. Without rules it is Validate. With rules
+// each one's outcome is reported, separated by semicolons, and a response
+// that only fails warning rules is degraded.
+func (v *Validator) ValidateResponse(config entities.ValidationConfig, resp assertion.Response, responseTime int64) (entities.HealthStatus, string) {
+	if len(config.Rules) == 0 {
+		valid, message := v.Validate(config, resp.StatusCode, resp.Body)
+		return validStatus(valid), message
+	}
+
+	status := entities.HealthStatusHealthy
+	var messages []string
+	if config.Type != "" {
+		valid, message := v.Validate(config, resp.StatusCode, resp.Body)
+		status = validStatus(valid)
+		messages = append(messages, message)
+	}
+	for _, rule := range config.Rules {
+		err := evaluateRule(rule, resp, responseTime)
+		switch {
+		case err == nil:
+			messages = append(messages, ruleLabel(rule)+": passed")
+		case rule.Severity == entities.RuleSeverityWarning:
+			if status == entities.HealthStatusHealthy {
+				status = entities.HealthStatusDegraded
+			}
+			messages = append(messages, "warning: "+err.Error())
+		default:
+			status = entities.HealthStatusUnhealthy
+			messages = append(messages, err.Error())
+		}
+	}
+	return status, strings.Join(messages, "; ")
+}
+
+func validStatus(valid bool) entities.HealthStatus {
+	if valid {
+		return entities.HealthStatusHealthy
+	}
+	return entities.HealthStatusUnhealthy
+}
+
+// evaluateRule checks one rule, returning an *assertion.Failure when the
+// response does not satisfy it
+func evaluateRule(rule entities.ValidationRule, resp assertion.Response, responseTime int64) error {
+	switch rule.Type {
+	case entities.ValidationRuleStatusCode:
+		if len(rule.StatusCodes) == 0 && len(rule.StatusRanges) == 0 {
+			return assertion.CheckStatus(nil, []string{"2xx"}, resp.StatusCode)
+		}
+		return assertion.CheckStatus(rule.StatusCodes, rule.StatusRanges, resp.StatusCode)
+	case entities.ValidationRuleJSONPath:
+		if rule.JSONPath == nil {
+			return fmt.Errorf("jsonPath rule has no jsonPath assertion")
+		}
+		return assertion.CheckJSONPath(*rule.JSONPath, resp.Body)
+	case entities.ValidationRuleHeader:
+		if rule.Header == "" {
+			return fmt.Errorf("header rule has no header name")
+		}
+		return assertion.CheckHeader(rule.Header, rule.Value, resp.Header)
+	case entities.ValidationRuleBodyContains:
+		return assertion.CheckBodyContains(rule.Contains, resp.Body)
+	case entities.ValidationRuleResponseTime:
+		if rule.MaxMs <= 0 {
+			return fmt.Errorf("responseTime rule has no maxMs")
+		}
+		if responseTime > int64(rule.MaxMs) {
+			return &assertion.Failure{
+				Assertion: "responseTime",
+				Expected:  fmt.Sprintf("at most %dms", rule.MaxMs),
+				Actual:    fmt.Sprintf("%dms", responseTime),
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown validation rule type %q", rule.Type)
+}
+
+// ruleLabel names a rule in messages
+func ruleLabel(rule entities.ValidationRule) string {
+	switch {
+	case rule.Type == entities.ValidationRuleJSONPath && rule.JSONPath != nil:
+		return "jsonPath " + rule.JSONPath.Path
+	case rule.Type == entities.ValidationRuleHeader:
+		return "header " + rule.Header
+	}
+	return string(rule.Type)
+}
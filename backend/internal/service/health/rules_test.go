@@ -0,0 +1,110 @@
+package health_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/assertion"
+	"app-env-manager/internal/service/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_ValidateResponse(t *testing.T) {
+	resp := assertion.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Version": []string{"2.1.0"}},
+		Body:       []byte(`{"checks":{"db":{"status":"up"},"queue":{"depth":12}}}`),
+	}
+	maxDepth := 100.0
+
+	tests := []struct {
+		name    string
+		config  entities.ValidationConfig
+		status  entities.HealthStatus
+		message string
+	}{
+		{"type only", entities.ValidationConfig{Type: "statusCode", Value: 200},
+			entities.HealthStatusHealthy, "Status code 200 matches expected 200"},
+		{"passing rules", entities.ValidationConfig{Rules: []entities.ValidationRule{
+			{Type: entities.ValidationRuleStatusCode, StatusRanges: []string{"2xx"}},
+			{Type: entities.ValidationRuleJSONPath, JSONPath: &entities.JSONPathAssertion{Path: "$.checks.db.status", Equals: "up"}},
+			{Type: entities.ValidationRuleJSONPath, JSONPath: &entities.JSONPathAssertion{Path: "$.checks.queue.depth", LessThan: &maxDepth}},
+			{Type: entities.ValidationRuleHeader, Header: "X-Version"},
+			{Type: entities.ValidationRuleBodyContains, Contains: `"db"`},
+			{Type: entities.ValidationRuleResponseTime, MaxMs: 500},
+		}}, entities.HealthStatusHealthy, "statusCode: passed; jsonPath $.checks.db.status: passed; jsonPath $.checks.queue.depth: passed; " +
+			"header X-Version: passed; bodyContains: passed; responseTime: passed"},
+		{"every rule is reported", entities.ValidationConfig{Rules: []entities.ValidationRule{
+			{Type: entities.ValidationRuleJSONPath, JSONPath: &entities.JSONPathAssertion{
+				Path: "$.checks.db.status", In: []interface{}{"down", "degraded"}}},
+			{Type: entities.ValidationRuleHeader, Header: "X-Version", Value: "2.0.0"},
+			{Type: entities.ValidationRuleResponseTime, MaxMs: 100},
+		}}, entities.HealthStatusUnhealthy, `jsonPath $.checks.db.status: expected one of ["down","degraded"], got "up"; ` +
+			`header X-Version: expected "2.0.0", got "2.1.0"; responseTime: expected at most 100ms, got 150ms`},
+		{"type and rules", entities.ValidationConfig{Type: "statusCode", Value: 204, Rules: []entities.ValidationRule{
+			{Type: entities.ValidationRuleStatusCode, StatusCodes: []int{200, 204}},
+		}}, entities.HealthStatusUnhealthy, "Status code 200 does not match expected 204; statusCode: passed"},
+		{"default status range", entities.ValidationConfig{Rules: []entities.ValidationRule{
+			{Type: entities.ValidationRuleStatusCode},
+		}}, entities.HealthStatusHealthy, "statusCode: passed"},
+		{"misconfigured rules", entities.ValidationConfig{Rules: []entities.ValidationRule{
+			{Type: entities.ValidationRuleJSONPath},
+			{Type: entities.ValidationRuleHeader},
+			{Type: entities.ValidationRuleResponseTime},
+			{Type: "latency"},
+		}}, entities.HealthStatusUnhealthy, "jsonPath rule has no jsonPath assertion; header rule has no header name; " +
+			`responseTime rule has no maxMs; unknown validation rule type "latency"`},
+		{"warning rule", entities.ValidationConfig{Rules: []entities.ValidationRule{
+			{Type: entities.ValidationRuleStatusCode},
+			{Type: entities.ValidationRuleResponseTime, MaxMs: 100, Severity: entities.RuleSeverityWarning},
+		}}, entities.HealthStatusDegraded, "statusCode: passed; warning: responseTime: expected at most 100ms, got 150ms"},
+		{"error outranks warning", entities.ValidationConfig{Rules: []entities.ValidationRule{
+			{Type: entities.ValidationRuleHeader, Header: "X-Version", Value: "3.0.0", Severity: entities.RuleSeverityWarning},
+			{Type: entities.ValidationRuleStatusCode, StatusCodes: []int{204}},
+		}}, entities.HealthStatusUnhealthy, `warning: header X-Version: expected "3.0.0", got "2.1.0"; ` +
+			"statusCode: expected one of 204, got 200"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := health.NewValidator().ValidateResponse(tt.config, resp, 150)
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.message, message)
+		})
+	}
+}
+
+func TestChecker_CheckHealth_ValidationRules(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"checks":{"db":{"status":"down"}}}`))
+	}))
+	defer server.Close()
+
+	env := &entities.Environment{
+		HealthCheck: entities.HealthCheckConfig{
+			Enabled:  true,
+			Endpoint: server.URL,
+			Method:   "GET",
+			Validation: entities.ValidationConfig{Rules: []entities.ValidationRule{
+				{Type: entities.ValidationRuleStatusCode, StatusRanges: []string{"200-202"}},
+				{Type: entities.ValidationRuleHeader, Header: "Content-Type", Value: "application/json"},
+				{Type: entities.ValidationRuleJSONPath, JSONPath: &entities.JSONPathAssertion{Path: "$.checks.db.status", Equals: "up"}},
+			}},
+		},
+	}
+
+	result, err := health.NewChecker(5*time.Second).CheckHealth(context.Background(), env)
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Equal(t, http.StatusAccepted, result.StatusCode)
+	assert.Equal(t, `statusCode: passed; header Content-Type: passed; jsonPath $.checks.db.status: expected "up", got "down"`,
+		result.Message)
+}
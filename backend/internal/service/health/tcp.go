@@ -0,0 +1,120 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+const (
+	// maxTCPResponse caps how much of a banner or response is read
+	maxTCPResponse = 4096
+	// defaultCheckTimeout bounds checks when neither the environment nor
+	// the checker sets a timeout
+	defaultCheckTimeout = 10 * time.Second
+)
+
+// checkTCP dials the environment's port, optionally sends the payload and
+// matches the banner or response. The response time is the connect latency.
+func (c *Checker) checkTCP(ctx context.Context, env *entities.Environment) *CheckResult {
+	config := env.HealthCheck.TCP
+	address := config.Address(env.Target)
+
+	var pattern *regexp.Regexp
+	if config != nil && config.ResponsePattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(config.ResponsePattern); err != nil {
+			return &CheckResult{
+				Status:  entities.HealthStatusUnhealthy,
+				Message: fmt.Sprintf("Invalid response pattern: %v", err),
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout(env))
+	defer cancel()
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	responseTime := time.Since(start).Milliseconds()
+	if err != nil {
+		return &CheckResult{
+			Status:       entities.HealthStatusUnhealthy,
+			Message:      fmt.Sprintf("Connection failed: %v", err),
+			ResponseTime: responseTime,
+		}
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if config != nil && config.Payload != "" {
+		if _, err := conn.Write([]byte(config.Payload)); err != nil {
+			return &CheckResult{
+				Status:       entities.HealthStatusUnhealthy,
+				Message:      fmt.Sprintf("Failed to send payload: %v", err),
+				ResponseTime: responseTime,
+			}
+		}
+	}
+
+	if pattern == nil {
+		return &CheckResult{
+			Status:       entities.HealthStatusHealthy,
+			Message:      fmt.Sprintf("Connected to %s", address),
+			ResponseTime: responseTime,
+		}
+	}
+
+	// Read until the response matches, the peer stops sending or the
+	// timeout passes
+	response := make([]byte, 0, 512)
+	buf := make([]byte, 512)
+	for len(response) < maxTCPResponse {
+		n, err := conn.Read(buf)
+		response = append(response, buf[:n]...)
+		if pattern.Match(response) {
+			return &CheckResult{
+				Status:       entities.HealthStatusHealthy,
+				Message:      "Response matches expected pattern",
+				ResponseTime: responseTime,
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	return &CheckResult{
+		Status:       entities.HealthStatusUnhealthy,
+		Message:      fmt.Sprintf("Response does not match expected pattern: %q", truncate(response, 128)),
+		ResponseTime: responseTime,
+	}
+}
+
+// timeout returns how long a check of the environment may take: its
+// configured timeout, or the checker's
+func (c *Checker) timeout(env *entities.Environment) time.Duration {
+	if env.HealthCheck.Timeout > 0 {
+		return time.Duration(env.HealthCheck.Timeout) * time.Second
+	}
+	if c.httpClient.Timeout > 0 {
+		return c.httpClient.Timeout
+	}
+	return defaultCheckTimeout
+}
+
+// truncate shortens a response for a message
+func truncate(response []byte, limit int) string {
+	if len(response) > limit {
+		return string(response[:limit]) + "..."
+	}
+	return string(response)
+}
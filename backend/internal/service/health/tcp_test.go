@@ -0,0 +1,154 @@
+package health_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTCPServer serves each connection with handle and returns its port
+func startTCPServer(t *testing.T, handle func(conn net.Conn)) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func newTCPEnv(port int, config *entities.TCPCheckConfig) *entities.Environment {
+	return &entities.Environment{
+		Target: entities.Target{Host: "127.0.0.1", Port: port},
+		HealthCheck: entities.HealthCheckConfig{
+			Enabled: true,
+			Type:    entities.HealthCheckTypeTCP,
+			Timeout: 1,
+			TCP:     config,
+		},
+	}
+}
+
+func TestChecker_CheckHealth_TCPConnect(t *testing.T) {
+	port := startTCPServer(t, func(conn net.Conn) {})
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(), newTCPEnv(port, nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
+	assert.Equal(t, "Connected to 127.0.0.1:"+strconv.Itoa(port), result.Message)
+	assert.GreaterOrEqual(t, result.ResponseTime, int64(0))
+}
+
+func TestChecker_CheckHealth_TCPConfiguredPort(t *testing.T) {
+	port := startTCPServer(t, func(conn net.Conn) {})
+	checker := health.NewChecker(5 * time.Second)
+
+	// The target's own port is the SSH port, which is closed here
+	env := newTCPEnv(closedTCPPort(t), &entities.TCPCheckConfig{Port: port})
+	result, err := checker.CheckHealth(context.Background(), env)
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
+}
+
+func TestChecker_CheckHealth_TCPConnectionRefused(t *testing.T) {
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(), newTCPEnv(closedTCPPort(t), nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "Connection failed")
+}
+
+func TestChecker_CheckHealth_TCPBanner(t *testing.T) {
+	port := startTCPServer(t, func(conn net.Conn) {
+		conn.Write([]byte("220 mail.example.com ESMTP ready\r\n"))
+	})
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(),
+		newTCPEnv(port, &entities.TCPCheckConfig{ResponsePattern: `^220 `}))
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
+
+	result, err = checker.CheckHealth(context.Background(),
+		newTCPEnv(port, &entities.TCPCheckConfig{ResponsePattern: `^421 `}))
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "220 mail.example.com")
+}
+
+func TestChecker_CheckHealth_TCPPayload(t *testing.T) {
+	port := startTCPServer(t, func(conn net.Conn) {
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil && line == "PING\r\n" {
+			conn.Write([]byte("+PONG\r\n"))
+		}
+	})
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(),
+		newTCPEnv(port, &entities.TCPCheckConfig{Payload: "PING\r\n", ResponsePattern: `\+PONG`}))
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusHealthy, result.Status)
+}
+
+func TestChecker_CheckHealth_TCPResponseTimeout(t *testing.T) {
+	// The server accepts and says nothing
+	port := startTCPServer(t, func(conn net.Conn) {
+		time.Sleep(3 * time.Second)
+	})
+	checker := health.NewChecker(5 * time.Second)
+
+	start := time.Now()
+	result, err := checker.CheckHealth(context.Background(),
+		newTCPEnv(port, &entities.TCPCheckConfig{ResponsePattern: "ready"}))
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Less(t, time.Since(start), 2*time.Second, "the check gives up after its timeout")
+}
+
+func TestChecker_CheckHealth_TCPInvalidPattern(t *testing.T) {
+	checker := health.NewChecker(5 * time.Second)
+
+	result, err := checker.CheckHealth(context.Background(),
+		newTCPEnv(1, &entities.TCPCheckConfig{ResponsePattern: "("}))
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Status)
+	assert.Contains(t, result.Message, "Invalid response pattern")
+}
+
+// closedTCPPort returns a local port nothing listens on
+func closedTCPPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
@@ -0,0 +1,396 @@
+// Package kubernetes is a small Kubernetes API client covering what
+// environment operations need: rollout restarts and image updates of a
+// Deployment or StatefulSet, and waiting for their rollouts to complete.
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RestartedAtAnnotation is the pod template annotation kubectl rollout
+// restart sets; changing it rolls every pod
+const RestartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// maxErrorBody bounds error responses read from the API server
+const maxErrorBody = 64 * 1024
+
+// namePattern is a DNS-1123 label or subdomain, which covers namespace,
+// workload and container names
+var namePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+
+// Config holds how to reach and authenticate to an API server
+type Config struct {
+	Server             string
+	CACert             []byte
+	InsecureSkipVerify bool
+	Token              string
+	ClientCert         []byte
+	ClientKey          []byte
+	Namespace          string // the kubeconfig context's namespace, if any
+}
+
+// Client talks to one API server
+type Client struct {
+	httpClient *http.Client
+	server     string
+	token      string
+}
+
+// NewClient creates a client for config.Server, which must be https. The CA
+// is required unless verification is skipped.
+func NewClient(config Config) (*Client, error) {
+	server, err := url.Parse(config.Server)
+	if err != nil || server.Scheme != "https" || server.Host == "" {
+		return nil, fmt.Errorf("Kubernetes API server must be an https URL")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+	if len(config.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CACert) {
+			return nil, fmt.Errorf("no certificates found in the Kubernetes CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	} else if !config.InsecureSkipVerify {
+		return nil, fmt.Errorf("a Kubernetes CA certificate is required")
+	}
+	if len(config.ClientCert) > 0 || len(config.ClientKey) > 0 {
+		pair, err := tls.X509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Kubernetes client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+	if config.Token == "" && len(tlsConfig.Certificates) == 0 {
+		return nil, fmt.Errorf("no Kubernetes token or client certificate configured")
+	}
+
+	return &Client{
+		httpClient: &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		server:     strings.TrimRight(config.Server, "/"),
+		token:      config.Token,
+	}, nil
+}
+
+// Server returns the API server URL
+func (c *Client) Server() string {
+	return c.server
+}
+
+// ValidateName checks a namespace, workload or container name
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid Kubernetes name %q", name)
+	}
+	return nil
+}
+
+// Error is an error response from the API server
+type Error struct {
+	StatusCode int
+	Reason     string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("kubernetes API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// WorkloadRef identifies a Deployment or StatefulSet
+type WorkloadRef struct {
+	Kind      string // "Deployment" or "StatefulSet"
+	Namespace string
+	Name      string
+}
+
+// String returns the reference as kubectl prints it, e.g. deployment/web
+func (r WorkloadRef) String() string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(r.Kind), r.Name)
+}
+
+// Path returns the API path of the workload
+func (r WorkloadRef) Path() (string, error) {
+	var resource string
+	switch r.Kind {
+	case "Deployment":
+		resource = "deployments"
+	case "StatefulSet":
+		resource = "statefulsets"
+	default:
+		return "", fmt.Errorf("unsupported workload kind %q", r.Kind)
+	}
+	return fmt.Sprintf("/apis/apps/v1/namespaces/%s/%s/%s",
+		url.PathEscape(r.Namespace), resource, url.PathEscape(r.Name)), nil
+}
+
+// Container is a pod template container
+type Container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// Condition is a workload status condition
+type Condition struct {
+	Type    string `json:"type"`
+	Status  string `json:"status"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// Workload is the part of a Deployment or StatefulSet rollouts depend on
+type Workload struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name       string `json:"name"`
+		Namespace  string `json:"namespace"`
+		Generation int64  `json:"generation"`
+	} `json:"metadata"`
+	Spec struct {
+		Replicas *int32 `json:"replicas"`
+		Template struct {
+			Metadata struct {
+				Annotations map[string]string `json:"annotations"`
+			} `json:"metadata"`
+			Spec struct {
+				Containers []Container `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+		UpdateStrategy struct {
+			Type          string `json:"type"`
+			RollingUpdate *struct {
+				Partition *int32 `json:"partition"`
+			} `json:"rollingUpdate"`
+		} `json:"updateStrategy"`
+	} `json:"spec"`
+	Status struct {
+		ObservedGeneration int64       `json:"observedGeneration"`
+		Replicas           int32       `json:"replicas"`
+		UpdatedReplicas    int32       `json:"updatedReplicas"`
+		ReadyReplicas      int32       `json:"readyReplicas"`
+		AvailableReplicas  int32       `json:"availableReplicas"`
+		CurrentRevision    string      `json:"currentRevision"`
+		UpdateRevision     string      `json:"updateRevision"`
+		Conditions         []Condition `json:"conditions"`
+	} `json:"status"`
+}
+
+// Container returns a pod template container by name
+func (w *Workload) Container(name string) (Container, bool) {
+	for _, c := range w.Spec.Template.Spec.Containers {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Container{}, false
+}
+
+// Get reads a workload
+func (c *Client) Get(ctx context.Context, ref WorkloadRef) (*Workload, error) {
+	path, err := ref.Path()
+	if err != nil {
+		return nil, err
+	}
+	var workload Workload
+	if err := c.do(ctx, http.MethodGet, path, nil, &workload); err != nil {
+		return nil, err
+	}
+	workload.Kind = ref.Kind
+	return &workload, nil
+}
+
+// RestartPatch is the patch RolloutRestart applies
+func RestartPatch(at time.Time) map[string]interface{} {
+	return templatePatch(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{RestartedAtAnnotation: at.UTC().Format(time.RFC3339)},
+		},
+	})
+}
+
+// ImagePatch is the patch SetImage applies
+func ImagePatch(container, image string) map[string]interface{} {
+	return templatePatch(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": container, "image": image}},
+		},
+	})
+}
+
+func templatePatch(template map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"spec": map[string]interface{}{"template": template}}
+}
+
+// RolloutRestart rolls every pod of the workload like kubectl rollout
+// restart and returns the workload's new generation
+func (c *Client) RolloutRestart(ctx context.Context, ref WorkloadRef, at time.Time) (int64, error) {
+	return c.patch(ctx, ref, RestartPatch(at))
+}
+
+// SetImage sets a container's image and returns the workload's new generation
+func (c *Client) SetImage(ctx context.Context, ref WorkloadRef, container, image string) (int64, error) {
+	return c.patch(ctx, ref, ImagePatch(container, image))
+}
+
+// patch applies a strategic merge patch
+func (c *Client) patch(ctx context.Context, ref WorkloadRef, patch map[string]interface{}) (int64, error) {
+	path, err := ref.Path()
+	if err != nil {
+		return 0, err
+	}
+	var workload Workload
+	if err := c.do(ctx, http.MethodPatch, path, patch, &workload); err != nil {
+		return 0, err
+	}
+	return workload.Metadata.Generation, nil
+}
+
+// WaitForRollout polls the workload every interval until the rollout of
+// generation completes, fails or ctx is done
+func (c *Client) WaitForRollout(ctx context.Context, ref WorkloadRef, generation int64, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	message := "waiting for rollout to start"
+	for {
+		workload, err := c.Get(ctx, ref)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if err == nil {
+			var done bool
+			done, message, err = RolloutStatus(workload, generation)
+			if err != nil {
+				return fmt.Errorf("rollout of %s failed: %w", ref, err)
+			}
+			if done {
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("rollout of %s did not complete: %s", ref, message)
+		}
+	}
+}
+
+// RolloutStatus reports whether the workload's rollout of generation is
+// complete, like kubectl rollout status. An error means it cannot complete.
+func RolloutStatus(w *Workload, generation int64) (bool, string, error) {
+	if generation < w.Metadata.Generation {
+		generation = w.Metadata.Generation
+	}
+	if w.Status.ObservedGeneration < generation {
+		return false, "waiting for the spec update to be observed", nil
+	}
+
+	if w.Kind == "StatefulSet" {
+		return statefulSetStatus(w)
+	}
+	return deploymentStatus(w)
+}
+
+func deploymentStatus(w *Workload) (bool, string, error) {
+	for _, condition := range w.Status.Conditions {
+		if condition.Type == "Progressing" && condition.Reason == "ProgressDeadlineExceeded" {
+			return false, "", fmt.Errorf("progress deadline exceeded: %s", condition.Message)
+		}
+	}
+	status := w.Status
+	if w.Spec.Replicas != nil && status.UpdatedReplicas < *w.Spec.Replicas {
+		return false, fmt.Sprintf("%d of %d new replicas have been updated", status.UpdatedReplicas, *w.Spec.Replicas), nil
+	}
+	if status.Replicas > status.UpdatedReplicas {
+		return false, fmt.Sprintf("%d old replicas are pending termination", status.Replicas-status.UpdatedReplicas), nil
+	}
+	if status.AvailableReplicas < status.UpdatedReplicas {
+		return false, fmt.Sprintf("%d of %d updated replicas are available", status.AvailableReplicas, status.UpdatedReplicas), nil
+	}
+	return true, "successfully rolled out", nil
+}
+
+func statefulSetStatus(w *Workload) (bool, string, error) {
+	strategy := w.Spec.UpdateStrategy
+	if strategy.Type != "" && strategy.Type != "RollingUpdate" {
+		return false, "", fmt.Errorf("rollout status is only available for the RollingUpdate strategy")
+	}
+	status := w.Status
+	if w.Spec.Replicas != nil && status.ReadyReplicas < *w.Spec.Replicas {
+		return false, fmt.Sprintf("%d of %d pods are ready", status.ReadyReplicas, *w.Spec.Replicas), nil
+	}
+	if strategy.RollingUpdate != nil && strategy.RollingUpdate.Partition != nil && w.Spec.Replicas != nil &&
+		*strategy.RollingUpdate.Partition > 0 {
+		want := *w.Spec.Replicas - *strategy.RollingUpdate.Partition
+		if status.UpdatedReplicas < want {
+			return false, fmt.Sprintf("%d of %d partitioned pods have been updated", status.UpdatedReplicas, want), nil
+		}
+		return true, "partitioned roll out complete", nil
+	}
+	if status.UpdateRevision != status.CurrentRevision {
+		return false, fmt.Sprintf("%d pods at revision %s", status.UpdatedReplicas, status.UpdateRevision), nil
+	}
+	return true, "successfully rolled out", nil
+}
+
+// do sends a request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode %s request: %w", path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.server+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/strategic-merge-patch+json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("kubernetes API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		var status struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &status) == nil && status.Message != "" {
+			message = status.Message
+		}
+		return &Error{StatusCode: resp.StatusCode, Reason: status.Reason, Message: message}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package kubernetes_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/service/kubernetes"
+	"app-env-manager/internal/service/kubernetes/kubetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, api *kubetest.Server) *kubernetes.Client {
+	t.Helper()
+	client, err := kubernetes.NewClient(kubernetes.Config{
+		Server: api.URL(),
+		CACert: api.CACert(),
+		Token:  kubetest.Token,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+var webRef = kubernetes.WorkloadRef{Kind: "Deployment", Namespace: "shop", Name: "web"}
+
+func TestClient_Get(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 3, "app", "registry.example.com/web:1.0.0", "proxy", "envoy:1.30")
+	client := newClient(t, api)
+
+	workload, err := client.Get(context.Background(), webRef)
+	require.NoError(t, err)
+	assert.Equal(t, "web", workload.Metadata.Name)
+	assert.Equal(t, int32(3), *workload.Spec.Replicas)
+	container, ok := workload.Container("app")
+	require.True(t, ok)
+	assert.Equal(t, "registry.example.com/web:1.0.0", container.Image)
+	assert.Len(t, workload.Spec.Template.Spec.Containers, 2)
+}
+
+func TestClient_Get_Errors(t *testing.T) {
+	api := kubetest.NewServer(t)
+	client := newClient(t, api)
+
+	_, err := client.Get(context.Background(), webRef)
+	var apiErr *kubernetes.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 404, apiErr.StatusCode)
+	assert.Equal(t, "NotFound", apiErr.Reason)
+	assert.Contains(t, apiErr.Message, `deployments.apps "web" not found`)
+
+	_, err = client.Get(context.Background(), kubernetes.WorkloadRef{Kind: "DaemonSet", Namespace: "shop", Name: "web"})
+	assert.ErrorContains(t, err, "unsupported workload kind")
+}
+
+func TestClient_Unauthorized(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 1, "app", "web:1.0.0")
+	client, err := kubernetes.NewClient(kubernetes.Config{Server: api.URL(), CACert: api.CACert(), Token: "wrong"})
+	require.NoError(t, err)
+
+	_, err = client.Get(context.Background(), webRef)
+	var apiErr *kubernetes.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 401, apiErr.StatusCode)
+}
+
+func TestClient_RolloutRestart(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 3, "app", "web:1.0.0")
+	api.SetRolloutReads(2)
+	client := newClient(t, api)
+	ctx := context.Background()
+
+	at := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	generation, err := client.RolloutRestart(ctx, webRef, at)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), generation)
+
+	require.NoError(t, client.WaitForRollout(ctx, webRef, generation, time.Millisecond))
+
+	workload, _ := api.Workload("Deployment", "shop", "web")
+	assert.Equal(t, "2024-05-01T10:00:00Z", workload.Annotations[kubernetes.RestartedAtAnnotation])
+	assert.Equal(t, "web:1.0.0", workload.Images["app"])
+	assert.Equal(t, []string{
+		"PATCH /apis/apps/v1/namespaces/shop/deployments/web",
+		"GET /apis/apps/v1/namespaces/shop/deployments/web",
+		"GET /apis/apps/v1/namespaces/shop/deployments/web",
+		"GET /apis/apps/v1/namespaces/shop/deployments/web",
+	}, api.Requests(), "two reads in progress, the third complete")
+}
+
+func TestClient_SetImage_StatefulSet(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddStatefulSet("shop", "db", 2, "postgres", "postgres:15")
+	api.SetRolloutReads(1)
+	client := newClient(t, api)
+	ctx := context.Background()
+	ref := kubernetes.WorkloadRef{Kind: "StatefulSet", Namespace: "shop", Name: "db"}
+
+	generation, err := client.SetImage(ctx, ref, "postgres", "postgres:16")
+	require.NoError(t, err)
+	require.NoError(t, client.WaitForRollout(ctx, ref, generation, time.Millisecond))
+
+	workload, _ := api.Workload("StatefulSet", "shop", "db")
+	assert.Equal(t, "postgres:16", workload.Images["postgres"])
+}
+
+func TestClient_SetImage_UnknownContainer(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 1, "app", "web:1.0.0")
+	client := newClient(t, api)
+
+	_, err := client.SetImage(context.Background(), webRef, "sidecar", "envoy:1.30")
+	var apiErr *kubernetes.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 422, apiErr.StatusCode)
+}
+
+func TestClient_WaitForRollout_DeadlineExceeded(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddDeployment("shop", "web", 2, "app", "web:1.0.0")
+	api.FailRollout("Deployment", "shop", "web")
+	client := newClient(t, api)
+	ctx := context.Background()
+
+	generation, err := client.SetImage(ctx, webRef, "app", "web:2.0.0")
+	require.NoError(t, err)
+
+	err = client.WaitForRollout(ctx, webRef, generation, time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rollout of deployment/web failed: progress deadline exceeded")
+}
+
+func TestClient_WaitForRollout_Timeout(t *testing.T) {
+	api := kubetest.NewServer(t)
+	api.AddStatefulSet("shop", "db", 2, "postgres", "postgres:15")
+	api.FailRollout("StatefulSet", "shop", "db")
+	client := newClient(t, api)
+	ref := kubernetes.WorkloadRef{Kind: "StatefulSet", Namespace: "shop", Name: "db"}
+
+	generation, err := client.SetImage(context.Background(), ref, "postgres", "postgres:16")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = client.WaitForRollout(ctx, ref, generation, 5*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rollout of statefulset/db did not complete: 0 of 2 pods are ready")
+}
+
+func TestRolloutStatus(t *testing.T) {
+	replicas := int32(3)
+	newDeployment := func() *kubernetes.Workload {
+		w := &kubernetes.Workload{Kind: "Deployment"}
+		w.Metadata.Generation = 4
+		w.Spec.Replicas = &replicas
+		w.Status.ObservedGeneration = 4
+		w.Status.Replicas = 3
+		w.Status.UpdatedReplicas = 3
+		w.Status.AvailableReplicas = 3
+		return w
+	}
+
+	done, _, err := kubernetes.RolloutStatus(newDeployment(), 4)
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	w := newDeployment()
+	w.Status.ObservedGeneration = 3
+	done, message, _ := kubernetes.RolloutStatus(w, 4)
+	assert.False(t, done)
+	assert.Contains(t, message, "spec update")
+
+	w = newDeployment()
+	done, _, _ = kubernetes.RolloutStatus(w, 5)
+	assert.False(t, done, "a generation newer than the workload's is still to be observed")
+
+	w = newDeployment()
+	w.Status.Replicas = 4
+	done, message, _ = kubernetes.RolloutStatus(w, 4)
+	assert.False(t, done)
+	assert.Equal(t, "1 old replicas are pending termination", message)
+
+	w = newDeployment()
+	w.Status.AvailableReplicas = 2
+	done, message, _ = kubernetes.RolloutStatus(w, 4)
+	assert.False(t, done)
+	assert.Equal(t, "2 of 3 updated replicas are available", message)
+
+	sts := &kubernetes.Workload{Kind: "StatefulSet"}
+	sts.Spec.UpdateStrategy.Type = "OnDelete"
+	_, _, err = kubernetes.RolloutStatus(sts, 0)
+	assert.ErrorContains(t, err, "RollingUpdate")
+}
+
+func TestNewClient_Validation(t *testing.T) {
+	api := kubetest.NewServer(t)
+	tests := []struct {
+		name   string
+		config kubernetes.Config
+		want   string
+	}{
+		{"http server", kubernetes.Config{Server: "http://k8s.example.com", Token: "t", InsecureSkipVerify: true}, "https URL"},
+		{"no CA", kubernetes.Config{Server: api.URL(), Token: "t"}, "CA certificate is required"},
+		{"bad CA", kubernetes.Config{Server: api.URL(), Token: "t", CACert: []byte("x")}, "no certificates found"},
+		{"no credentials", kubernetes.Config{Server: api.URL(), CACert: api.CACert()}, "no Kubernetes token or client certificate"},
+		{"bad client cert", kubernetes.Config{Server: api.URL(), CACert: api.CACert(), ClientCert: []byte("x")}, "invalid Kubernetes client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := kubernetes.NewClient(tt.config)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, kubernetes.ValidateName("web"))
+	assert.NoError(t, kubernetes.ValidateName("shop-prod.v2"))
+	assert.Error(t, kubernetes.ValidateName("Web"))
+	assert.Error(t, kubernetes.ValidateName("web/../secrets"))
+	assert.Error(t, kubernetes.ValidateName(""))
+}
@@ -0,0 +1,142 @@
+package kubernetes
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubeconfig is the subset of a kubeconfig file the client understands
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string                 `yaml:"token"`
+			TokenFile             string                 `yaml:"tokenFile"`
+			ClientCertificateData string                 `yaml:"client-certificate-data"`
+			ClientKeyData         string                 `yaml:"client-key-data"`
+			ClientCertificate     string                 `yaml:"client-certificate"`
+			ClientKey             string                 `yaml:"client-key"`
+			Exec                  map[string]interface{} `yaml:"exec"`
+			AuthProvider          map[string]interface{} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// ParseKubeconfig reads the connection settings of a kubeconfig context, the
+// current one when contextName is empty. Only embedded credentials are
+// supported: files, exec plugins and auth providers would run or read
+// things on this server.
+func ParseKubeconfig(data []byte, contextName string) (Config, error) {
+	var file kubeconfig
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return Config{}, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	if contextName == "" {
+		contextName = file.CurrentContext
+	}
+	if contextName == "" {
+		return Config{}, fmt.Errorf("kubeconfig has no current context")
+	}
+
+	var config Config
+	var clusterName, userName string
+	found := false
+	for _, c := range file.Contexts {
+		if c.Name == contextName {
+			clusterName, userName = c.Context.Cluster, c.Context.User
+			config.Namespace = c.Context.Namespace
+			found = true
+			break
+		}
+	}
+	if !found {
+		return Config{}, fmt.Errorf("kubeconfig has no context %q", contextName)
+	}
+
+	found = false
+	for _, c := range file.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		if c.Cluster.CertificateAuthority != "" {
+			return Config{}, fmt.Errorf("kubeconfig cluster %q references a CA file; embed certificate-authority-data instead", clusterName)
+		}
+		ca, err := decodeData("certificate-authority-data", c.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return Config{}, err
+		}
+		config.Server = c.Cluster.Server
+		config.CACert = ca
+		config.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+		found = true
+		break
+	}
+	if !found {
+		return Config{}, fmt.Errorf("kubeconfig has no cluster %q", clusterName)
+	}
+
+	found = false
+	for _, u := range file.Users {
+		if u.Name != userName {
+			continue
+		}
+		switch {
+		case u.User.Exec != nil:
+			return Config{}, fmt.Errorf("kubeconfig user %q uses an exec plugin, which is not supported", userName)
+		case u.User.AuthProvider != nil:
+			return Config{}, fmt.Errorf("kubeconfig user %q uses an auth provider, which is not supported", userName)
+		case u.User.TokenFile != "" || u.User.ClientCertificate != "" || u.User.ClientKey != "":
+			return Config{}, fmt.Errorf("kubeconfig user %q references files; embed the token or certificate data instead", userName)
+		}
+		cert, err := decodeData("client-certificate-data", u.User.ClientCertificateData)
+		if err != nil {
+			return Config{}, err
+		}
+		key, err := decodeData("client-key-data", u.User.ClientKeyData)
+		if err != nil {
+			return Config{}, err
+		}
+		config.Token = u.User.Token
+		config.ClientCert = cert
+		config.ClientKey = key
+		found = true
+		break
+	}
+	if !found {
+		return Config{}, fmt.Errorf("kubeconfig has no user %q", userName)
+	}
+
+	return config, nil
+}
+
+// decodeData decodes a base64 *-data field; empty is allowed
+func decodeData(field, value string) ([]byte, error) {
+	if value == "" {
+		return nil, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig %s: %w", field, err)
+	}
+	return data, nil
+}
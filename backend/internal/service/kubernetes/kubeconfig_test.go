@@ -0,0 +1,92 @@
+package kubernetes_test
+
+import (
+	"strings"
+	"testing"
+
+	"app-env-manager/internal/service/kubernetes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod-cluster
+  cluster:
+    server: https://k8s.example.com:6443
+    certificate-authority-data: Q0EgUEVN
+- name: staging-cluster
+  cluster:
+    server: https://staging.example.com:6443
+    insecure-skip-tls-verify: true
+users:
+- name: deployer
+  user:
+    token: prod-token
+- name: staging-user
+  user:
+    client-certificate-data: Q0VSVA==
+    client-key-data: S0VZ
+contexts:
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: deployer
+    namespace: shop
+- name: staging
+  context:
+    cluster: staging-cluster
+    user: staging-user
+`
+
+func TestParseKubeconfig_CurrentContext(t *testing.T) {
+	config, err := kubernetes.ParseKubeconfig([]byte(testKubeconfig), "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://k8s.example.com:6443", config.Server)
+	assert.Equal(t, []byte("CA PEM"), config.CACert)
+	assert.Equal(t, "prod-token", config.Token)
+	assert.Equal(t, "shop", config.Namespace)
+	assert.False(t, config.InsecureSkipVerify)
+}
+
+func TestParseKubeconfig_NamedContext(t *testing.T) {
+	config, err := kubernetes.ParseKubeconfig([]byte(testKubeconfig), "staging")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://staging.example.com:6443", config.Server)
+	assert.True(t, config.InsecureSkipVerify)
+	assert.Equal(t, []byte("CERT"), config.ClientCert)
+	assert.Equal(t, []byte("KEY"), config.ClientKey)
+	assert.Empty(t, config.Token)
+	assert.Empty(t, config.Namespace)
+}
+
+func TestParseKubeconfig_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		kubeconfig string
+		context    string
+		want       string
+	}{
+		{"not yaml", "clusters: [", "", "invalid kubeconfig"},
+		{"no current context", "clusters: []", "", "no current context"},
+		{"unknown context", testKubeconfig, "dev", `no context "dev"`},
+		{"unknown cluster", strings.Replace(testKubeconfig, "cluster: prod-cluster", "cluster: other", 1), "", `no cluster "other"`},
+		{"unknown user", strings.Replace(testKubeconfig, "user: deployer", "user: other", 1), "", `no user "other"`},
+		{"exec plugin", strings.Replace(testKubeconfig, "token: prod-token", "exec:\n      command: aws", 1), "", "exec plugin"},
+		{"auth provider", strings.Replace(testKubeconfig, "token: prod-token", "auth-provider:\n      name: gcp", 1), "", "auth provider"},
+		{"token file", strings.Replace(testKubeconfig, "token: prod-token", "tokenFile: /var/run/token", 1), "", "references files"},
+		{"CA file", strings.Replace(testKubeconfig, "certificate-authority-data: Q0EgUEVN", "certificate-authority: /etc/ca.crt", 1), "", "references a CA file"},
+		{"bad base64", strings.Replace(testKubeconfig, "Q0EgUEVN", "not-base64!", 1), "", "invalid kubeconfig certificate-authority-data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := kubernetes.ParseKubeconfig([]byte(tt.kubeconfig), tt.context)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,346 @@
+// Package kubetest provides a fake Kubernetes API server for tests. It keeps
+// Deployments and StatefulSets in memory, applies the strategic merge
+// patches the kubernetes package sends and simulates their rollouts.
+package kubetest
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Token is the bearer token the server accepts
+const Token = "test-token"
+
+// Workload is a fake workload's state
+type Workload struct {
+	Kind        string
+	Namespace   string
+	Name        string
+	Replicas    int32
+	Generation  int64
+	Images      map[string]string // container -> image
+	Annotations map[string]string // pod template annotations
+	Patches     int
+
+	order    []string // container order
+	observed int64    // generation the controller has seen
+	updated  int32    // replicas running the current template
+	pending  int      // reads left until the rollout advances
+}
+
+// Server is a running fake API server
+type Server struct {
+	tls *httptest.Server
+
+	mu           sync.Mutex
+	workloads    map[string]*Workload // by kind/namespace/name
+	rolloutReads int
+	failRollout  map[string]bool
+	requests     []string
+}
+
+// NewServer starts a fake API server on a TLS port; it is stopped when the
+// test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		workloads:   make(map[string]*Workload),
+		failRollout: make(map[string]bool),
+	}
+	s.tls = httptest.NewTLSServer(s.handler())
+	t.Cleanup(s.tls.Close)
+	return s
+}
+
+// URL returns the server's https URL
+func (s *Server) URL() string {
+	return s.tls.URL
+}
+
+// CACert returns the PEM encoded certificate clients must trust
+func (s *Server) CACert() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.tls.Certificate().Raw})
+}
+
+// AddDeployment adds a fully rolled out Deployment; containers alternate
+// name and image
+func (s *Server) AddDeployment(namespace, name string, replicas int32, containers ...string) {
+	s.add("Deployment", namespace, name, replicas, containers)
+}
+
+// AddStatefulSet adds a fully rolled out StatefulSet; containers alternate
+// name and image
+func (s *Server) AddStatefulSet(namespace, name string, replicas int32, containers ...string) {
+	s.add("StatefulSet", namespace, name, replicas, containers)
+}
+
+func (s *Server) add(kind, namespace, name string, replicas int32, containers []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w := &Workload{
+		Kind:        kind,
+		Namespace:   namespace,
+		Name:        name,
+		Replicas:    replicas,
+		Generation:  1,
+		Images:      make(map[string]string),
+		Annotations: make(map[string]string),
+		observed:    1,
+		updated:     replicas,
+	}
+	for i := 0; i+1 < len(containers); i += 2 {
+		w.order = append(w.order, containers[i])
+		w.Images[containers[i]] = containers[i+1]
+	}
+	s.workloads[key(kind, namespace, name)] = w
+}
+
+// SetRolloutReads makes rollouts take reads workload reads to complete, one
+// replica at a time; the default of zero completes them immediately
+func (s *Server) SetRolloutReads(reads int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rolloutReads = reads
+}
+
+// FailRollout makes the workload's rollouts exceed their progress deadline
+func (s *Server) FailRollout(kind, namespace, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failRollout[key(kind, namespace, name)] = true
+}
+
+// Workload returns a copy of a workload's state
+func (s *Server) Workload(kind, namespace, name string) (Workload, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.workloads[key(kind, namespace, name)]
+	if !ok {
+		return Workload{}, false
+	}
+	snapshot := *w
+	snapshot.Images = make(map[string]string, len(w.Images))
+	for k, v := range w.Images {
+		snapshot.Images[k] = v
+	}
+	snapshot.Annotations = make(map[string]string, len(w.Annotations))
+	for k, v := range w.Annotations {
+		snapshot.Annotations[k] = v
+	}
+	return snapshot, true
+}
+
+// Requests returns the requests received so far as "METHOD /path"
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /apis/apps/v1/namespaces/{namespace}/{resource}/{name}", s.get)
+	mux.HandleFunc("PATCH /apis/apps/v1/namespaces/{namespace}/{resource}/{name}", s.patch)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer "+Token {
+			writeStatus(w, http.StatusUnauthorized, "Unauthorized", "Unauthorized")
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	workload, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	s.advance(workload)
+	writeJSON(w, http.StatusOK, s.object(workload))
+}
+
+func (s *Server) patch(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-Type") != "application/strategic-merge-patch+json" {
+		writeStatus(w, http.StatusUnsupportedMediaType, "UnsupportedMediaType", "unsupported patch type")
+		return
+	}
+	var patch struct {
+		Spec struct {
+			Template struct {
+				Metadata struct {
+					Annotations map[string]string `json:"annotations"`
+				} `json:"metadata"`
+				Spec struct {
+					Containers []struct {
+						Name  string `json:"name"`
+						Image string `json:"image"`
+					} `json:"containers"`
+				} `json:"spec"`
+			} `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeStatus(w, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	workload, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	for _, c := range patch.Spec.Template.Spec.Containers {
+		if _, ok := workload.Images[c.Name]; !ok {
+			writeStatus(w, http.StatusUnprocessableEntity, "Invalid",
+				"spec.template.spec.containers: container "+c.Name+" does not exist")
+			return
+		}
+	}
+
+	changed := false
+	for name, value := range patch.Spec.Template.Metadata.Annotations {
+		if workload.Annotations[name] != value {
+			workload.Annotations[name] = value
+			changed = true
+		}
+	}
+	for _, c := range patch.Spec.Template.Spec.Containers {
+		if c.Image != "" && workload.Images[c.Name] != c.Image {
+			workload.Images[c.Name] = c.Image
+			changed = true
+		}
+	}
+	workload.Patches++
+	if changed {
+		// A new template starts a rollout the controller picks up on the next read
+		workload.Generation++
+		workload.updated = 0
+		workload.pending = s.rolloutReads
+	}
+	writeJSON(w, http.StatusOK, s.object(workload))
+}
+
+// lookup finds the workload a request addresses or writes a 404; the caller
+// holds mu
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*Workload, bool) {
+	var kind string
+	switch r.PathValue("resource") {
+	case "deployments":
+		kind = "Deployment"
+	case "statefulsets":
+		kind = "StatefulSet"
+	default:
+		writeStatus(w, http.StatusNotFound, "NotFound", "the server could not find the requested resource")
+		return nil, false
+	}
+	workload, ok := s.workloads[key(kind, r.PathValue("namespace"), r.PathValue("name"))]
+	if !ok {
+		writeStatus(w, http.StatusNotFound, "NotFound",
+			r.PathValue("resource")+".apps \""+r.PathValue("name")+"\" not found")
+		return nil, false
+	}
+	return workload, true
+}
+
+// advance moves a rollout along by one read; the caller holds mu
+func (s *Server) advance(w *Workload) {
+	w.observed = w.Generation
+	if w.updated >= w.Replicas || s.failRollout[key(w.Kind, w.Namespace, w.Name)] {
+		return
+	}
+	if w.pending > 0 {
+		w.pending--
+		if w.updated < w.Replicas-1 {
+			w.updated++
+		}
+		return
+	}
+	w.updated = w.Replicas
+}
+
+// object renders a workload as the API does; the caller holds mu
+func (s *Server) object(w *Workload) map[string]interface{} {
+	var containers []map[string]string
+	for _, name := range w.order {
+		containers = append(containers, map[string]string{"name": name, "image": w.Images[name]})
+	}
+	spec := map[string]interface{}{
+		"replicas": w.Replicas,
+		"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"annotations": w.Annotations},
+			"spec":     map[string]interface{}{"containers": containers},
+		},
+	}
+
+	revision := "rev-" + strconv.FormatInt(w.Generation, 10)
+	current := revision
+	if w.updated < w.Replicas {
+		current = "rev-" + strconv.FormatInt(w.Generation-1, 10)
+	}
+	status := map[string]interface{}{
+		"observedGeneration": w.observed,
+		"replicas":           w.Replicas,
+		"updatedReplicas":    w.updated,
+		"readyReplicas":      w.Replicas,
+		"availableReplicas":  w.updated,
+	}
+	if w.Kind == "StatefulSet" {
+		spec["updateStrategy"] = map[string]interface{}{"type": "RollingUpdate"}
+		status["readyReplicas"] = w.updated
+		status["currentRevision"] = current
+		status["updateRevision"] = revision
+	} else if s.failRollout[key(w.Kind, w.Namespace, w.Name)] && w.updated < w.Replicas {
+		status["conditions"] = []map[string]string{{
+			"type":    "Progressing",
+			"status":  "False",
+			"reason":  "ProgressDeadlineExceeded",
+			"message": "ReplicaSet \"" + w.Name + "\" has timed out progressing.",
+		}}
+	}
+
+	return map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       w.Kind,
+		"metadata": map[string]interface{}{
+			"name":       w.Name,
+			"namespace":  w.Namespace,
+			"generation": w.Generation,
+		},
+		"spec":   spec,
+		"status": status,
+	}
+}
+
+func key(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeStatus writes a Status object like the API server's errors
+func writeStatus(w http.ResponseWriter, code int, reason, message string) {
+	writeJSON(w, code, map[string]interface{}{
+		"kind":       "Status",
+		"apiVersion": "v1",
+		"status":     "Failure",
+		"message":    message,
+		"reason":     reason,
+		"code":       code,
+	})
+}
@@ -77,7 +77,7 @@ func (s *Service) LogHealthCheck(ctx context.Context, env *entities.Environment,
 	level := entities.LogLevelSuccess
 	if status == entities.HealthStatusUnhealthy {
 		level = entities.LogLevelError
-	} else if status == entities.HealthStatusUnknown {
+	} else if status == entities.HealthStatusUnknown || status == entities.HealthStatusDegraded {
 		level = entities.LogLevelWarning
 	}
 	
@@ -88,6 +88,21 @@ func (s *Service) LogHealthCheck(ctx context.Context, env *entities.Environment,
 	return s.Create(ctx, log)
 }
 
+// LogHealthFlapping logs an environment starting to flap, whose health
+// changes are not logged until it is stable, or becoming stable again
+func (s *Service) LogHealthFlapping(ctx context.Context, env *entities.Environment, flapping bool, message string, details map[string]interface{}) error {
+	level := entities.LogLevelInfo
+	if flapping {
+		level = entities.LogLevelWarning
+	}
+
+	log := entities.NewLog(entities.LogTypeHealthCheck, level, message).
+		WithEnvironment(env.ID, env.Name).
+		WithDetails(details)
+
+	return s.Create(ctx, log)
+}
+
 // LogAuth logs authentication-related events
 func (s *Service) LogAuth(ctx context.Context, userID *primitive.ObjectID, username string, action entities.ActionType, message string, success bool) error {
 	level := entities.LogLevelInfo
@@ -237,6 +237,51 @@ func TestService_LogHealthCheck_Failed(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestService_LogHealthCheck_Levels(t *testing.T) {
+	tests := []struct {
+		status entities.HealthStatus
+		level  entities.LogLevel
+	}{
+		{entities.HealthStatusHealthy, entities.LogLevelSuccess},
+		{entities.HealthStatusDegraded, entities.LogLevelWarning},
+		{entities.HealthStatusUnknown, entities.LogLevelWarning},
+		{entities.HealthStatusUnhealthy, entities.LogLevelError},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			mockRepo := new(MockLogRepository)
+			service := log.NewService(mockRepo)
+			env := &entities.Environment{ID: primitive.NewObjectID(), Name: "Test Environment"}
+			mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *entities.Log) bool {
+				return entry.Level == tt.level
+			})).Return(nil)
+
+			err := service.LogHealthCheck(context.Background(), env, tt.status, "Health changed", nil)
+			assert.NoError(t, err)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestService_LogHealthFlapping(t *testing.T) {
+	for _, flapping := range []bool{true, false} {
+		level := entities.LogLevelInfo
+		if flapping {
+			level = entities.LogLevelWarning
+		}
+		mockRepo := new(MockLogRepository)
+		service := log.NewService(mockRepo)
+		env := &entities.Environment{ID: primitive.NewObjectID(), Name: "Test Environment"}
+		mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(entry *entities.Log) bool {
+			return entry.Level == level && entry.Type == entities.LogTypeHealthCheck
+		})).Return(nil)
+
+		err := service.LogHealthFlapping(context.Background(), env, flapping, "Health is flapping", nil)
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	}
+}
+
 func TestService_GetEnvironmentLogs(t *testing.T) {
 	mockRepo := new(MockLogRepository)
 	service := log.NewService(mockRepo)
@@ -0,0 +1,416 @@
+// Package remotelog reads and follows log sources on environment hosts over
+// SSH.
+package remotelog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/ssh"
+)
+
+// Reasons a follow stream ends
+const (
+	ReasonClientClosed = "client_closed"
+	ReasonStreamEnded  = "stream_ended"
+	ReasonMaxDuration  = "max_duration"
+	ReasonShutdown     = "shutdown"
+)
+
+// Message types sent to followers
+const (
+	MessageLines   = "lines"
+	MessageDropped = "dropped"
+	MessageClosed  = "closed"
+)
+
+// Defaults for unset configuration
+const (
+	DefaultLines             = 100
+	DefaultMaxLines          = 5000
+	DefaultMaxTailsPerHost   = 3
+	DefaultLinesPerSecond    = 200
+	DefaultMaxFollowDuration = time.Hour
+)
+
+// flushInterval batches followed lines into fewer messages
+const flushInterval = 200 * time.Millisecond
+
+// maxLineLength bounds a single followed line
+const maxLineLength = 64 * 1024
+
+var (
+	filePathPattern = regexp.MustCompile(`^/[A-Za-z0-9._/@+-]+$`)
+	unitPattern     = regexp.MustCompile(`^[A-Za-z0-9@._:-]+$`)
+)
+
+// Config contains remote log settings
+type Config struct {
+	DefaultLines      int           // Lines returned when the request does not ask for a number
+	MaxLines          int           // Upper bound on requested lines
+	MaxTailsPerHost   int           // Concurrent reads and follows per host
+	LinesPerSecond    int           // Followed lines beyond this rate are dropped
+	MaxFollowDuration time.Duration // Follow streams are closed after this long
+}
+
+// TargetResolver builds the SSH target for an environment's credentials
+type TargetResolver interface {
+	SSHTarget(env *entities.Environment) (*ssh.Target, error)
+}
+
+// Snapshot is the end of a log source
+type Snapshot struct {
+	Source string   `json:"source"`
+	Lines  []string `json:"lines"`
+}
+
+// Message is sent to followers
+type Message struct {
+	Type    string   `json:"type"` // "lines", "dropped" or "closed"
+	Lines   []string `json:"lines,omitempty"`
+	Dropped int      `json:"dropped,omitempty"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+// Client is the browser side of a follow stream
+type Client interface {
+	// Send delivers a message to the browser
+	Send(message Message) error
+	// Wait blocks until the browser goes away
+	Wait() error
+}
+
+// Service reads remote logs
+type Service struct {
+	envRepo    interfaces.EnvironmentRepository
+	sshManager *ssh.Manager
+	targets    TargetResolver
+	config     Config
+
+	mu     sync.Mutex
+	active map[string]int // running tails per host
+}
+
+// NewService creates a new remote log service
+func NewService(
+	envRepo interfaces.EnvironmentRepository,
+	sshManager *ssh.Manager,
+	targets TargetResolver,
+	config Config,
+) *Service {
+	if config.DefaultLines <= 0 {
+		config.DefaultLines = DefaultLines
+	}
+	if config.MaxLines <= 0 {
+		config.MaxLines = DefaultMaxLines
+	}
+	if config.MaxTailsPerHost <= 0 {
+		config.MaxTailsPerHost = DefaultMaxTailsPerHost
+	}
+	if config.LinesPerSecond <= 0 {
+		config.LinesPerSecond = DefaultLinesPerSecond
+	}
+	if config.MaxFollowDuration <= 0 {
+		config.MaxFollowDuration = DefaultMaxFollowDuration
+	}
+	return &Service{
+		envRepo:    envRepo,
+		sshManager: sshManager,
+		targets:    targets,
+		config:     config,
+		active:     make(map[string]int),
+	}
+}
+
+// Tail returns the last lines of a log source. Zero lines means the default.
+func (s *Service) Tail(ctx context.Context, envID, sourceName string, lines int) (*Snapshot, error) {
+	lines, err := s.lineCount(lines)
+	if err != nil {
+		return nil, err
+	}
+	source, target, rules, err := s.resolve(ctx, envID, sourceName)
+	if err != nil {
+		return nil, err
+	}
+	command, err := tailCommand(source, lines, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(command, rules); err != nil {
+		return nil, err
+	}
+
+	release, err := s.acquire(target)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	result, err := s.sshManager.Execute(ctx, *target, command)
+	if err != nil {
+		return nil, connectionFailed(err)
+	}
+	if result.ExitCode != 0 {
+		return nil, errors.DomainError{
+			Code:    errors.ErrOperationFailed.Code,
+			Message: errors.ErrOperationFailed.Message,
+			Details: map[string]interface{}{"exitCode": result.ExitCode, "output": result.Output},
+		}
+	}
+
+	snapshot := &Snapshot{Source: source.Name, Lines: []string{}}
+	if output := strings.TrimRight(result.Output, "\n"); output != "" {
+		snapshot.Lines = strings.Split(output, "\n")
+	}
+	return snapshot, nil
+}
+
+// Stream is an open follow stream. It holds one of its host's tail slots
+// until it is served or closed.
+type Stream struct {
+	service *Service
+	target  *ssh.Target
+	command string
+	release func()
+	once    sync.Once
+}
+
+// OpenFollow checks the source and reserves a tail slot on its host.
+// The stream starts with the last lines of the source, zero meaning the
+// default.
+func (s *Service) OpenFollow(ctx context.Context, envID, sourceName string, lines int) (*Stream, error) {
+	lines, err := s.lineCount(lines)
+	if err != nil {
+		return nil, err
+	}
+	source, target, rules, err := s.resolve(ctx, envID, sourceName)
+	if err != nil {
+		return nil, err
+	}
+	command, err := tailCommand(source, lines, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(command, rules); err != nil {
+		return nil, err
+	}
+	release, err := s.acquire(target)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{service: s, target: target, command: command, release: release}, nil
+}
+
+// Close releases the stream's tail slot if it was never served
+func (st *Stream) Close() {
+	st.once.Do(st.release)
+}
+
+// Serve sends new lines to the client until the browser goes away, the
+// remote command ends or the stream reaches its maximum duration. Lines
+// beyond the configured rate are dropped and counted.
+func (st *Stream) Serve(ctx context.Context, client Client) string {
+	defer st.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, st.service.config.MaxFollowDuration)
+	defer cancel()
+
+	clientGone := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(clientGone)
+		cancel()
+	}()
+
+	reader, writer := io.Pipe()
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		err := st.service.sshManager.Stream(ctx, *st.target, st.command, writer)
+		writer.CloseWithError(err)
+	}()
+
+	lines := make(chan string, 256)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 4096), maxLineLength)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		// Unblock the writer if the scanner gave up early
+		reader.Close()
+	}()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []string
+	dropped := 0
+	windowStart := time.Now()
+	sent := 0
+	sendFailed := false
+	flush := func() {
+		if sendFailed {
+			return
+		}
+		if len(batch) > 0 {
+			if client.Send(Message{Type: MessageLines, Lines: batch}) != nil {
+				sendFailed = true
+				return
+			}
+			batch = nil
+		}
+		if dropped > 0 {
+			if client.Send(Message{Type: MessageDropped, Dropped: dropped}) != nil {
+				sendFailed = true
+				return
+			}
+			dropped = 0
+		}
+	}
+
+	open := true
+	for open {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				open = false
+				break
+			}
+			if now := time.Now(); now.Sub(windowStart) >= time.Second {
+				windowStart, sent = now, 0
+			}
+			if sent >= st.service.config.LinesPerSecond {
+				dropped++
+				continue
+			}
+			sent++
+			batch = append(batch, line)
+		case <-ticker.C:
+			if flush(); sendFailed {
+				cancel()
+			}
+		}
+	}
+	flush()
+	<-streamDone
+
+	select {
+	case <-clientGone:
+		return ReasonClientClosed
+	default:
+	}
+	if sendFailed {
+		return ReasonClientClosed
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return ReasonMaxDuration
+	case context.Canceled:
+		return ReasonShutdown
+	}
+	return ReasonStreamEnded
+}
+
+// lineCount applies the default and bounds to a requested number of lines
+func (s *Service) lineCount(lines int) (int, error) {
+	if lines == 0 {
+		return s.config.DefaultLines, nil
+	}
+	if lines < 0 || lines > s.config.MaxLines {
+		return 0, errors.NewValidationError("lines", fmt.Sprintf("must be between 1 and %d", s.config.MaxLines))
+	}
+	return lines, nil
+}
+
+// resolve finds the environment's log source, SSH target and command
+// allowlist
+func (s *Service) resolve(ctx context.Context, envID, sourceName string) (entities.LogSource, *ssh.Target, []entities.CommandRule, error) {
+	env, err := s.envRepo.GetByID(ctx, envID)
+	if err != nil {
+		return entities.LogSource{}, nil, nil, err
+	}
+	source, ok := env.LogSource(sourceName)
+	if !ok {
+		return entities.LogSource{}, nil, nil, errors.ErrLogSourceNotFound
+	}
+	target, err := s.targets.SSHTarget(env)
+	if err != nil {
+		return entities.LogSource{}, nil, nil, errors.NewValidationError("credentials", err.Error())
+	}
+	return source, target, env.Commands.Allowlist, nil
+}
+
+// authorize checks a log command against the environment's allowlist and the
+// global one, like any other SSH command
+func (s *Service) authorize(command string, rules []entities.CommandRule) error {
+	if _, err := s.sshManager.Authorize(command, rules); err != nil {
+		return errors.NewValidationError("command", err.Error())
+	}
+	return nil
+}
+
+// acquire takes one of the host's tail slots
+func (s *Service) acquire(target *ssh.Target) (func(), error) {
+	host := fmt.Sprintf("%s:%d", target.Host, target.Port)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[host] >= s.config.MaxTailsPerHost {
+		return nil, errors.ErrTailLimitReached
+	}
+	s.active[host]++
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.active[host]--; s.active[host] <= 0 {
+			delete(s.active, host)
+		}
+	}, nil
+}
+
+// tailCommand builds the command that reads a source. Paths and units are
+// restricted to characters that need no quoting.
+func tailCommand(source entities.LogSource, lines int, follow bool) (string, error) {
+	switch source.Type {
+	case entities.LogSourceFile:
+		if !filePathPattern.MatchString(source.Path) || strings.Contains(source.Path, "..") {
+			return "", errors.NewValidationError("path", fmt.Sprintf("log source %s has an invalid file path", source.Name))
+		}
+		if follow {
+			return fmt.Sprintf("tail -n %d -F -- %s", lines, source.Path), nil
+		}
+		return fmt.Sprintf("tail -n %d -- %s", lines, source.Path), nil
+	case entities.LogSourceJournal:
+		if !unitPattern.MatchString(source.Unit) {
+			return "", errors.NewValidationError("unit", fmt.Sprintf("log source %s has an invalid unit", source.Name))
+		}
+		command := fmt.Sprintf("journalctl -u %s -n %d --no-pager -o short-iso", source.Unit, lines)
+		if follow {
+			command += " -f"
+		}
+		return command, nil
+	default:
+		return "", errors.NewValidationError("type", fmt.Sprintf("log source %s has unknown type %q", source.Name, source.Type))
+	}
+}
+
+// connectionFailed wraps an SSH error for the API
+func connectionFailed(err error) error {
+	return errors.DomainError{
+		Code:    errors.ErrSSHConnectionFailed.Code,
+		Message: errors.ErrSSHConnectionFailed.Message,
+		Details: map[string]interface{}{"error": err.Error()},
+	}
+}
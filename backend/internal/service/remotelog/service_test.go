@@ -0,0 +1,414 @@
+package remotelog_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/remotelog"
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockEnvRepo struct{ mock.Mock }
+
+func (m *mockEnvRepo) Create(ctx context.Context, env *entities.Environment) error {
+	return m.Called(ctx, env).Error(0)
+}
+
+func (m *mockEnvRepo) GetByID(ctx context.Context, id string) (*entities.Environment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) GetByName(ctx context.Context, name string) (*entities.Environment, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) List(ctx context.Context, filter interfaces.ListFilter) ([]*entities.Environment, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) Update(ctx context.Context, id string, env *entities.Environment) error {
+	return m.Called(ctx, id, env).Error(0)
+}
+
+func (m *mockEnvRepo) UpdateStatus(ctx context.Context, id string, status entities.Status) error {
+	return m.Called(ctx, id, status).Error(0)
+}
+
+func (m *mockEnvRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockEnvRepo) Count(ctx context.Context, filter interfaces.ListFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// targetFunc resolves every environment to a fixed target
+type targetFunc func(env *entities.Environment) (*ssh.Target, error)
+
+func (f targetFunc) SSHTarget(env *entities.Environment) (*ssh.Target, error) {
+	return f(env)
+}
+
+// fakeClient collects messages until it is closed
+type fakeClient struct {
+	closed chan struct{}
+	once   sync.Once
+
+	mu       sync.Mutex
+	messages []remotelog.Message
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{closed: make(chan struct{})}
+}
+
+func (c *fakeClient) Send(message remotelog.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, message)
+	return nil
+}
+
+func (c *fakeClient) Wait() error {
+	<-c.closed
+	return nil
+}
+
+func (c *fakeClient) Close() {
+	c.once.Do(func() { close(c.closed) })
+}
+
+// Lines returns every followed line received so far
+func (c *fakeClient) Lines() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var lines []string
+	for _, message := range c.messages {
+		lines = append(lines, message.Lines...)
+	}
+	return lines
+}
+
+// Dropped returns the number of dropped lines reported so far
+func (c *fakeClient) Dropped() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	dropped := 0
+	for _, message := range c.messages {
+		if message.Type == remotelog.MessageDropped {
+			dropped += message.Dropped
+		}
+	}
+	return dropped
+}
+
+type remoteLogSetup struct {
+	server  *sshtest.Server
+	service *remotelog.Service
+	env     *entities.Environment
+	logFile string
+}
+
+func newRemoteLogSetup(t *testing.T, config remotelog.Config) *remoteLogSetup {
+	t.Helper()
+	server := sshtest.NewServer(t)
+	manager := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: 5 * time.Second,
+		CommandTimeout:    10 * time.Second,
+		MaxConnections:    10,
+	})
+	t.Cleanup(func() { manager.Close() })
+
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("one\ntwo\nthree\n"), 0o644))
+
+	env := &entities.Environment{
+		ID:   primitive.NewObjectID(),
+		Name: "staging",
+		LogSources: []entities.LogSource{
+			{Name: "app", Type: entities.LogSourceFile, Path: logFile},
+			{Name: "nginx", Type: entities.LogSourceJournal, Unit: "nginx.service"},
+			{Name: "bad", Type: entities.LogSourceFile, Path: "/var/log/../../etc/shadow"},
+		},
+	}
+	envRepo := new(mockEnvRepo)
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil).Maybe()
+	envRepo.On("GetByID", mock.Anything, mock.Anything).Return(nil, errors.ErrEnvironmentNotFound).Maybe()
+
+	targets := targetFunc(func(env *entities.Environment) (*ssh.Target, error) {
+		target := server.Target()
+		return &target, nil
+	})
+
+	return &remoteLogSetup{
+		server:  server,
+		service: remotelog.NewService(envRepo, manager, targets, config),
+		env:     env,
+		logFile: logFile,
+	}
+}
+
+func (s *remoteLogSetup) appendLines(t *testing.T, lines ...string) {
+	t.Helper()
+	f, err := os.OpenFile(s.logFile, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(strings.Join(lines, "\n") + "\n")
+	require.NoError(t, err)
+}
+
+// serve follows the stream in the background and returns its end reason
+func serve(stream *remotelog.Stream, client *fakeClient) <-chan string {
+	done := make(chan string, 1)
+	go func() {
+		done <- stream.Serve(context.Background(), client)
+	}()
+	return done
+}
+
+func waitReason(t *testing.T, done <-chan string) string {
+	t.Helper()
+	select {
+	case reason := <-done:
+		return reason
+	case <-time.After(10 * time.Second):
+		t.Fatal("stream did not end")
+		return ""
+	}
+}
+
+func errorCode(err error) string {
+	if domainErr, ok := err.(errors.DomainError); ok {
+		return domainErr.Code
+	}
+	return ""
+}
+
+func TestService_Tail_File(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{})
+
+	snapshot, err := s.service.Tail(context.Background(), s.env.ID.Hex(), "app", 2)
+	require.NoError(t, err)
+	assert.Equal(t, "app", snapshot.Source)
+	assert.Equal(t, []string{"two", "three"}, snapshot.Lines)
+	assert.Contains(t, s.server.Commands(), "tail -n 2 -- "+s.logFile)
+}
+
+func TestService_Tail_DefaultLines(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{DefaultLines: 1})
+
+	snapshot, err := s.service.Tail(context.Background(), s.env.ID.Hex(), "app", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"three"}, snapshot.Lines)
+}
+
+func TestService_Tail_EmptyFile(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{})
+	require.NoError(t, os.WriteFile(s.logFile, nil, 0o644))
+
+	snapshot, err := s.service.Tail(context.Background(), s.env.ID.Hex(), "app", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, snapshot.Lines)
+}
+
+// fakeJournal puts a journalctl on the PATH of commands run by the test
+// server that prints its arguments and exits
+func fakeJournal(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"journal $*\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "journalctl"), []byte(script), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestService_Tail_Journal(t *testing.T) {
+	fakeJournal(t)
+	s := newRemoteLogSetup(t, remotelog.Config{})
+
+	snapshot, err := s.service.Tail(context.Background(), s.env.ID.Hex(), "nginx", 50)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"journal -u nginx.service -n 50 --no-pager -o short-iso"}, snapshot.Lines)
+}
+
+func TestService_Tail_CommandFails(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{})
+	require.NoError(t, os.Remove(s.logFile))
+
+	_, err := s.service.Tail(context.Background(), s.env.ID.Hex(), "app", 10)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrOperationFailed.Code, errorCode(err))
+	assert.NotZero(t, err.(errors.DomainError).Details["exitCode"])
+}
+
+func TestService_Tail_Errors(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{MaxLines: 100})
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		envID  string
+		source string
+		lines  int
+		code   string
+	}{
+		{"too many lines", s.env.ID.Hex(), "app", 101, "VALIDATION_ERROR"},
+		{"negative lines", s.env.ID.Hex(), "app", -1, "VALIDATION_ERROR"},
+		{"unknown source", s.env.ID.Hex(), "missing", 10, "LOG_SOURCE_NOT_FOUND"},
+		{"unknown environment", primitive.NewObjectID().Hex(), "app", 10, "ENV_NOT_FOUND"},
+		{"unsafe path", s.env.ID.Hex(), "bad", 10, "VALIDATION_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.service.Tail(ctx, tt.envID, tt.source, tt.lines)
+			require.Error(t, err)
+			assert.Equal(t, tt.code, errorCode(err))
+		})
+	}
+	assert.Empty(t, s.server.Commands(), "nothing runs for rejected requests")
+}
+
+func TestService_Allowlist(t *testing.T) {
+	fakeJournal(t)
+	s := newRemoteLogSetup(t, remotelog.Config{})
+	s.env.Commands.Allowlist = []entities.CommandRule{
+		{Name: "journal", Type: entities.CommandRulePrefix, Pattern: "journalctl -u nginx.service "},
+	}
+	ctx := context.Background()
+
+	_, err := s.service.Tail(ctx, s.env.ID.Hex(), "app", 10)
+	require.Error(t, err)
+	assert.Equal(t, "VALIDATION_ERROR", errorCode(err))
+
+	_, err = s.service.OpenFollow(ctx, s.env.ID.Hex(), "app", 10)
+	require.Error(t, err)
+	assert.Equal(t, "VALIDATION_ERROR", errorCode(err))
+	assert.Empty(t, s.server.Commands(), "denied commands do not run")
+
+	snapshot, err := s.service.Tail(ctx, s.env.ID.Hex(), "nginx", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"journal -u nginx.service -n 10 --no-pager -o short-iso"}, snapshot.Lines)
+}
+
+func TestService_Follow(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{})
+
+	stream, err := s.service.OpenFollow(context.Background(), s.env.ID.Hex(), "app", 1)
+	require.NoError(t, err)
+	client := newFakeClient()
+	done := serve(stream, client)
+
+	assert.Eventually(t, func() bool {
+		return len(client.Lines()) == 1
+	}, 5*time.Second, 20*time.Millisecond)
+	s.appendLines(t, "four", "five")
+	assert.Eventually(t, func() bool {
+		return len(client.Lines()) == 3
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, []string{"three", "four", "five"}, client.Lines())
+	assert.Contains(t, s.server.Commands(), "tail -n 1 -F -- "+s.logFile)
+
+	client.Close()
+	assert.Equal(t, remotelog.ReasonClientClosed, waitReason(t, done))
+}
+
+func TestService_Follow_DropsLinesOverRate(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{LinesPerSecond: 5})
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	s.appendLines(t, lines...)
+
+	stream, err := s.service.OpenFollow(context.Background(), s.env.ID.Hex(), "app", 20)
+	require.NoError(t, err)
+	client := newFakeClient()
+	done := serve(stream, client)
+
+	assert.Eventually(t, func() bool {
+		return client.Dropped() == 15
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, lines[:5], client.Lines())
+
+	client.Close()
+	waitReason(t, done)
+}
+
+func TestService_Follow_MaxDuration(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{MaxFollowDuration: 300 * time.Millisecond})
+
+	stream, err := s.service.OpenFollow(context.Background(), s.env.ID.Hex(), "app", 1)
+	require.NoError(t, err)
+	client := newFakeClient()
+	defer client.Close()
+
+	assert.Equal(t, remotelog.ReasonMaxDuration, waitReason(t, serve(stream, client)))
+}
+
+func TestService_Follow_StreamEnded(t *testing.T) {
+	fakeJournal(t)
+	s := newRemoteLogSetup(t, remotelog.Config{})
+
+	stream, err := s.service.OpenFollow(context.Background(), s.env.ID.Hex(), "nginx", 5)
+	require.NoError(t, err)
+	client := newFakeClient()
+	defer client.Close()
+
+	assert.Equal(t, remotelog.ReasonStreamEnded, waitReason(t, serve(stream, client)))
+	assert.Equal(t, []string{"journal -u nginx.service -n 5 --no-pager -o short-iso -f"}, client.Lines())
+}
+
+func TestService_PerHostLimit(t *testing.T) {
+	s := newRemoteLogSetup(t, remotelog.Config{MaxTailsPerHost: 1})
+	ctx := context.Background()
+
+	stream, err := s.service.OpenFollow(ctx, s.env.ID.Hex(), "app", 1)
+	require.NoError(t, err)
+
+	_, err = s.service.Tail(ctx, s.env.ID.Hex(), "app", 1)
+	assert.Equal(t, errors.ErrTailLimitReached, err)
+	_, err = s.service.OpenFollow(ctx, s.env.ID.Hex(), "app", 1)
+	assert.Equal(t, errors.ErrTailLimitReached, err)
+
+	// Closing twice releases the slot once
+	stream.Close()
+	stream.Close()
+	_, err = s.service.Tail(ctx, s.env.ID.Hex(), "app", 1)
+	assert.NoError(t, err)
+
+	stream, err = s.service.OpenFollow(ctx, s.env.ID.Hex(), "app", 1)
+	require.NoError(t, err)
+	client := newFakeClient()
+	done := serve(stream, client)
+	client.Close()
+	waitReason(t, done)
+
+	_, err = s.service.Tail(ctx, s.env.ID.Hex(), "app", 1)
+	assert.NoError(t, err, "a served stream releases its slot")
+}
@@ -0,0 +1,490 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/health"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Upgrader performs the per-environment steps of a rollout
+type Upgrader interface {
+	UpgradeEnvironment(ctx context.Context, id string, version string) error
+	RollbackEnvironment(ctx context.Context, id string, version string) error // not rate limited
+	ProbeHealth(ctx context.Context, id string) (*health.CheckResult, error)  // ignores health thresholds
+}
+
+// ProgressFunc is called every time the rollout state changes
+type ProgressFunc func(rollout *entities.Rollout)
+
+// Request describes a rollout. Exactly one of EnvironmentIDs or Selector must
+// be set; with Canary the first environment is upgraded alone first.
+type Request struct {
+	EnvironmentIDs    []string          `json:"environmentIds,omitempty"`
+	Selector          map[string]string `json:"selector,omitempty"`
+	Version           string            `json:"version"`
+	Canary            bool              `json:"canary"`
+	BatchSize         int               `json:"batchSize"`
+	SoakSeconds       int               `json:"soakSeconds"`
+	RollbackOnFailure bool              `json:"rollbackOnFailure"`
+}
+
+// control lets API calls steer a rollout running in this process
+type control struct {
+	mu      sync.Mutex
+	paused  bool
+	resume  chan struct{}
+	aborted bool
+	cancel  context.CancelFunc
+}
+
+// Service runs rolling and canary upgrades across groups of environments
+type Service struct {
+	repo          interfaces.RolloutRepository
+	envRepo       interfaces.EnvironmentRepository
+	upgrader      Upgrader
+	checkInterval time.Duration
+
+	mu       sync.Mutex
+	controls map[string]*control
+}
+
+// NewService creates a new rollout service. checkInterval is how often
+// health is re-checked while a batch soaks.
+func NewService(
+	repo interfaces.RolloutRepository,
+	envRepo interfaces.EnvironmentRepository,
+	upgrader Upgrader,
+	checkInterval time.Duration,
+) *Service {
+	if checkInterval <= 0 {
+		checkInterval = 10 * time.Second
+	}
+	return &Service{
+		repo:          repo,
+		envRepo:       envRepo,
+		upgrader:      upgrader,
+		checkInterval: checkInterval,
+		controls:      make(map[string]*control),
+	}
+}
+
+// Create validates the request, resolves the member environments and stores
+// a pending rollout. Call Run to execute it.
+func (s *Service) Create(ctx context.Context, req Request) (*entities.Rollout, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	userID, username := ctxutil.UserFromContext(ctx)
+	if userID == "" {
+		return nil, errors.ErrUnauthorized
+	}
+
+	envs, err := s.resolveMembers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(envs) == 0 {
+		return nil, errors.NewValidationError("selector", "no environments matched")
+	}
+
+	var protected, unchecked []string
+	for _, env := range envs {
+		if env.RequiresApproval {
+			protected = append(protected, env.Name)
+		}
+		// Their health is unknown, so they could never pass verification
+		if !env.HealthCheck.Enabled {
+			unchecked = append(unchecked, env.Name)
+		}
+	}
+	if len(protected) > 0 {
+		return nil, errors.NewValidationError("environmentIds",
+			fmt.Sprintf("environments requiring approval cannot be rolled out: %s", strings.Join(protected, ", ")))
+	}
+	if len(unchecked) > 0 {
+		return nil, errors.NewValidationError("environmentIds",
+			fmt.Sprintf("environments without a health check cannot be rolled out: %s", strings.Join(unchecked, ", ")))
+	}
+
+	rollout := &entities.Rollout{
+		ID:                primitive.NewObjectID(),
+		Version:           req.Version,
+		Selector:          req.Selector,
+		Canary:            req.Canary,
+		BatchSize:         req.BatchSize,
+		SoakSeconds:       req.SoakSeconds,
+		RollbackOnFailure: req.RollbackOnFailure,
+		Status:            entities.RolloutStatusPending,
+		CreatedBy:         entities.Actor{Type: "user", ID: userID, Name: username},
+		CreatedAt:         time.Now(),
+	}
+	if rollout.BatchSize <= 0 {
+		rollout.BatchSize = 1
+	}
+	for _, env := range envs {
+		rollout.Members = append(rollout.Members, entities.RolloutMember{
+			EnvironmentID:   env.ID,
+			EnvironmentName: env.Name,
+			PreviousVersion: env.SystemInfo.AppVersion,
+			Status:          entities.MemberStatusPending,
+		})
+	}
+	rollout.AssignBatches()
+
+	if err := s.repo.Create(ctx, rollout); err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
+
+// Run executes the rollout batch by batch. It halts on the first failed
+// upgrade or health verification and, when requested, rolls back the members
+// that had already been upgraded.
+func (s *Service) Run(ctx context.Context, rollout *entities.Rollout, progress ProgressFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	ctrl := &control{cancel: cancel}
+	id := rollout.ID.Hex()
+
+	s.mu.Lock()
+	s.controls[id] = ctrl
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.controls, id)
+		s.mu.Unlock()
+		cancel()
+	}()
+
+	var mu sync.Mutex
+	update := func(change func()) {
+		mu.Lock()
+		defer mu.Unlock()
+		change()
+		// Persist even after ctx is cancelled so the final state is recorded
+		saveCtx, cancelSave := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		_ = s.repo.Update(saveCtx, rollout)
+		cancelSave()
+		if progress != nil {
+			progress(rollout)
+		}
+	}
+
+	update(func() {
+		now := time.Now()
+		rollout.Status = entities.RolloutStatusRunning
+		rollout.StartedAt = &now
+	})
+
+	failed := false
+	for batch := 0; batch < rollout.TotalBatches && !failed; batch++ {
+		if !s.waitWhilePaused(ctx, ctrl, rollout, update) {
+			break
+		}
+
+		members := rollout.BatchMembers(batch)
+		update(func() {
+			rollout.CurrentBatch = batch
+			for _, i := range members {
+				rollout.Members[i].Status = entities.MemberStatusUpgrading
+			}
+		})
+
+		// Upgrade the batch in parallel
+		var wg sync.WaitGroup
+		for _, i := range members {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				err := s.upgrader.UpgradeEnvironment(context.WithoutCancel(ctx), rollout.Members[i].EnvironmentID.Hex(), rollout.Version)
+				update(func() {
+					member := &rollout.Members[i]
+					if err != nil {
+						member.Status = entities.MemberStatusFailed
+						member.Error = err.Error()
+						return
+					}
+					now := time.Now()
+					member.UpgradedAt = &now
+					member.Status = entities.MemberStatusVerifying
+				})
+			}(i)
+		}
+		wg.Wait()
+
+		for _, i := range members {
+			if rollout.Members[i].Status == entities.MemberStatusFailed {
+				failed = true
+			}
+		}
+		if failed {
+			update(func() {
+				rollout.Message = fmt.Sprintf("upgrade failed in batch %d", batch)
+			})
+			break
+		}
+
+		// Verify the batch stays healthy for the soak period
+		if err := s.verify(ctx, rollout, members); err != nil {
+			if ctx.Err() != nil {
+				// Aborted while soaking
+				break
+			}
+			failed = true
+			update(func() {
+				rollout.Message = fmt.Sprintf("health verification failed in batch %d: %s", batch, err.Error())
+				for _, i := range members {
+					if rollout.Members[i].Status == entities.MemberStatusVerifying {
+						rollout.Members[i].Status = entities.MemberStatusFailed
+						rollout.Members[i].Error = err.Error()
+					}
+				}
+			})
+			break
+		}
+
+		update(func() {
+			now := time.Now()
+			for _, i := range members {
+				rollout.Members[i].Status = entities.MemberStatusSucceeded
+				rollout.Members[i].VerifiedAt = &now
+			}
+		})
+	}
+
+	ctrl.mu.Lock()
+	aborted := ctrl.aborted
+	ctrl.mu.Unlock()
+
+	if failed && rollout.RollbackOnFailure {
+		s.rollback(ctx, rollout, update)
+	}
+
+	update(func() {
+		now := time.Now()
+		rollout.CompletedAt = &now
+		for i := range rollout.Members {
+			switch rollout.Members[i].Status {
+			case entities.MemberStatusPending:
+				rollout.Members[i].Status = entities.MemberStatusSkipped
+			case entities.MemberStatusVerifying:
+				// Upgraded, but the rollout halted before health was verified
+				rollout.Members[i].Status = entities.MemberStatusFailed
+				rollout.Members[i].Error = "rollout halted before verification"
+			}
+		}
+
+		switch {
+		case aborted:
+			rollout.Status = entities.RolloutStatusAborted
+			rollout.Message = "rollout aborted"
+		case failed && rollout.Status == entities.RolloutStatusRolledBack:
+			// Every upgraded member was restored; keep rolled_back
+		case failed:
+			rollout.Status = entities.RolloutStatusFailed
+		default:
+			rollout.Status = entities.RolloutStatusCompleted
+		}
+	})
+}
+
+// Pause stops the rollout before its next batch
+func (s *Service) Pause(ctx context.Context, id string) (*entities.Rollout, error) {
+	return s.steer(ctx, id, func(ctrl *control) {
+		if !ctrl.paused {
+			ctrl.paused = true
+			ctrl.resume = make(chan struct{})
+		}
+	})
+}
+
+// Resume continues a paused rollout
+func (s *Service) Resume(ctx context.Context, id string) (*entities.Rollout, error) {
+	return s.steer(ctx, id, func(ctrl *control) {
+		if ctrl.paused {
+			ctrl.paused = false
+			close(ctrl.resume)
+		}
+	})
+}
+
+// Abort stops the rollout; upgrades already in flight are allowed to finish
+func (s *Service) Abort(ctx context.Context, id string) (*entities.Rollout, error) {
+	return s.steer(ctx, id, func(ctrl *control) {
+		ctrl.aborted = true
+		ctrl.cancel()
+	})
+}
+
+// Get retrieves a rollout by ID
+func (s *Service) Get(ctx context.Context, id string) (*entities.Rollout, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List lists rollouts
+func (s *Service) List(ctx context.Context, filter interfaces.RolloutFilter) ([]*entities.Rollout, error) {
+	return s.repo.List(ctx, filter)
+}
+
+// steer applies a control change to a rollout running in this process
+func (s *Service) steer(ctx context.Context, id string, change func(ctrl *control)) (*entities.Rollout, error) {
+	s.mu.Lock()
+	ctrl, ok := s.controls[id]
+	s.mu.Unlock()
+	if !ok {
+		if _, err := s.repo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrRolloutNotActive
+	}
+
+	ctrl.mu.Lock()
+	change(ctrl)
+	ctrl.mu.Unlock()
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// waitWhilePaused blocks while the rollout is paused. It returns false when
+// the rollout was aborted.
+func (s *Service) waitWhilePaused(ctx context.Context, ctrl *control, rollout *entities.Rollout, update func(func())) bool {
+	for {
+		ctrl.mu.Lock()
+		paused, resume := ctrl.paused, ctrl.resume
+		ctrl.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return false
+		}
+		if !paused {
+			if rollout.Status == entities.RolloutStatusPaused {
+				update(func() { rollout.Status = entities.RolloutStatusRunning })
+			}
+			return true
+		}
+
+		if rollout.Status != entities.RolloutStatusPaused {
+			update(func() { rollout.Status = entities.RolloutStatusPaused })
+		}
+		select {
+		case <-resume:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// verify re-checks every member of a batch until the soak period has
+// elapsed, failing as soon as one is down. Each check's own result counts,
+// not the health the environment's thresholds let it keep, so a single
+// failed check fails the batch. Degraded members are up and pass.
+func (s *Service) verify(ctx context.Context, rollout *entities.Rollout, members []int) error {
+	deadline := time.Now().Add(time.Duration(rollout.SoakSeconds) * time.Second)
+	for {
+		for _, i := range members {
+			id := rollout.Members[i].EnvironmentID.Hex()
+			result, err := s.upgrader.ProbeHealth(ctx, id)
+			if err != nil {
+				return fmt.Errorf("%s: %w", rollout.Members[i].EnvironmentName, err)
+			}
+			if !result.Status.IsUp() {
+				return fmt.Errorf("%s is %s: %s", rollout.Members[i].EnvironmentName, result.Status, result.Message)
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil
+		}
+
+		select {
+		case <-time.After(s.checkInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// rollback returns every upgraded member to the version it ran before
+func (s *Service) rollback(ctx context.Context, rollout *entities.Rollout, update func(func())) {
+	update(func() { rollout.Status = entities.RolloutStatusRollingBack })
+
+	allRolledBack := true
+	for i := range rollout.Members {
+		member := rollout.Members[i]
+		if member.UpgradedAt == nil {
+			// The upgrade never applied, nothing to undo
+			continue
+		}
+
+		var err error
+		if member.PreviousVersion == "" {
+			err = fmt.Errorf("previous version unknown")
+		} else {
+			err = s.upgrader.RollbackEnvironment(context.WithoutCancel(ctx), member.EnvironmentID.Hex(), member.PreviousVersion)
+		}
+
+		update(func() {
+			if err != nil {
+				allRolledBack = false
+				rollout.Members[i].Status = entities.MemberStatusRollbackFailed
+				rollout.Members[i].Error = fmt.Sprintf("rollback failed: %v", err)
+				return
+			}
+			rollout.Members[i].Status = entities.MemberStatusRolledBack
+		})
+	}
+
+	if allRolledBack {
+		update(func() { rollout.Status = entities.RolloutStatusRolledBack })
+	}
+}
+
+// resolveMembers loads the rollout members, preserving the requested order
+func (s *Service) resolveMembers(ctx context.Context, req Request) ([]*entities.Environment, error) {
+	if len(req.Selector) > 0 {
+		return s.envRepo.List(ctx, interfaces.ListFilter{Labels: req.Selector})
+	}
+
+	seen := make(map[string]bool, len(req.EnvironmentIDs))
+	envs := make([]*entities.Environment, 0, len(req.EnvironmentIDs))
+	for _, id := range req.EnvironmentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		env, err := s.envRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		envs = append(envs, env)
+	}
+	return envs, nil
+}
+
+// validate checks the request shape before any environment is resolved
+func validate(req Request) error {
+	if (len(req.EnvironmentIDs) == 0) == (len(req.Selector) == 0) {
+		return errors.NewValidationError("environmentIds", "provide either environmentIds or selector")
+	}
+	if req.Version == "" {
+		return errors.NewValidationError("version", "version is required")
+	}
+	if req.BatchSize < 0 {
+		return errors.NewValidationError("batchSize", "batchSize must not be negative")
+	}
+	if req.SoakSeconds < 0 {
+		return errors.NewValidationError("soakSeconds", "soakSeconds must not be negative")
+	}
+	return nil
+}
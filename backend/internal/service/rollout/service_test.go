@@ -0,0 +1,485 @@
+package rollout_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/health"
+	"app-env-manager/internal/service/rollout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockRolloutRepo struct{ mock.Mock }
+
+func (m *mockRolloutRepo) Create(ctx context.Context, r *entities.Rollout) error {
+	return m.Called(ctx, r).Error(0)
+}
+
+func (m *mockRolloutRepo) GetByID(ctx context.Context, id string) (*entities.Rollout, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Rollout), args.Error(1)
+}
+
+func (m *mockRolloutRepo) List(ctx context.Context, filter interfaces.RolloutFilter) ([]*entities.Rollout, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Rollout), args.Error(1)
+}
+
+func (m *mockRolloutRepo) Update(ctx context.Context, r *entities.Rollout) error {
+	return m.Called(ctx, r).Error(0)
+}
+
+type mockEnvRepo struct{ mock.Mock }
+
+func (m *mockEnvRepo) Create(ctx context.Context, env *entities.Environment) error {
+	return m.Called(ctx, env).Error(0)
+}
+
+func (m *mockEnvRepo) GetByID(ctx context.Context, id string) (*entities.Environment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) GetByName(ctx context.Context, name string) (*entities.Environment, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) List(ctx context.Context, filter interfaces.ListFilter) ([]*entities.Environment, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) Update(ctx context.Context, id string, env *entities.Environment) error {
+	return m.Called(ctx, id, env).Error(0)
+}
+
+func (m *mockEnvRepo) UpdateStatus(ctx context.Context, id string, status entities.Status) error {
+	return m.Called(ctx, id, status).Error(0)
+}
+
+func (m *mockEnvRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockEnvRepo) Count(ctx context.Context, filter interfaces.ListFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// fakeUpgrader records upgrades in order and fails the configured calls
+type fakeUpgrader struct {
+	mu            sync.Mutex
+	upgrades      []string
+	rollbacks     []string                         // also in upgrades
+	failUpgrade   map[string]bool                  // "id@version"
+	failHealth    map[string]bool                  // health checks that cannot run
+	health        map[string]entities.HealthStatus // health check results; healthy when unset
+	block         chan struct{}                    // when set, upgrades wait for it to close
+	healthChecked chan struct{}                    // when set, signalled on every health check
+}
+
+func (f *fakeUpgrader) UpgradeEnvironment(ctx context.Context, id string, version string) error {
+	if f.block != nil {
+		<-f.block
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := id + "@" + version
+	f.upgrades = append(f.upgrades, key)
+	if f.failUpgrade[key] {
+		return fmt.Errorf("upgrade to %s failed", version)
+	}
+	return nil
+}
+
+func (f *fakeUpgrader) RollbackEnvironment(ctx context.Context, id string, version string) error {
+	f.mu.Lock()
+	f.rollbacks = append(f.rollbacks, id+"@"+version)
+	f.mu.Unlock()
+	return f.UpgradeEnvironment(ctx, id, version)
+}
+
+func (f *fakeUpgrader) ProbeHealth(ctx context.Context, id string) (*health.CheckResult, error) {
+	if f.healthChecked != nil {
+		select {
+		case f.healthChecked <- struct{}{}:
+		default:
+		}
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.failHealth[id] {
+		return nil, fmt.Errorf("health check failed")
+	}
+	result := &health.CheckResult{Status: entities.HealthStatusHealthy, Message: "ok"}
+	if status, ok := f.health[id]; ok {
+		result.Status, result.Message = status, "checked"
+	}
+	return result, nil
+}
+
+func (f *fakeUpgrader) calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.upgrades...)
+}
+
+func userCtx() context.Context {
+	return ctxutil.WithUser(context.Background(), "u1", "alice")
+}
+
+func makeEnvs(n int) []*entities.Environment {
+	envs := make([]*entities.Environment, n)
+	for i := range envs {
+		envs[i] = &entities.Environment{
+			ID:          primitive.NewObjectID(),
+			Name:        fmt.Sprintf("env%d", i),
+			HealthCheck: entities.HealthCheckConfig{Enabled: true},
+			Status:      entities.Status{Health: entities.HealthStatusHealthy},
+			SystemInfo:  entities.SystemInfo{AppVersion: "1.0.0"},
+		}
+	}
+	return envs
+}
+
+func idsOf(envs []*entities.Environment) []string {
+	ids := make([]string, len(envs))
+	for i, env := range envs {
+		ids[i] = env.ID.Hex()
+	}
+	return ids
+}
+
+func newService(envs []*entities.Environment, upgrader *fakeUpgrader) (*rollout.Service, *mockRolloutRepo, *mockEnvRepo) {
+	repo := new(mockRolloutRepo)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	envRepo := new(mockEnvRepo)
+	for _, env := range envs {
+		envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+	}
+	return rollout.NewService(repo, envRepo, upgrader, 5*time.Millisecond), repo, envRepo
+}
+
+// recorder collects the statuses reported through the progress callback
+type recorder struct {
+	mu       sync.Mutex
+	statuses []entities.RolloutStatus
+}
+
+func (r *recorder) progress(ro *entities.Rollout) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses = append(r.statuses, ro.Status)
+}
+
+func (r *recorder) saw(status entities.RolloutStatus) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func TestService_Create_Validation(t *testing.T) {
+	svc, _, _ := newService(nil, &fakeUpgrader{})
+
+	tests := []struct {
+		name string
+		req  rollout.Request
+	}{
+		{"no targets", rollout.Request{Version: "2.0.0"}},
+		{"both targets", rollout.Request{EnvironmentIDs: []string{"a"}, Selector: map[string]string{"a": "b"}, Version: "2.0.0"}},
+		{"no version", rollout.Request{EnvironmentIDs: []string{"a"}}},
+		{"negative batch size", rollout.Request{EnvironmentIDs: []string{"a"}, Version: "2.0.0", BatchSize: -1}},
+		{"negative soak", rollout.Request{EnvironmentIDs: []string{"a"}, Version: "2.0.0", SoakSeconds: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Create(userCtx(), tt.req)
+			require.Error(t, err)
+			_, ok := err.(errors.DomainError)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestService_Create_RequiresUser(t *testing.T) {
+	svc, _, _ := newService(nil, &fakeUpgrader{})
+
+	_, err := svc.Create(context.Background(), rollout.Request{EnvironmentIDs: []string{"a"}, Version: "2.0.0"})
+
+	assert.Equal(t, errors.ErrUnauthorized, err)
+}
+
+func TestService_Create_RejectsProtectedEnvironments(t *testing.T) {
+	envs := makeEnvs(2)
+	envs[1].RequiresApproval = true
+	svc, _, _ := newService(envs, &fakeUpgrader{})
+
+	_, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0"})
+
+	require.Error(t, err)
+	domainErr, ok := err.(errors.DomainError)
+	require.True(t, ok)
+	assert.Contains(t, domainErr.Details["reason"], "env1")
+}
+
+func TestService_Create_RejectsEnvironmentsWithoutHealthCheck(t *testing.T) {
+	envs := makeEnvs(2)
+	envs[1].HealthCheck.Enabled = false
+	upgrader := &fakeUpgrader{}
+	svc, repo, _ := newService(envs, upgrader)
+
+	_, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0"})
+
+	require.Error(t, err)
+	domainErr, ok := err.(errors.DomainError)
+	require.True(t, ok)
+	assert.Equal(t, "VALIDATION_ERROR", domainErr.Code)
+	assert.Contains(t, domainErr.Details["reason"], "without a health check")
+	assert.Contains(t, domainErr.Details["reason"], "env1")
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	assert.Empty(t, upgrader.calls())
+}
+
+func TestService_Create_BySelector(t *testing.T) {
+	envs := makeEnvs(3)
+	svc, _, envRepo := newService(nil, &fakeUpgrader{})
+	selector := map[string]string{"tier": "web"}
+	envRepo.On("List", mock.Anything, interfaces.ListFilter{Labels: selector}).Return(envs, nil)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{Selector: selector, Version: "2.0.0", Canary: true, BatchSize: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, entities.RolloutStatusPending, ro.Status)
+	assert.Equal(t, 2, ro.TotalBatches)
+	require.Len(t, ro.Members, 3)
+	assert.Equal(t, "1.0.0", ro.Members[0].PreviousVersion)
+	assert.Equal(t, "u1", ro.CreatedBy.ID)
+}
+
+func TestService_Run_CanaryThenBatches(t *testing.T) {
+	envs := makeEnvs(3)
+	upgrader := &fakeUpgrader{}
+	svc, _, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0", Canary: true, BatchSize: 2})
+	require.NoError(t, err)
+
+	rec := &recorder{}
+	svc.Run(userCtx(), ro, rec.progress)
+
+	assert.Equal(t, entities.RolloutStatusCompleted, ro.Status)
+	assert.NotNil(t, ro.CompletedAt)
+	for _, member := range ro.Members {
+		assert.Equal(t, entities.MemberStatusSucceeded, member.Status)
+		assert.NotNil(t, member.VerifiedAt)
+	}
+	calls := upgrader.calls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, envs[0].ID.Hex()+"@2.0.0", calls[0], "canary is upgraded first")
+	assert.True(t, rec.saw(entities.RolloutStatusRunning))
+}
+
+func TestService_Run_UpgradeFailureRollsBack(t *testing.T) {
+	envs := makeEnvs(3)
+	upgrader := &fakeUpgrader{failUpgrade: map[string]bool{envs[2].ID.Hex() + "@2.0.0": true}}
+	svc, _, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{
+		EnvironmentIDs:    idsOf(envs),
+		Version:           "2.0.0",
+		Canary:            true,
+		BatchSize:         1,
+		RollbackOnFailure: true,
+	})
+	require.NoError(t, err)
+
+	svc.Run(userCtx(), ro, nil)
+
+	assert.Equal(t, entities.RolloutStatusRolledBack, ro.Status)
+	assert.Contains(t, ro.Message, "batch 2")
+	assert.Equal(t, entities.MemberStatusRolledBack, ro.Members[0].Status)
+	assert.Equal(t, entities.MemberStatusRolledBack, ro.Members[1].Status)
+	assert.Equal(t, entities.MemberStatusFailed, ro.Members[2].Status)
+	assert.Contains(t, upgrader.calls(), envs[0].ID.Hex()+"@1.0.0")
+	assert.NotContains(t, upgrader.calls(), envs[2].ID.Hex()+"@1.0.0", "failed upgrade is not rolled back")
+	assert.Equal(t, []string{envs[0].ID.Hex() + "@1.0.0", envs[1].ID.Hex() + "@1.0.0"}, upgrader.rollbacks,
+		"rollbacks bypass the upgrade rate limit")
+}
+
+func TestService_Run_VerificationFailureHalts(t *testing.T) {
+	envs := makeEnvs(3)
+	upgrader := &fakeUpgrader{health: map[string]entities.HealthStatus{envs[0].ID.Hex(): entities.HealthStatusUnhealthy}}
+	svc, _, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0", Canary: true, BatchSize: 2})
+	require.NoError(t, err)
+
+	svc.Run(userCtx(), ro, nil)
+
+	assert.Equal(t, entities.RolloutStatusFailed, ro.Status)
+	assert.Contains(t, ro.Message, "health verification failed in batch 0")
+	assert.Equal(t, entities.MemberStatusFailed, ro.Members[0].Status)
+	assert.Equal(t, entities.MemberStatusSkipped, ro.Members[1].Status)
+	assert.Equal(t, entities.MemberStatusSkipped, ro.Members[2].Status)
+	assert.Len(t, upgrader.calls(), 1, "remaining batches are not started")
+}
+
+func TestService_Run_VerificationIgnoresThreshold(t *testing.T) {
+	envs := makeEnvs(2)
+	// The environment keeps its healthy status until three checks fail, but
+	// the rollout judges each check
+	envs[0].HealthCheck.FailuresBeforeUnhealthy = 3
+	upgrader := &fakeUpgrader{health: map[string]entities.HealthStatus{envs[0].ID.Hex(): entities.HealthStatusUnhealthy}}
+	svc, _, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0", Canary: true, BatchSize: 1})
+	require.NoError(t, err)
+
+	svc.Run(userCtx(), ro, nil)
+
+	assert.Equal(t, entities.HealthStatusHealthy, envs[0].Status.Health)
+	assert.Equal(t, entities.RolloutStatusFailed, ro.Status)
+	assert.Contains(t, ro.Message, "env0 is unhealthy")
+	assert.Len(t, upgrader.calls(), 1, "the next batch is not started")
+}
+
+func TestService_Run_DegradedMemberPasses(t *testing.T) {
+	envs := makeEnvs(2)
+	upgrader := &fakeUpgrader{health: map[string]entities.HealthStatus{envs[0].ID.Hex(): entities.HealthStatusDegraded}}
+	svc, _, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0", Canary: true, BatchSize: 1})
+	require.NoError(t, err)
+
+	svc.Run(userCtx(), ro, nil)
+
+	assert.Equal(t, entities.RolloutStatusCompleted, ro.Status)
+	assert.Equal(t, entities.MemberStatusSucceeded, ro.Members[0].Status)
+	assert.Len(t, upgrader.calls(), 2)
+}
+
+func TestService_Run_RollbackFailureKeepsFailed(t *testing.T) {
+	envs := makeEnvs(1)
+	upgrader := &fakeUpgrader{
+		failHealth:  map[string]bool{envs[0].ID.Hex(): true},
+		failUpgrade: map[string]bool{envs[0].ID.Hex() + "@1.0.0": true},
+	}
+	svc, _, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0", RollbackOnFailure: true})
+	require.NoError(t, err)
+
+	svc.Run(userCtx(), ro, nil)
+
+	assert.Equal(t, entities.RolloutStatusFailed, ro.Status)
+	assert.Equal(t, entities.MemberStatusRollbackFailed, ro.Members[0].Status)
+}
+
+func TestService_PauseResume(t *testing.T) {
+	envs := makeEnvs(2)
+	upgrader := &fakeUpgrader{block: make(chan struct{})}
+	svc, repo, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0", BatchSize: 1})
+	require.NoError(t, err)
+	repo.On("GetByID", mock.Anything, ro.ID.Hex()).Return(&entities.Rollout{ID: ro.ID}, nil)
+
+	rec := &recorder{}
+	done := make(chan struct{})
+	go func() {
+		svc.Run(userCtx(), ro, rec.progress)
+		close(done)
+	}()
+
+	// Pause while the first batch is upgrading
+	require.Eventually(t, func() bool {
+		_, err := svc.Pause(userCtx(), ro.ID.Hex())
+		return err == nil
+	}, time.Second, time.Millisecond)
+	close(upgrader.block)
+
+	require.Eventually(t, func() bool { return rec.saw(entities.RolloutStatusPaused) }, time.Second, time.Millisecond)
+	assert.Len(t, upgrader.calls(), 1, "next batch waits while paused")
+
+	_, err = svc.Resume(userCtx(), ro.ID.Hex())
+	require.NoError(t, err)
+	<-done
+
+	assert.Equal(t, entities.RolloutStatusCompleted, ro.Status)
+	assert.Len(t, upgrader.calls(), 2)
+}
+
+func TestService_Abort(t *testing.T) {
+	envs := makeEnvs(2)
+	upgrader := &fakeUpgrader{healthChecked: make(chan struct{}, 1)}
+	svc, repo, _ := newService(envs, upgrader)
+
+	ro, err := svc.Create(userCtx(), rollout.Request{EnvironmentIDs: idsOf(envs), Version: "2.0.0", Canary: true, SoakSeconds: 60})
+	require.NoError(t, err)
+	repo.On("GetByID", mock.Anything, ro.ID.Hex()).Return(&entities.Rollout{ID: ro.ID}, nil)
+
+	done := make(chan struct{})
+	go func() {
+		svc.Run(userCtx(), ro, nil)
+		close(done)
+	}()
+
+	// Abort while the canary soaks
+	<-upgrader.healthChecked
+	_, err = svc.Abort(userCtx(), ro.ID.Hex())
+	require.NoError(t, err)
+	<-done
+
+	assert.Equal(t, entities.RolloutStatusAborted, ro.Status)
+	assert.Equal(t, entities.MemberStatusFailed, ro.Members[0].Status)
+	assert.Equal(t, entities.MemberStatusSkipped, ro.Members[1].Status)
+	assert.Len(t, upgrader.calls(), 1)
+}
+
+func TestService_Steer_NotRunning(t *testing.T) {
+	svc, repo, _ := newService(nil, &fakeUpgrader{})
+	id := primitive.NewObjectID().Hex()
+	repo.On("GetByID", mock.Anything, id).Return(&entities.Rollout{Status: entities.RolloutStatusCompleted}, nil)
+	missing := primitive.NewObjectID().Hex()
+	repo.On("GetByID", mock.Anything, missing).Return(nil, errors.ErrRolloutNotFound)
+
+	_, err := svc.Pause(userCtx(), id)
+	assert.Equal(t, errors.ErrRolloutNotActive, err)
+
+	_, err = svc.Abort(userCtx(), missing)
+	assert.Equal(t, errors.ErrRolloutNotFound, err)
+}
@@ -0,0 +1,197 @@
+// Package simulation implements the driver for simulated environments. It
+// runs nothing: operations and health probes wait for the configured latency
+// and succeed, fail or report health at random, so restarts, upgrades and
+// health transitions can be exercised without SSH or HTTP targets.
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/executor"
+)
+
+// Validate checks a simulation configuration
+func Validate(config entities.SimulationConfig) error {
+	if config.LatencyMs < 0 || config.JitterMs < 0 {
+		return fmt.Errorf("simulated latency must not be negative")
+	}
+	if config.LatencyMs+config.JitterMs > entities.MaxSimulationLatencyMs {
+		return fmt.Errorf("simulated latency must not exceed %dms", entities.MaxSimulationLatencyMs)
+	}
+	if config.FailureRate < 0 || config.FailureRate > 1 {
+		return fmt.Errorf("simulated failure rate must be between 0 and 1")
+	}
+	if config.UnhealthyRate < 0 || config.UnhealthyRate > 1 {
+		return fmt.Errorf("simulated unhealthy rate must be between 0 and 1")
+	}
+	if config.FlapInterval < 0 {
+		return fmt.Errorf("simulated flap interval must not be negative")
+	}
+	for _, version := range config.Versions {
+		if version == "" {
+			return fmt.Errorf("simulated versions must not be empty")
+		}
+	}
+	return nil
+}
+
+// Simulator is the executor for simulated environments. It is safe for
+// concurrent use.
+type Simulator struct {
+	mu     sync.Mutex
+	random *rand.Rand
+	now    func() time.Time
+}
+
+// NewSimulator creates a simulator
+func NewSimulator() *Simulator {
+	return &Simulator{
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:    time.Now,
+	}
+}
+
+// Execute simulates the operation on the environment
+func (s *Simulator) Execute(ctx context.Context, req *executor.Request) *executor.Result {
+	config := configOf(req.Environment)
+	if err := Validate(config); err != nil {
+		return executor.Failure(err.Error())
+	}
+	version := req.Param(executor.ParamVersion)
+	if req.Operation == executor.OperationUpgrade {
+		if err := checkVersion(config, version); err != nil {
+			return executor.Failure(err.Error())
+		}
+	}
+
+	what := describe(req)
+	if err := s.wait(ctx, config); err != nil {
+		return executor.Failuref("simulated %s interrupted: %v", what, err)
+	}
+
+	if req.Operation == executor.OperationHealth {
+		return s.probe(config)
+	}
+	if s.chance(config.FailureRate) {
+		return executor.Failuref("simulated %s failed", what)
+	}
+	result := executor.Success(fmt.Sprintf("simulated %s completed", what))
+	if req.Operation == executor.OperationUpgrade {
+		result.Artifacts = map[string]interface{}{"version": version}
+	}
+	return result
+}
+
+// Plan shows the simulated step and checks the configuration
+func (s *Simulator) Plan(ctx context.Context, req *executor.Request, plan *entities.OperationPlan) {
+	config := configOf(req.Environment)
+	plan.Steps = append(plan.Steps, entities.PlanStep{
+		Type:    entities.CommandTypeSimulated,
+		Command: "simulate " + describe(req),
+	})
+	plan.AddCheck("simulation", "", Validate(config))
+	if req.Operation == executor.OperationUpgrade {
+		version := req.Param(executor.ParamVersion)
+		plan.AddCheck("version", version, checkVersion(config, version))
+	}
+}
+
+// Versions returns the versions the environment offers for upgrade. The list
+// is empty when the environment lists none, since any version is accepted.
+func (s *Simulator) Versions(ctx context.Context, env *entities.Environment) ([]string, error) {
+	return append([]string{}, configOf(env).Versions...), nil
+}
+
+// probe reports the environment unhealthy in the unhealthy half of each flap
+// period, or at random
+func (s *Simulator) probe(config entities.SimulationConfig) *executor.Result {
+	result := &executor.Result{
+		Status: executor.StatusSucceeded,
+		Health: entities.HealthStatusHealthy,
+		Output: "Simulated environment is healthy",
+	}
+	switch {
+	case config.FlapInterval > 0 && (s.now().Unix()/int64(config.FlapInterval))%2 == 1:
+		result.Health = entities.HealthStatusUnhealthy
+		result.Output = "Simulated environment is flapping"
+	case s.chance(config.UnhealthyRate):
+		result.Health = entities.HealthStatusUnhealthy
+		result.Output = "Simulated health check failed"
+	}
+	return result
+}
+
+// wait sleeps for the latency plus a random part of the jitter
+func (s *Simulator) wait(ctx context.Context, config entities.SimulationConfig) error {
+	latency := time.Duration(config.LatencyMs) * time.Millisecond
+	if config.JitterMs > 0 {
+		s.mu.Lock()
+		latency += time.Duration(s.random.Intn(config.JitterMs+1)) * time.Millisecond
+		s.mu.Unlock()
+	}
+	if latency <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// chance reports true with probability rate
+func (s *Simulator) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.random.Float64() < rate
+}
+
+// checkVersion rejects versions the environment does not offer
+func checkVersion(config entities.SimulationConfig, version string) error {
+	if version == "" {
+		return fmt.Errorf("version is required")
+	}
+	if len(config.Versions) == 0 {
+		return nil
+	}
+	for _, offered := range config.Versions {
+		if offered == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("version %s is not available", version)
+}
+
+// configOf returns the environment's simulation settings; environments
+// without any are always healthy and succeed immediately
+func configOf(env *entities.Environment) entities.SimulationConfig {
+	if env == nil || env.Commands.Simulation == nil {
+		return entities.SimulationConfig{}
+	}
+	return *env.Commands.Simulation
+}
+
+// describe names the operation in messages
+func describe(req *executor.Request) string {
+	switch req.Operation {
+	case executor.OperationCustomAction:
+		return fmt.Sprintf("custom action %s", req.Param(executor.ParamAction))
+	case executor.OperationUpgrade:
+		return fmt.Sprintf("upgrade to %s", req.Param(executor.ParamVersion))
+	case executor.OperationHealth:
+		return "health check"
+	}
+	return string(req.Operation)
+}
@@ -0,0 +1,207 @@
+package simulation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func simulatedEnv(config *entities.SimulationConfig) *entities.Environment {
+	return &entities.Environment{
+		Name:     "demo",
+		Commands: entities.CommandConfig{Type: entities.CommandTypeSimulated, Simulation: config},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config entities.SimulationConfig
+		want   string
+	}{
+		{"empty", entities.SimulationConfig{}, ""},
+		{"full", entities.SimulationConfig{LatencyMs: 500, JitterMs: 100, FailureRate: 0.2,
+			UnhealthyRate: 0.1, FlapInterval: 60, Versions: []string{"1.0.0"}}, ""},
+		{"negative latency", entities.SimulationConfig{LatencyMs: -1}, "must not be negative"},
+		{"negative jitter", entities.SimulationConfig{JitterMs: -1}, "must not be negative"},
+		{"latency too long", entities.SimulationConfig{LatencyMs: 50000, JitterMs: 20000}, "must not exceed"},
+		{"failure rate", entities.SimulationConfig{FailureRate: 1.5}, "failure rate"},
+		{"unhealthy rate", entities.SimulationConfig{UnhealthyRate: -0.1}, "unhealthy rate"},
+		{"flap interval", entities.SimulationConfig{FlapInterval: -5}, "flap interval"},
+		{"empty version", entities.SimulationConfig{Versions: []string{"1.0.0", ""}}, "versions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.config)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestSimulator_Operations(t *testing.T) {
+	sim := NewSimulator()
+	env := simulatedEnv(nil)
+
+	tests := []struct {
+		req  *executor.Request
+		want string
+	}{
+		{&executor.Request{Operation: executor.OperationRestart}, "simulated restart completed"},
+		{&executor.Request{Operation: executor.OperationUpgrade,
+			Params: map[string]string{executor.ParamVersion: "2.0.0"}}, "simulated upgrade to 2.0.0 completed"},
+		{&executor.Request{Operation: executor.OperationCustomAction,
+			Params: map[string]string{executor.ParamAction: "flush"}}, "simulated custom action flush completed"},
+	}
+	for _, tt := range tests {
+		tt.req.Environment = env
+		result := sim.Execute(context.Background(), tt.req)
+		assert.True(t, result.Succeeded(), tt.want)
+		assert.Equal(t, tt.want, result.Output)
+	}
+}
+
+func TestSimulator_UpgradeArtifacts(t *testing.T) {
+	result := NewSimulator().Execute(context.Background(), &executor.Request{
+		Operation:   executor.OperationUpgrade,
+		Environment: simulatedEnv(&entities.SimulationConfig{Versions: []string{"1.0.0", "2.0.0"}}),
+		Params:      map[string]string{executor.ParamVersion: "2.0.0"},
+	})
+
+	require.True(t, result.Succeeded())
+	assert.Equal(t, "2.0.0", result.Artifacts["version"])
+}
+
+func TestSimulator_UpgradeUnknownVersion(t *testing.T) {
+	result := NewSimulator().Execute(context.Background(), &executor.Request{
+		Operation:   executor.OperationUpgrade,
+		Environment: simulatedEnv(&entities.SimulationConfig{Versions: []string{"1.0.0"}}),
+		Params:      map[string]string{executor.ParamVersion: "9.9.9"},
+	})
+
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, "version 9.9.9 is not available", result.Message())
+}
+
+func TestSimulator_FailureRate(t *testing.T) {
+	sim := NewSimulator()
+	env := simulatedEnv(&entities.SimulationConfig{FailureRate: 1})
+
+	result := sim.Execute(context.Background(), &executor.Request{Operation: executor.OperationRestart, Environment: env})
+
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, "simulated restart failed", result.Message())
+}
+
+func TestSimulator_InvalidConfig(t *testing.T) {
+	result := NewSimulator().Execute(context.Background(), &executor.Request{
+		Operation:   executor.OperationRestart,
+		Environment: simulatedEnv(&entities.SimulationConfig{FailureRate: 2}),
+	})
+
+	assert.False(t, result.Succeeded())
+	assert.Contains(t, result.Message(), "failure rate")
+}
+
+func TestSimulator_Latency(t *testing.T) {
+	sim := NewSimulator()
+	env := simulatedEnv(&entities.SimulationConfig{LatencyMs: 50, JitterMs: 10})
+
+	start := time.Now()
+	result := sim.Execute(context.Background(), &executor.Request{Operation: executor.OperationRestart, Environment: env})
+
+	assert.True(t, result.Succeeded())
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+}
+
+func TestSimulator_LatencyCancelled(t *testing.T) {
+	sim := NewSimulator()
+	env := simulatedEnv(&entities.SimulationConfig{LatencyMs: 10000})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result := sim.Execute(ctx, &executor.Request{Operation: executor.OperationRestart, Environment: env})
+
+	assert.False(t, result.Succeeded())
+	assert.Contains(t, result.Message(), "simulated restart interrupted")
+}
+
+func TestSimulator_Health(t *testing.T) {
+	sim := NewSimulator()
+	probe := &executor.Request{Operation: executor.OperationHealth}
+
+	probe.Environment = simulatedEnv(nil)
+	result := sim.Execute(context.Background(), probe)
+	assert.True(t, result.Succeeded())
+	assert.Equal(t, entities.HealthStatusHealthy, result.Health)
+
+	probe.Environment = simulatedEnv(&entities.SimulationConfig{UnhealthyRate: 1, FailureRate: 1})
+	result = sim.Execute(context.Background(), probe)
+	assert.True(t, result.Succeeded(), "the failure rate applies to operations only")
+	assert.Equal(t, entities.HealthStatusUnhealthy, result.Health)
+	assert.Equal(t, "Simulated health check failed", result.Output)
+}
+
+func TestSimulator_HealthFlapping(t *testing.T) {
+	sim := NewSimulator()
+	var now time.Time
+	sim.now = func() time.Time { return now }
+	probe := &executor.Request{
+		Operation:   executor.OperationHealth,
+		Environment: simulatedEnv(&entities.SimulationConfig{FlapInterval: 30}),
+	}
+
+	var observed []entities.HealthStatus
+	for _, second := range []int64{0, 29, 30, 59, 60, 95} {
+		now = time.Unix(second, 0)
+		observed = append(observed, sim.Execute(context.Background(), probe).Health)
+	}
+
+	assert.Equal(t, []entities.HealthStatus{
+		entities.HealthStatusHealthy, entities.HealthStatusHealthy,
+		entities.HealthStatusUnhealthy, entities.HealthStatusUnhealthy,
+		entities.HealthStatusHealthy, entities.HealthStatusUnhealthy,
+	}, observed)
+}
+
+func TestSimulator_Plan(t *testing.T) {
+	env := simulatedEnv(&entities.SimulationConfig{Versions: []string{"1.0.0"}})
+	plan := &entities.OperationPlan{Valid: true}
+
+	NewSimulator().Plan(context.Background(), &executor.Request{
+		Operation:   executor.OperationUpgrade,
+		Environment: env,
+		Params:      map[string]string{executor.ParamVersion: "2.0.0"},
+	}, plan)
+
+	require.Len(t, plan.Steps, 1)
+	assert.Equal(t, entities.CommandTypeSimulated, plan.Steps[0].Type)
+	assert.Equal(t, "simulate upgrade to 2.0.0", plan.Steps[0].Command)
+	assert.False(t, plan.Valid)
+	require.Len(t, plan.Checks, 2)
+	assert.True(t, plan.Checks[0].Passed)
+	assert.Equal(t, "version 2.0.0 is not available", plan.Checks[1].Error)
+}
+
+func TestSimulator_Versions(t *testing.T) {
+	sim := NewSimulator()
+
+	versions, err := sim.Versions(context.Background(), simulatedEnv(&entities.SimulationConfig{Versions: []string{"1.0.0", "1.1.0"}}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.0.0", "1.1.0"}, versions)
+
+	versions, err = sim.Versions(context.Background(), simulatedEnv(nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, versions)
+}
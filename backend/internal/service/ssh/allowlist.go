@@ -0,0 +1,254 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"app-env-manager/internal/domain/entities"
+)
+
+// ErrCommandNotAllowed is returned for commands no allowlist rule matches
+var ErrCommandNotAllowed = errors.New("command not allowed by allowlist")
+
+// Rule scopes reported in a Match
+const (
+	ScopeEnvironment = "environment"
+	ScopeGlobal      = "global"
+)
+
+// defaultSlotPattern is what a parameter slot accepts without a Params entry
+const defaultSlotPattern = `[A-Za-z0-9._:/@%+=,-]+`
+
+var slotPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Match is the allowlist rule a command matched and the values of its
+// parameter slots
+type Match struct {
+	Rule   entities.CommandRule `json:"rule"`
+	Scope  string               `json:"scope"`
+	Params map[string]string    `json:"params,omitempty"`
+}
+
+// Authorize checks a command against the environment's rules, then the global
+// allowlist. Without any rules the character checks apply instead and the
+// match is nil.
+func (m *Manager) Authorize(command string, rules []entities.CommandRule) (*Match, error) {
+	if len(rules) == 0 && len(m.config.Allowlist) == 0 {
+		return nil, validateCommand(command)
+	}
+
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("command cannot be empty")
+	}
+	if strings.ContainsAny(command, "\x00\n\r") {
+		return nil, fmt.Errorf("command contains null bytes or newline characters")
+	}
+
+	scopes := []struct {
+		name  string
+		rules []entities.CommandRule
+	}{
+		{ScopeEnvironment, rules},
+		{ScopeGlobal, m.config.Allowlist},
+	}
+	for _, scope := range scopes {
+		for _, rule := range scope.rules {
+			compiled, err := compileRule(rule)
+			if err != nil {
+				return nil, err
+			}
+			if params, ok := compiled.match(command); ok {
+				return &Match{Rule: rule, Scope: scope.name, Params: params}, nil
+			}
+		}
+	}
+	return nil, ErrCommandNotAllowed
+}
+
+// ValidateRules checks that every rule has a known type and a pattern that
+// compiles
+func ValidateRules(rules []entities.CommandRule) error {
+	for _, rule := range rules {
+		if _, err := compileRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compiledRule is a rule turned into regular expressions
+type compiledRule struct {
+	ruleType entities.CommandRuleType
+	pattern  *regexp.Regexp // exact and prefix rules
+	tokens   []globToken    // glob rules
+}
+
+// globToken matches one argument of a glob rule; rest matches all remaining
+// arguments
+type globToken struct {
+	pattern  *regexp.Regexp
+	wildcard bool
+	rest     bool
+}
+
+// compileRule compiles a rule's pattern and slot constraints
+func compileRule(rule entities.CommandRule) (*compiledRule, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("invalid allowlist rule %q: %s", rule.DisplayName(), fmt.Sprintf(format, args...))
+	}
+
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return nil, invalid("pattern is required")
+	}
+	slots := map[string]bool{}
+	for _, m := range slotPattern.FindAllStringSubmatch(rule.Pattern, -1) {
+		slots[m[1]] = true
+	}
+	for name, expr := range rule.Params {
+		if !slots[name] {
+			return nil, invalid("parameter %s has no slot in the pattern", name)
+		}
+		if _, err := regexp.Compile(expr); err != nil {
+			return nil, invalid("parameter %s: %v", name, err)
+		}
+	}
+
+	compiled := &compiledRule{ruleType: rule.Type}
+	switch rule.Type {
+	case entities.CommandRuleExact, entities.CommandRulePrefix:
+		expr := "^" + patternRegex(rule.Pattern, rule.Params, false)
+		if rule.Type == entities.CommandRuleExact {
+			expr += "$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, invalid("%v", err)
+		}
+		compiled.pattern = re
+	case entities.CommandRuleGlob:
+		fields := strings.Fields(rule.Pattern)
+		for i, field := range fields {
+			if field == "**" {
+				if i != len(fields)-1 {
+					return nil, invalid("** must be the last argument")
+				}
+				compiled.tokens = append(compiled.tokens, globToken{rest: true})
+				continue
+			}
+			re, err := regexp.Compile("^" + patternRegex(field, rule.Params, true) + "$")
+			if err != nil {
+				return nil, invalid("%v", err)
+			}
+			compiled.tokens = append(compiled.tokens, globToken{pattern: re, wildcard: strings.ContainsAny(field, "*?")})
+		}
+	default:
+		return nil, invalid("unknown type %q", rule.Type)
+	}
+	return compiled, nil
+}
+
+// match reports whether the command matches and returns the slot values
+func (r *compiledRule) match(command string) (map[string]string, bool) {
+	params := map[string]string{}
+	if r.ruleType == entities.CommandRuleGlob {
+		return params, r.matchArgs(strings.Fields(command), params)
+	}
+
+	loc := r.pattern.FindStringSubmatchIndex(command)
+	if loc == nil || !collectParams(r.pattern, command, loc, params) {
+		return nil, false
+	}
+	if r.ruleType == entities.CommandRulePrefix {
+		// Whatever follows the prefix must not chain or redirect
+		if checkShellChars(command[loc[1]:]) != nil {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// matchArgs matches the command's arguments against a glob rule's tokens
+func (r *compiledRule) matchArgs(args []string, params map[string]string) bool {
+	for i, token := range r.tokens {
+		if token.rest {
+			for _, arg := range args[i:] {
+				if checkShellChars(arg) != nil {
+					return false
+				}
+			}
+			return true
+		}
+		if i >= len(args) {
+			return false
+		}
+		loc := token.pattern.FindStringSubmatchIndex(args[i])
+		if loc == nil || !collectParams(token.pattern, args[i], loc, params) {
+			return false
+		}
+		if token.wildcard && checkShellChars(args[i]) != nil {
+			return false
+		}
+	}
+	return len(args) == len(r.tokens)
+}
+
+// collectParams records the slot values of a match. Slot values must never
+// contain shell metacharacters, whatever their constraint allows.
+func collectParams(re *regexp.Regexp, s string, loc []int, params map[string]string) bool {
+	for i, name := range re.SubexpNames() {
+		if !strings.HasPrefix(name, "slot_") || loc[2*i] < 0 {
+			continue
+		}
+		value := s[loc[2*i]:loc[2*i+1]]
+		if checkShellChars(value) != nil {
+			return false
+		}
+		params[strings.TrimPrefix(name, "slot_")] = value
+	}
+	return true
+}
+
+// patternRegex turns a pattern into a regular expression. Slots become named
+// groups with their constraint; with wildcards, * and ? match any characters.
+func patternRegex(pattern string, params map[string]string, wildcards bool) string {
+	var b strings.Builder
+	literal := func(s string) {
+		if !wildcards {
+			b.WriteString(regexp.QuoteMeta(s))
+			return
+		}
+		for _, r := range s {
+			switch r {
+			case '*':
+				b.WriteString(".*")
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	last := 0
+	for _, loc := range slotPattern.FindAllStringSubmatchIndex(pattern, -1) {
+		literal(pattern[last:loc[0]])
+		name := pattern[loc[2]:loc[3]]
+		expr := defaultSlotPattern
+		if custom, ok := params[name]; ok {
+			expr = custom
+		}
+		if seen[name] {
+			// Go regexps reject duplicate group names
+			fmt.Fprintf(&b, "(?:%s)", expr)
+		} else {
+			fmt.Fprintf(&b, "(?P<slot_%s>%s)", name, expr)
+			seen[name] = true
+		}
+		last = loc[1]
+	}
+	literal(pattern[last:])
+	return b.String()
+}
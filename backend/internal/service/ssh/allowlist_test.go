@@ -0,0 +1,195 @@
+package ssh_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func allowlistManager(global ...entities.CommandRule) *ssh.Manager {
+	return ssh.NewManager(ssh.Config{Allowlist: global})
+}
+
+func TestAuthorize_NoRulesKeepsCharacterChecks(t *testing.T) {
+	manager := allowlistManager()
+
+	match, err := manager.Authorize("systemctl status app", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, match)
+
+	_, err = manager.Authorize("systemctl status app | tee /tmp/x", nil)
+	assert.Error(t, err)
+}
+
+func TestAuthorize_Exact(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{
+		Name: "restart", Type: entities.CommandRuleExact, Pattern: "sudo systemctl restart app && sleep 5",
+	})
+
+	match, err := manager.Authorize("sudo systemctl restart app && sleep 5", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "restart", match.Rule.Name)
+	assert.Equal(t, ssh.ScopeGlobal, match.Scope)
+
+	_, err = manager.Authorize("sudo systemctl restart app", nil)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+	_, err = manager.Authorize("sudo systemctl restart app && sleep 5 && reboot", nil)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+}
+
+func TestAuthorize_Prefix(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{
+		Name: "journal", Type: entities.CommandRulePrefix, Pattern: "journalctl -u app ",
+	})
+
+	_, err := manager.Authorize("journalctl -u app --since today", nil)
+	assert.NoError(t, err)
+
+	_, err = manager.Authorize("journalctl -u app --since today; rm -rf /", nil)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed, "the remainder cannot chain commands")
+	_, err = manager.Authorize("journalctl -u other", nil)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+}
+
+func TestAuthorize_GlobWithSlots(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{
+		Name:    "upgrade",
+		Type:    entities.CommandRuleGlob,
+		Pattern: "sudo app-upgrade --version={version} --channel=*",
+		Params:  map[string]string{"version": `[0-9]+\.[0-9]+\.[0-9]+`},
+	})
+
+	match, err := manager.Authorize("sudo  app-upgrade --version=2.1.0 --channel=stable", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"version": "2.1.0"}, match.Params)
+
+	for _, command := range []string{
+		"sudo app-upgrade --version=latest --channel=stable",
+		"sudo app-upgrade --version=2.1.0",
+		"sudo app-upgrade --version=2.1.0 --channel=stable extra",
+		"sudo app-upgrade --version=2.1.0 --channel=$(id)",
+	} {
+		_, err := manager.Authorize(command, nil)
+		assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed, command)
+	}
+}
+
+func TestAuthorize_GlobRest(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{
+		Type: entities.CommandRuleGlob, Pattern: "docker compose -f /opt/app/*.yml **",
+	})
+
+	_, err := manager.Authorize("docker compose -f /opt/app/prod.yml up -d", nil)
+	assert.NoError(t, err)
+	_, err = manager.Authorize("docker compose -f /opt/app/prod.yml", nil)
+	assert.NoError(t, err)
+	_, err = manager.Authorize("docker compose -f /opt/app/prod.yml up > /etc/passwd", nil)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+}
+
+func TestAuthorize_DefaultSlotRejectsMetacharacters(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{
+		Type: entities.CommandRuleExact, Pattern: "sudo systemctl restart {service}",
+	})
+
+	match, err := manager.Authorize("sudo systemctl restart nginx", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "nginx", match.Params["service"])
+
+	_, err = manager.Authorize("sudo systemctl restart nginx;reboot", nil)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+}
+
+func TestAuthorize_CustomSlotCannotAllowMetacharacters(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{
+		Type: entities.CommandRuleExact, Pattern: "echo {msg}", Params: map[string]string{"msg": ".+"},
+	})
+
+	_, err := manager.Authorize("echo hello", nil)
+	assert.NoError(t, err)
+	_, err = manager.Authorize("echo hello && reboot", nil)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+}
+
+func TestAuthorize_EnvironmentRulesFirst(t *testing.T) {
+	rule := entities.CommandRule{Name: "status", Type: entities.CommandRuleExact, Pattern: "systemctl status app"}
+	manager := allowlistManager(rule)
+
+	envRule := rule
+	envRule.Name = "env-status"
+	match, err := manager.Authorize("systemctl status app", []entities.CommandRule{envRule})
+	require.NoError(t, err)
+	assert.Equal(t, "env-status", match.Rule.Name)
+	assert.Equal(t, ssh.ScopeEnvironment, match.Scope)
+
+	// Environment rules alone switch the environment to allowlist mode
+	_, err = allowlistManager().Authorize("uptime", []entities.CommandRule{envRule})
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+}
+
+func TestAuthorize_RejectsNewlines(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{Type: entities.CommandRulePrefix, Pattern: "echo"})
+
+	_, err := manager.Authorize("echo ok\nreboot", nil)
+	assert.Error(t, err)
+	_, err = manager.Authorize("  ", nil)
+	assert.Error(t, err)
+}
+
+func TestValidateRules(t *testing.T) {
+	assert.NoError(t, ssh.ValidateRules(nil))
+	assert.NoError(t, ssh.ValidateRules([]entities.CommandRule{
+		{Type: entities.CommandRuleGlob, Pattern: "ls {dir} **", Params: map[string]string{"dir": "/var/log/[a-z]+"}},
+	}))
+
+	tests := []struct {
+		name string
+		rule entities.CommandRule
+		err  string
+	}{
+		{"empty pattern", entities.CommandRule{Type: entities.CommandRuleExact}, "pattern is required"},
+		{"unknown type", entities.CommandRule{Type: "regex", Pattern: "ls"}, "unknown type"},
+		{"unused param", entities.CommandRule{Type: entities.CommandRuleExact, Pattern: "ls", Params: map[string]string{"dir": ".*"}}, "no slot"},
+		{"bad param regex", entities.CommandRule{Type: entities.CommandRuleExact, Pattern: "ls {dir}", Params: map[string]string{"dir": "("}}, "parameter dir"},
+		{"rest not last", entities.CommandRule{Type: entities.CommandRuleGlob, Pattern: "ls ** -l"}, "must be the last"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, ssh.ValidateRules([]entities.CommandRule{tt.rule}), tt.err)
+		})
+	}
+}
+
+func TestManager_ExecuteAuthorized_Denied(t *testing.T) {
+	manager := allowlistManager(entities.CommandRule{Type: entities.CommandRuleExact, Pattern: "uptime"})
+	defer manager.Close()
+
+	result, match, err := manager.ExecuteAuthorized(context.Background(), ssh.Target{}, "reboot", nil)
+	assert.Nil(t, result)
+	assert.Nil(t, match)
+	assert.ErrorIs(t, err, ssh.ErrCommandNotAllowed)
+}
+
+func TestManager_ExecuteAuthorized_ReturnsMatch(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: 5 * time.Second,
+		CommandTimeout:    10 * time.Second,
+		MaxConnections:    10,
+		Allowlist: []entities.CommandRule{
+			{Name: "greet", Type: entities.CommandRuleExact, Pattern: "echo hello | tr a-z A-Z"},
+		},
+	})
+	defer manager.Close()
+
+	result, match, err := manager.ExecuteAuthorized(context.Background(), server.Target(), "echo hello | tr a-z A-Z", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "HELLO\n", result.Output)
+	assert.Equal(t, "greet", match.Rule.Name)
+}
@@ -0,0 +1,37 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// Dial opens a connection from the target host to address, such as
+// ("unix", "/var/run/docker.sock"), over the pooled SSH connection. The
+// pooled connection is held until the returned connection is closed.
+func (m *Manager) Dial(target Target, network, address string) (net.Conn, error) {
+	conn, err := m.getConnection(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH connection: %w", err)
+	}
+
+	forwarded, err := conn.client.Dial(network, address)
+	if err != nil {
+		m.releaseConnection(target)
+		return nil, fmt.Errorf("failed to dial %s over SSH: %w", address, err)
+	}
+	return &dialedConn{Conn: forwarded, release: func() { m.releaseConnection(target) }}, nil
+}
+
+// dialedConn releases its pooled SSH connection when closed
+type dialedConn struct {
+	net.Conn
+	release func()
+	once    sync.Once
+}
+
+func (c *dialedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,49 @@
+package ssh_test
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Dial_UnixSocket(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := newShellManager(t)
+
+	socket := filepath.Join(t.TempDir(), "echo.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		conn.Write([]byte("echo: " + line))
+	}()
+
+	conn, err := manager.Dial(server.Target(), "unix", socket)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "echo: hello\n", reply)
+	assert.NoError(t, conn.Close())
+}
+
+func TestManager_Dial_MissingSocket(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := newShellManager(t)
+
+	_, err := manager.Dial(server.Target(), "unix", filepath.Join(t.TempDir(), "missing.sock"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to dial")
+}
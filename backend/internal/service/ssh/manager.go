@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"app-env-manager/internal/domain/entities"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -30,7 +31,9 @@ type Config struct {
 	ConnectionTimeout time.Duration
 	CommandTimeout    time.Duration
 	MaxConnections    int
-	KnownHostsFile    string // Path to known_hosts file for host key verification
+	KnownHostsFile    string                 // Path to known_hosts file for host key verification
+	Allowlist         []entities.CommandRule // Global command allowlist; empty keeps the character checks
+	ScriptDir         string                 // Remote directory for uploaded scripts, defaults to /tmp
 }
 
 // Target represents an SSH connection target
@@ -42,6 +45,7 @@ type Target struct {
 	PrivateKey                  []byte
 	HostKey                     []byte // Expected host public key for verification
 	InsecureSkipHostKeyVerify   bool   // Skip host key verification (test/dev only)
+	CommandTimeout              time.Duration // Overrides Config.CommandTimeout when set
 }
 
 // ExecutionResult contains the result of an SSH command
@@ -67,6 +71,12 @@ func validateCommand(command string) error {
 		return fmt.Errorf("command cannot be empty")
 	}
 
+	return checkShellChars(command)
+}
+
+// checkShellChars rejects characters that would let a value break out of a
+// single command
+func checkShellChars(command string) error {
 	// Reject null bytes which can be used to truncate strings in some contexts
 	if strings.ContainsRune(command, '\x00') {
 		return fmt.Errorf("command contains null bytes")
@@ -88,6 +98,11 @@ func validateCommand(command string) error {
 	return nil
 }
 
+// ValidateCommand reports whether Execute would accept the command
+func ValidateCommand(command string) error {
+	return validateCommand(command)
+}
+
 // Execute executes a command on a remote host
 func (m *Manager) Execute(ctx context.Context, target Target, command string) (*ExecutionResult, error) {
 	start := time.Now()
@@ -97,6 +112,26 @@ func (m *Manager) Execute(ctx context.Context, target Target, command string) (*
 		return nil, fmt.Errorf("invalid command: %w", err)
 	}
 	
+	return m.execute(ctx, target, command, start)
+}
+
+// ExecuteAuthorized executes a command allowed by the given rules or the
+// global allowlist and returns the rule it matched
+func (m *Manager) ExecuteAuthorized(ctx context.Context, target Target, command string,
+	rules []entities.CommandRule) (*ExecutionResult, *Match, error) {
+
+	start := time.Now()
+	match, err := m.Authorize(command, rules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid command: %w", err)
+	}
+
+	result, err := m.execute(ctx, target, command, start)
+	return result, match, err
+}
+
+// execute runs an already validated command
+func (m *Manager) execute(ctx context.Context, target Target, command string, start time.Time) (*ExecutionResult, error) {
 	// Get or create connection
 	conn, err := m.getConnection(target)
 	if err != nil {
@@ -104,6 +139,19 @@ func (m *Manager) Execute(ctx context.Context, target Target, command string) (*
 	}
 	defer m.releaseConnection(target)
 
+	return m.run(ctx, conn, command, m.commandTimeout(target), start)
+}
+
+// commandTimeout returns how long a command on the target may run
+func (m *Manager) commandTimeout(target Target) time.Duration {
+	if target.CommandTimeout > 0 {
+		return target.CommandTimeout
+	}
+	return m.config.CommandTimeout
+}
+
+// run runs a command in a new session on the connection
+func (m *Manager) run(ctx context.Context, conn *Connection, command string, timeout time.Duration, start time.Time) (*ExecutionResult, error) {
 	// Create session
 	session, err := conn.client.NewSession()
 	if err != nil {
@@ -142,8 +190,8 @@ func (m *Manager) Execute(ctx context.Context, target Target, command string) (*
 	case <-ctx.Done():
 		return nil, ctx.Err()
 		
-	case <-time.After(m.config.CommandTimeout):
-		return nil, fmt.Errorf("command timeout after %v", m.config.CommandTimeout)
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("command timeout after %v", timeout)
 	}
 }
 
@@ -55,3 +55,8 @@ func TestValidateCommand_DangerousChars(t *testing.T) {
 		assert.Error(t, err, "expected error for: %s", cmd)
 	}
 }
+
+func TestValidateCommand_Exported(t *testing.T) {
+	assert.NoError(t, ValidateCommand("systemctl status app"))
+	assert.Error(t, ValidateCommand("systemctl status app; reboot"))
+}
@@ -470,6 +470,34 @@ func TestManager_Execute_CommandTimeout(t *testing.T) {
 	assert.Contains(t, err.Error(), "command timeout after")
 }
 
+func TestManager_Execute_TargetCommandTimeout(t *testing.T) {
+	server := newMockSSHServer(t)
+	defer server.stop()
+
+	hostKeyBytes := gossh.MarshalAuthorizedKey(server.hostKey.PublicKey())
+
+	manager := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: 5 * time.Second,
+		CommandTimeout:    10 * time.Second,
+		MaxConnections:    10,
+	})
+	defer manager.Close()
+
+	target := ssh.Target{
+		Host:           "127.0.0.1",
+		Port:           server.port(),
+		Username:       "testuser",
+		Password:       "testpass",
+		HostKey:        hostKeyBytes,
+		CommandTimeout: 500 * time.Millisecond,
+	}
+
+	result, err := manager.Execute(context.Background(), target, "sleep 2")
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "command timeout after 500ms")
+}
+
 func TestManager_Execute_ConnectionReuse(t *testing.T) {
 	server := newMockSSHServer(t)
 	defer server.stop()
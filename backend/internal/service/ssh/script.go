@@ -0,0 +1,182 @@
+package ssh
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// DefaultInterpreter runs scripts that do not name an interpreter
+const DefaultInterpreter = "/bin/sh"
+
+// defaultScriptDir is where scripts are uploaded when Config.ScriptDir is unset
+const defaultScriptDir = "/tmp"
+
+var (
+	interpreterPattern = regexp.MustCompile(`^/[A-Za-z0-9._/+-]+$`)
+	envNamePattern     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Script is a script body uploaded over SFTP and run with an explicit
+// interpreter. Parameters reach the script as environment variables, never
+// as part of a command line.
+type Script struct {
+	Body        string
+	Interpreter string // absolute path, defaults to /bin/sh
+	Env         map[string]string
+}
+
+// ValidateScript reports whether ExecuteScript would accept the script
+func ValidateScript(script Script) error {
+	if strings.TrimSpace(script.Body) == "" {
+		return fmt.Errorf("script body cannot be empty")
+	}
+	if strings.ContainsRune(script.Body, '\x00') {
+		return fmt.Errorf("script contains null bytes")
+	}
+	if script.Interpreter != "" && !interpreterPattern.MatchString(script.Interpreter) {
+		return fmt.Errorf("interpreter must be an absolute path: %s", script.Interpreter)
+	}
+	for name, value := range script.Env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name: %s", name)
+		}
+		if strings.ContainsRune(value, '\x00') {
+			return fmt.Errorf("environment variable %s contains null bytes", name)
+		}
+	}
+	return nil
+}
+
+// ExecuteScript uploads the script to a private temporary directory on the
+// remote host, runs it and removes it again
+func (m *Manager) ExecuteScript(ctx context.Context, target Target, script Script) (*ExecutionResult, error) {
+	start := time.Now()
+
+	if err := ValidateScript(script); err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+
+	conn, err := m.getConnection(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH connection: %w", err)
+	}
+	defer m.releaseConnection(target)
+
+	client, err := sftp.NewClient(conn.client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start SFTP session: %w", err)
+	}
+	defer client.Close()
+
+	dir, err := m.createScriptDir(client)
+	if err != nil {
+		return nil, err
+	}
+	defer removeScriptDir(client, dir)
+
+	scriptPath := path.Join(dir, "script")
+	runPath := path.Join(dir, "run")
+	if err := uploadFile(client, scriptPath, []byte(script.Body), 0700); err != nil {
+		return nil, err
+	}
+	if err := uploadFile(client, runPath, []byte(renderWrapper(script, scriptPath)), 0700); err != nil {
+		return nil, err
+	}
+
+	// The command line only holds paths generated here
+	return m.run(ctx, conn, fmt.Sprintf("%s %s", DefaultInterpreter, runPath), m.commandTimeout(target), start)
+}
+
+// createScriptDir creates a uniquely named directory only the SSH user can
+// access
+func (m *Manager) createScriptDir(client *sftp.Client) (string, error) {
+	base := m.config.ScriptDir
+	if base == "" {
+		base = defaultScriptDir
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate script directory name: %w", err)
+	}
+	dir := path.Join(base, "app-env-manager-"+hex.EncodeToString(suffix))
+
+	// Mkdir fails if the path exists, so nobody can plant a directory first
+	if err := client.Mkdir(dir); err != nil {
+		return "", fmt.Errorf("failed to create script directory: %w", err)
+	}
+	if err := client.Chmod(dir, 0700); err != nil {
+		_ = client.RemoveDirectory(dir)
+		return "", fmt.Errorf("failed to restrict script directory: %w", err)
+	}
+	return dir, nil
+}
+
+// uploadFile writes a new file with the given permissions
+func uploadFile(client *sftp.Client, name string, data []byte, perm os.FileMode) error {
+	file, err := client.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	if err != nil {
+		return fmt.Errorf("failed to upload script: %w", err)
+	}
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to restrict script permissions: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to upload script: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to upload script: %w", err)
+	}
+	return nil
+}
+
+// removeScriptDir removes the uploaded files and their directory
+func removeScriptDir(client *sftp.Client, dir string) {
+	entries, err := client.ReadDir(dir)
+	if err == nil {
+		for _, entry := range entries {
+			_ = client.Remove(path.Join(dir, entry.Name()))
+		}
+	}
+	_ = client.RemoveDirectory(dir)
+}
+
+// renderWrapper renders the POSIX shell wrapper that exports the script's
+// environment and hands over to the interpreter
+func renderWrapper(script Script, scriptPath string) string {
+	interpreter := script.Interpreter
+	if interpreter == "" {
+		interpreter = DefaultInterpreter
+	}
+
+	names := make([]string, 0, len(script.Env))
+	for name := range script.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(script.Env[name]))
+	}
+	fmt.Fprintf(&b, "exec %s %s\n", interpreter, shellQuote(scriptPath))
+	return b.String()
+}
+
+// shellQuote quotes a value for a POSIX shell
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,121 @@
+package ssh_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newScriptManager(t *testing.T) (*ssh.Manager, string) {
+	t.Helper()
+	dir := t.TempDir()
+	manager := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: 5 * time.Second,
+		CommandTimeout:    10 * time.Second,
+		MaxConnections:    10,
+		ScriptDir:         dir,
+	})
+	t.Cleanup(func() { manager.Close() })
+	return manager, dir
+}
+
+func TestManager_ExecuteScript_RunsAndCleansUp(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager, dir := newScriptManager(t)
+
+	script := ssh.Script{
+		Body: "set -e\nfor n in 1 2; do echo \"step $n\"; done\n" +
+			"echo \"version=$VERSION\" | tr a-z A-Z\n" +
+			"[ \"$(stat -c %a \"$0\")\" = 700 ] && echo private\n",
+		Env: map[string]string{"VERSION": "1.2.3; rm -rf /"},
+	}
+	result, err := manager.ExecuteScript(context.Background(), server.Target(), script)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "step 1\nstep 2\nVERSION=1.2.3; RM -RF /\nprivate\n", result.Output)
+
+	// The command line only references generated paths
+	require.Len(t, server.Commands(), 1)
+	command := server.Commands()[0]
+	assert.Regexp(t, `^/bin/sh /.+/app-env-manager-[0-9a-f]{16}/run$`, command)
+	assert.NotContains(t, command, "1.2.3")
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "uploaded files are removed")
+}
+
+func TestManager_ExecuteScript_Interpreter(t *testing.T) {
+	if _, err := os.Stat("/bin/bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	server := sshtest.NewServer(t)
+	manager, _ := newScriptManager(t)
+
+	script := ssh.Script{
+		Body:        "words=(a b c)\necho \"${#words[@]} $NAME\"\n",
+		Interpreter: "/bin/bash",
+		Env:         map[string]string{"NAME": "it's quoted"},
+	}
+	result, err := manager.ExecuteScript(context.Background(), server.Target(), script)
+	require.NoError(t, err)
+	assert.Equal(t, "3 it's quoted\n", result.Output)
+}
+
+func TestManager_ExecuteScript_ExitCode(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager, dir := newScriptManager(t)
+
+	result, err := manager.ExecuteScript(context.Background(), server.Target(), ssh.Script{Body: "echo failing\nexit 3\n"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "failing\n", result.Output)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestManager_ExecuteScript_UploadFailure(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: 5 * time.Second,
+		CommandTimeout:    10 * time.Second,
+		MaxConnections:    10,
+		ScriptDir:         filepath.Join(t.TempDir(), "missing"),
+	})
+	defer manager.Close()
+
+	_, err := manager.ExecuteScript(context.Background(), server.Target(), ssh.Script{Body: "echo hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create script directory")
+}
+
+func TestManager_ExecuteScript_Invalid(t *testing.T) {
+	manager := ssh.NewManager(ssh.Config{})
+	defer manager.Close()
+
+	_, err := manager.ExecuteScript(context.Background(), ssh.Target{}, ssh.Script{Body: "  "})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid script")
+}
+
+func TestValidateScript(t *testing.T) {
+	assert.NoError(t, ssh.ValidateScript(ssh.Script{Body: "echo ok"}))
+	assert.NoError(t, ssh.ValidateScript(ssh.Script{Body: "print(1)", Interpreter: "/usr/bin/python3"}))
+
+	assert.Error(t, ssh.ValidateScript(ssh.Script{Body: ""}))
+	assert.Error(t, ssh.ValidateScript(ssh.Script{Body: "echo \x00"}))
+	assert.Error(t, ssh.ValidateScript(ssh.Script{Body: "echo", Interpreter: "bash"}))
+	assert.Error(t, ssh.ValidateScript(ssh.Script{Body: "echo", Interpreter: "/bin/sh -c 'id'"}))
+	assert.Error(t, ssh.ValidateScript(ssh.Script{Body: "echo", Env: map[string]string{"BAD-NAME": "x"}}))
+	assert.Error(t, ssh.ValidateScript(ssh.Script{Body: "echo", Env: map[string]string{"OK": "a\x00b"}}))
+}
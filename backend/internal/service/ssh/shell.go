@@ -0,0 +1,167 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Default pseudo-terminal settings
+const (
+	DefaultTerm = "xterm-256color"
+	DefaultCols = 80
+	DefaultRows = 24
+)
+
+// PTY describes the pseudo-terminal requested for a shell
+type PTY struct {
+	Term string
+	Cols int
+	Rows int
+}
+
+// withDefaults fills in unset fields
+func (p PTY) withDefaults() PTY {
+	if p.Term == "" {
+		p.Term = DefaultTerm
+	}
+	if p.Cols <= 0 {
+		p.Cols = DefaultCols
+	}
+	if p.Rows <= 0 {
+		p.Rows = DefaultRows
+	}
+	return p
+}
+
+// Shell is an interactive shell on a remote host. Reads return the terminal
+// output, writes send keystrokes.
+type Shell struct {
+	session *ssh.Session
+	stdin   io.WriteCloser
+	output  *io.PipeReader
+	done    chan struct{}
+	err     error
+
+	closeOnce sync.Once
+	release   func()
+}
+
+// OpenShell starts a login shell on a pseudo-terminal. The shell holds its
+// pooled connection until it is closed.
+func (m *Manager) OpenShell(ctx context.Context, target Target, pty PTY) (*Shell, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	pty = pty.withDefaults()
+
+	conn, err := m.getConnection(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get SSH connection: %w", err)
+	}
+	release := func() { m.releaseConnection(target) }
+
+	session, err := conn.client.NewSession()
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("failed to create SSH session: %w", err)
+	}
+	fail := func(format string, err error) (*Shell, error) {
+		session.Close()
+		release()
+		return nil, fmt.Errorf(format, err)
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := session.RequestPty(pty.Term, pty.Rows, pty.Cols, modes); err != nil {
+		return fail("failed to request pseudo-terminal: %w", err)
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return fail("failed to open shell input: %w", err)
+	}
+
+	// A pseudo-terminal merges both streams; servers that still send stderr
+	// separately are interleaved in arrival order
+	reader, writer := io.Pipe()
+	session.Stdout = writer
+	session.Stderr = writer
+	if err := session.Shell(); err != nil {
+		return fail("failed to start shell: %w", err)
+	}
+
+	shell := &Shell{
+		session: session,
+		stdin:   stdin,
+		output:  reader,
+		done:    make(chan struct{}),
+		release: release,
+	}
+	go func() {
+		shell.err = session.Wait()
+		writer.Close()
+		close(shell.done)
+	}()
+	return shell, nil
+}
+
+// Read reads terminal output. It returns io.EOF once the shell has exited.
+func (s *Shell) Read(p []byte) (int, error) {
+	return s.output.Read(p)
+}
+
+// Write sends input to the shell
+func (s *Shell) Write(p []byte) (int, error) {
+	return s.stdin.Write(p)
+}
+
+// Resize changes the size of the pseudo-terminal
+func (s *Shell) Resize(cols, rows int) error {
+	if cols <= 0 || rows <= 0 {
+		return fmt.Errorf("invalid terminal size %dx%d", cols, rows)
+	}
+	return s.session.WindowChange(rows, cols)
+}
+
+// Done is closed when the shell has exited
+func (s *Shell) Done() <-chan struct{} {
+	return s.done
+}
+
+// ExitCode returns the shell's exit status once it is done, or -1 if it did
+// not report one
+func (s *Shell) ExitCode() int {
+	select {
+	case <-s.done:
+	default:
+		return -1
+	}
+	if s.err == nil {
+		return 0
+	}
+	if exitErr, ok := s.err.(*ssh.ExitError); ok {
+		return exitErr.ExitStatus()
+	}
+	return -1
+}
+
+// Close ends the session and releases the connection
+func (s *Shell) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.session.Close()
+		s.output.Close()
+		s.release()
+	})
+	if err == io.EOF {
+		err = nil
+	}
+	return err
+}
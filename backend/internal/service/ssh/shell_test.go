@@ -0,0 +1,121 @@
+package ssh_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newShellManager(t *testing.T) *ssh.Manager {
+	t.Helper()
+	manager := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: 5 * time.Second,
+		CommandTimeout:    10 * time.Second,
+		MaxConnections:    10,
+	})
+	t.Cleanup(func() { manager.Close() })
+	return manager
+}
+
+// readUntil reads shell output until it contains want
+func readUntil(t *testing.T, r io.Reader, want string) string {
+	t.Helper()
+	found := make(chan string, 1)
+	go func() {
+		var out strings.Builder
+		reader := bufio.NewReader(r)
+		for {
+			b, err := reader.ReadByte()
+			if err != nil {
+				found <- out.String()
+				return
+			}
+			out.WriteByte(b)
+			if strings.Contains(out.String(), want) {
+				found <- out.String()
+				return
+			}
+		}
+	}()
+	select {
+	case out := <-found:
+		require.Contains(t, out, want)
+		return out
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for %q", want)
+		return ""
+	}
+}
+
+func TestManager_OpenShell(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := newShellManager(t)
+
+	shell, err := manager.OpenShell(context.Background(), server.Target(), ssh.PTY{Term: "xterm", Cols: 120, Rows: 40})
+	require.NoError(t, err)
+	defer shell.Close()
+
+	_, err = shell.Write([]byte("echo \"term=$TERM\"\n"))
+	require.NoError(t, err)
+	readUntil(t, shell, "term=xterm\n")
+
+	require.NoError(t, shell.Resize(100, 30))
+	_, err = shell.Write([]byte("exit 4\n"))
+	require.NoError(t, err)
+
+	select {
+	case <-shell.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("shell did not exit")
+	}
+	assert.Equal(t, 4, shell.ExitCode())
+
+	_, err = io.ReadAll(shell)
+	assert.NoError(t, err, "output ends with EOF")
+	// Window changes need no reply, so the server may record them late
+	assert.Eventually(t, func() bool { return len(server.Windows()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []sshtest.Window{
+		{Term: "xterm", Cols: 120, Rows: 40},
+		{Cols: 100, Rows: 30},
+	}, server.Windows())
+}
+
+func TestManager_OpenShell_Defaults(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := newShellManager(t)
+
+	shell, err := manager.OpenShell(context.Background(), server.Target(), ssh.PTY{})
+	require.NoError(t, err)
+	assert.Equal(t, -1, shell.ExitCode(), "still running")
+	require.NoError(t, shell.Close())
+	require.NoError(t, shell.Close(), "closing twice is harmless")
+
+	assert.Equal(t, []sshtest.Window{{Term: ssh.DefaultTerm, Cols: ssh.DefaultCols, Rows: ssh.DefaultRows}}, server.Windows())
+	assert.Error(t, shell.Resize(0, 10))
+}
+
+func TestManager_OpenShell_ConnectionFailure(t *testing.T) {
+	manager := newShellManager(t)
+
+	target := ssh.Target{Host: "127.0.0.1", Port: 1, Username: "u", Password: "p", InsecureSkipHostKeyVerify: true}
+	_, err := manager.OpenShell(context.Background(), target, ssh.PTY{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get SSH connection")
+}
+
+func TestManager_OpenShell_CancelledContext(t *testing.T) {
+	manager := newShellManager(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := manager.OpenShell(ctx, ssh.Target{}, ssh.PTY{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,321 @@
+// Package sshtest provides an in-process SSH server for tests. It serves
+// SFTP from the local filesystem and runs exec requests with the local
+// shell, so commands and uploaded scripts really run. Shell requests run a
+// non-interactive /bin/sh reading the channel; pseudo-terminal sizes are only
+// recorded. Unix socket forwarding connects to local sockets.
+package sshtest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"sync"
+	"syscall"
+	"testing"
+
+	"app-env-manager/internal/service/ssh"
+	"github.com/pkg/sftp"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Credentials accepted by the server
+const (
+	Username = "testuser"
+	Password = "testpass"
+)
+
+// Server is a running test SSH server
+type Server struct {
+	listener net.Listener
+	hostKey  gossh.Signer
+
+	mu          sync.Mutex
+	commands    []string
+	windows     []Window
+	connections int
+}
+
+// Window is a terminal size a client requested, with pty-req or
+// window-change
+type Window struct {
+	Term string // set for pty-req only
+	Cols int
+	Rows int
+}
+
+// NewServer starts a server that is stopped when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate host key: %v", err)
+	}
+	signer, err := gossh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("create host key signer: %v", err)
+	}
+
+	config := &gossh.ServerConfig{
+		PasswordCallback: func(c gossh.ConnMetadata, pass []byte) (*gossh.Permissions, error) {
+			if c.User() == Username && string(pass) == Password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected for %q", c.User())
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &Server{listener: listener, hostKey: signer}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn, config)
+		}
+	}()
+	return server
+}
+
+// Target returns a target that connects to the server
+func (s *Server) Target() ssh.Target {
+	return ssh.Target{
+		Host:     "127.0.0.1",
+		Port:     s.Port(),
+		Username: Username,
+		Password: Password,
+		HostKey:  s.HostKey(),
+	}
+}
+
+// Port returns the port the server listens on
+func (s *Server) Port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+// HostKey returns the server's public host key in authorized_keys format
+func (s *Server) HostKey() []byte {
+	return gossh.MarshalAuthorizedKey(s.hostKey.PublicKey())
+}
+
+// Commands returns the exec commands received so far
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Connections returns how many SSH connections were established so far
+func (s *Server) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// Windows returns the terminal sizes requested so far
+func (s *Server) Windows() []Window {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Window(nil), s.windows...)
+}
+
+func (s *Server) handle(conn net.Conn, config *gossh.ServerConfig) {
+	defer conn.Close()
+	sshConn, chans, reqs, err := gossh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	defer sshConn.Close()
+	s.mu.Lock()
+	s.connections++
+	s.mu.Unlock()
+	go gossh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				continue
+			}
+			go s.serveSession(channel, requests)
+		case "direct-streamlocal@openssh.com":
+			go forwardUnix(newChannel)
+		default:
+			newChannel.Reject(gossh.UnknownChannelType, "unknown channel type")
+		}
+	}
+}
+
+// forwardUnix connects a direct-streamlocal channel to the local socket it
+// names
+func forwardUnix(newChannel gossh.NewChannel) {
+	path := parseString(newChannel.ExtraData())
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		newChannel.Reject(gossh.ConnectionFailed, err.Error())
+		return
+	}
+	defer conn.Close()
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		return
+	}
+	defer channel.Close()
+	go gossh.DiscardRequests(requests)
+
+	done := make(chan struct{})
+	go func() {
+		io.Copy(conn, channel)
+		conn.(*net.UnixConn).CloseWrite()
+		close(done)
+	}()
+	io.Copy(channel, conn)
+	channel.CloseWrite()
+	<-done
+}
+
+func (s *Server) serveSession(channel gossh.Channel, requests <-chan *gossh.Request) {
+	defer channel.Close()
+	var env []string
+	for req := range requests {
+		switch req.Type {
+		case "pty-req":
+			var pty struct {
+				Term          string
+				Cols, Rows    uint32
+				Width, Height uint32
+				Modes         string
+			}
+			if err := gossh.Unmarshal(req.Payload, &pty); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			s.addWindow(Window{Term: pty.Term, Cols: int(pty.Cols), Rows: int(pty.Rows)})
+			env = append(env, "TERM="+pty.Term)
+			req.Reply(true, nil)
+		case "window-change":
+			var size struct {
+				Cols, Rows    uint32
+				Width, Height uint32
+			}
+			if err := gossh.Unmarshal(req.Payload, &size); err == nil {
+				s.addWindow(Window{Cols: int(size.Cols), Rows: int(size.Rows)})
+			}
+			if req.WantReply {
+				req.Reply(true, nil)
+			}
+		case "shell":
+			req.Reply(true, nil)
+			go runShell(channel, env)
+		case "subsystem":
+			if parseString(req.Payload) != "sftp" {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			server, err := sftp.NewServer(channel)
+			if err != nil {
+				return
+			}
+			_ = server.Serve()
+			return
+		case "exec":
+			command := parseString(req.Payload)
+			s.mu.Lock()
+			s.commands = append(s.commands, command)
+			s.mu.Unlock()
+
+			// The command runs in its own process group so that everything
+			// it started is killed when the client goes away
+			cmd := exec.Command("/bin/sh", "-c", command)
+			cmd.Stdout = channel
+			cmd.Stderr = channel.Stderr()
+			cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+			if err := cmd.Start(); err != nil {
+				req.Reply(false, nil)
+				continue
+			}
+			req.Reply(true, nil)
+			defer syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+			go func() {
+				sendExitStatus(channel, exitStatus(cmd.Wait()))
+				channel.Close()
+			}()
+		case "signal":
+			// Commands are killed when the channel closes
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+func (s *Server) addWindow(window Window) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.windows = append(s.windows, window)
+}
+
+// runShell runs a shell fed from the channel until it exits
+func runShell(channel gossh.Channel, env []string) {
+	defer channel.Close()
+	cmd := exec.Command("/bin/sh")
+	cmd.Env = append([]string{"PS1=$ "}, env...)
+	cmd.Stdout = channel
+	cmd.Stderr = channel.Stderr()
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		sendExitStatus(channel, 127)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		sendExitStatus(channel, 127)
+		return
+	}
+	go func() {
+		io.Copy(stdin, channel)
+		stdin.Close()
+	}()
+	sendExitStatus(channel, exitStatus(cmd.Wait()))
+}
+
+// exitStatus converts a command error into an exit status
+func exitStatus(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode()
+	}
+	return 127
+}
+
+// parseString reads an SSH string from a request payload
+func parseString(payload []byte) string {
+	if len(payload) < 4 {
+		return ""
+	}
+	length := binary.BigEndian.Uint32(payload)
+	if int(length) > len(payload)-4 {
+		return ""
+	}
+	return string(payload[4 : 4+length])
+}
+
+// sendExitStatus reports a command's exit status to the client
+func sendExitStatus(channel gossh.Channel, status int) {
+	payload := make([]byte, 4)
+	binary.BigEndian.PutUint32(payload, uint32(status))
+	channel.SendRequest("exit-status", false, payload)
+}
@@ -0,0 +1,53 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Stream runs a long-running command, such as tail -F, and copies its output
+// to w until the command exits or the context is cancelled. Unlike Execute no
+// command timeout applies. Nothing is written to w after Stream returns.
+func (m *Manager) Stream(ctx context.Context, target Target, command string, w io.Writer) error {
+	if err := validateCommand(command); err != nil {
+		return fmt.Errorf("invalid command: %w", err)
+	}
+
+	conn, err := m.getConnection(target)
+	if err != nil {
+		return fmt.Errorf("failed to get SSH connection: %w", err)
+	}
+	defer m.releaseConnection(target)
+
+	session, err := conn.client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to create SSH session: %w", err)
+	}
+	defer session.Close()
+
+	session.Stdout = w
+	session.Stderr = w
+	if err := session.Start(command); err != nil {
+		return fmt.Errorf("failed to start command: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// Not every server honours signals; closing the session ends the
+		// command either way
+		session.Signal(ssh.SIGTERM)
+		session.Close()
+		<-done
+		return ctx.Err()
+	}
+}
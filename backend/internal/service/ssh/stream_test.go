@@ -0,0 +1,72 @@
+package ssh_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Stream_UntilCancelled(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := newShellManager(t)
+
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(logFile, []byte("old\n"), 0o644))
+
+	reader, writer := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Stream(ctx, server.Target(), "tail -n 1 -F "+logFile, writer)
+		writer.Close()
+	}()
+
+	readUntil(t, reader, "old\n")
+	f, err := os.OpenFile(logFile, os.O_APPEND|os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString("new line\n")
+	require.NoError(t, err)
+	f.Close()
+	readUntil(t, reader, "new line\n")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not stop")
+	}
+}
+
+func TestManager_Stream_CommandExits(t *testing.T) {
+	server := sshtest.NewServer(t)
+	manager := newShellManager(t)
+
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- manager.Stream(context.Background(), server.Target(), "echo one", writer)
+		writer.Close()
+	}()
+
+	output, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "one\n", string(output))
+	assert.NoError(t, <-done)
+}
+
+func TestManager_Stream_InvalidCommand(t *testing.T) {
+	manager := newShellManager(t)
+
+	err := manager.Stream(context.Background(), ssh.Target{}, "tail -F /var/log/app.log; id", io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid command")
+}
@@ -0,0 +1,117 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// asciicast v2 event codes
+const (
+	eventOutput = "o"
+	eventInput  = "i"
+	eventResize = "r"
+)
+
+// Header is the first line of an asciicast v2 recording
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Recorder writes a terminal session as an asciicast v2 recording: a header
+// line followed by one [time, code, data] line per event. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+	err   error
+
+	// Incomplete UTF-8 sequences held back until the next chunk
+	pending map[string][]byte
+}
+
+// NewRecorder writes the header and returns a recorder timing events from
+// the header's timestamp
+func NewRecorder(w io.Writer, header Header, start time.Time) (*Recorder, error) {
+	header.Version = 2
+	header.Timestamp = start.Unix()
+	line, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", line); err != nil {
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+	return &Recorder{w: w, start: start, pending: map[string][]byte{}}, nil
+}
+
+// Output records terminal output
+func (r *Recorder) Output(p []byte) {
+	r.text(eventOutput, p)
+}
+
+// Input records keystrokes
+func (r *Recorder) Input(p []byte) {
+	r.text(eventInput, p)
+}
+
+// Resize records a terminal size change
+func (r *Recorder) Resize(cols, rows int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.write(eventResize, fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// Err returns the first write error. Recording stops at that point.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// text records a chunk of a stream. Chunks may split multi-byte characters,
+// so an incomplete tail is kept for the stream's next chunk.
+func (r *Recorder) text(code string, p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := append(r.pending[code], p...)
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	r.pending[code] = append([]byte(nil), data[cut:]...)
+	if cut > 0 {
+		r.write(code, string(data[:cut]))
+	}
+}
+
+// write appends an event line; callers hold the lock
+func (r *Recorder) write(code, data string) {
+	if r.err != nil {
+		return
+	}
+	elapsed := time.Since(r.start).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, code, data})
+	if err != nil {
+		r.err = err
+		return
+	}
+	if _, err := fmt.Fprintf(r.w, "%s\n", line); err != nil {
+		r.err = fmt.Errorf("failed to write recording: %w", err)
+	}
+}
@@ -0,0 +1,90 @@
+package terminal_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/service/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// castLines parses a recording into its header and events
+func castLines(t *testing.T, data []byte) (terminal.Header, [][]interface{}) {
+	t.Helper()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	require.True(t, scanner.Scan(), "recording has a header")
+	var header terminal.Header
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &header))
+
+	var events [][]interface{}
+	for scanner.Scan() {
+		var event []interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.Len(t, event, 3)
+		events = append(events, event)
+	}
+	return header, events
+}
+
+func TestRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Unix(1700000000, 0)
+	recorder, err := terminal.NewRecorder(&buf, terminal.Header{Width: 100, Height: 30, Title: "staging"}, start)
+	require.NoError(t, err)
+
+	recorder.Input([]byte("ls\r"))
+	recorder.Output([]byte("a.txt\r\n"))
+	recorder.Resize(120, 40)
+	require.NoError(t, recorder.Err())
+
+	header, events := castLines(t, buf.Bytes())
+	assert.Equal(t, terminal.Header{Version: 2, Width: 100, Height: 30, Timestamp: 1700000000, Title: "staging"}, header)
+	require.Len(t, events, 3)
+	assert.Equal(t, []interface{}{"i", "ls\r"}, events[0][1:])
+	assert.Equal(t, []interface{}{"o", "a.txt\r\n"}, events[1][1:])
+	assert.Equal(t, []interface{}{"r", "120x40"}, events[2][1:])
+	assert.IsType(t, float64(0), events[0][0])
+}
+
+func TestRecorder_SplitCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	recorder, err := terminal.NewRecorder(&buf, terminal.Header{Width: 80, Height: 24}, time.Now())
+	require.NoError(t, err)
+
+	euro := []byte("€") // three bytes
+	recorder.Output([]byte{'x', euro[0]})
+	recorder.Output(euro[1:2])
+	recorder.Input([]byte("y"))
+	recorder.Output(euro[2:])
+
+	_, events := castLines(t, buf.Bytes())
+	require.Len(t, events, 3)
+	assert.Equal(t, []interface{}{"o", "x"}, events[0][1:])
+	assert.Equal(t, []interface{}{"i", "y"}, events[1][1:])
+	assert.Equal(t, []interface{}{"o", "€"}, events[2][1:])
+}
+
+type failingWriter struct{ after int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.after == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.after--
+	return len(p), nil
+}
+
+func TestRecorder_WriteError(t *testing.T) {
+	_, err := terminal.NewRecorder(&failingWriter{}, terminal.Header{}, time.Now())
+	assert.Error(t, err)
+
+	recorder, err := terminal.NewRecorder(&failingWriter{after: 1}, terminal.Header{}, time.Now())
+	require.NoError(t, err)
+	recorder.Output([]byte("lost"))
+	assert.ErrorContains(t, recorder.Err(), "disk full")
+}
@@ -0,0 +1,378 @@
+// Package terminal runs interactive SSH sessions for the browser terminal and
+// records them for replay.
+package terminal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/ssh"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reasons a session ends
+const (
+	ReasonClientClosed = "client_closed"
+	ReasonShellExited  = "shell_exited"
+	ReasonIdleTimeout  = "idle_timeout"
+	ReasonMaxDuration  = "max_duration"
+	ReasonShutdown     = "shutdown"
+)
+
+// Client event types
+const (
+	EventInput  = "input"
+	EventResize = "resize"
+)
+
+// Defaults for unset configuration
+const (
+	DefaultIdleTimeout  = 15 * time.Minute
+	DefaultMaxDuration  = time.Hour
+	DefaultRecordingDir = "recordings"
+)
+
+// recordingPath is where the API serves a session's recording
+const recordingPath = "/api/v1/terminal-sessions/%s/recording"
+
+var sessionIDPattern = regexp.MustCompile(`^ts-[0-9a-f]{24}$`)
+
+// Config contains terminal settings
+type Config struct {
+	MinRole      entities.UserRole // Minimum role allowed to open a terminal
+	IdleTimeout  time.Duration     // Sessions without input are closed after this long
+	MaxDuration  time.Duration     // Sessions are closed after this long regardless of activity
+	RecordingDir string            // Local directory for asciicast recordings
+}
+
+// TargetResolver builds the SSH target for an environment's credentials
+type TargetResolver interface {
+	SSHTarget(env *entities.Environment) (*ssh.Target, error)
+}
+
+// Size is a terminal size in characters
+type Size struct {
+	Cols int
+	Rows int
+}
+
+// Event is a message from the browser
+type Event struct {
+	Type string `json:"type"` // "input" or "resize"
+	Data string `json:"data,omitempty"`
+	Cols int    `json:"cols,omitempty"`
+	Rows int    `json:"rows,omitempty"`
+}
+
+// Client is the browser side of a session
+type Client interface {
+	// Next blocks until the next event from the browser
+	Next() (Event, error)
+	// Send delivers terminal output to the browser
+	Send(p []byte) error
+}
+
+// Service opens and supervises terminal sessions
+type Service struct {
+	envRepo    interfaces.EnvironmentRepository
+	auditRepo  interfaces.AuditLogRepository
+	sshManager *ssh.Manager
+	targets    TargetResolver
+	config     Config
+}
+
+// NewService creates a new terminal service
+func NewService(
+	envRepo interfaces.EnvironmentRepository,
+	auditRepo interfaces.AuditLogRepository,
+	sshManager *ssh.Manager,
+	targets TargetResolver,
+	config Config,
+) *Service {
+	if config.MinRole == "" {
+		config.MinRole = entities.UserRoleAdmin
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = DefaultIdleTimeout
+	}
+	if config.MaxDuration <= 0 {
+		config.MaxDuration = DefaultMaxDuration
+	}
+	if config.RecordingDir == "" {
+		config.RecordingDir = DefaultRecordingDir
+	}
+	return &Service{
+		envRepo:    envRepo,
+		auditRepo:  auditRepo,
+		sshManager: sshManager,
+		targets:    targets,
+		config:     config,
+	}
+}
+
+// Session is an open terminal session
+type Session struct {
+	ID string
+
+	service  *Service
+	env      *entities.Environment
+	shell    *ssh.Shell
+	file     *os.File
+	recorder *Recorder
+	started  time.Time
+	bytesIn  atomic.Int64
+	bytesOut atomic.Int64
+}
+
+// Open authorizes the caller and starts a shell on the environment's host.
+// The session is recorded from the first byte.
+func (s *Service) Open(ctx context.Context, envID string, size Size) (*Session, error) {
+	role := entities.UserRole(ctxutil.RoleFromContext(ctx))
+	if !role.AtLeast(s.config.MinRole) {
+		return nil, errors.ErrForbidden
+	}
+
+	env, err := s.envRepo.GetByID(ctx, envID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.targets.SSHTarget(env)
+	if err != nil {
+		return nil, errors.NewValidationError("credentials", err.Error())
+	}
+
+	session := &Session{
+		ID:      fmt.Sprintf("ts-%s", primitive.NewObjectID().Hex()),
+		service: s,
+		env:     env,
+		started: time.Now(),
+	}
+
+	if err := os.MkdirAll(s.config.RecordingDir, 0o700); err != nil {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to create recording directory: %w", err))
+	}
+	file, err := os.OpenFile(s.recordingFile(session.ID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, errors.NewInternalError(fmt.Errorf("failed to create recording: %w", err))
+	}
+
+	shell, err := s.sshManager.OpenShell(ctx, *target, ssh.PTY{Cols: size.Cols, Rows: size.Rows})
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		s.audit(ctx, session, entities.SeverityWarning, "failed", 0, map[string]interface{}{
+			"sessionId": session.ID,
+			"error":     err.Error(),
+		})
+		return nil, errors.DomainError{
+			Code:    errors.ErrSSHConnectionFailed.Code,
+			Message: errors.ErrSSHConnectionFailed.Message,
+			Details: map[string]interface{}{"error": err.Error()},
+		}
+	}
+
+	header := Header{Width: size.Cols, Height: size.Rows, Title: env.Name, Env: map[string]string{"TERM": ssh.DefaultTerm}}
+	if header.Width <= 0 {
+		header.Width = ssh.DefaultCols
+	}
+	if header.Height <= 0 {
+		header.Height = ssh.DefaultRows
+	}
+	recorder, err := NewRecorder(file, header, session.started)
+	if err != nil {
+		shell.Close()
+		file.Close()
+		os.Remove(file.Name())
+		return nil, errors.NewInternalError(err)
+	}
+
+	session.shell = shell
+	session.file = file
+	session.recorder = recorder
+	s.audit(ctx, session, entities.SeverityInfo, "started", 0, session.links())
+	return session, nil
+}
+
+// OpenRecording opens a session's recording for reading
+func (s *Service) OpenRecording(id string) (*os.File, error) {
+	if !sessionIDPattern.MatchString(id) {
+		return nil, errors.ErrRecordingNotFound
+	}
+	file, err := os.Open(s.recordingFile(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrRecordingNotFound
+		}
+		return nil, errors.NewInternalError(err)
+	}
+	return file, nil
+}
+
+func (s *Service) recordingFile(id string) string {
+	return filepath.Join(s.config.RecordingDir, id+".cast")
+}
+
+// Serve relays the session between the browser and the shell until either
+// side closes it or a timeout expires. It closes the shell, finishes the
+// recording and returns why the session ended.
+func (sess *Session) Serve(ctx context.Context, client Client) string {
+	ended := make(chan string, 2)
+	activity := make(chan struct{}, 1)
+
+	outputDone := make(chan struct{})
+	go func() {
+		defer close(outputDone)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := sess.shell.Read(buf)
+			if n > 0 {
+				sess.bytesOut.Add(int64(n))
+				sess.recorder.Output(buf[:n])
+				if sendErr := client.Send(buf[:n]); sendErr != nil {
+					ended <- ReasonClientClosed
+					return
+				}
+			}
+			if err != nil {
+				ended <- ReasonShellExited
+				return
+			}
+		}
+	}()
+
+	go func() {
+		for {
+			event, err := client.Next()
+			if err != nil {
+				ended <- ReasonClientClosed
+				return
+			}
+			switch event.Type {
+			case EventInput:
+				sess.bytesIn.Add(int64(len(event.Data)))
+				sess.recorder.Input([]byte(event.Data))
+				if _, err := sess.shell.Write([]byte(event.Data)); err != nil {
+					return
+				}
+			case EventResize:
+				if sess.shell.Resize(event.Cols, event.Rows) == nil {
+					sess.recorder.Resize(event.Cols, event.Rows)
+				}
+			default:
+				continue
+			}
+			select {
+			case activity <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	idle := time.NewTimer(sess.service.config.IdleTimeout)
+	defer idle.Stop()
+	deadline := time.NewTimer(sess.service.config.MaxDuration)
+	defer deadline.Stop()
+
+	var reason string
+	for reason == "" {
+		select {
+		case <-activity:
+			idle.Reset(sess.service.config.IdleTimeout)
+		case <-idle.C:
+			reason = ReasonIdleTimeout
+		case <-deadline.C:
+			reason = ReasonMaxDuration
+		case <-ctx.Done():
+			reason = ReasonShutdown
+		case reason = <-ended:
+		}
+	}
+
+	sess.shell.Close()
+	<-outputDone
+	sess.finish(ctx, reason)
+	return reason
+}
+
+// Close ends a session that was never served, for example because the
+// WebSocket upgrade failed
+func (sess *Session) Close(ctx context.Context) {
+	sess.shell.Close()
+	sess.finish(ctx, ReasonClientClosed)
+}
+
+// finish closes the recording and audits the end of the session
+func (sess *Session) finish(ctx context.Context, reason string) {
+	metadata := sess.links()
+	metadata["reason"] = reason
+	metadata["bytesIn"] = sess.bytesIn.Load()
+	metadata["bytesOut"] = sess.bytesOut.Load()
+	if reason == ReasonShellExited {
+		metadata["exitCode"] = sess.shell.ExitCode()
+	}
+	if err := sess.recorder.Err(); err != nil {
+		metadata["recordingError"] = err.Error()
+	}
+	if err := sess.file.Close(); err != nil && metadata["recordingError"] == nil {
+		metadata["recordingError"] = err.Error()
+	}
+
+	severity := entities.SeverityInfo
+	if metadata["recordingError"] != nil {
+		severity = entities.SeverityWarning
+	}
+	sess.service.audit(ctx, sess, severity, "completed", time.Since(sess.started), metadata)
+}
+
+// links identifies the session and where its recording is served
+func (sess *Session) links() map[string]interface{} {
+	return map[string]interface{}{
+		"sessionId": sess.ID,
+		"recording": fmt.Sprintf(recordingPath, sess.ID),
+	}
+}
+
+// audit records a terminal_session audit entry for the caller
+func (s *Service) audit(ctx context.Context, sess *Session, severity entities.Severity, status string,
+	duration time.Duration, metadata map[string]interface{}) {
+
+	actor := entities.Actor{Type: "system", ID: "system", Name: "System"}
+	userID, username := ctxutil.UserFromContext(ctx)
+	if userID != "" {
+		actor = entities.Actor{Type: "user", ID: userID, Name: username}
+	}
+
+	entry := &entities.AuditLog{
+		Timestamp:       time.Now(),
+		EnvironmentID:   sess.env.ID,
+		EnvironmentName: sess.env.Name,
+		Type:            entities.EventTypeTerminalSession,
+		Severity:        severity,
+		Actor:           actor,
+		Action: entities.Action{
+			Operation: "terminal",
+			Status:    status,
+			Duration:  duration.Milliseconds(),
+		},
+		Payload: entities.Payload{
+			Metadata: metadata,
+		},
+		Tags: []string{"terminal"},
+	}
+
+	// Create audit log asynchronously, preserving user context
+	go func() {
+		bgCtx, cancel := context.WithTimeout(ctxutil.WithUser(context.Background(), userID, username), 5*time.Second)
+		defer cancel()
+		_ = s.auditRepo.Create(bgCtx, entry)
+	}()
+}
@@ -0,0 +1,431 @@
+package terminal_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"app-env-manager/internal/ctxutil"
+	"app-env-manager/internal/domain/entities"
+	"app-env-manager/internal/domain/errors"
+	"app-env-manager/internal/repository/interfaces"
+	"app-env-manager/internal/service/ssh"
+	"app-env-manager/internal/service/ssh/sshtest"
+	"app-env-manager/internal/service/terminal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockEnvRepo struct{ mock.Mock }
+
+func (m *mockEnvRepo) Create(ctx context.Context, env *entities.Environment) error {
+	return m.Called(ctx, env).Error(0)
+}
+
+func (m *mockEnvRepo) GetByID(ctx context.Context, id string) (*entities.Environment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) GetByName(ctx context.Context, name string) (*entities.Environment, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) List(ctx context.Context, filter interfaces.ListFilter) ([]*entities.Environment, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entities.Environment), args.Error(1)
+}
+
+func (m *mockEnvRepo) Update(ctx context.Context, id string, env *entities.Environment) error {
+	return m.Called(ctx, id, env).Error(0)
+}
+
+func (m *mockEnvRepo) UpdateStatus(ctx context.Context, id string, status entities.Status) error {
+	return m.Called(ctx, id, status).Error(0)
+}
+
+func (m *mockEnvRepo) Delete(ctx context.Context, id string) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *mockEnvRepo) Count(ctx context.Context, filter interfaces.ListFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// auditRecorder captures audit entries written in the background
+type auditRecorder struct {
+	entries chan *entities.AuditLog
+}
+
+func (a *auditRecorder) Create(ctx context.Context, entry *entities.AuditLog) error {
+	a.entries <- entry
+	return nil
+}
+
+func (a *auditRecorder) GetByID(ctx context.Context, id string) (*entities.AuditLog, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *auditRecorder) List(ctx context.Context, filter interfaces.AuditLogFilter) ([]*entities.AuditLog, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (a *auditRecorder) Count(ctx context.Context, filter interfaces.AuditLogFilter) (int64, error) {
+	return 0, fmt.Errorf("not implemented")
+}
+
+func (a *auditRecorder) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	return 0, fmt.Errorf("not implemented")
+}
+
+func (a *auditRecorder) next(t *testing.T, status string) *entities.AuditLog {
+	t.Helper()
+	select {
+	case entry := <-a.entries:
+		require.Equal(t, status, entry.Action.Status)
+		return entry
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no %s audit entry", status)
+		return nil
+	}
+}
+
+// targetFunc resolves every environment to a fixed target
+type targetFunc func(env *entities.Environment) (*ssh.Target, error)
+
+func (f targetFunc) SSHTarget(env *entities.Environment) (*ssh.Target, error) {
+	return f(env)
+}
+
+// fakeClient is a browser scripted through channels
+type fakeClient struct {
+	events chan terminal.Event
+	closed chan struct{}
+
+	mu     sync.Mutex
+	output strings.Builder
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{events: make(chan terminal.Event, 10), closed: make(chan struct{})}
+}
+
+func (c *fakeClient) Next() (terminal.Event, error) {
+	select {
+	case event := <-c.events:
+		return event, nil
+	case <-c.closed:
+		return terminal.Event{}, io.EOF
+	}
+}
+
+func (c *fakeClient) Send(p []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.output.Write(p)
+	return nil
+}
+
+func (c *fakeClient) Output() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.output.String()
+}
+
+type terminalSetup struct {
+	envRepo *mockEnvRepo
+	audit   *auditRecorder
+	server  *sshtest.Server
+	service *terminal.Service
+	dir     string
+	env     *entities.Environment
+}
+
+func newTerminalSetup(t *testing.T, config terminal.Config) *terminalSetup {
+	t.Helper()
+	server := sshtest.NewServer(t)
+	manager := ssh.NewManager(ssh.Config{
+		ConnectionTimeout: 5 * time.Second,
+		CommandTimeout:    10 * time.Second,
+		MaxConnections:    10,
+	})
+	t.Cleanup(func() { manager.Close() })
+
+	if config.RecordingDir == "" {
+		config.RecordingDir = filepath.Join(t.TempDir(), "recordings")
+	}
+	envRepo := new(mockEnvRepo)
+	audit := &auditRecorder{entries: make(chan *entities.AuditLog, 10)}
+	targets := targetFunc(func(env *entities.Environment) (*ssh.Target, error) {
+		target := server.Target()
+		return &target, nil
+	})
+
+	env := &entities.Environment{ID: primitive.NewObjectID(), Name: "staging"}
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil).Maybe()
+
+	return &terminalSetup{
+		envRepo: envRepo,
+		audit:   audit,
+		server:  server,
+		service: terminal.NewService(envRepo, audit, manager, targets, config),
+		dir:     config.RecordingDir,
+		env:     env,
+	}
+}
+
+func adminContext() context.Context {
+	return ctxutil.WithUserFull(context.Background(), "u1", "alice", "admin")
+}
+
+// serve runs the session in the background and returns its close reason
+func serve(session *terminal.Session, client terminal.Client) <-chan string {
+	reason := make(chan string, 1)
+	go func() { reason <- session.Serve(context.Background(), client) }()
+	return reason
+}
+
+func waitReason(t *testing.T, reason <-chan string) string {
+	t.Helper()
+	select {
+	case r := <-reason:
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatal("session did not end")
+		return ""
+	}
+}
+
+func TestService_Session_RecordsAndAudits(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{})
+
+	session, err := s.service.Open(adminContext(), s.env.ID.Hex(), terminal.Size{Cols: 100, Rows: 30})
+	require.NoError(t, err)
+	assert.Regexp(t, `^ts-[0-9a-f]{24}$`, session.ID)
+
+	started := s.audit.next(t, "started")
+	assert.Equal(t, entities.EventTypeTerminalSession, started.Type)
+	assert.Equal(t, "alice", started.Actor.Name)
+	assert.Equal(t, s.env.ID, started.EnvironmentID)
+	assert.Equal(t, "/api/v1/terminal-sessions/"+session.ID+"/recording", started.Payload.Metadata["recording"])
+
+	client := newFakeClient()
+	reason := serve(session, client)
+	client.events <- terminal.Event{Type: terminal.EventResize, Cols: 120, Rows: 40}
+	client.events <- terminal.Event{Type: terminal.EventInput, Data: "echo hello-$TERM\n"}
+	assert.Eventually(t, func() bool { return strings.Contains(client.Output(), "hello-xterm-256color") },
+		5*time.Second, 10*time.Millisecond)
+	client.events <- terminal.Event{Type: terminal.EventInput, Data: "exit 2\n"}
+
+	assert.Equal(t, terminal.ReasonShellExited, waitReason(t, reason))
+
+	completed := s.audit.next(t, "completed")
+	metadata := completed.Payload.Metadata
+	assert.Equal(t, session.ID, metadata["sessionId"])
+	assert.Equal(t, terminal.ReasonShellExited, metadata["reason"])
+	assert.Equal(t, 2, metadata["exitCode"])
+	assert.Equal(t, int64(len("echo hello-$TERM\nexit 2\n")), metadata["bytesIn"])
+	assert.NotContains(t, metadata, "recordingError")
+
+	recording, err := s.service.OpenRecording(session.ID)
+	require.NoError(t, err)
+	defer recording.Close()
+	data, err := io.ReadAll(recording)
+	require.NoError(t, err)
+
+	header, events := castLines(t, data)
+	assert.Equal(t, 2, header.Version)
+	assert.Equal(t, 100, header.Width)
+	assert.Equal(t, "staging", header.Title)
+
+	var input, output strings.Builder
+	var resized bool
+	for _, event := range events {
+		switch event[1] {
+		case "i":
+			input.WriteString(event[2].(string))
+		case "o":
+			output.WriteString(event[2].(string))
+		case "r":
+			resized = event[2] == "120x40"
+		}
+	}
+	assert.Equal(t, "echo hello-$TERM\nexit 2\n", input.String(), "keystrokes are recorded")
+	assert.Contains(t, output.String(), "hello-xterm-256color")
+	assert.True(t, resized)
+
+	info, err := os.Stat(filepath.Join(s.dir, session.ID+".cast"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestService_Session_IdleTimeout(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{IdleTimeout: 150 * time.Millisecond, MaxDuration: time.Minute})
+
+	session, err := s.service.Open(adminContext(), s.env.ID.Hex(), terminal.Size{})
+	require.NoError(t, err)
+	s.audit.next(t, "started")
+
+	client := newFakeClient()
+	defer close(client.closed)
+	reason := serve(session, client)
+	for i := 0; i < 3; i++ {
+		time.Sleep(75 * time.Millisecond)
+		client.events <- terminal.Event{Type: terminal.EventInput, Data: ":\n"}
+	}
+
+	start := time.Now()
+	assert.Equal(t, terminal.ReasonIdleTimeout, waitReason(t, reason))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, terminal.ReasonIdleTimeout, s.audit.next(t, "completed").Payload.Metadata["reason"])
+}
+
+func TestService_Session_MaxDuration(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{IdleTimeout: time.Minute, MaxDuration: 200 * time.Millisecond})
+
+	session, err := s.service.Open(adminContext(), s.env.ID.Hex(), terminal.Size{})
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	defer close(client.closed)
+	reason := serve(session, client)
+	go func() {
+		for i := 0; i < 10; i++ {
+			client.events <- terminal.Event{Type: terminal.EventInput, Data: ":\n"}
+			time.Sleep(30 * time.Millisecond)
+		}
+	}()
+
+	assert.Equal(t, terminal.ReasonMaxDuration, waitReason(t, reason))
+}
+
+func TestService_Session_ClientClosed(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{})
+
+	session, err := s.service.Open(adminContext(), s.env.ID.Hex(), terminal.Size{})
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	reason := serve(session, client)
+	close(client.closed)
+
+	assert.Equal(t, terminal.ReasonClientClosed, waitReason(t, reason))
+}
+
+func TestService_Open_RoleRestricted(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{})
+
+	ctx := ctxutil.WithUserFull(context.Background(), "u2", "bob", "user")
+	_, err := s.service.Open(ctx, s.env.ID.Hex(), terminal.Size{})
+	assert.Equal(t, errors.ErrForbidden, err)
+
+	_, err = s.service.Open(context.Background(), s.env.ID.Hex(), terminal.Size{})
+	assert.Equal(t, errors.ErrForbidden, err)
+	s.envRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestService_Open_MinRole(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{MinRole: entities.UserRoleUser})
+
+	ctx := ctxutil.WithUserFull(context.Background(), "u2", "bob", "user")
+	session, err := s.service.Open(ctx, s.env.ID.Hex(), terminal.Size{})
+	require.NoError(t, err)
+
+	client := newFakeClient()
+	close(client.closed)
+	assert.Equal(t, terminal.ReasonClientClosed, session.Serve(context.Background(), client))
+}
+
+func TestService_Open_NotFound(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{})
+	s.envRepo.On("GetByID", mock.Anything, "missing").Return(nil, errors.ErrEnvironmentNotFound)
+
+	_, err := s.service.Open(adminContext(), "missing", terminal.Size{})
+	assert.Equal(t, errors.ErrEnvironmentNotFound, err)
+}
+
+func TestService_Open_ConnectionFailed(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "recordings")
+	envRepo := new(mockEnvRepo)
+	audit := &auditRecorder{entries: make(chan *entities.AuditLog, 10)}
+	manager := ssh.NewManager(ssh.Config{ConnectionTimeout: time.Second, MaxConnections: 1})
+	defer manager.Close()
+	targets := targetFunc(func(env *entities.Environment) (*ssh.Target, error) {
+		return &ssh.Target{Host: "127.0.0.1", Port: 1, Username: "u", Password: "p", InsecureSkipHostKeyVerify: true}, nil
+	})
+	service := terminal.NewService(envRepo, audit, manager, targets, terminal.Config{RecordingDir: dir})
+
+	env := &entities.Environment{ID: primitive.NewObjectID(), Name: "staging"}
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+
+	_, err := service.Open(adminContext(), env.ID.Hex(), terminal.Size{})
+	require.Error(t, err)
+	assert.Equal(t, "SSH_CONNECTION_FAILED", err.(errors.DomainError).Code)
+
+	failed := audit.next(t, "failed")
+	assert.Equal(t, entities.SeverityWarning, failed.Severity)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "no recording is left behind")
+}
+
+func TestService_Open_InvalidCredentials(t *testing.T) {
+	envRepo := new(mockEnvRepo)
+	targets := targetFunc(func(env *entities.Environment) (*ssh.Target, error) {
+		return nil, fmt.Errorf("no SSH password configured for environment")
+	})
+	service := terminal.NewService(envRepo, &auditRecorder{}, nil, targets, terminal.Config{RecordingDir: t.TempDir()})
+
+	env := &entities.Environment{ID: primitive.NewObjectID()}
+	envRepo.On("GetByID", mock.Anything, env.ID.Hex()).Return(env, nil)
+
+	_, err := service.Open(adminContext(), env.ID.Hex(), terminal.Size{})
+	require.Error(t, err)
+	assert.Equal(t, "VALIDATION_ERROR", err.(errors.DomainError).Code)
+}
+
+func TestService_OpenRecording_NotFound(t *testing.T) {
+	service := terminal.NewService(nil, nil, nil, nil, terminal.Config{RecordingDir: t.TempDir()})
+
+	_, err := service.OpenRecording("ts-0123456789abcdef01234567")
+	assert.Equal(t, errors.ErrRecordingNotFound, err)
+
+	_, err = service.OpenRecording("../../etc/passwd")
+	assert.Equal(t, errors.ErrRecordingNotFound, err)
+}
+
+func TestService_Session_Close(t *testing.T) {
+	s := newTerminalSetup(t, terminal.Config{})
+
+	session, err := s.service.Open(adminContext(), s.env.ID.Hex(), terminal.Size{})
+	require.NoError(t, err)
+	s.audit.next(t, "started")
+
+	session.Close(adminContext())
+	completed := s.audit.next(t, "completed")
+	assert.Equal(t, terminal.ReasonClientClosed, completed.Payload.Metadata["reason"])
+
+	recording, err := s.service.OpenRecording(session.ID)
+	require.NoError(t, err)
+	recording.Close()
+}
@@ -0,0 +1,36 @@
+package hub_test
+
+import (
+	"testing"
+	"time"
+
+	"app-env-manager/internal/websocket/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_BroadcastApprovalUpdate_OnlyApprovers(t *testing.T) {
+	h, client, dialConn, cleanup := newHubAndClient(t, "approver")
+	defer cleanup()
+	client.Role = "admin"
+
+	h.BroadcastApprovalUpdate("appr-1", []string{"admin"}, map[string]interface{}{"action": "requested"})
+
+	var msg hub.Message
+	require.NoError(t, dialConn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, dialConn.ReadJSON(&msg))
+	assert.Equal(t, "approval_update", msg.Type)
+	assert.Equal(t, "appr-1", msg.Payload["approvalId"])
+}
+
+func TestHub_BroadcastApprovalUpdate_SkipsOtherRoles(t *testing.T) {
+	h, client, dialConn, cleanup := newHubAndClient(t, "viewer")
+	defer cleanup()
+	client.Role = "viewer"
+
+	h.BroadcastApprovalUpdate("appr-1", []string{"admin"}, map[string]interface{}{"action": "requested"})
+
+	var msg hub.Message
+	require.NoError(t, dialConn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+	assert.Error(t, dialConn.ReadJSON(&msg))
+}
@@ -199,6 +199,24 @@ func TestHub_BroadcastOperationUpdate_WithClient(t *testing.T) {
 	}
 }
 
+// TestHub_BroadcastRolloutUpdate verifies that every client receives rollout
+// updates.
+func TestHub_BroadcastRolloutUpdate(t *testing.T) {
+	h, _, dialConn, cleanup := newHubAndClient(t, "viewer")
+	defer cleanup()
+
+	h.BroadcastRolloutUpdate("ro-1", map[string]interface{}{"status": "paused", "currentBatch": 1})
+
+	var msg hub.Message
+	require.NoError(t, dialConn.SetReadDeadline(time.Now().Add(time.Second)))
+	require.NoError(t, dialConn.ReadJSON(&msg))
+	assert.Equal(t, "rollout_update", msg.Type)
+	assert.Equal(t, "ro-1", msg.Payload["rolloutId"])
+	update, ok := msg.Payload["update"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "paused", update["status"])
+}
+
 // TestHub_BroadcastEnvironmentUpdate_NotSubscribed verifies that a client not
 // subscribed to an env does not receive the update.
 func TestHub_BroadcastEnvironmentUpdate_NotSubscribed(t *testing.T) {
@@ -242,3 +260,28 @@ func TestClient_UnknownMessageType2(t *testing.T) {
 	assert.NoError(t, err)
 	time.Sleep(60 * time.Millisecond)
 }
+
+func TestHub_BroadcastEnvironmentStatus_DeliversStatus(t *testing.T) {
+	h, client, dialConn, cleanup := newHubAndClient(t, "status-client")
+	defer cleanup()
+
+	require.NoError(t, dialConn.WriteJSON(hub.Message{
+		Type:    "subscribe",
+		Payload: map[string]interface{}{"environments": []string{"env-D"}},
+	}))
+	require.Eventually(t, func() bool { return client.IsSubscribedTo("env-D") }, time.Second, 10*time.Millisecond)
+
+	h.BroadcastEnvironmentStatus("env-D", map[string]interface{}{"health": "degraded", "failedProbe": "worker"})
+
+	dialConn.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		var msg hub.Message
+		require.NoError(t, dialConn.ReadJSON(&msg))
+		if msg.Type != "status_update" {
+			continue // the subscription's confirmation
+		}
+		assert.Equal(t, "env-D", msg.Payload["environmentId"])
+		assert.Equal(t, map[string]interface{}{"health": "degraded", "failedProbe": "worker"}, msg.Payload["status"])
+		return
+	}
+}
@@ -22,6 +22,7 @@ type Hub struct {
 // Client represents a WebSocket client connection
 type Client struct {
 	ID            string
+	Role          string // Role of the authenticated user, used to target notifications
 	conn          *websocket.Conn
 	send          chan Message
 	subscriptions map[string]bool
@@ -90,6 +91,19 @@ func (h *Hub) BroadcastEnvironmentUpdate(envID string, update map[string]interfa
 	h.broadcast <- message
 }
 
+// BroadcastEnvironmentStatus broadcasts an environment's new status, such as
+// after its health changed, as a status update carrying the whole status
+func (h *Hub) BroadcastEnvironmentStatus(envID string, status interface{}) {
+	message := Message{
+		Type: "status_update",
+		Payload: map[string]interface{}{
+			"environmentId": envID,
+			"status":        status,
+		},
+	}
+	h.broadcast <- message
+}
+
 // BroadcastOperationUpdate broadcasts an operation update
 func (h *Hub) BroadcastOperationUpdate(operationID string, update map[string]interface{}) {
 	message := Message{
@@ -102,6 +116,33 @@ func (h *Hub) BroadcastOperationUpdate(operationID string, update map[string]int
 	h.broadcast <- message
 }
 
+// BroadcastRolloutUpdate sends a rollout progress update to all clients
+func (h *Hub) BroadcastRolloutUpdate(rolloutID string, update map[string]interface{}) {
+	message := Message{
+		Type: "rollout_update",
+		Payload: map[string]interface{}{
+			"rolloutId": rolloutID,
+			"update":    update,
+		},
+	}
+	h.broadcast <- message
+}
+
+// BroadcastApprovalUpdate notifies users allowed to decide approval requests
+// about a new or changed request. Only clients whose role is listed in
+// approverRoles receive the message.
+func (h *Hub) BroadcastApprovalUpdate(approvalID string, approverRoles []string, update map[string]interface{}) {
+	message := Message{
+		Type: "approval_update",
+		Payload: map[string]interface{}{
+			"approvalId":    approvalID,
+			"approverRoles": approverRoles,
+			"update":        update,
+		},
+	}
+	h.broadcast <- message
+}
+
 // registerClient handles client registration
 func (h *Hub) registerClient(client *Client) {
 	h.mu.Lock()
@@ -148,6 +189,11 @@ func (h *Hub) broadcastToSubscribers(message Message) {
 			}
 		}
 
+		// Approval notifications are only sent to users who may decide them
+		if message.Type == "approval_update" && !client.hasAnyRole(message.Payload["approverRoles"]) {
+			continue
+		}
+
 		select {
 		case client.send <- message:
 		default:
@@ -309,6 +355,20 @@ func (c *Client) handleUnsubscribe(payload map[string]interface{}) {
 	}
 }
 
+// hasAnyRole reports whether the client's role is in the given role list
+func (c *Client) hasAnyRole(roles interface{}) bool {
+	list, ok := roles.([]string)
+	if !ok {
+		return false
+	}
+	for _, role := range list {
+		if role == c.Role {
+			return true
+		}
+	}
+	return false
+}
+
 // IsSubscribedTo checks if the client is subscribed to an environment
 func (c *Client) IsSubscribedTo(envID string) bool {
 	c.subMu.RLock()
@@ -24,6 +24,7 @@ import {
   CheckCircle,
   Error,
   Help,
+  ReportProblem,
   Upgrade,
   AccessTime,
   Link as LinkIcon,
@@ -51,6 +52,13 @@ const STATUS_CONFIG = {
     icon: <CheckCircle sx={{ color: '#34d399', fontSize: 22 }} />,
     chipColor: 'success' as const,
   },
+  [HealthStatus.Degraded]: {
+    color: '#fb923c',
+    border: '#fb923c',
+    bg: 'rgba(251,146,60,0.06)',
+    icon: <ReportProblem sx={{ color: '#fb923c', fontSize: 22 }} />,
+    chipColor: 'warning' as const,
+  },
   [HealthStatus.Unhealthy]: {
     color: '#f87171',
     border: '#f87171',
@@ -15,6 +15,7 @@ import {
   CheckCircleOutline,
   ErrorOutline,
   HelpOutline,
+  ReportProblemOutlined,
   LayersOutlined,
 } from '@mui/icons-material';
 import { useQuery } from '@tanstack/react-query';
@@ -113,8 +114,8 @@ export const Dashboard: React.FC = () => {
           </Box>
         </Box>
         <Grid container spacing={2.5} mb={4}>
-          {[1, 2, 3, 4].map((i) => (
-            <Grid item xs={12} sm={6} md={3} key={i}>
+          {[1, 2, 3, 4, 5].map((i) => (
+            <Grid item xs={12} sm={6} md key={i}>
               <Skeleton variant="rectangular" height={88} sx={{ borderRadius: '12px' }} />
             </Grid>
           ))}
@@ -142,6 +143,7 @@ export const Dashboard: React.FC = () => {
 
   const environments = data?.environments || [];
   const healthyCount   = environments.filter(e => e.status.health === 'healthy').length;
+  const degradedCount  = environments.filter(e => e.status.health === 'degraded').length;
   const unhealthyCount = environments.filter(e => e.status.health === 'unhealthy').length;
   const unknownCount   = environments.filter(e => e.status.health === 'unknown').length;
 
@@ -174,7 +176,7 @@ export const Dashboard: React.FC = () => {
 
       {/* Stats */}
       <Grid container spacing={2.5} mb={4}>
-        <Grid item xs={12} sm={6} md={3}>
+        <Grid item xs={12} sm={6} md>
           <StatCard
             value={environments.length}
             label="Total Environments"
@@ -182,7 +184,7 @@ export const Dashboard: React.FC = () => {
             color="#818cf8"
           />
         </Grid>
-        <Grid item xs={12} sm={6} md={3}>
+        <Grid item xs={12} sm={6} md>
           <StatCard
             value={healthyCount}
             label="Healthy"
@@ -190,7 +192,15 @@ export const Dashboard: React.FC = () => {
             color="#34d399"
           />
         </Grid>
-        <Grid item xs={12} sm={6} md={3}>
+        <Grid item xs={12} sm={6} md>
+          <StatCard
+            value={degradedCount}
+            label="Degraded"
+            icon={<ReportProblemOutlined fontSize="small" />}
+            color="#fb923c"
+          />
+        </Grid>
+        <Grid item xs={12} sm={6} md>
           <StatCard
             value={unhealthyCount}
             label="Unhealthy"
@@ -198,7 +208,7 @@ export const Dashboard: React.FC = () => {
             color="#f87171"
           />
         </Grid>
-        <Grid item xs={12} sm={6} md={3}>
+        <Grid item xs={12} sm={6} md>
           <StatCard
             value={unknownCount}
             label="Unknown"
@@ -24,6 +24,7 @@ import {
   CheckCircle,
   Error,
   Help,
+  ReportProblem,
   OpenInNew,
   InfoOutlined,
 } from '@mui/icons-material';
@@ -163,6 +164,8 @@ export const EnvironmentDetails: React.FC = () => {
   let healthIcon: React.ReactNode = <Help sx={{ color: 'warning.main' }} />;
   if (environment.status.health === HealthStatus.Healthy) {
     healthIcon = <CheckCircle sx={{ color: 'success.main' }} />;
+  } else if (environment.status.health === HealthStatus.Degraded) {
+    healthIcon = <ReportProblem sx={{ color: 'warning.main' }} />;
   } else if (environment.status.health === HealthStatus.Unhealthy) {
     healthIcon = <Error sx={{ color: 'error.main' }} />;
   }
@@ -78,15 +78,17 @@ describe('Dashboard page', () => {
   it('shows stat cards with counts', async () => {
     const unhealthyEnv = { ...mockEnvironment, id: 'env-2', status: { ...mockEnvironment.status, health: HealthStatus.Unhealthy } };
     const unknownEnv = { ...mockEnvironment, id: 'env-3', status: { ...mockEnvironment.status, health: HealthStatus.Unknown } };
+    const degradedEnv = { ...mockEnvironment, id: 'env-4', status: { ...mockEnvironment.status, health: HealthStatus.Degraded } };
     mockedEnvApi.list = vi.fn().mockResolvedValue({
-      environments: [mockEnvironment, unhealthyEnv, unknownEnv],
-      pagination: { page: 1, limit: 10, total: 3 },
+      environments: [mockEnvironment, unhealthyEnv, unknownEnv, degradedEnv],
+      pagination: { page: 1, limit: 10, total: 4 },
     });
 
     render(<Dashboard />);
     await waitFor(() => {
       expect(screen.getByText('Total Environments')).toBeInTheDocument();
       expect(screen.getByText('Healthy')).toBeInTheDocument();
+      expect(screen.getByText('Degraded')).toBeInTheDocument();
       expect(screen.getByText('Unhealthy')).toBeInTheDocument();
       expect(screen.getByText('Unknown')).toBeInTheDocument();
     });
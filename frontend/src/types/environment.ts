@@ -46,10 +46,25 @@ export interface Status {
   lastCheck: string;
   message: string;
   responseTime: number;
+  probes?: ProbeStatus[];
+  failedProbe?: string;
+  consecutiveFailures?: number;
+  consecutiveUnhealthy?: number;
+  consecutiveSuccesses?: number;
+  flapping?: boolean;
+}
+
+export interface ProbeStatus {
+  name: string;
+  criticality: string;
+  health: HealthStatus;
+  message: string;
+  responseTime: number;
 }
 
 export enum HealthStatus {
   Healthy = 'healthy',
+  Degraded = 'degraded',
   Unhealthy = 'unhealthy',
   Unknown = 'unknown',
 }